@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/http/pprof"
+	"net/url"
 	"os"
 	"os/signal"
 	"sort"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -25,6 +29,7 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics"
 	gokit_influx "github.com/go-kit/kit/metrics/influx"
+	"github.com/go-kit/kit/metrics/multi"
 	grpc_transport "github.com/go-kit/kit/transport/grpc"
 	flatbuffers "github.com/google/flatbuffers/go"
 	"github.com/gorilla/mux"
@@ -34,6 +39,7 @@ import (
 	"github.com/spf13/viper"
 	jaeger "github.com/uber/jaeger-client-go/config"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 var (
@@ -47,20 +53,23 @@ var (
 
 func main() {
 
-	// Logger.
-	var logger = log.NewJSONLogger(os.Stdout)
-	{
-		logger = log.With(logger, "ts", log.DefaultTimestampUTC, "caller", log.DefaultCaller)
-	}
+	// Command. "flakid healthcheck" runs the health checks once and exits, see runHealthCheck.
+	var healthCheckCommand = isHealthCheckCommand(os.Args[1:])
+
+	// Logger. With the healthcheck command, the standard output is reserved to the health report.
+	var logOutput = logWriter(healthCheckCommand, os.Stdout, os.Stderr)
+	var logger = newLogger(logOutput, nil, "")
 	defer logger.Log("msg", "goodbye")
 
 	// Configurations.
 	var config = config(log.With(logger, "unit", "config"))
 	var (
 		// Component
-		componentName = config["component-name"].(string)
-		grpcAddr      = config["component-grpc-host-port"].(string)
-		httpAddr      = config["component-http-host-port"].(string)
+		componentName   = config["component-name"].(string)
+		grpcAddr        = config["component-grpc-host-port"].(string)
+		httpAddr        = config["component-http-host-port"].(string)
+		adminAddr       = config["component-admin-http-host-port"].(string)
+		shutdownTimeout = time.Duration(config["component-shutdown-timeout-ms"].(int)) * time.Millisecond
 
 		// Flaki
 		flakiNodeID      = uint64(config["flaki-node-id"].(int))
@@ -71,6 +80,8 @@ func main() {
 		sentryEnabled     = config["sentry"].(bool)
 		redisEnabled      = config["redis"].(bool)
 		jaegerEnabled     = config["jaeger"].(bool)
+		smtpEnabled       = config["smtp"].(bool)
+		dockerEnabled     = config["docker"].(bool)
 		pprofRouteEnabled = config["pprof-route-enabled"].(bool)
 
 		// Influx
@@ -86,6 +97,7 @@ func main() {
 			WriteConsistency: config["influx-write-consistency"].(string),
 		}
 		influxWriteInterval = time.Duration(config["influx-write-interval-ms"].(int)) * time.Millisecond
+		influxBufferSize    = config["influx-write-buffer-size"].(int)
 
 		// Jaeger
 		jaegerConfig = jaeger.Configuration{
@@ -103,13 +115,113 @@ func main() {
 		jaegerCollectorHealthcheckURL = config["jaeger-collector-healthcheck-host-port"].(string)
 
 		// Sentry
-		sentryDSN = fmt.Sprintf(config["sentry-dsn"].(string))
+		sentryDSN         = fmt.Sprintf(config["sentry-dsn"].(string))
+		sentryProjectDSNs = config["sentry-projects-dsns"].([]string)
 
 		// Redis
 		redisURL           = config["redis-host-port"].(string)
 		redisPassword      = config["redis-password"].(string)
 		redisDatabase      = config["redis-database"].(int)
 		redisWriteInterval = time.Duration(config["redis-write-interval-ms"].(int)) * time.Millisecond
+		redisShardURLs     = config["redis-shards-host-ports"].([]string)
+
+		// SMTP
+		smtpAddr = config["smtp-host-port"].(string)
+		smtpTLS  = config["smtp-tls"].(bool)
+
+		// Docker
+		dockerSocket = config["docker-socket"].(string)
+
+		// Health
+		healthRequiredSubsystems = config["health-required-subsystems"].([]string)
+		healthKOWhenNil          = config["health-ko-when-not-configured"].([]string)
+		healthDependencies       = config["health-dependencies"].(map[string][]string)
+		healthSoftDependencies   = config["health-soft-dependencies"].([]string)
+		healthGroupErrors        = config["health-group-errors"].(bool)
+		healthSlowThreshold      = time.Duration(config["health-slow-threshold-ms"].(int)) * time.Millisecond
+		healthSlowThresholds     = config["health-subsystem-slow-thresholds-ms"].(map[string]int)
+		healthRunbookURLs        = config["health-runbook-urls"].(map[string]string)
+		healthTimeout            = time.Duration(config["health-timeout-ms"].(int)) * time.Millisecond
+		healthMaxTimeout         = time.Duration(config["health-max-timeout-ms"].(int)) * time.Millisecond
+		healthStartupTimeout     = time.Duration(config["health-startup-timeout-ms"].(int)) * time.Millisecond
+		healthStartupInterval    = time.Duration(config["health-startup-poll-interval-ms"].(int)) * time.Millisecond
+		healthOmitDeactivated    = config["health-omit-deactivated"].(bool)
+		healthInstanceHostname   = config["health-instance-hostname"].(bool)
+		healthInstanceID         = config["health-instance-id"].(string)
+		healthAllDeactivated     = config["health-all-deactivated-status"].(string)
+		healthSentryMaxBodySize  = int64(config["health-sentry-max-body-bytes"].(int))
+		healthSentrySnippetSize  = config["health-sentry-response-snippet-bytes"].(int)
+		healthInfluxWriteCheck   = config["health-influx-write-check"].(bool)
+		healthInfluxWriteEvery   = time.Duration(config["health-influx-write-check-interval-ms"].(int)) * time.Millisecond
+		healthTraceCached        = config["health-trace-cached"].(bool)
+		healthInfluxRetentionMin = time.Duration(config["health-influx-retention-min-hours"].(int)) * time.Hour
+		healthInfluxV2Org        = config["health-influx-v2-org"].(string)
+		healthInfluxV2Bucket     = config["health-influx-v2-bucket"].(string)
+		healthInfluxV2Token      = config["health-influx-v2-token"].(string)
+		healthInfluxFlushStale   = time.Duration(config["health-influx-flush-staleness-ms"].(int)) * time.Millisecond
+		healthRedisClusterCheck  = config["health-redis-cluster-check"].(bool)
+		healthRedisReplicas      = config["health-redis-cluster-replicas"].(int)
+		healthRedisAuthCheck     = config["health-redis-auth-check"].(bool)
+		healthRedisAuthUser      = config["health-redis-auth-user"].(string)
+		healthRedisMinHealthy    = config["health-redis-min-healthy-shards"].(int)
+		healthRedisPersistence   = config["health-redis-persistence-check"].(bool)
+		healthRedisMaxSaveAge    = time.Duration(config["health-redis-persistence-max-save-age-ms"].(int)) * time.Millisecond
+		healthStreamInterval     = time.Duration(config["health-stream-poll-interval-ms"].(int)) * time.Millisecond
+		healthStreamKeepAlive    = time.Duration(config["health-stream-keepalive-ms"].(int)) * time.Millisecond
+		healthStreamIntervals    = config["health-stream-subsystem-intervals-ms"].(map[string]int)
+		healthShutdownDrain      = time.Duration(config["health-shutdown-drain-ms"].(int)) * time.Millisecond
+		healthGzip               = config["health-gzip"].(bool)
+		healthGzipMinSize        = config["health-gzip-min-size"].(int)
+		healthTLSCertFile        = config["health-tls-cert-file"].(string)
+		healthTLSKeyFile         = config["health-tls-key-file"].(string)
+		healthTLSCAFile          = config["health-tls-ca-file"].(string)
+		healthHTTPProxy          = config["health-http-proxy"].(string)
+		healthLogSinkCheck       = config["health-log-sink-check"].(bool)
+		healthMaxConcurrent      = config["health-max-concurrent-checks"].(int)
+		healthE2ECheck           = config["health-e2e-check"].(bool)
+		healthGRPCTarget         = config["health-grpc-reflection-target"].(string)
+		healthEgressCanaryURL    = config["health-egress-canary-url"].(string)
+		healthFDCheck            = config["health-fd-check"].(bool)
+		healthFDWarnRatio        = config["health-fd-warn-ratio"].(float64)
+		healthFDCritRatio        = config["health-fd-crit-ratio"].(float64)
+		healthIDGenCheck         = config["health-idgen-check"].(bool)
+		healthIDGenEpoch         = config["health-idgen-epoch"].(string)
+		healthIDGenBits          = uint(config["health-idgen-timestamp-bits"].(int))
+		healthIDGenHorizon       = time.Duration(config["health-idgen-overflow-horizon-days"].(int)) * 24 * time.Hour
+		healthIDGenClockCheck    = config["health-idgen-clock-check"].(bool)
+		healthIDGenClockDrift    = time.Duration(config["health-idgen-clock-max-drift-ms"].(int)) * time.Millisecond
+		healthFeatureFlagURL     = config["health-featureflag-url"].(string)
+		healthCommandName        = config["health-command-name"].(string)
+		healthCommandArgv        = config["health-command-argv"].([]string)
+		healthSelfCheck          = config["health-self-check"].(bool)
+		healthSelfRequestPath    = config["health-self-request-path"].(string)
+		healthAuditLogDir        = config["health-auditlog-dir"].(string)
+		healthAuditLogRetention  = time.Duration(config["health-auditlog-min-retention-hours"].(int)) * time.Hour
+		healthOptionsRoute       = config["health-options-route-enabled"].(bool)
+		healthStartupGracePeriod = time.Duration(config["health-startup-grace-period-ms"].(int)) * time.Millisecond
+		healthAdaptiveFactor     = config["health-adaptive-timeout-factor"].(float64)
+		healthAdaptiveMin        = time.Duration(config["health-adaptive-timeout-min-ms"].(int)) * time.Millisecond
+		healthAdaptiveMax        = time.Duration(config["health-adaptive-timeout-max-ms"].(int)) * time.Millisecond
+		healthJaegerAgent        = config["health-jaeger-agent-host-port"].(string)
+		healthJaegerAgentMetrics = config["health-jaeger-agent-metrics-url"].(string)
+		healthJaegerSampling     = config["health-jaeger-sampling-check"].(bool)
+		healthCriticalSubsystems = config["health-critical-subsystems"].([]string)
+		healthDeadlineMargin     = time.Duration(config["health-deadline-margin-ms"].(int)) * time.Millisecond
+		healthSentryUsername     = config["health-sentry-username"].(string)
+		healthSentryPassword     = config["health-sentry-password"].(string)
+		healthSentryToken        = config["health-sentry-token"].(string)
+		healthJaegerUsername     = config["health-jaeger-collector-username"].(string)
+		healthJaegerPassword     = config["health-jaeger-collector-password"].(string)
+		healthJaegerToken        = config["health-jaeger-collector-token"].(string)
+		healthLatencyBuckets     = config["health-latency-buckets-seconds"].([]float64)
+		healthStatsDHostPort     = config["health-statsd-host-port"].(string)
+		healthStatsDPrefix       = config["health-statsd-prefix"].(string)
+		healthStatsDInterval     = time.Duration(config["health-statsd-send-interval-ms"].(int)) * time.Millisecond
+		healthSentryQuotaCheck   = config["health-sentry-quota-check"].(bool)
+		healthSentryQuotaEvery   = time.Duration(config["health-sentry-quota-check-interval-ms"].(int)) * time.Millisecond
+		healthSentryTagsCheck    = config["health-sentry-tags-check"].(bool)
+		healthHistorySize        = config["health-history-size"].(int)
+		healthUptimeDegraded     = config["health-uptime-counts-degraded"].(bool)
 	)
 
 	// Redis.
@@ -130,26 +242,48 @@ func main() {
 		}
 		defer redisClient.Close()
 
-		// Create logger that duplicates logs to stdout and Redis.
-		logger = log.NewJSONLogger(io.MultiWriter(os.Stdout, flakid.NewLogstashRedisWriter(redisClient, componentName)))
-		logger = log.With(logger, "ts", log.DefaultTimestampUTC, "caller", log.DefaultCaller)
+		// Create logger that duplicates logs to the log output and Redis.
+		logger = newLogger(logOutput, redisClient, componentName)
 	} else {
 		redisClient = &flakid.NoopRedis{}
 	}
 
+	// Redis of the health checks. The redis client is not safe for concurrent use and the logs already use it,
+	// so the health checks, that run concurrently, execute their commands on the connections of a pool.
+	var redisHealth health.Redis = redisClient
+	if redisEnabled {
+		var pool = newRedisPool(redisURL, redisDatabase, redisPassword)
+		defer pool.Close()
+		redisHealth = health.NewRedisPool(pool)
+	}
+
+	// Redis shards, monitored by the health checks.
+	var redisShards = []health.Redis{}
+	if redisEnabled {
+		for _, url := range redisShardURLs {
+			var pool = newRedisPool(url, redisDatabase, redisPassword)
+			var conn = pool.Get()
+			var err = conn.Err()
+			conn.Close()
+			if err != nil {
+				logger.Log("msg", "could not create redis shard client", "shard", url, "error", err)
+				return
+			}
+			defer pool.Close()
+			redisShards = append(redisShards, health.NewRedisPool(pool))
+		}
+	}
+
 	// Add component name and version to the logger tags.
 	logger = log.With(logger, "component_name", componentName, "component_version", Version)
 
 	// Log component version infos.
 	logger.Log("environment", Environment, "git_commit", GitCommit)
 
-	// Critical errors channel.
+	// Critical errors channel. The signals are handled once the health component exists, see below.
 	var errc = make(chan error)
-	go func() {
-		var c = make(chan os.Signal, 1)
-		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-		errc <- fmt.Errorf("%s", <-c)
-	}()
+	var sigc = make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
 
 	// Flaki unique distributed ID generator.
 	var flakiGen *flaki_gen.Flaki
@@ -184,6 +318,20 @@ func main() {
 		sentryClient = &flakid.NoopSentry{}
 	}
 
+	// Sentry projects, monitored by the health checks. They are monitored even if the errors of the service are
+	// not reported to sentry, i.e. without sentry-dsn.
+	var sentryProjects = []health.Sentry{}
+	for _, dsn := range sentryProjectDSNs {
+		var project, err = sentry.New(dsn)
+		if err != nil {
+			logger.Log("msg", "could not create sentry project client", "error", err)
+			return
+		}
+		defer project.Close()
+		sentryProjects = append(sentryProjects, project)
+	}
+	var sentryHealthEnabled = sentryEnabled || len(sentryProjects) > 0
+
 	// Influx client.
 	type Metrics interface {
 		NewCounter(name string) metrics.Counter
@@ -191,6 +339,8 @@ func main() {
 		NewHistogram(name string) metrics.Histogram
 		WriteLoop(c <-chan time.Time)
 		Ping(timeout time.Duration) (time.Duration, string, error)
+		BufferDepth() (int, int)
+		LastFlush() time.Time
 	}
 
 	var influxMetrics Metrics
@@ -210,7 +360,11 @@ func main() {
 			log.With(logger, "unit", "go-kit influx"),
 		)
 
-		influxMetrics = flakid.NewMetrics(influxClient, gokitInflux)
+		if influxBufferSize > 0 {
+			influxMetrics = flakid.NewBufferedMetrics(influxClient, gokitInflux, influxBufferSize, logger)
+		} else {
+			influxMetrics = flakid.NewMetrics(influxClient, gokitInflux)
+		}
 	} else {
 		influxMetrics = &flakid.NoopMetrics{}
 	}
@@ -286,24 +440,320 @@ func main() {
 	// Health service.
 	var healthLogger = log.With(logger, "svc", "health")
 
+	// The latency histogram of the health checks is exported for the Prometheus scrapes.
+	var healthLatency = health.NewLatencyHistogram("health_check_latency_seconds", healthLatencyBuckets...)
+
+	// If a StatsD server is configured, the durations of the health checks and the status of each subsystem
+	// are also sent to it.
+	var healthDurations metrics.Histogram = healthLatency
+	var healthStatsD *health.StatsDSink
+	if healthStatsDHostPort != "" {
+		healthStatsD = health.NewStatsDSink(healthStatsDPrefix, log.With(logger, "unit", "statsd"))
+		healthDurations = multi.NewHistogram(healthLatency, healthStatsD.DurationHistogram())
+	}
+
 	var healthComponent health.Component
 	{
-		var influxHM = health.NewInfluxModule(influxMetrics, influxEnabled)
+		var healthChecksCounter = influxMetrics.NewCounter("checks_total")
+		var healthFailuresCounter = influxMetrics.NewCounter("checks_failed_total")
+
+		// The HTTP-based health checks share a client that does not follow the redirects, it authenticates
+		// with a client certificate if one is configured.
+		var healthHTTPClient = health.NewHTTPClient()
+		if healthTLSCertFile != "" {
+			var err error
+			healthHTTPClient, err = health.NewMTLSHTTPClient(healthTLSCertFile, healthTLSKeyFile, healthTLSCAFile)
+			if err != nil {
+				healthLogger.Log("msg", "could not create health checks mTLS client", "error", err)
+				return
+			}
+		}
+		if healthHTTPProxy != "" {
+			var proxyURL, err = url.Parse(healthHTTPProxy)
+			if err == nil {
+				err = health.SetProxy(healthHTTPClient, proxyURL)
+			}
+			if err != nil {
+				healthLogger.Log("msg", "could not set health checks proxy", "error", err)
+				return
+			}
+		}
+
+		var influxOptions = []health.InfluxOption{}
+		if healthInfluxWriteCheck {
+			influxOptions = append(influxOptions, health.InfluxWriteCheck(healthHTTPClient, influxHTTPConfig.Addr, influxBatchPointsConfig.Database, influxHTTPConfig.Username, influxHTTPConfig.Password))
+		}
+		if healthInfluxWriteEvery > 0 {
+			influxOptions = append(influxOptions, health.InfluxCheckInterval("write", healthInfluxWriteEvery))
+		}
+		if healthInfluxRetentionMin > 0 {
+			influxOptions = append(influxOptions, health.InfluxRetentionCheck(healthHTTPClient, influxHTTPConfig.Addr, influxBatchPointsConfig.Database, influxBatchPointsConfig.RetentionPolicy, influxHTTPConfig.Username, influxHTTPConfig.Password, healthInfluxRetentionMin))
+		}
+		if healthInfluxV2Token != "" {
+			influxOptions = append(influxOptions, health.InfluxV2(healthHTTPClient, influxHTTPConfig.Addr, healthInfluxV2Org, healthInfluxV2Bucket, healthInfluxV2Token))
+		}
+		if influxBufferSize > 0 {
+			influxOptions = append(influxOptions, health.InfluxBufferCheck(influxMetrics))
+		}
+		if healthInfluxFlushStale > 0 {
+			influxOptions = append(influxOptions, health.InfluxFlushCheck(influxMetrics, healthInfluxFlushStale))
+		}
+		if healthAdaptiveFactor > 0 {
+			influxOptions = append(influxOptions, health.InfluxAdaptiveTimeout(healthAdaptiveFactor, healthAdaptiveMin, healthAdaptiveMax))
+		}
+		var influxHM = health.NewInfluxModule(influxMetrics, influxEnabled, influxOptions...)
+		influxHM = health.MakeInfluxModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(influxHM)
 		influxHM = health.MakeInfluxModuleLoggingMW(log.With(healthLogger, "mw", "module"))(influxHM)
+		influxHM = health.MakeInfluxModuleTracingMW(tracer, health.CachedSpans(healthTraceCached))(influxHM)
 
-		var jaegerHM = health.NewJaegerModule(systemDConn, http.DefaultClient, jaegerCollectorHealthcheckURL, jaegerEnabled)
+		var jaegerOptions = []health.JaegerOption{}
+		if healthJaegerAgent != "" {
+			jaegerOptions = append(jaegerOptions, health.JaegerAgentUDPCheck(healthJaegerAgent, healthJaegerAgentMetrics))
+		}
+		if healthJaegerSampling {
+			jaegerOptions = append(jaegerOptions, health.JaegerSamplingCheck(jaegerConfig.Sampler.SamplingServerURL, componentName, jaegerConfig.Sampler.Param))
+		}
+		switch {
+		case healthJaegerToken != "":
+			jaegerOptions = append(jaegerOptions, health.JaegerBearerToken(healthJaegerToken))
+		case healthJaegerUsername != "":
+			jaegerOptions = append(jaegerOptions, health.JaegerBasicAuth(healthJaegerUsername, healthJaegerPassword))
+		}
+		var jaegerHM = health.NewJaegerModule(systemDConn, healthHTTPClient, jaegerCollectorHealthcheckURL, jaegerEnabled, jaegerOptions...)
+		jaegerHM = health.MakeJaegerModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(jaegerHM)
 		jaegerHM = health.MakeJaegerModuleLoggingMW(log.With(healthLogger, "mw", "module"))(jaegerHM)
+		jaegerHM = health.MakeJaegerModuleTracingMW(tracer)(jaegerHM)
 
-		var redisHM = health.NewRedisModule(redisClient, redisEnabled)
+		var redisOptions = []health.RedisOption{}
+		if healthRedisClusterCheck {
+			redisOptions = append(redisOptions, health.RedisClusterCheck(healthRedisReplicas))
+		}
+		if healthRedisAuthCheck {
+			redisOptions = append(redisOptions, health.RedisAuthCheck(healthRedisAuthUser))
+		}
+		if healthRedisPersistence {
+			redisOptions = append(redisOptions, health.RedisPersistenceCheck(healthRedisMaxSaveAge))
+		}
+		var redisHM = health.NewRedisModule(redisHealth, redisEnabled, redisOptions...)
+		if len(redisShards) > 0 {
+			if healthRedisMinHealthy != 0 {
+				redisOptions = append(redisOptions, health.RedisMinHealthyShards(healthRedisMinHealthy))
+			}
+			redisHM = health.NewRedisShardsModule(redisShards, redisEnabled, redisOptions...)
+		}
+		redisHM = health.MakeRedisModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(redisHM)
 		redisHM = health.MakeRedisModuleLoggingMW(log.With(healthLogger, "mw", "module"))(redisHM)
+		redisHM = health.MakeRedisModuleTracingMW(tracer)(redisHM)
 
-		var sentryHM = health.NewSentryModule(sentryClient, http.DefaultClient, sentryEnabled)
+		var sentryOptions = []health.SentryOption{health.SentryMaxBodySize(healthSentryMaxBodySize), health.SentryResponseSnippet(healthSentrySnippetSize)}
+		if healthAdaptiveFactor > 0 {
+			sentryOptions = append(sentryOptions, health.SentryAdaptiveTimeout(healthAdaptiveFactor, healthAdaptiveMin, healthAdaptiveMax))
+		}
+		switch {
+		case healthSentryToken != "":
+			sentryOptions = append(sentryOptions, health.SentryBearerToken(healthSentryToken))
+		case healthSentryUsername != "":
+			sentryOptions = append(sentryOptions, health.SentryBasicAuth(healthSentryUsername, healthSentryPassword))
+		}
+		if healthSentryQuotaCheck && sentryHealthEnabled {
+			// The public key is the user of the DSN. With projects, the quota of the first one is checked.
+			var dsn = sentryDSN
+			if len(sentryProjectDSNs) > 0 {
+				dsn = sentryProjectDSNs[0]
+			}
+			var u, err = url.Parse(dsn)
+			if err != nil || u.User == nil {
+				logger.Log("msg", "could not get the public key of the sentry DSN", "error", err)
+				return
+			}
+			sentryOptions = append(sentryOptions, health.SentryQuotaCheck(u.User.Username(), healthSentryQuotaEvery))
+		}
+		if healthSentryTagsCheck && sentryEnabled {
+			// The release and the environment are read from the client reporting the errors.
+			if tags, ok := sentryClient.(health.SentryTags); ok {
+				sentryOptions = append(sentryOptions, health.SentryTagsCheck(tags))
+			}
+		}
+		var sentryHM = health.NewSentryModule(sentryClient, healthHTTPClient, sentryEnabled, sentryOptions...)
+		if len(sentryProjects) > 0 {
+			sentryHM = health.NewSentryProjectsModule(sentryProjects, healthHTTPClient, sentryHealthEnabled, sentryOptions...)
+		}
+		sentryHM = health.MakeSentryModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(sentryHM)
 		sentryHM = health.MakeSentryModuleLoggingMW(log.With(healthLogger, "mw", "module"))(sentryHM)
+		sentryHM = health.MakeSentryModuleTracingMW(tracer)(sentryHM)
+
+		var smtpHM = health.NewSMTPModule(smtpAddr, smtpTLS, smtpEnabled)
+		smtpHM = health.MakeSMTPModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(smtpHM)
+		smtpHM = health.MakeSMTPModuleLoggingMW(log.With(healthLogger, "mw", "module"))(smtpHM)
+		smtpHM = health.MakeSMTPModuleTracingMW(tracer)(smtpHM)
+
+		// The docker daemon is reached over its unix socket.
+		var dockerClient = &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", dockerSocket)
+				},
+			},
+			CheckRedirect: health.NoRedirect,
+		}
+		var dockerHM = health.NewDockerModule(dockerClient, dockerEnabled)
+		dockerHM = health.MakeDockerModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(dockerHM)
+		dockerHM = health.MakeDockerModuleLoggingMW(log.With(healthLogger, "mw", "module"))(dockerHM)
+		dockerHM = health.MakeDockerModuleTracingMW(tracer)(dockerHM)
+
+		// The logs are written to the log output.
+		var logSinkHM = health.NewLogSinkModule(logOutput, healthLogSinkCheck)
+		logSinkHM = health.MakeLogSinkModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(logSinkHM)
+		logSinkHM = health.MakeLogSinkModuleLoggingMW(log.With(healthLogger, "mw", "module"))(logSinkHM)
+		logSinkHM = health.MakeLogSinkModuleTracingMW(tracer)(logSinkHM)
+
+		// The end-to-end check stores the generated IDs in redis.
+		var e2eHM = health.NewE2EModule(flakiModule, redisHealth, healthE2ECheck && redisEnabled)
+		e2eHM = health.MakeE2EModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(e2eHM)
+		e2eHM = health.MakeE2EModuleLoggingMW(log.With(healthLogger, "mw", "module"))(e2eHM)
+		e2eHM = health.MakeE2EModuleTracingMW(tracer)(e2eHM)
+
+		var grpcReflectionHM = health.NewGRPCReflectionModule(healthGRPCTarget, healthGRPCTarget != "")
+		grpcReflectionHM = health.MakeGRPCReflectionModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(grpcReflectionHM)
+		grpcReflectionHM = health.MakeGRPCReflectionModuleLoggingMW(log.With(healthLogger, "mw", "module"))(grpcReflectionHM)
+		grpcReflectionHM = health.MakeGRPCReflectionModuleTracingMW(tracer)(grpcReflectionHM)
+
+		var egressHM = health.NewEgressModule(healthEgressCanaryURL, healthHTTPClient, healthEgressCanaryURL != "")
+		egressHM = health.MakeEgressModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(egressHM)
+		egressHM = health.MakeEgressModuleLoggingMW(log.With(healthLogger, "mw", "module"))(egressHM)
+		egressHM = health.MakeEgressModuleTracingMW(tracer)(egressHM)
+
+		var fdHM = health.NewFDModule(healthFDWarnRatio, healthFDCritRatio, healthFDCheck)
+		fdHM = health.MakeFDModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(fdHM)
+		fdHM = health.MakeFDModuleLoggingMW(log.With(healthLogger, "mw", "module"))(fdHM)
+		fdHM = health.MakeFDModuleTracingMW(tracer)(fdHM)
+
+		var idGenEpoch time.Time
+		{
+			var err error
+			idGenEpoch, err = time.Parse(time.RFC3339, healthIDGenEpoch)
+			if err != nil {
+				logger.Log("msg", "could not parse the ID generator epoch", "error", err)
+				return
+			}
+		}
+		var idGenOptions = []health.IDGenOption{}
+		if healthIDGenClockCheck {
+			idGenOptions = append(idGenOptions, health.IDGenClockCheck(health.NewIDGenSystemClock(), healthIDGenClockDrift))
+		}
+		var idGenHM = health.NewIDGenModule(idGenEpoch, healthIDGenBits, healthIDGenHorizon, healthIDGenCheck, idGenOptions...)
+		idGenHM = health.MakeIDGenModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(idGenHM)
+		idGenHM = health.MakeIDGenModuleLoggingMW(log.With(healthLogger, "mw", "module"))(idGenHM)
+		idGenHM = health.MakeIDGenModuleTracingMW(tracer)(idGenHM)
+
+		var featureFlagHM = health.NewFeatureFlagModule(healthHTTPClient, healthFeatureFlagURL, healthFeatureFlagURL != "")
+		featureFlagHM = health.MakeFeatureFlagModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(featureFlagHM)
+		featureFlagHM = health.MakeFeatureFlagModuleLoggingMW(log.With(healthLogger, "mw", "module"))(featureFlagHM)
+		featureFlagHM = health.MakeFeatureFlagModuleTracingMW(tracer)(featureFlagHM)
+
+		var commandHM = health.NewCommandModule(healthCommandName, healthCommandArgv, len(healthCommandArgv) > 0)
+		commandHM = health.MakeCommandModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(commandHM)
+		commandHM = health.MakeCommandModuleLoggingMW(log.With(healthLogger, "mw", "module"))(commandHM)
+		commandHM = health.MakeCommandModuleTracingMW(tracer)(commandHM)
+
+		// The listener is reached directly, without the proxy nor the client certificate of the other checks.
+		var selfOptions = []health.SelfOption{}
+		if healthSelfRequestPath != "" {
+			selfOptions = append(selfOptions, health.SelfRequest(health.NewHTTPClient(), healthSelfRequestPath))
+		}
+		var selfHM = health.NewSelfModule(httpAddr, healthSelfCheck, selfOptions...)
+		selfHM = health.MakeSelfModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(selfHM)
+		selfHM = health.MakeSelfModuleLoggingMW(log.With(healthLogger, "mw", "module"))(selfHM)
+		selfHM = health.MakeSelfModuleTracingMW(tracer)(selfHM)
+
+		var auditLogHM = health.NewAuditLogModule(healthAuditLogDir, healthAuditLogRetention, healthAuditLogDir != "")
+		auditLogHM = health.MakeAuditLogModuleInstrumentingMW(healthChecksCounter, healthFailuresCounter, healthDurations)(auditLogHM)
+		auditLogHM = health.MakeAuditLogModuleLoggingMW(log.With(healthLogger, "mw", "module"))(auditLogHM)
+		auditLogHM = health.MakeAuditLogModuleTracingMW(tracer)(auditLogHM)
+
+		var healthOptions = []health.ComponentOption{
+			health.WithSMTPModule(smtpHM),
+			health.WithDockerModule(dockerHM),
+			health.WithLogSinkModule(logSinkHM),
+			health.WithE2EModule(e2eHM),
+			health.WithGRPCReflectionModule(grpcReflectionHM),
+			health.WithEgressModule(egressHM),
+			health.WithFDModule(fdHM),
+			health.WithIDGenModule(idGenHM),
+			health.WithFeatureFlagModule(featureFlagHM),
+			health.WithCommandModule(commandHM),
+			health.WithSelfModule(selfHM),
+			health.WithAuditLogModule(auditLogHM),
+			health.RequiredSubsystems(healthRequiredSubsystems...),
+			health.NilModuleStatus(health.KO, healthKOWhenNil...),
+			health.Dependencies(healthDependencies),
+			health.SoftDependencies(healthSoftDependencies...),
+			health.StartupGracePeriod(startupGracePeriod(healthStartupGracePeriod, healthCheckCommand)),
+		}
+		if healthGroupErrors {
+			healthOptions = append(healthOptions, health.GroupErrors())
+		}
+		if healthSlowThreshold > 0 {
+			healthOptions = append(healthOptions, health.SlowThreshold(healthSlowThreshold))
+		}
+		for name, ms := range healthSlowThresholds {
+			healthOptions = append(healthOptions, health.SubsystemSlowThreshold(name, time.Duration(ms)*time.Millisecond))
+		}
+		if len(healthRunbookURLs) > 0 {
+			healthOptions = append(healthOptions, health.RunbookURLs(healthRunbookURLs))
+		}
+		if healthMaxConcurrent > 0 {
+			healthOptions = append(healthOptions, health.MaxConcurrentChecks(healthMaxConcurrent))
+		}
+		if len(healthCriticalSubsystems) > 0 {
+			healthOptions = append(healthOptions, health.PriorityOrder(healthDeadlineMargin, healthCriticalSubsystems...))
+		}
+		if healthHistorySize > 0 {
+			healthOptions = append(healthOptions, health.History(healthHistorySize))
+		}
+		if healthUptimeDegraded {
+			healthOptions = append(healthOptions, health.UptimeCountsDegraded())
+		}
+		if healthStatsD != nil {
+			healthOptions = append(healthOptions, health.StatusGauge(healthStatsD.StatusGauge()))
+		}
+
+		// The overall status when all the subsystems are deactivated, the invalid values are rejected by
+		// the component.
+		{
+			var s = health.KO
+			for _, valid := range []health.Status{health.OK, health.Deactivated} {
+				if valid.String() == healthAllDeactivated {
+					s = valid
+				}
+			}
+			healthOptions = append(healthOptions, health.AllDeactivatedStatus(s))
+		}
 
-		healthComponent = health.NewComponent(influxHM, jaegerHM, redisHM, sentryHM)
+		var err error
+		healthComponent, err = health.NewComponent(influxHM, jaegerHM, redisHM, sentryHM, healthOptions...)
+		if err != nil {
+			logger.Log("msg", "could not create health component", "error", err)
+			return
+		}
 		healthComponent = health.MakeComponentLoggingMW(log.With(healthLogger, "mw", "component"))(healthComponent)
 	}
 
+	// One-shot health check, for debugging from a shell and for the smoke tests.
+	if healthCheckCommand {
+		os.Exit(runHealthCheck(healthComponent, flakiModule, healthTimeout, os.Stdout, health.OmitDeactivated(healthOmitDeactivated)))
+	}
+
+	// On a signal, the readiness flips to KO so the load balancers drain the instance, and the service stops
+	// after the drain period. The liveness stays OK in the meantime.
+	go func() {
+		var sig = <-sigc
+		healthComponent.BeginShutdown()
+		time.Sleep(healthShutdownDrain)
+		errc <- fmt.Errorf("%s", sig)
+	}()
+
 	var influxHealthEndpoint endpoint.Endpoint
 	{
 		influxHealthEndpoint = health.MakeInfluxHealthCheckEndpoint(healthComponent)
@@ -328,19 +778,148 @@ func main() {
 		sentryHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "SentryHealthCheck"))(sentryHealthEndpoint)
 		sentryHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(sentryHealthEndpoint)
 	}
+	var smtpHealthEndpoint endpoint.Endpoint
+	{
+		smtpHealthEndpoint = health.MakeSMTPHealthCheckEndpoint(healthComponent)
+		smtpHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "SMTPHealthCheck"))(smtpHealthEndpoint)
+		smtpHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(smtpHealthEndpoint)
+	}
+	var dockerHealthEndpoint endpoint.Endpoint
+	{
+		dockerHealthEndpoint = health.MakeDockerHealthCheckEndpoint(healthComponent)
+		dockerHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "DockerHealthCheck"))(dockerHealthEndpoint)
+		dockerHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(dockerHealthEndpoint)
+	}
+	var logSinkHealthEndpoint endpoint.Endpoint
+	{
+		logSinkHealthEndpoint = health.MakeLogSinkHealthCheckEndpoint(healthComponent)
+		logSinkHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "LogSinkHealthCheck"))(logSinkHealthEndpoint)
+		logSinkHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(logSinkHealthEndpoint)
+	}
+	var e2eHealthEndpoint endpoint.Endpoint
+	{
+		e2eHealthEndpoint = health.MakeE2EHealthCheckEndpoint(healthComponent)
+		e2eHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "E2EHealthCheck"))(e2eHealthEndpoint)
+		e2eHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(e2eHealthEndpoint)
+	}
+	var grpcReflectionHealthEndpoint endpoint.Endpoint
+	{
+		grpcReflectionHealthEndpoint = health.MakeGRPCReflectionHealthCheckEndpoint(healthComponent)
+		grpcReflectionHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "GRPCReflectionHealthCheck"))(grpcReflectionHealthEndpoint)
+		grpcReflectionHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(grpcReflectionHealthEndpoint)
+	}
+	var egressHealthEndpoint endpoint.Endpoint
+	{
+		egressHealthEndpoint = health.MakeEgressHealthCheckEndpoint(healthComponent)
+		egressHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "EgressHealthCheck"))(egressHealthEndpoint)
+		egressHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(egressHealthEndpoint)
+	}
+	var fdHealthEndpoint endpoint.Endpoint
+	{
+		fdHealthEndpoint = health.MakeFDHealthCheckEndpoint(healthComponent)
+		fdHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "FDHealthCheck"))(fdHealthEndpoint)
+		fdHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(fdHealthEndpoint)
+	}
+	var idGenHealthEndpoint endpoint.Endpoint
+	{
+		idGenHealthEndpoint = health.MakeIDGenHealthCheckEndpoint(healthComponent)
+		idGenHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "IDGenHealthCheck"))(idGenHealthEndpoint)
+		idGenHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(idGenHealthEndpoint)
+	}
+	var featureFlagHealthEndpoint endpoint.Endpoint
+	{
+		featureFlagHealthEndpoint = health.MakeFeatureFlagHealthCheckEndpoint(healthComponent)
+		featureFlagHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "FeatureFlagHealthCheck"))(featureFlagHealthEndpoint)
+		featureFlagHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(featureFlagHealthEndpoint)
+	}
+	var commandHealthEndpoint endpoint.Endpoint
+	{
+		commandHealthEndpoint = health.MakeCommandHealthCheckEndpoint(healthComponent)
+		commandHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "CommandHealthCheck"))(commandHealthEndpoint)
+		commandHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(commandHealthEndpoint)
+	}
+	var selfHealthEndpoint endpoint.Endpoint
+	{
+		selfHealthEndpoint = health.MakeSelfHealthCheckEndpoint(healthComponent)
+		selfHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "SelfHealthCheck"))(selfHealthEndpoint)
+		selfHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(selfHealthEndpoint)
+	}
+	var auditLogHealthEndpoint endpoint.Endpoint
+	{
+		auditLogHealthEndpoint = health.MakeAuditLogHealthCheckEndpoint(healthComponent)
+		auditLogHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "AuditLogHealthCheck"))(auditLogHealthEndpoint)
+		auditLogHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(auditLogHealthEndpoint)
+	}
 	var allHealthEndpoint endpoint.Endpoint
 	{
 		allHealthEndpoint = health.MakeAllHealthChecksEndpoint(healthComponent)
 		allHealthEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "AllHealthCheck"))(allHealthEndpoint)
 		allHealthEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(allHealthEndpoint)
 	}
+	var allHealthDetailedEndpoint endpoint.Endpoint
+	{
+		allHealthDetailedEndpoint = health.MakeAllHealthChecksDetailedEndpoint(healthComponent)
+		allHealthDetailedEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "AllHealthChecksDetailed"))(allHealthDetailedEndpoint)
+		allHealthDetailedEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(allHealthDetailedEndpoint)
+	}
+	var healthOptionsEndpoint endpoint.Endpoint
+	{
+		healthOptionsEndpoint = health.MakeOptionsEndpoint(healthComponent)
+		healthOptionsEndpoint = health.MakeEndpointLoggingMW(log.With(healthLogger, "mw", "endpoint", "unit", "Options"))(healthOptionsEndpoint)
+		healthOptionsEndpoint = health.MakeEndpointCorrelationIDMW(flakiModule)(healthOptionsEndpoint)
+	}
 
 	var healthEndpoints = health.Endpoints{
 		InfluxHealthCheck: influxHealthEndpoint,
 		JaegerHealthCheck: jaegerHealthEndpoint,
 		RedisHealthCheck:  redisHealthEndpoint,
 		SentryHealthCheck: sentryHealthEndpoint,
+		SMTPHealthCheck:   smtpHealthEndpoint,
+		DockerHealthCheck: dockerHealthEndpoint,
 		AllHealthChecks:   allHealthEndpoint,
+
+		LogSinkHealthCheck:        logSinkHealthEndpoint,
+		E2EHealthCheck:            e2eHealthEndpoint,
+		GRPCReflectionHealthCheck: grpcReflectionHealthEndpoint,
+		EgressHealthCheck:         egressHealthEndpoint,
+		FDHealthCheck:             fdHealthEndpoint,
+		IDGenHealthCheck:          idGenHealthEndpoint,
+		FeatureFlagHealthCheck:    featureFlagHealthEndpoint,
+		CommandHealthCheck:        commandHealthEndpoint,
+		SelfHealthCheck:           selfHealthEndpoint,
+		AuditLogHealthCheck:       auditLogHealthEndpoint,
+		AllHealthChecksDetailed:   allHealthDetailedEndpoint,
+		Options:                   healthOptionsEndpoint,
+	}
+
+	// Health poller, it feeds the live health stream. It checks the health only while clients are streaming,
+	// and stops at shutdown.
+	var healthPoller *health.Poller
+	{
+		var pollerOptions = []health.PollerOption{}
+		for name, ms := range healthStreamIntervals {
+			pollerOptions = append(pollerOptions, health.SubsystemInterval(name, time.Duration(ms)*time.Millisecond))
+		}
+
+		var err error
+		healthPoller, err = health.NewPoller(healthEndpoints.AllHealthChecksDetailed, healthStreamInterval, healthTimeout, pollerOptions...)
+		if err != nil {
+			logger.Log("msg", "could not create health poller", "error", err)
+			return
+		}
+	}
+	var healthPollerCtx, stopHealthPoller = context.WithCancel(context.Background())
+	defer stopHealthPoller()
+	go healthPoller.Run(healthPollerCtx)
+
+	// Wait until the dependencies are ready before serving.
+	if healthStartupTimeout > 0 {
+		var logger = log.With(logger, "unit", "startup")
+		var ctx, cancel = context.WithTimeout(context.Background(), healthStartupTimeout)
+		ctx = context.WithValue(ctx, "correlation_id", flakiModule.NextValidID(ctx))
+		var s = healthComponent.WaitUntilReady(ctx, healthStartupInterval)
+		cancel()
+		logger.Log("msg", "startup health checks done", "status", s.String())
 	}
 
 	// GRPC server.
@@ -373,14 +952,25 @@ func main() {
 			nextValidIDHandler = flaki.MakeGRPCTracingMW(tracer, componentName, "grpc_server_nextvalidid")(nextValidIDHandler)
 		}
 
+		// Health.
+		var healthHandler = health.MakeGRPCAllHealthChecksHandler(healthEndpoints.AllHealthChecksDetailed)
+
 		var grpcServer = flaki.NewGRPCServer(nextIDHandler, nextValidIDHandler)
-		var flakiServer = grpc.NewServer(grpc.CustomCodec(flatbuffers.FlatbuffersCodec{}))
+		var flakiServer = grpc.NewServer(grpc.CustomCodec(health.NewGRPCCodec(flatbuffers.FlatbuffersCodec{})))
 		fb.RegisterFlakiServer(flakiServer, grpcServer)
+		grpc_health_v1.RegisterHealthServer(flakiServer, health.NewGRPCServer(healthHandler))
 
 		errc <- flakiServer.Serve(lis)
 	}()
 
-	// HTTP server.
+	// HTTP servers. When the admin address is set, the health routes are served by a dedicated admin server,
+	// so they are not exposed on the API address.
+	var httpServer = &http.Server{Addr: httpAddr}
+	var adminServer *http.Server
+	if adminAddr != "" {
+		adminServer = &http.Server{Addr: adminAddr}
+	}
+
 	go func() {
 		var logger = log.With(logger, "transport", "http")
 		logger.Log("addr", httpAddr)
@@ -407,23 +997,232 @@ func main() {
 		route.Handle("/", http.HandlerFunc(makeVersion(componentName, Version, Environment, GitCommit)))
 
 		// Health checks.
-		var healthSubroute = route.PathPrefix("/health").Subrouter()
+		var healthRoute = route
+		if adminServer != nil {
+			healthRoute = mux.NewRouter()
+		}
+		var healthSubroute = healthRoute.PathPrefix("/health").Subrouter()
+		var healthTimeoutMW = health.MakeHTTPTimeoutMW(healthTimeout, healthMaxTimeout)
+
+		// The reports of all subsystems are large and scraped often, they are compressed.
+		var healthGzipMW = func(next http.Handler) http.Handler { return next }
+		if healthGzip {
+			healthGzipMW = health.MakeHTTPGzipMW(healthGzipMinSize)
+		}
+
+		// The hostname and the instance ID tell which instance answered behind a load balancer.
+		var healthHostname string
+		if healthInstanceHostname {
+			var err error
+			healthHostname, err = os.Hostname()
+			if err != nil {
+				logger.Log("msg", "could not get the hostname for the health replies", "error", err)
+			}
+		}
+		var healthHandlerOptions = []health.HandlerOption{
+			health.OmitDeactivated(healthOmitDeactivated),
+			health.Instance(healthHostname, healthInstanceID),
+		}
 
-		var allHealthChecksHandler = health.MakeAllHealthChecksHandler(healthEndpoints.AllHealthChecks)
+		var allHealthChecksHandler http.Handler
+		{
+			allHealthChecksHandler = health.MakeAllHealthChecksHandler(healthEndpoints.AllHealthChecksDetailed, healthHandlerOptions...)
+			allHealthChecksHandler = health.MakeHTTPHeadMW(healthEndpoints.AllHealthChecksDetailed)(allHealthChecksHandler)
+			allHealthChecksHandler = healthGzipMW(allHealthChecksHandler)
+			allHealthChecksHandler = health.MakeHTTPTracingMW(tracer, "http_server_health")(allHealthChecksHandler)
+			allHealthChecksHandler = healthTimeoutMW(allHealthChecksHandler)
+		}
 		healthSubroute.Handle("", allHealthChecksHandler)
 
-		var influxHealthCheckHandler = health.MakeInfluxHealthCheckHandler(healthEndpoints.InfluxHealthCheck)
+		var allHealthChecksDetailedHandler http.Handler
+		{
+			allHealthChecksDetailedHandler = health.MakeAllHealthChecksDetailedHandler(healthEndpoints.AllHealthChecksDetailed, healthHandlerOptions...)
+			allHealthChecksDetailedHandler = health.MakeHTTPHeadMW(healthEndpoints.AllHealthChecksDetailed)(allHealthChecksDetailedHandler)
+			allHealthChecksDetailedHandler = health.MakeHTTPNDJSONMW(healthEndpoints.AllHealthChecksDetailed, healthHandlerOptions...)(allHealthChecksDetailedHandler)
+			allHealthChecksDetailedHandler = healthGzipMW(allHealthChecksDetailedHandler)
+			allHealthChecksDetailedHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_detailed")(allHealthChecksDetailedHandler)
+			allHealthChecksDetailedHandler = healthTimeoutMW(allHealthChecksDetailedHandler)
+		}
+		healthSubroute.Handle("/detailed", allHealthChecksDetailedHandler)
+
+		var readinessHandler http.Handler
+		{
+			readinessHandler = health.MakeReadinessHandler(healthEndpoints.AllHealthChecksDetailed)
+			readinessHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_ready")(readinessHandler)
+			readinessHandler = healthTimeoutMW(readinessHandler)
+		}
+		healthSubroute.Handle("/ready", readinessHandler)
+
+		// The liveness does not execute the health checks.
+		healthSubroute.Handle("/live", health.MakeLivenessHandler())
+
+		var openMetricsHandler http.Handler
+		{
+			openMetricsHandler = health.MakeOpenMetricsHandler(healthEndpoints.AllHealthChecksDetailed)
+			openMetricsHandler = healthGzipMW(openMetricsHandler)
+			openMetricsHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_openmetrics")(openMetricsHandler)
+			openMetricsHandler = healthTimeoutMW(openMetricsHandler)
+		}
+		healthSubroute.Handle("/openmetrics", openMetricsHandler)
+
+		// The histogram is read from memory, the health checks are not executed.
+		healthSubroute.Handle("/metrics", healthGzipMW(health.MakePrometheusHandler(healthLatency)))
+
+		var logfmtHandler http.Handler
+		{
+			logfmtHandler = health.MakeLogfmtHandler(healthEndpoints.AllHealthChecksDetailed)
+			logfmtHandler = healthGzipMW(logfmtHandler)
+			logfmtHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_logfmt")(logfmtHandler)
+			logfmtHandler = healthTimeoutMW(logfmtHandler)
+		}
+		healthSubroute.Handle("/logfmt", logfmtHandler)
+
+		// The stream is long-lived, so it is not subject to the health checks deadline.
+		var healthStreamHandler http.Handler
+		{
+			healthStreamHandler = health.MakeHealthStreamHandler(healthPoller, healthStreamKeepAlive, healthHandlerOptions...)
+			healthStreamHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_stream")(healthStreamHandler)
+		}
+		healthSubroute.Handle("/stream", healthStreamHandler)
+
+		var influxHealthCheckHandler http.Handler
+		{
+			influxHealthCheckHandler = health.MakeInfluxHealthCheckHandler(healthEndpoints.InfluxHealthCheck)
+			influxHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_influx")(influxHealthCheckHandler)
+			influxHealthCheckHandler = healthTimeoutMW(influxHealthCheckHandler)
+		}
 		healthSubroute.Handle("/influx", influxHealthCheckHandler)
 
-		var jaegerHealthCheckHandler = health.MakeJaegerHealthCheckHandler(healthEndpoints.JaegerHealthCheck)
+		var jaegerHealthCheckHandler http.Handler
+		{
+			jaegerHealthCheckHandler = health.MakeJaegerHealthCheckHandler(healthEndpoints.JaegerHealthCheck)
+			jaegerHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_jaeger")(jaegerHealthCheckHandler)
+			jaegerHealthCheckHandler = healthTimeoutMW(jaegerHealthCheckHandler)
+		}
 		healthSubroute.Handle("/jaeger", jaegerHealthCheckHandler)
 
-		var redisHealthCheckHandler = health.MakeRedisHealthCheckHandler(healthEndpoints.RedisHealthCheck)
+		var redisHealthCheckHandler http.Handler
+		{
+			redisHealthCheckHandler = health.MakeRedisHealthCheckHandler(healthEndpoints.RedisHealthCheck)
+			redisHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_redis")(redisHealthCheckHandler)
+			redisHealthCheckHandler = healthTimeoutMW(redisHealthCheckHandler)
+		}
 		healthSubroute.Handle("/redis", redisHealthCheckHandler)
 
-		var sentryHealthCheckHandler = health.MakeSentryHealthCheckHandler(healthEndpoints.SentryHealthCheck)
+		var sentryHealthCheckHandler http.Handler
+		{
+			sentryHealthCheckHandler = health.MakeSentryHealthCheckHandler(healthEndpoints.SentryHealthCheck)
+			sentryHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_sentry")(sentryHealthCheckHandler)
+			sentryHealthCheckHandler = healthTimeoutMW(sentryHealthCheckHandler)
+		}
 		healthSubroute.Handle("/sentry", sentryHealthCheckHandler)
 
+		var smtpHealthCheckHandler http.Handler
+		{
+			smtpHealthCheckHandler = health.MakeSMTPHealthCheckHandler(healthEndpoints.SMTPHealthCheck)
+			smtpHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_smtp")(smtpHealthCheckHandler)
+			smtpHealthCheckHandler = healthTimeoutMW(smtpHealthCheckHandler)
+		}
+		healthSubroute.Handle("/smtp", smtpHealthCheckHandler)
+
+		var dockerHealthCheckHandler http.Handler
+		{
+			dockerHealthCheckHandler = health.MakeDockerHealthCheckHandler(healthEndpoints.DockerHealthCheck)
+			dockerHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_docker")(dockerHealthCheckHandler)
+			dockerHealthCheckHandler = healthTimeoutMW(dockerHealthCheckHandler)
+		}
+		healthSubroute.Handle("/docker", dockerHealthCheckHandler)
+
+		var logSinkHealthCheckHandler http.Handler
+		{
+			logSinkHealthCheckHandler = health.MakeLogSinkHealthCheckHandler(healthEndpoints.LogSinkHealthCheck)
+			logSinkHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_logsink")(logSinkHealthCheckHandler)
+			logSinkHealthCheckHandler = healthTimeoutMW(logSinkHealthCheckHandler)
+		}
+		healthSubroute.Handle("/logsink", logSinkHealthCheckHandler)
+
+		var e2eHealthCheckHandler http.Handler
+		{
+			e2eHealthCheckHandler = health.MakeE2EHealthCheckHandler(healthEndpoints.E2EHealthCheck)
+			e2eHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_e2e")(e2eHealthCheckHandler)
+			e2eHealthCheckHandler = healthTimeoutMW(e2eHealthCheckHandler)
+		}
+		healthSubroute.Handle("/e2e", e2eHealthCheckHandler)
+
+		var grpcReflectionHealthCheckHandler http.Handler
+		{
+			grpcReflectionHealthCheckHandler = health.MakeGRPCReflectionHealthCheckHandler(healthEndpoints.GRPCReflectionHealthCheck)
+			grpcReflectionHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_grpc")(grpcReflectionHealthCheckHandler)
+			grpcReflectionHealthCheckHandler = healthTimeoutMW(grpcReflectionHealthCheckHandler)
+		}
+		healthSubroute.Handle("/grpc", grpcReflectionHealthCheckHandler)
+
+		var egressHealthCheckHandler http.Handler
+		{
+			egressHealthCheckHandler = health.MakeEgressHealthCheckHandler(healthEndpoints.EgressHealthCheck)
+			egressHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_egress")(egressHealthCheckHandler)
+			egressHealthCheckHandler = healthTimeoutMW(egressHealthCheckHandler)
+		}
+		healthSubroute.Handle("/egress", egressHealthCheckHandler)
+
+		var fdHealthCheckHandler http.Handler
+		{
+			fdHealthCheckHandler = health.MakeFDHealthCheckHandler(healthEndpoints.FDHealthCheck)
+			fdHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_fd")(fdHealthCheckHandler)
+			fdHealthCheckHandler = healthTimeoutMW(fdHealthCheckHandler)
+		}
+		healthSubroute.Handle("/fd", fdHealthCheckHandler)
+
+		var idGenHealthCheckHandler http.Handler
+		{
+			idGenHealthCheckHandler = health.MakeIDGenHealthCheckHandler(healthEndpoints.IDGenHealthCheck)
+			idGenHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_idgen")(idGenHealthCheckHandler)
+			idGenHealthCheckHandler = healthTimeoutMW(idGenHealthCheckHandler)
+		}
+		healthSubroute.Handle("/idgen", idGenHealthCheckHandler)
+
+		var featureFlagHealthCheckHandler http.Handler
+		{
+			featureFlagHealthCheckHandler = health.MakeFeatureFlagHealthCheckHandler(healthEndpoints.FeatureFlagHealthCheck)
+			featureFlagHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_featureflag")(featureFlagHealthCheckHandler)
+			featureFlagHealthCheckHandler = healthTimeoutMW(featureFlagHealthCheckHandler)
+		}
+		healthSubroute.Handle("/featureflag", featureFlagHealthCheckHandler)
+
+		var commandHealthCheckHandler http.Handler
+		{
+			commandHealthCheckHandler = health.MakeCommandHealthCheckHandler(healthEndpoints.CommandHealthCheck)
+			commandHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_command")(commandHealthCheckHandler)
+			commandHealthCheckHandler = healthTimeoutMW(commandHealthCheckHandler)
+		}
+		healthSubroute.Handle("/command", commandHealthCheckHandler)
+
+		var selfHealthCheckHandler http.Handler
+		{
+			selfHealthCheckHandler = health.MakeSelfHealthCheckHandler(healthEndpoints.SelfHealthCheck)
+			selfHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_self")(selfHealthCheckHandler)
+			selfHealthCheckHandler = healthTimeoutMW(selfHealthCheckHandler)
+		}
+		healthSubroute.Handle("/self", selfHealthCheckHandler)
+
+		var auditLogHealthCheckHandler http.Handler
+		{
+			auditLogHealthCheckHandler = health.MakeAuditLogHealthCheckHandler(healthEndpoints.AuditLogHealthCheck)
+			auditLogHealthCheckHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_auditlog")(auditLogHealthCheckHandler)
+			auditLogHealthCheckHandler = healthTimeoutMW(auditLogHealthCheckHandler)
+		}
+		healthSubroute.Handle("/auditlog", auditLogHealthCheckHandler)
+
+		// Effective configuration of the health checks, for debugging.
+		if healthOptionsRoute {
+			var healthOptionsHandler http.Handler
+			{
+				healthOptionsHandler = health.MakeOptionsHandler(healthEndpoints.Options)
+				healthOptionsHandler = health.MakeHTTPTracingMW(tracer, "http_server_health_options")(healthOptionsHandler)
+			}
+			healthSubroute.Handle("/debug/options", healthOptionsHandler)
+		}
+
 		// Debug.
 		if pprofRouteEnabled {
 			var debugSubroute = route.PathPrefix("/debug").Subrouter()
@@ -434,7 +1233,16 @@ func main() {
 			debugSubroute.HandleFunc("/pprof/trace", http.HandlerFunc(pprof.Trace))
 		}
 
-		errc <- http.ListenAndServe(httpAddr, route)
+		if adminServer != nil {
+			adminServer.Handler = healthRoute
+			go func() {
+				log.With(logger, "server", "admin").Log("addr", adminAddr)
+				errc <- adminServer.ListenAndServe()
+			}()
+		}
+
+		httpServer.Handler = route
+		errc <- httpServer.ListenAndServe()
 	}()
 
 	// Influx writing.
@@ -446,6 +1254,15 @@ func main() {
 		}()
 	}
 
+	// StatsD writing.
+	if healthStatsD != nil {
+		go func() {
+			var tic = time.NewTicker(healthStatsDInterval)
+			defer tic.Stop()
+			healthStatsD.SendLoop(tic.C, "udp", healthStatsDHostPort)
+		}()
+	}
+
 	// Redis writing.
 	if redisEnabled {
 		go func() {
@@ -457,6 +1274,20 @@ func main() {
 		}()
 	}
 	logger.Log("error", <-errc)
+	stopHealthPoller()
+
+	// Graceful shutdown of the HTTP servers, the requests in progress are completed.
+	{
+		var servers = []*http.Server{httpServer}
+		if adminServer != nil {
+			servers = append(servers, adminServer)
+		}
+		var ctx, cancel = context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := health.ShutdownServers(ctx, servers...); err != nil {
+			logger.Log("msg", "could not shutdown the HTTP servers", "error", err)
+		}
+	}
 }
 
 type info struct {
@@ -466,6 +1297,86 @@ type info struct {
 	Commit  string `json:"commit"`
 }
 
+// isHealthCheckCommand returns true if the command is "healthcheck", e.g. flakid healthcheck --config-file
+// ./configs/flakid.yml.
+func isHealthCheckCommand(args []string) bool {
+	for _, a := range args {
+		if a == "healthcheck" {
+			return true
+		}
+	}
+	return false
+}
+
+// logWriter returns the output of the logs: stdout, or stderr for the healthcheck command, whose standard
+// output is reserved to the health report.
+func logWriter(healthCheckCommand bool, stdout, stderr io.Writer) io.Writer {
+	if healthCheckCommand {
+		return stderr
+	}
+	return stdout
+}
+
+// newLogger returns the JSON logger that writes to w. If redis is not nil, the logs are also written to
+// redis, in logstash format, under key.
+func newLogger(w io.Writer, redis flakid.Redis, key string) log.Logger {
+	if redis != nil {
+		w = io.MultiWriter(w, flakid.NewLogstashRedisWriter(redis, key))
+	}
+	var logger = log.NewJSONLogger(w)
+	return log.With(logger, "ts", log.DefaultTimestampUTC, "caller", log.DefaultCaller)
+}
+
+// redacted returns the value of the configuration key k to log. The passwords and the tokens, i.e. the keys
+// that end with "-password" or "-token", are redacted unless they are empty.
+func redacted(k string, v interface{}) interface{} {
+	if !strings.HasSuffix(k, "-password") && !strings.HasSuffix(k, "-token") {
+		return v
+	}
+	if s, ok := v.(string); ok && s == "" {
+		return v
+	}
+	return "<redacted>"
+}
+
+// newRedisPool returns a pool of connections to the redis at the given address. The health checks bound
+// the duration of the commands, the connection timeout bounds the duration of the dial.
+func newRedisPool(url string, database int, password string) *redis.Pool {
+	return &redis.Pool{
+		MaxIdle:     2,
+		IdleTimeout: time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", url, redis.DialDatabase(database), redis.DialPassword(password), redis.DialConnectTimeout(5*time.Second))
+		},
+	}
+}
+
+// startupGracePeriod returns the startup grace period of the health component, 0 for the healthcheck
+// command: it runs at startup, the grace period would hide the KO subsystems.
+func startupGracePeriod(d time.Duration, healthCheckCommand bool) time.Duration {
+	if healthCheckCommand {
+		return 0
+	}
+	return d
+}
+
+// runHealthCheck executes all the health checks once, bounded by timeout, and writes the detailed report to
+// w. It returns the exit code: 1 if the overall status is KO or the report could not be written, 0 otherwise.
+func runHealthCheck(c health.Component, g flaki.Module, timeout time.Duration, w io.Writer, options ...health.HandlerOption) int {
+	var ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, "correlation_id", g.NextValidID(ctx))
+
+	var report = c.AllHealthChecksDetailed(ctx)
+	if err := health.EncodeDetailedReport(w, report, options...); err != nil {
+		return 1
+	}
+	if report.Status == health.KO {
+		return 1
+	}
+	return 0
+}
+
 // makeVersion makes a HTTP handler that returns information about the version of the service.
 func makeVersion(componentName, version, environment, gitCommit string) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -496,6 +1407,8 @@ func config(logger log.Logger) map[string]interface{} {
 	viper.SetDefault("component-name", "flaki-service")
 	viper.SetDefault("component-http-host-port", "0.0.0.0:8888")
 	viper.SetDefault("component-grpc-host-port", "0.0.0.0:5555")
+	viper.SetDefault("component-admin-http-host-port", "")
+	viper.SetDefault("component-shutdown-timeout-ms", 5000)
 
 	// Flaki generator default.
 	viper.SetDefault("flaki-node-id", 0)
@@ -511,10 +1424,12 @@ func config(logger log.Logger) map[string]interface{} {
 	viper.SetDefault("influx-retention-policy", "")
 	viper.SetDefault("influx-write-consistency", "")
 	viper.SetDefault("influx-write-interval-ms", 1000)
+	viper.SetDefault("influx-write-buffer-size", 0)
 
 	// Sentry client default.
 	viper.SetDefault("sentry", false)
 	viper.SetDefault("sentry-dsn", "")
+	viper.SetDefault("sentry-projects-dsns", []string{})
 
 	// Jaeger tracing default.
 	viper.SetDefault("jaeger", false)
@@ -527,6 +1442,7 @@ func config(logger log.Logger) map[string]interface{} {
 
 	// Debug routes enabled.
 	viper.SetDefault("pprof-route-enabled", true)
+	viper.SetDefault("health-options-route-enabled", false)
 
 	// Redis.
 	viper.SetDefault("redis", false)
@@ -534,6 +1450,106 @@ func config(logger log.Logger) map[string]interface{} {
 	viper.SetDefault("redis-password", "")
 	viper.SetDefault("redis-database", 0)
 	viper.SetDefault("redis-write-interval-ms", 1000)
+	viper.SetDefault("redis-shards-host-ports", []string{})
+
+	// SMTP.
+	viper.SetDefault("smtp", false)
+	viper.SetDefault("smtp-host-port", "")
+	viper.SetDefault("smtp-tls", false)
+
+	// Docker.
+	viper.SetDefault("docker", false)
+	viper.SetDefault("docker-socket", "")
+
+	// Health.
+	viper.SetDefault("health-required-subsystems", []string{})
+	viper.SetDefault("health-ko-when-not-configured", []string{})
+	viper.SetDefault("health-dependencies", map[string][]string{})
+	viper.SetDefault("health-soft-dependencies", []string{})
+	viper.SetDefault("health-group-errors", false)
+	viper.SetDefault("health-slow-threshold-ms", 0)
+	viper.SetDefault("health-subsystem-slow-thresholds-ms", map[string]int{})
+	viper.SetDefault("health-runbook-urls", map[string]string{})
+	viper.SetDefault("health-timeout-ms", 5000)
+	viper.SetDefault("health-max-timeout-ms", 30000)
+	viper.SetDefault("health-startup-timeout-ms", 0)
+	viper.SetDefault("health-startup-poll-interval-ms", 1000)
+	viper.SetDefault("health-omit-deactivated", false)
+	viper.SetDefault("health-instance-hostname", false)
+	viper.SetDefault("health-instance-id", "")
+	viper.SetDefault("health-all-deactivated-status", "OK")
+	viper.SetDefault("health-sentry-max-body-bytes", 64*1024)
+	viper.SetDefault("health-sentry-response-snippet-bytes", 0)
+	viper.SetDefault("health-influx-write-check", false)
+	viper.SetDefault("health-influx-write-check-interval-ms", 0)
+	viper.SetDefault("health-trace-cached", false)
+	viper.SetDefault("health-influx-retention-min-hours", 0)
+	viper.SetDefault("health-influx-flush-staleness-ms", 0)
+	viper.SetDefault("health-influx-v2-org", "")
+	viper.SetDefault("health-influx-v2-bucket", "")
+	viper.SetDefault("health-influx-v2-token", "")
+	viper.SetDefault("health-redis-cluster-check", false)
+	viper.SetDefault("health-redis-cluster-replicas", 0)
+	viper.SetDefault("health-redis-auth-check", false)
+	viper.SetDefault("health-redis-auth-user", "")
+	viper.SetDefault("health-redis-min-healthy-shards", 0)
+	viper.SetDefault("health-redis-persistence-check", false)
+	viper.SetDefault("health-redis-persistence-max-save-age-ms", 0)
+	viper.SetDefault("health-stream-poll-interval-ms", 10000)
+	viper.SetDefault("health-stream-keepalive-ms", 15000)
+	viper.SetDefault("health-stream-subsystem-intervals-ms", map[string]int{})
+	viper.SetDefault("health-shutdown-drain-ms", 0)
+	viper.SetDefault("health-gzip", true)
+	viper.SetDefault("health-gzip-min-size", 1024)
+	viper.SetDefault("health-tls-cert-file", "")
+	viper.SetDefault("health-tls-key-file", "")
+	viper.SetDefault("health-tls-ca-file", "")
+	viper.SetDefault("health-http-proxy", "")
+	viper.SetDefault("health-log-sink-check", false)
+	viper.SetDefault("health-max-concurrent-checks", 0)
+	viper.SetDefault("health-e2e-check", false)
+	viper.SetDefault("health-grpc-reflection-target", "")
+	viper.SetDefault("health-egress-canary-url", "")
+	viper.SetDefault("health-fd-check", true)
+	viper.SetDefault("health-fd-warn-ratio", 0.8)
+	viper.SetDefault("health-fd-crit-ratio", 0.95)
+	viper.SetDefault("health-idgen-check", true)
+	viper.SetDefault("health-idgen-epoch", "2017-01-01T00:00:00Z")
+	viper.SetDefault("health-idgen-timestamp-bits", 41)
+	viper.SetDefault("health-idgen-overflow-horizon-days", 365)
+	viper.SetDefault("health-idgen-clock-check", true)
+	viper.SetDefault("health-idgen-clock-max-drift-ms", 1000)
+	viper.SetDefault("health-featureflag-url", "")
+	viper.SetDefault("health-command-name", "command")
+	viper.SetDefault("health-command-argv", []string{})
+	viper.SetDefault("health-self-check", false)
+	viper.SetDefault("health-self-request-path", "")
+	viper.SetDefault("health-auditlog-dir", "")
+	viper.SetDefault("health-auditlog-min-retention-hours", 2160)
+	viper.SetDefault("health-startup-grace-period-ms", 0)
+	viper.SetDefault("health-adaptive-timeout-factor", 0.0)
+	viper.SetDefault("health-adaptive-timeout-min-ms", 500)
+	viper.SetDefault("health-adaptive-timeout-max-ms", 5000)
+	viper.SetDefault("health-jaeger-agent-host-port", "")
+	viper.SetDefault("health-jaeger-agent-metrics-url", "")
+	viper.SetDefault("health-jaeger-sampling-check", false)
+	viper.SetDefault("health-critical-subsystems", []string{})
+	viper.SetDefault("health-deadline-margin-ms", 0)
+	viper.SetDefault("health-sentry-username", "")
+	viper.SetDefault("health-sentry-password", "")
+	viper.SetDefault("health-sentry-token", "")
+	viper.SetDefault("health-jaeger-collector-username", "")
+	viper.SetDefault("health-jaeger-collector-password", "")
+	viper.SetDefault("health-jaeger-collector-token", "")
+	viper.SetDefault("health-latency-buckets-seconds", []string{})
+	viper.SetDefault("health-statsd-host-port", "")
+	viper.SetDefault("health-statsd-prefix", "flaki.health.")
+	viper.SetDefault("health-statsd-send-interval-ms", 10000)
+	viper.SetDefault("health-sentry-quota-check", false)
+	viper.SetDefault("health-sentry-quota-check-interval-ms", 60000)
+	viper.SetDefault("health-sentry-tags-check", false)
+	viper.SetDefault("health-history-size", 0)
+	viper.SetDefault("health-uptime-counts-degraded", false)
 
 	// First level of override.
 	pflag.String("config-file", viper.GetString("config-file"), "The configuration file path can be relative or absolute.")
@@ -553,6 +1569,56 @@ func config(logger log.Logger) map[string]interface{} {
 	config["sentry"] = config["sentry-dsn"].(string) != ""
 	config["jaeger"] = config["jaeger-sampler-host-port"].(string) != ""
 	config["redis"] = config["redis-host-port"].(string) != ""
+	config["smtp"] = config["smtp-host-port"].(string) != ""
+	config["docker"] = config["docker-socket"].(string) != ""
+
+	// Lists are decoded from the configuration file as []interface{}.
+	config["health-required-subsystems"] = viper.GetStringSlice("health-required-subsystems")
+	config["health-ko-when-not-configured"] = viper.GetStringSlice("health-ko-when-not-configured")
+	config["health-critical-subsystems"] = viper.GetStringSlice("health-critical-subsystems")
+	config["redis-shards-host-ports"] = viper.GetStringSlice("redis-shards-host-ports")
+	config["sentry-projects-dsns"] = viper.GetStringSlice("sentry-projects-dsns")
+	config["health-command-argv"] = viper.GetStringSlice("health-command-argv")
+	config["health-dependencies"] = viper.GetStringMapStringSlice("health-dependencies")
+	config["health-soft-dependencies"] = viper.GetStringSlice("health-soft-dependencies")
+
+	// The buckets are decoded as strings, so both the integers and the floats are accepted.
+	var buckets = []float64{}
+	for _, b := range viper.GetStringSlice("health-latency-buckets-seconds") {
+		var f, err = strconv.ParseFloat(b, 64)
+		if err != nil {
+			logger.Log("msg", "invalid health latency bucket", "bucket", b, "error", err)
+			continue
+		}
+		buckets = append(buckets, f)
+	}
+	config["health-latency-buckets-seconds"] = buckets
+	// The intervals are decoded as strings, like the buckets.
+	var intervals = map[string]int{}
+	for name, v := range viper.GetStringMapString("health-stream-subsystem-intervals-ms") {
+		var ms, err = strconv.Atoi(v)
+		if err != nil {
+			logger.Log("msg", "invalid health stream subsystem interval", "subsystem", name, "interval", v, "error", err)
+			continue
+		}
+		intervals[name] = ms
+	}
+	config["health-stream-subsystem-intervals-ms"] = intervals
+	var thresholds = map[string]int{}
+	for name, v := range viper.GetStringMapString("health-subsystem-slow-thresholds-ms") {
+		var ms, err = strconv.Atoi(v)
+		if err != nil {
+			logger.Log("msg", "invalid health subsystem slow threshold", "subsystem", name, "threshold", v, "error", err)
+			continue
+		}
+		thresholds[name] = ms
+	}
+	config["health-subsystem-slow-thresholds-ms"] = thresholds
+	config["health-runbook-urls"] = viper.GetStringMapString("health-runbook-urls")
+	// A factor or a ratio written without decimals is decoded as an int.
+	config["health-adaptive-timeout-factor"] = viper.GetFloat64("health-adaptive-timeout-factor")
+	config["health-fd-warn-ratio"] = viper.GetFloat64("health-fd-warn-ratio")
+	config["health-fd-crit-ratio"] = viper.GetFloat64("health-fd-crit-ratio")
 
 	// Log config in alphabetical order.
 	var keys []string
@@ -561,7 +1627,7 @@ func config(logger log.Logger) map[string]interface{} {
 	}
 	sort.Strings(keys)
 	for _, k := range keys {
-		logger.Log(k, config[k])
+		logger.Log(k, redacted(k, config[k]))
 	}
 
 	return config
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	flakid_mock "github.com/cloudtrust/flaki-service/internal/flakid/mock"
+	flaki_mock "github.com/cloudtrust/flaki-service/pkg/flaki/mock"
+	"github.com/cloudtrust/flaki-service/pkg/health"
+	health_mock "github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunHealthCheck(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = health_mock.NewRedisModule(mockCtrl)
+	var mockFlakiModule = flaki_mock.NewModule(mockCtrl)
+
+	mockFlakiModule.EXPECT().NextValidID(gomock.Any()).Return("1").AnyTimes()
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]health.RedisReport{{Report: health.Report{Name: "ping", Status: health.KO, Error: "fail"}}}).AnyTimes()
+
+	var gracePeriod = time.Hour
+
+	// The healthcheck command reports the KO subsystems despite the grace period.
+	{
+		var c, err = health.NewComponent(nil, nil, mockRedisModule, nil, health.StartupGracePeriod(startupGracePeriod(gracePeriod, true)))
+		assert.Nil(t, err)
+
+		var w bytes.Buffer
+		assert.Equal(t, 1, runHealthCheck(c, mockFlakiModule, time.Second, &w))
+		assert.Contains(t, w.String(), "fail")
+	}
+
+	// The server hides them during the grace period.
+	{
+		var c, err = health.NewComponent(nil, nil, mockRedisModule, nil, health.StartupGracePeriod(startupGracePeriod(gracePeriod, false)))
+		assert.Nil(t, err)
+
+		var w bytes.Buffer
+		assert.Equal(t, 0, runHealthCheck(c, mockFlakiModule, time.Second, &w))
+		assert.Contains(t, w.String(), "starting up: fail")
+	}
+}
+
+func TestHealthCheckCommandOutput(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedis = flakid_mock.NewRedis(mockCtrl)
+	var mockRedisModule = health_mock.NewRedisModule(mockCtrl)
+	var mockFlakiModule = flaki_mock.NewModule(mockCtrl)
+
+	mockRedis.EXPECT().Send("RPUSH", "flaki-service", gomock.Any()).Return(nil).AnyTimes()
+	mockFlakiModule.EXPECT().NextValidID(gomock.Any()).Return("1").AnyTimes()
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]health.RedisReport{{Report: health.Report{Name: "ping", Status: health.OK}}}).AnyTimes()
+
+	// With redis enabled, the logs are duplicated to redis, and still written to stderr only.
+	var stdout, stderr bytes.Buffer
+	var logger = newLogger(logWriter(true, &stdout, &stderr), mockRedis, "flaki-service")
+	logger.Log("msg", "starting")
+
+	var c, err = health.NewComponent(nil, nil, mockRedisModule, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, runHealthCheck(c, mockFlakiModule, time.Second, &stdout))
+
+	// The standard output holds the report only.
+	var report map[string]interface{}
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.Equal(t, "OK", report["status"])
+	assert.Contains(t, stderr.String(), "starting")
+}
+
+func TestRedacted(t *testing.T) {
+	assert.Equal(t, "<redacted>", redacted("health-sentry-password", "secret"))
+	assert.Equal(t, "<redacted>", redacted("health-influx-v2-token", "secret"))
+	assert.Equal(t, "", redacted("redis-password", ""))
+	assert.Equal(t, "flaki", redacted("influx-username", "flaki"))
+	assert.Equal(t, 5000, redacted("component-shutdown-timeout-ms", 5000))
+}
@@ -3,8 +3,11 @@ package flakid
 //go:generate mockgen -source=instrumenting.go -destination=./mock/instrumenting.go -package=mock -mock_names=Influx=Influx,GoKitMetrics=GoKitMetrics github.com/cloudtrust/flaki-service/cmd Influx,GoKitMetrics
 
 import (
+	"fmt"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics"
 	metric "github.com/go-kit/kit/metrics/influx"
 	influx "github.com/influxdata/influxdb/client/v2"
@@ -29,6 +32,8 @@ type GoKitMetrics interface {
 type InfluxMetrics struct {
 	influx  Influx
 	metrics GoKitMetrics
+	writer  *flushWriter
+	buffer  *writeBuffer
 }
 
 // NewMetrics returns an InfluxMetrics.
@@ -36,6 +41,20 @@ func NewMetrics(influx Influx, metrics GoKitMetrics) *InfluxMetrics {
 	return &InfluxMetrics{
 		influx:  influx,
 		metrics: metrics,
+		writer:  &flushWriter{client: influx},
+	}
+}
+
+// NewBufferedMetrics returns an InfluxMetrics whose writes are buffered. The write loop queues the batches
+// in a buffer of size batches, and a separate goroutine writes them to the Influx DB, so a slow Influx DB
+// does not delay the collection. When the buffer is full, the batches are dropped.
+func NewBufferedMetrics(influx Influx, metrics GoKitMetrics, size int, logger log.Logger) *InfluxMetrics {
+	var writer = &flushWriter{client: influx}
+	return &InfluxMetrics{
+		influx:  influx,
+		metrics: metrics,
+		writer:  writer,
+		buffer:  newWriteBuffer(writer, size, logger),
 	}
 }
 
@@ -56,7 +75,29 @@ func (m *InfluxMetrics) NewHistogram(name string) metrics.Histogram {
 
 // WriteLoop writes the data to the Influx DB.
 func (m *InfluxMetrics) WriteLoop(c <-chan time.Time) {
-	m.metrics.WriteLoop(c, m.influx)
+	if m.buffer == nil {
+		m.metrics.WriteLoop(c, m.writer)
+		return
+	}
+
+	go m.buffer.run()
+	defer m.buffer.close()
+	m.metrics.WriteLoop(c, m.buffer)
+}
+
+// BufferDepth returns the number of batches waiting in the write buffer, and the capacity of the buffer.
+// Both are 0 if the writes are not buffered.
+func (m *InfluxMetrics) BufferDepth() (int, int) {
+	if m.buffer == nil {
+		return 0, 0
+	}
+	return len(m.buffer.batches), cap(m.buffer.batches)
+}
+
+// LastFlush returns the time of the last batch successfully written to the Influx DB, or the zero time if
+// none was written yet.
+func (m *InfluxMetrics) LastFlush() time.Time {
+	return m.writer.lastFlush()
 }
 
 // Ping test the connection to the Influx DB.
@@ -64,6 +105,68 @@ func (m *InfluxMetrics) Ping(timeout time.Duration) (time.Duration, string, erro
 	return m.influx.Ping(timeout)
 }
 
+// flushWriter writes the batches to the Influx DB, and records the time of the last successful write.
+type flushWriter struct {
+	client Influx
+	last   int64
+}
+
+// Write writes the batch to the Influx DB. It implements the go-kit BatchPointsWriter.
+func (w *flushWriter) Write(bp influx.BatchPoints) error {
+	var err = w.client.Write(bp)
+	if err == nil {
+		atomic.StoreInt64(&w.last, time.Now().UnixNano())
+	}
+	return err
+}
+
+func (w *flushWriter) lastFlush() time.Time {
+	var last = atomic.LoadInt64(&w.last)
+	if last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, last)
+}
+
+// writeBuffer queues the batches written by the go-kit write loop, and writes them to the Influx DB.
+type writeBuffer struct {
+	client  metric.BatchPointsWriter
+	batches chan influx.BatchPoints
+	logger  log.Logger
+}
+
+func newWriteBuffer(client metric.BatchPointsWriter, size int, logger log.Logger) *writeBuffer {
+	return &writeBuffer{
+		client:  client,
+		batches: make(chan influx.BatchPoints, size),
+		logger:  logger,
+	}
+}
+
+// Write queues the batch, or drops it if the buffer is full. It implements the go-kit BatchPointsWriter.
+func (b *writeBuffer) Write(bp influx.BatchPoints) error {
+	select {
+	case b.batches <- bp:
+		return nil
+	default:
+		return fmt.Errorf("influx write buffer full, batch of %d points dropped", len(bp.Points()))
+	}
+}
+
+// run writes the queued batches to the Influx DB, until the buffer is closed.
+func (b *writeBuffer) run() {
+	for bp := range b.batches {
+		var err = b.client.Write(bp)
+		if err != nil {
+			b.logger.Log("msg", "could not write batch to influx", "error", err)
+		}
+	}
+}
+
+func (b *writeBuffer) close() {
+	close(b.batches)
+}
+
 // NoopMetrics is an Influx metrics that does nothing.
 type NoopMetrics struct{}
 
@@ -79,6 +182,12 @@ func (m *NoopMetrics) NewHistogram(name string) metrics.Histogram { return &Noop
 // WriteLoop does nothing.
 func (m *NoopMetrics) WriteLoop(c <-chan time.Time) {}
 
+// BufferDepth returns 0, 0.
+func (m *NoopMetrics) BufferDepth() (int, int) { return 0, 0 }
+
+// LastFlush returns the zero time.
+func (m *NoopMetrics) LastFlush() time.Time { return time.Time{} }
+
 // Ping does nothing.
 func (m *NoopMetrics) Ping(timeout time.Duration) (time.Duration, string, error) {
 	return time.Duration(0), "", nil
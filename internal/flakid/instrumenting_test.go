@@ -1,11 +1,15 @@
 package flakid
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/cloudtrust/flaki-service/internal/flakid/mock"
+	"github.com/go-kit/kit/log"
+	metric "github.com/go-kit/kit/metrics/influx"
 	"github.com/golang/mock/gomock"
+	influx "github.com/influxdata/influxdb/client/v2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,13 +30,76 @@ func TestMetrics(t *testing.T) {
 	mockMetrics.EXPECT().NewHistogram("histogram name").Return(nil).Times(1)
 	influxMetrics.NewHistogram("histogram name")
 
-	mockMetrics.EXPECT().WriteLoop(gomock.Any(), mockInflux).Return().Times(1)
+	// The successful writes are recorded as flushes.
+	var bp, _ = influx.NewBatchPoints(influx.BatchPointsConfig{})
+	assert.True(t, influxMetrics.LastFlush().IsZero())
+	mockMetrics.EXPECT().WriteLoop(gomock.Any(), gomock.Any()).Do(func(c <-chan time.Time, w metric.BatchPointsWriter) {
+		mockInflux.EXPECT().Write(bp).Return(fmt.Errorf("fail")).Times(1)
+		assert.NotNil(t, w.Write(bp))
+		assert.True(t, influxMetrics.LastFlush().IsZero())
+
+		mockInflux.EXPECT().Write(bp).Return(nil).Times(1)
+		assert.Nil(t, w.Write(bp))
+	}).Times(1)
 	influxMetrics.WriteLoop(make(chan time.Time))
+	assert.True(t, time.Since(influxMetrics.LastFlush()) < time.Second)
 
 	mockInflux.EXPECT().Ping(1*time.Second).Return(time.Duration(0), "", nil).Times(1)
 	influxMetrics.Ping(1 * time.Second)
 }
 
+func TestBufferedMetrics(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInflux = mock.NewInflux(mockCtrl)
+	var mockMetrics = mock.NewGoKitMetrics(mockCtrl)
+
+	var influxMetrics = NewBufferedMetrics(mockInflux, mockMetrics, 2, log.NewNopLogger())
+	var bp, _ = influx.NewBatchPoints(influx.BatchPointsConfig{})
+
+	// The first write blocks until it is released.
+	var writing = make(chan struct{}, 4)
+	var release = make(chan struct{})
+	mockInflux.EXPECT().Write(bp).DoAndReturn(func(influx.BatchPoints) error {
+		writing <- struct{}{}
+		<-release
+		return nil
+	}).Times(3)
+
+	mockMetrics.EXPECT().WriteLoop(gomock.Any(), gomock.Any()).Do(func(c <-chan time.Time, w metric.BatchPointsWriter) {
+		assert.Nil(t, w.Write(bp))
+		<-writing
+
+		// The buffer fills while the Influx DB is slow.
+		assert.Nil(t, w.Write(bp))
+		assert.Nil(t, w.Write(bp))
+		var depth, capacity = influxMetrics.BufferDepth()
+		assert.Equal(t, 2, depth)
+		assert.Equal(t, 2, capacity)
+
+		// The batch is dropped when the buffer is full.
+		assert.NotNil(t, w.Write(bp))
+
+		close(release)
+		<-writing
+		<-writing
+	}).Times(1)
+
+	influxMetrics.WriteLoop(make(chan time.Time))
+	var depth, _ = influxMetrics.BufferDepth()
+	assert.Equal(t, 0, depth)
+	assert.False(t, influxMetrics.LastFlush().IsZero())
+}
+
+func TestUnbufferedMetricsBufferDepth(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var depth, capacity = NewMetrics(mock.NewInflux(mockCtrl), mock.NewGoKitMetrics(mockCtrl)).BufferDepth()
+	assert.Equal(t, 0, depth)
+	assert.Equal(t, 0, capacity)
+}
+
 func TestNoopMetrics(t *testing.T) {
 	var noopMetrics = &NoopMetrics{}
 
@@ -48,6 +115,8 @@ func TestNoopMetrics(t *testing.T) {
 	assert.IsType(t, &NoopHistogram{}, histogram)
 	assert.IsType(t, &NoopHistogram{}, histogram.With())
 
+	assert.True(t, noopMetrics.LastFlush().IsZero())
+
 	var duration, s, err = noopMetrics.Ping(1 * time.Second)
 	assert.Equal(t, time.Duration(0), duration)
 	assert.Equal(t, "", s)
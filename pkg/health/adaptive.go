@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// adaptiveTimeoutWindow is the number of successful durations used to compute an adaptive timeout.
+const adaptiveTimeoutWindow = 100
+
+// childTimeout returns the timeout of a check executed under ctx: timeout, or the time remaining before the
+// deadline of ctx if it is sooner, so a check with its own timeout never outlives the deadline of its caller.
+// The result is never zero, for the clients that read a zero timeout as no timeout.
+func childTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	var deadline, ok = ctx.Deadline()
+	if !ok {
+		return timeout
+	}
+
+	switch remaining := time.Until(deadline); {
+	case remaining <= 0:
+		return time.Nanosecond
+	case remaining < timeout:
+		return remaining
+	default:
+		return timeout
+	}
+}
+
+// adaptiveTimeout computes the timeout of a check from the durations of its last successful executions:
+// the p99 multiplied by factor, bounded by min and max. Until a duration is observed, the timeout is max.
+type adaptiveTimeout struct {
+	factor float64
+	min    time.Duration
+	max    time.Duration
+
+	mutex     sync.Mutex
+	durations []time.Duration
+	next      int
+}
+
+func newAdaptiveTimeout(factor float64, min, max time.Duration) *adaptiveTimeout {
+	return &adaptiveTimeout{
+		factor:    factor,
+		min:       min,
+		max:       max,
+		durations: make([]time.Duration, 0, adaptiveTimeoutWindow),
+	}
+}
+
+// timeout returns the currently effective timeout.
+func (a *adaptiveTimeout) timeout() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.durations) == 0 {
+		return a.max
+	}
+
+	var sorted = make([]time.Duration, len(a.durations))
+	copy(sorted, a.durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var p99 = sorted[int(math.Ceil(0.99*float64(len(sorted))))-1]
+	var timeout = time.Duration(float64(p99) * a.factor)
+	switch {
+	case timeout < a.min:
+		return a.min
+	case timeout > a.max:
+		return a.max
+	default:
+		return timeout
+	}
+}
+
+// observe records the duration of a successful execution. The oldest duration is dropped when the
+// window is full.
+func (a *adaptiveTimeout) observe(d time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.durations) < adaptiveTimeoutWindow {
+		a.durations = append(a.durations, d)
+		return
+	}
+	a.durations[a.next] = d
+	a.next = (a.next + 1) % adaptiveTimeoutWindow
+}
+
+// reset forgets the observed durations, so the timeout is max until a duration is observed.
+func (a *adaptiveTimeout) reset() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.durations = make([]time.Duration, 0, adaptiveTimeoutWindow)
+	a.next = 0
+}
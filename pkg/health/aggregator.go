@@ -0,0 +1,92 @@
+package health
+
+// Aggregator computes a status from a set of reports. The health component uses an aggregator to
+// compute the status of each subsystem from the reports of its tests, and one to compute the overall
+// status from the status of the subsystems.
+type Aggregator interface {
+	Aggregate([]Report) Status
+}
+
+// DefaultAggregator is the default aggregation strategy: KO if a report is KO, Degraded if a report
+// is Degraded, Deactivated if all the reports are Deactivated, and OK otherwise.
+type DefaultAggregator struct{}
+
+// Aggregate implements Aggregator.
+func (DefaultAggregator) Aggregate(reports []Report) Status {
+	var degraded = false
+	var deactivated = len(reports) > 0
+	for _, r := range reports {
+		switch r.Status {
+		case KO:
+			return KO
+		case Degraded:
+			degraded = true
+		}
+		if r.Status != Deactivated {
+			deactivated = false
+		}
+	}
+
+	switch {
+	case degraded:
+		return Degraded
+	case deactivated:
+		return Deactivated
+	default:
+		return OK
+	}
+}
+
+// AllMustPass is a strict aggregation strategy: OK if all the reports are OK, Deactivated if all the
+// reports are Deactivated, and KO otherwise.
+type AllMustPass struct{}
+
+// Aggregate implements Aggregator.
+func (AllMustPass) Aggregate(reports []Report) Status {
+	var ok, deactivated = count(reports)
+
+	switch {
+	case len(reports) > 0 && deactivated == len(reports):
+		return Deactivated
+	case ok == len(reports):
+		return OK
+	default:
+		return KO
+	}
+}
+
+// Quorum is an aggregation strategy that tolerates failures: OK if all the reports are OK, Degraded if
+// at least Min reports are OK, and KO otherwise. The Deactivated reports do not count as OK, and if
+// all the reports are Deactivated the status is Deactivated.
+type Quorum struct {
+	Min int
+}
+
+// Aggregate implements Aggregator.
+func (q Quorum) Aggregate(reports []Report) Status {
+	var ok, deactivated = count(reports)
+
+	switch {
+	case len(reports) > 0 && deactivated == len(reports):
+		return Deactivated
+	case ok == len(reports):
+		return OK
+	case ok >= q.Min:
+		return Degraded
+	default:
+		return KO
+	}
+}
+
+// count returns the number of OK and Deactivated reports.
+func count(reports []Report) (ok, deactivated int) {
+	for _, r := range reports {
+		switch r.Status {
+		case OK:
+			ok++
+		case Deactivated:
+			deactivated++
+		}
+	}
+	return ok, deactivated
+}
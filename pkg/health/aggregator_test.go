@@ -0,0 +1,48 @@
+package health_test
+
+import (
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func reports(statuses ...Status) []Report {
+	var reports = []Report{}
+	for _, s := range statuses {
+		reports = append(reports, Report{Status: s})
+	}
+	return reports
+}
+
+func TestDefaultAggregator(t *testing.T) {
+	var a = DefaultAggregator{}
+
+	assert.Equal(t, OK, a.Aggregate(reports()))
+	assert.Equal(t, OK, a.Aggregate(reports(OK, OK)))
+	assert.Equal(t, OK, a.Aggregate(reports(OK, Deactivated)))
+	assert.Equal(t, Degraded, a.Aggregate(reports(OK, Degraded)))
+	assert.Equal(t, KO, a.Aggregate(reports(Degraded, KO, OK)))
+	assert.Equal(t, KO, a.Aggregate(reports(Deactivated, KO)))
+	assert.Equal(t, Deactivated, a.Aggregate(reports(Deactivated, Deactivated)))
+}
+
+func TestAllMustPass(t *testing.T) {
+	var a = AllMustPass{}
+
+	assert.Equal(t, OK, a.Aggregate(reports()))
+	assert.Equal(t, OK, a.Aggregate(reports(OK, OK)))
+	assert.Equal(t, KO, a.Aggregate(reports(OK, Degraded)))
+	assert.Equal(t, KO, a.Aggregate(reports(OK, Deactivated)))
+	assert.Equal(t, Deactivated, a.Aggregate(reports(Deactivated, Deactivated)))
+}
+
+func TestQuorum(t *testing.T) {
+	var a = Quorum{Min: 2}
+
+	assert.Equal(t, OK, a.Aggregate(reports(OK, OK, OK)))
+	assert.Equal(t, Degraded, a.Aggregate(reports(OK, OK, KO)))
+	assert.Equal(t, KO, a.Aggregate(reports(OK, KO, KO)))
+	assert.Equal(t, KO, a.Aggregate(reports(OK, Deactivated, Degraded)))
+	assert.Equal(t, Deactivated, a.Aggregate(reports(Deactivated)))
+}
@@ -0,0 +1,218 @@
+package health
+
+//go:generate mockgen -destination=./mock/auditlog.go -package=mock -mock_names=AuditLogModule=AuditLogModule github.com/cloudtrust/flaki-service/pkg/health AuditLogModule
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+// auditLogProbePrefix is the prefix of the files created by the write check. They are hidden, so the
+// retention check and the log shippers ignore them.
+const auditLogProbePrefix = ".health-"
+
+// AuditLogModule is the health check module for the audit logs. They must be retained for compliance, so a
+// rotation that deletes them too early, or a directory where they can no longer be written, is reported.
+type AuditLogModule interface {
+	HealthChecks(context.Context) []AuditLogReport
+}
+
+type auditLogModule struct {
+	dir          string
+	minRetention time.Duration
+	enabled      bool
+}
+
+// AuditLogReport is the health report returned by the audit log module.
+type AuditLogReport = Report
+
+// NewAuditLogModule returns the audit log health module. dir is the directory of the audit logs, and
+// minRetention the window they must cover. The hidden files of dir are ignored.
+func NewAuditLogModule(dir string, minRetention time.Duration, enabled bool) AuditLogModule {
+	return &auditLogModule{
+		dir:          dir,
+		minRetention: minRetention,
+		enabled:      enabled,
+	}
+}
+
+// HealthChecks executes all health checks for the audit logs.
+func (m *auditLogModule) HealthChecks(ctx context.Context) []AuditLogReport {
+	var reports = []AuditLogReport{}
+	reports = append(reports, m.auditLogRetentionCheck())
+	reports = append(reports, m.auditLogWriteCheck(ctx))
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *auditLogModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *auditLogModule) auditLogRetentionCheck() AuditLogReport {
+	var healthCheckName = "retention"
+	var healthCheckDescription = fmt.Sprintf("Checks that the audit logs cover the last %v. If it fails, the rotation deleted audit logs that must be retained. Degraded means the oldest audit log is too recent.", m.minRetention)
+
+	if !m.enabled {
+		return AuditLogReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	if m.dir == "" {
+		return AuditLogReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+			Error:       notConfiguredError,
+		}
+	}
+
+	var now = time.Now()
+	var oldest, err = oldestAuditLog(m.dir)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not list audit logs: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	case oldest.IsZero():
+		error = fmt.Sprintf("no audit log in '%s'", m.dir)
+		s = KO
+		category = DataIntegrity
+	case now.Sub(oldest) < m.minRetention:
+		error = fmt.Sprintf("audit logs cover %v, less than the retention of %v", now.Sub(oldest).Truncate(time.Second), m.minRetention)
+		s = Degraded
+		category = DataIntegrity
+	default:
+		s = OK
+	}
+
+	return AuditLogReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+func (m *auditLogModule) auditLogWriteCheck(ctx context.Context) AuditLogReport {
+	var healthCheckName = "write"
+	var healthCheckDescription = "Creates, syncs and removes a hidden file in the audit log directory. If it fails, the audit logs can no longer be written, e.g. because the disk is full."
+
+	if !m.enabled {
+		return AuditLogReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	if m.dir == "" {
+		return AuditLogReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+			Error:       notConfiguredError,
+		}
+	}
+
+	var now = time.Now()
+	var err = writeAuditLogProbe(ctx, m.dir)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not write to audit log directory: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return AuditLogReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// oldestAuditLog returns the modification time of the oldest audit log of dir, or the zero time if there is
+// none. A file is modified until it is rotated, so its entries are at least as old as its modification time,
+// and the window covered is at least the age of the oldest modification time.
+func oldestAuditLog(dir string) (time.Time, error) {
+	var files, err = ioutil.ReadDir(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var oldest time.Time
+	for _, f := range files {
+		if !f.Mode().IsRegular() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		if oldest.IsZero() || f.ModTime().Before(oldest) {
+			oldest = f.ModTime()
+		}
+	}
+	return oldest, nil
+}
+
+// writeAuditLogProbe creates a hidden file in dir, writes and syncs it, and removes it, within the context
+// deadline. A write that blocks past the deadline is reported as an error, but it is not interrupted.
+func writeAuditLogProbe(ctx context.Context, dir string) error {
+	var errc = make(chan error, 1)
+	go func() {
+		errc <- writeProbe(dir)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// writeProbe creates, writes, syncs and removes a hidden file in dir.
+func writeProbe(dir string) error {
+	var f, err = ioutil.TempFile(dir, auditLogProbePrefix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write([]byte("health")); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,87 @@
+package health_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLogHealthChecks(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "auditlog")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var m = NewAuditLogModule(dir, 24*time.Hour, true)
+
+	// No audit log.
+	{
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 2, len(reports))
+		assert.Equal(t, "retention", reports[0].Name)
+		assert.Equal(t, KO, reports[0].Status)
+		assert.Equal(t, DataIntegrity, reports[0].Category)
+		assert.Equal(t, "write", reports[1].Name)
+		assert.Equal(t, OK, reports[1].Status)
+		assert.Equal(t, 1, reports[1].Attempts)
+	}
+
+	// The oldest audit log is too recent. The hidden files are ignored.
+	var old = filepath.Join(dir, "audit.log.1")
+	assert.Nil(t, ioutil.WriteFile(old, []byte("entry"), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, ".audit.log.swp"), []byte("entry"), 0600))
+	assert.Nil(t, os.Chtimes(filepath.Join(dir, ".audit.log.swp"), time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)))
+	{
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Contains(t, report.Error, "less than the retention of 24h0m0s")
+	}
+
+	// The audit logs cover the retention.
+	assert.Nil(t, os.Chtimes(old, time.Now().Add(-25*time.Hour), time.Now().Add(-25*time.Hour)))
+	{
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, OK, reports[0].Status)
+		assert.Zero(t, reports[0].Error)
+		assert.Equal(t, OK, reports[1].Status)
+	}
+
+	// The write check leaves no file behind.
+	var files, _ = ioutil.ReadDir(dir)
+	assert.Equal(t, 2, len(files))
+}
+
+func TestAuditLogMissingDir(t *testing.T) {
+	var m = NewAuditLogModule("/nonexistent/audit", time.Hour, true)
+
+	var reports = m.HealthChecks(context.Background())
+	assert.Equal(t, KO, reports[0].Status)
+	assert.Contains(t, reports[0].Error, "could not list audit logs")
+	assert.Equal(t, KO, reports[1].Status)
+	assert.Contains(t, reports[1].Error, "could not write to audit log directory")
+}
+
+func TestAuditLogNotConfigured(t *testing.T) {
+	var m = NewAuditLogModule("", time.Hour, true)
+
+	for _, report := range m.HealthChecks(context.Background()) {
+		assert.Equal(t, Deactivated, report.Status)
+		assert.Equal(t, "not configured", report.Error)
+	}
+}
+
+func TestNoopAuditLogHealthChecks(t *testing.T) {
+	var m = NewAuditLogModule("/var/log/audit", time.Hour, false)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "retention", report.Name)
+	assert.NotZero(t, report.Description)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+}
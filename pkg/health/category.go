@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"net"
+)
+
+// Category is the category of a health check failure. It lets the dashboards aggregate the failures
+// across subsystems, e.g. "3 auth failures", rather than showing the raw errors.
+type Category int
+
+const (
+	// Uncategorized is the category of the successful checks, and of the failures that fit no other category.
+	Uncategorized Category = iota
+	// Network is the category of the failures to reach a dependency, e.g. connection refused or DNS failure.
+	Network
+	// Auth is the category of the failures caused by a dependency that rejects our credentials.
+	Auth
+	// Capacity is the category of the failures caused by a lack of resources, e.g. a full disk or no free check slot.
+	Capacity
+	// DataIntegrity is the category of the failures caused by unexpected data, e.g. a corrupted round trip.
+	DataIntegrity
+	// Timeout is the category of the failures caused by a deadline exceeded.
+	Timeout
+)
+
+func (c Category) String() string {
+	var names = []string{"uncategorized", "network", "auth", "capacity", "data-integrity", "timeout"}
+
+	if c < Uncategorized || c > Timeout {
+		return "unknown"
+	}
+
+	return names[c]
+}
+
+// categorizedError is an error whose category is known where it is created, e.g. a 403 response.
+type categorizedError struct {
+	category Category
+	err      error
+}
+
+func (e *categorizedError) Error() string {
+	return e.err.Error()
+}
+
+// withCategory sets the category of err, errorCategory returns it.
+func withCategory(c Category, err error) error {
+	return &categorizedError{
+		category: c,
+		err:      err,
+	}
+}
+
+// errorCategory returns the category of err: the category set with withCategory, Timeout for the deadline
+// and network timeout errors, Network for the other network errors, Capacity for a full disk, and
+// Uncategorized otherwise.
+func errorCategory(err error) Category {
+	if ce, ok := err.(*categorizedError); ok {
+		return ce.category
+	}
+	if err == context.DeadlineExceeded {
+		return Timeout
+	}
+	if ne, ok := err.(net.Error); ok {
+		if ne.Timeout() {
+			return Timeout
+		}
+		return Network
+	}
+	if err != nil && isDiskFull(err) {
+		return Capacity
+	}
+	return Uncategorized
+}
@@ -0,0 +1,19 @@
+package health_test
+
+import (
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryString(t *testing.T) {
+	assert.Equal(t, "uncategorized", Uncategorized.String())
+	assert.Equal(t, "network", Network.String())
+	assert.Equal(t, "auth", Auth.String())
+	assert.Equal(t, "capacity", Capacity.String())
+	assert.Equal(t, "data-integrity", DataIntegrity.String())
+	assert.Equal(t, "timeout", Timeout.String())
+	assert.Equal(t, "unknown", Category(-1).String())
+	assert.Equal(t, "unknown", Category(6).String())
+}
@@ -0,0 +1,152 @@
+package health
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NewHTTPClient returns the http client of the HTTP-based health checks. It does not follow the
+// redirects, a health endpoint that redirects, e.g. to a login page, is misconfigured.
+func NewHTTPClient() *http.Client {
+	return &http.Client{
+		CheckRedirect: NoRedirect,
+	}
+}
+
+// SetProxy routes the requests of the http client c through the proxy at proxyURL, an HTTP or a SOCKS5
+// proxy, e.g. http://proxy.example.com:3128 or socks5://localhost:1080, so the health checks reach the
+// dependencies the way the service does. By default, the clients use the proxy of the environment variables
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY. c must be a client returned by NewHTTPClient or NewMTLSHTTPClient,
+// or a client whose Transport is an *http.Transport.
+func SetProxy(c *http.Client, proxyURL *url.URL) error {
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("proxy scheme '%s' must be http, https or socks5", proxyURL.Scheme)
+	}
+
+	if c.Transport == nil {
+		c.Transport = newTransport()
+	}
+	var t, ok = c.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("cannot set the proxy of a %T transport", c.Transport)
+	}
+	t.Proxy = http.ProxyURL(proxyURL)
+	return nil
+}
+
+// newTransport returns a transport with the settings of the default transport, that uses the proxy of the
+// environment.
+func newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// NoRedirect is the redirect policy of the health checks http clients: the redirects are not followed,
+// so the checks get the 3xx responses and report them as KO.
+func NoRedirect(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// responseSnippet returns the status, the content type and the first n bytes of the body of a response,
+// to be appended to the error of a failed check. It returns an empty string if n is not positive.
+func responseSnippet(res *http.Response, body []byte, n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	var truncated string
+	if len(body) > n {
+		body = body[:n]
+		truncated = "..."
+	}
+	return fmt.Sprintf(" (status: %v, content-type: %v, body: %q%s)", res.Status, res.Header.Get("Content-Type"), body, truncated)
+}
+
+// isRedirect returns true if the response is a redirect.
+func isRedirect(res *http.Response) bool {
+	return res.StatusCode >= 300 && res.StatusCode < 400
+}
+
+// unexpectedRedirect returns the error reported for a redirect response.
+func unexpectedRedirect(res *http.Response) error {
+	return fmt.Errorf("unexpected redirect: %v to '%s'", res.Status, res.Header.Get("Location"))
+}
+
+// readBody reads at most limit bytes of the body, within the ctx deadline. The body is closed when ctx is
+// done, which unblocks a pending read, so a server that streams the body slowly cannot hang the check past
+// its timeout. In that case the ctx error is returned.
+func readBody(ctx context.Context, body io.ReadCloser, limit int64) ([]byte, error) {
+	var done = make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			body.Close()
+		case <-done:
+		}
+	}()
+
+	var b, err = ioutil.ReadAll(io.LimitReader(body, limit))
+	if err != nil && ctx.Err() != nil {
+		return b, ctx.Err()
+	}
+	return b, err
+}
+
+// httpAuth is the authentication of the requests of a HTTP-based health check: basic auth if username is
+// set, bearer token if token is set, nothing otherwise.
+type httpAuth struct {
+	username string
+	password string
+	token    string
+}
+
+// apply sets the Authorization header of the request.
+func (a httpAuth) apply(req *http.Request) {
+	switch {
+	case a.token != "":
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	case a.username != "":
+		req.SetBasicAuth(a.username, a.password)
+	}
+}
+
+// scrub removes the credentials from s, e.g. from a response body echoed in an error, so they never end up
+// in the health reports or in the logs.
+func (a httpAuth) scrub(s string) string {
+	var secrets = []string{}
+	if a.token != "" {
+		secrets = append(secrets, a.token)
+	}
+	if a.username != "" {
+		secrets = append(secrets, base64.StdEncoding.EncodeToString([]byte(a.username+":"+a.password)))
+	}
+	if a.password != "" {
+		secrets = append(secrets, a.password)
+	}
+
+	for _, secret := range secrets {
+		s = strings.Replace(s, secret, "***", -1)
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+package health_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetProxy(t *testing.T) {
+	// The stub proxy answers the requests for the canary, which does not resolve.
+	var proxied = []string{}
+	var proxy = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	var proxyURL, err = url.Parse(proxy.URL)
+	assert.Nil(t, err)
+
+	var client = NewHTTPClient()
+	assert.Nil(t, SetProxy(client, proxyURL))
+
+	var m = NewEgressModule("http://canary.invalid/generate_204", client, true)
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, OK, report.Status)
+	assert.Equal(t, []string{"http://canary.invalid/generate_204"}, proxied)
+
+	// The redirects are still not followed.
+	assert.NotNil(t, client.CheckRedirect)
+}
+
+func TestSetProxyInvalid(t *testing.T) {
+	// Unsupported scheme.
+	{
+		var proxyURL, _ = url.Parse("ftp://proxy.example.com")
+		assert.NotNil(t, SetProxy(NewHTTPClient(), proxyURL))
+	}
+
+	// Unknown transport.
+	{
+		var proxyURL, _ = url.Parse("socks5://localhost:1080")
+		var client = &http.Client{Transport: http.NewFileTransport(http.Dir("."))}
+		assert.NotNil(t, SetProxy(client, proxyURL))
+	}
+}
@@ -0,0 +1,160 @@
+package health
+
+//go:generate mockgen -destination=./mock/command.go -package=mock -mock_names=CommandModule=CommandModule github.com/cloudtrust/flaki-service/pkg/health CommandModule
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// CommandModule is the health check module that runs an external command, e.g. a bespoke check written as
+// a shell script, so its result is part of the health report.
+type CommandModule interface {
+	HealthChecks(context.Context) []CommandReport
+}
+
+type commandModule struct {
+	name    string
+	argv    []string
+	enabled bool
+}
+
+// CommandReport is the health report returned by the command module.
+type CommandReport = Report
+
+// commandOutputSize is the number of bytes of the output of the command kept in the error of the check.
+const commandOutputSize = 512
+
+// NewCommandModule returns the command health module. The check is named name, and runs the executable
+// argv[0] with the arguments argv[1:]. The exit code 0 is OK, 1 is Degraded, and any other exit code is KO.
+// The stdout and the stderr of a failed command are in the error of the check, truncated. The command is
+// killed when the context deadline fires. The description of the check names the executable only, as the
+// arguments may hold credentials.
+func NewCommandModule(name string, argv []string, enabled bool) CommandModule {
+	return &commandModule{
+		name:    name,
+		argv:    argv,
+		enabled: enabled,
+	}
+}
+
+// HealthChecks executes all health checks for the command.
+func (m *commandModule) HealthChecks(ctx context.Context) []CommandReport {
+	var reports = []CommandReport{}
+	reports = append(reports, m.commandCheck(ctx))
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *commandModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *commandModule) commandCheck(ctx context.Context) CommandReport {
+	var healthCheckName = m.name
+	var healthCheckDescription = fmt.Sprintf("Runs the command '%s'. It exits with 0 when OK, 1 when Degraded, and any other code when KO.", m.argv[0])
+
+	if !m.enabled {
+		return CommandReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var code, output, err = runCommand(ctx, m.argv)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	// A command that exits on its own at the deadline is reported with its exit code, only a command
+	// terminated by a signal after the deadline was killed.
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not run command: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	case code < 0 && ctx.Err() != nil:
+		error = fmt.Sprintf("command killed: %v%s", ctx.Err(), output)
+		s = KO
+		category = Timeout
+	case code == 0:
+		s = OK
+	case code == 1:
+		error = fmt.Sprintf("command exited with 1%s", output)
+		s = Degraded
+	default:
+		error = fmt.Sprintf("command exited with %d%s", code, output)
+		s = KO
+	}
+
+	return CommandReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// runCommand runs the command, killed when ctx is done, and returns its exit code and the beginning of its
+// combined stdout and stderr, formatted to be appended to the error of the check. The error is set when the
+// command could not run, e.g. the executable does not exist, not when it exits with a non zero code. The
+// exit code is -1 when the command is terminated by a signal.
+func runCommand(ctx context.Context, argv []string) (int, string, error) {
+	var cmd = exec.CommandContext(ctx, argv[0], argv[1:]...)
+	setWaitDelay(cmd)
+
+	var output = &commandOutput{limit: commandOutputSize}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	var err = cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), output.String(), nil
+	}
+	return 0, output.String(), err
+}
+
+// commandOutput keeps the first limit bytes written to it, and discards the rest, so a verbose command does
+// not fill the memory nor block on a full pipe.
+type commandOutput struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write implements io.Writer.
+func (o *commandOutput) Write(p []byte) (int, error) {
+	var n = len(p)
+	if free := o.limit - o.buf.Len(); n > free {
+		p = p[:free]
+		o.truncated = true
+	}
+	o.buf.Write(p)
+	return n, nil
+}
+
+// String returns the output formatted to be appended to the error of the check, or an empty string if
+// there is no output.
+func (o *commandOutput) String() string {
+	var out = strings.TrimSpace(o.buf.String())
+	if out == "" {
+		return ""
+	}
+
+	var truncated string
+	if o.truncated {
+		truncated = "..."
+	}
+	return fmt.Sprintf(" (output: %q%s)", out, truncated)
+}
@@ -0,0 +1,10 @@
+//go:build !go1.20
+// +build !go1.20
+
+package health
+
+import "os/exec"
+
+// setWaitDelay does nothing: exec.Cmd.WaitDelay requires Go 1.20. Before, a child of the command that keeps
+// its output open, e.g. a background process of a shell script, delays the check past the deadline.
+func setWaitDelay(*exec.Cmd) {}
@@ -0,0 +1,100 @@
+package health_test
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandHealthChecks(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell on this platform")
+	}
+
+	// Exit code 0.
+	{
+		var m = NewCommandModule("script", []string{"sh", "-c", "echo fine"}, true)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 1, len(reports))
+		var report = reports[0]
+		assert.Equal(t, "script", report.Name)
+		assert.Contains(t, report.Description, "'sh'")
+		assert.NotContains(t, report.Description, "echo fine")
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// Exit code 1, with the output in the error.
+	{
+		var m = NewCommandModule("script", []string{"sh", "-c", "echo almost; echo broken >&2; exit 1"}, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, `command exited with 1 (output: "almost\nbroken")`, report.Error)
+	}
+
+	// Other exit code.
+	{
+		var m = NewCommandModule("script", []string{"sh", "-c", "exit 3"}, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "command exited with 3", report.Error)
+	}
+
+	// The output is truncated.
+	{
+		var m = NewCommandModule("script", []string{"sh", "-c", "yes | head -c 100000; exit 2"}, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.True(t, strings.HasSuffix(report.Error, `"...)`))
+		assert.True(t, len(report.Error) < 2048)
+	}
+
+	// No executable.
+	{
+		var m = NewCommandModule("script", []string{"/nonexistent/script"}, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Contains(t, report.Error, "could not run command")
+	}
+
+	// The command is killed at the deadline.
+	{
+		var m = NewCommandModule("script", []string{"sh", "-c", "sleep 10"}, true)
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		var now = time.Now()
+		var report = m.HealthChecks(ctx)[0]
+		assert.True(t, time.Since(now) < 5*time.Second)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, Timeout, report.Category)
+		assert.Contains(t, report.Error, "command killed")
+	}
+
+	// The command exits on its own, and its output is read past the deadline.
+	{
+		var m = NewCommandModule("script", []string{"sh", "-c", "sleep 0.5 & exit 2"}, true)
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		var report = m.HealthChecks(ctx)[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "command exited with 2", report.Error)
+	}
+}
+
+func TestNoopCommandHealthChecks(t *testing.T) {
+	var m = NewCommandModule("script", []string{"sh", "-c", "exit 0"}, false)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "script", report.Name)
+	assert.NotZero(t, report.Description)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+}
@@ -0,0 +1,18 @@
+//go:build go1.20
+// +build go1.20
+
+package health
+
+import (
+	"os/exec"
+	"time"
+)
+
+// commandWaitDelay is the time given to the command to close its output after it is killed, e.g. when a
+// child of a shell script keeps it open.
+const commandWaitDelay = 1 * time.Second
+
+// setWaitDelay bounds the wait for the output of the command after it is killed.
+func setWaitDelay(cmd *exec.Cmd) {
+	cmd.WaitDelay = commandWaitDelay
+}
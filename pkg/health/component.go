@@ -4,6 +4,13 @@ package health
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
 )
 
 // Status is the status of the health check.
@@ -36,109 +43,1708 @@ type Component interface {
 	JaegerHealthChecks(context.Context) Reports
 	RedisHealthChecks(context.Context) Reports
 	SentryHealthChecks(context.Context) Reports
+	SMTPHealthChecks(context.Context) Reports
+	DockerHealthChecks(context.Context) Reports
+	LogSinkHealthChecks(context.Context) Reports
+	E2EHealthChecks(context.Context) Reports
+	GRPCReflectionHealthChecks(context.Context) Reports
+	EgressHealthChecks(context.Context) Reports
+	FDHealthChecks(context.Context) Reports
+	IDGenHealthChecks(context.Context) Reports
+	FeatureFlagHealthChecks(context.Context) Reports
+	ReplicaHealthChecks(context.Context) Reports
+	CommandHealthChecks(context.Context) Reports
+	SelfHealthChecks(context.Context) Reports
+	AuditLogHealthChecks(context.Context) Reports
+	CompositeHealthChecks(context.Context) Reports
 	AllHealthChecks(context.Context) map[string]string
+	AllHealthChecksDetailed(context.Context) DetailedReport
+	HealthChecksDetailed(ctx context.Context, subsystems ...string) DetailedReport
+	OverallStatusWithReason(context.Context) (Status, string)
+	WaitUntilReady(ctx context.Context, pollInterval time.Duration) Status
+	Enable(subsystem string) error
+	Disable(subsystem string) error
+	BeginShutdown()
+	Reset()
+	UptimePercent(subsystem string, window time.Duration) (float64, error)
+	Options() Options
+	Subsystems() []string
+}
+
+// Warmer is implemented by the health modules that can prime their connections. Warmup performs a
+// throwaway connection, e.g. a ping, so the latency of the first health check reflects the steady state
+// rather than the connection establishment. It is optional, the modules without it are not warmed up.
+type Warmer interface {
+	Warmup(context.Context)
+}
+
+// Resetter is implemented by the health modules that accumulate state between the health checks, e.g. the
+// cached reports of the sampled checks and the durations of the adaptive timeouts. Reset clears it, as if
+// the checks had never been executed. It is optional, the modules without it have no state to clear.
+type Resetter interface {
+	Reset()
 }
 
-// Reports contains the results of all health tests for a given module.
+// Reports contains the results of all health tests for a given module. ScrapeError is set when the tests
+// could not run, e.g. no check slot was available or the health module is not configured while it is
+// required. The KO status then comes from the health checks themselves, often from our configuration,
+// rather than from the target. It is empty when the tests ran. SlowThreshold is the effective slow threshold
+// of the tests, see SlowThreshold, it is empty when there is none. RunbookURLs are the runbooks of the tests,
+// by test name, see RunbookURLs.
 type Reports struct {
-	Reports []Report
+	Reports       []Report
+	ScrapeError   string
+	SlowThreshold string
+	RunbookURLs   map[string]string
+}
+
+// DetailedReport contains the overall health status and the detailed health of each subsystem. Errors
+// lists the errors of all the tests, prefixed with the subsystem and the test, e.g. "redis/ping: could not
+// ping redis", so a consumer can display what is wrong without walking the subsystems. TotalDuration is
+// the wall-clock duration of the health checks run, from its start to its end, so a slow probe is detected
+// independently of any single subsystem.
+type DetailedReport struct {
+	Status        Status
+	Subsystems    map[string]SubsystemReport
+	Errors        []string
+	TotalDuration time.Duration
+}
+
+// SubsystemReportFunc is called with the report of a subsystem as soon as its health checks complete, see
+// WithSubsystemReportFunc. The ConsecutiveFailures of the report are not set.
+type SubsystemReportFunc func(subsystem string, r SubsystemReport)
+
+// contextKey is the type of the keys of the context values set by the health package, so they do not
+// collide with the keys of the other packages.
+type contextKey int
+
+// subsystemReportFuncKey is the context key of the SubsystemReportFunc.
+const subsystemReportFuncKey contextKey = iota
+
+// WithSubsystemReportFunc returns a copy of ctx with f. The detailed health checks executed with it call f
+// with the report of each subsystem as soon as the subsystem and its dependencies are checked, so a caller
+// can stream the progress of a slow run, while the detailed report is returned once all of them complete.
+// f may be called concurrently, e.g. by the components merged with MergeComponents.
+func WithSubsystemReportFunc(ctx context.Context, f SubsystemReportFunc) context.Context {
+	return context.WithValue(ctx, subsystemReportFuncKey, f)
 }
 
-// Report contains the result of one health test.
+// SubsystemReport contains the health of a subsystem. ConsecutiveFailures is the number of consecutive
+// health checks runs for which the subsystem was KO, it is reset as soon as the subsystem is no longer KO.
+// ScrapeError is set when the tests of the subsystem could not run, see Reports. It tells a target that is
+// down apart from a health check that could not run. Dependency is "hard" or "soft", see SoftDependencies.
+// SlowThreshold is the effective slow threshold of the tests of the subsystem, see SlowThreshold. RunbookURLs
+// are the runbooks of the tests, by test name, see RunbookURLs.
+type SubsystemReport struct {
+	Status              Status
+	ConsecutiveFailures int
+	Reports             []Report
+	ScrapeError         string
+	Dependency          string
+	SlowThreshold       string
+	RunbookURLs         map[string]string
+}
+
+// Report contains the result of one health test. Description explains what the test verifies and what a
+// failure implies. Attempts is the number of attempts made by the test, a test that passes only after
+// several attempts is flaky. Category is the category of the failure, it is Uncategorized when the test
+// passes. Cached is true if the test is sampled and the report is its last result, rather than the result
+// of a new execution. Timeout is the effective timeout of a test with an adaptive timeout, it is empty otherwise.
+// The reports of the health modules are aliases of Report, e.g. JaegerReport, or embed it when the module
+// reports specific fields, e.g. InfluxReport, so a field added here is reported by all the modules.
 type Report struct {
-	Name     string
-	Duration string
-	Status   Status
-	Error    string
+	Name        string
+	Description string
+	Duration    string
+	Status      Status
+	Error       string
+	Category    Category
+	Attempts    int
+	Cached      bool
+	Timeout     string
 }
 
+// subsystems is the list of the subsystems monitored by the health component.
+var subsystems = []string{"influx", "jaeger", "redis", "sentry", "smtp", "docker", "logsink", "e2e", "grpc", "egress", "fd", "idgen", "featureflag", "replica", "command", "self", "auditlog", "composite"}
+
 // component is the Health component.
 type component struct {
-	influx InfluxModule
-	jaeger JaegerModule
-	redis  RedisModule
-	sentry SentryModule
+	influx      InfluxModule
+	jaeger      JaegerModule
+	redis       RedisModule
+	sentry      SentryModule
+	smtp        SMTPModule
+	docker      DockerModule
+	logSink     LogSinkModule
+	e2e         E2EModule
+	grpc        GRPCReflectionModule
+	egress      EgressModule
+	fd          FDModule
+	idgen       IDGenModule
+	featureFlag FeatureFlagModule
+	replica     ReplicaModule
+	command     CommandModule
+	self        SelfModule
+	auditLog    AuditLogModule
+	composite   CompositeModule
+	required    map[string]bool
+	ready       []Status
+	deps        map[string][]string
+
+	// checks are the health checks of the configured modules, by subsystem, see moduleChecks.
+	checks map[string]func(context.Context) []Report
+	// deactivated are the subsystems whose module is deactivated by configuration, see Enabler.
+	deactivated map[string]bool
+
+	// nilStatus is the status of the subsystems whose health module is nil, Deactivated by default.
+	nilStatus map[string]Status
+
+	aggregators map[string]Aggregator
+	overall     Aggregator
+
+	// allDeactivated is the overall status when all the subsystems are Deactivated, OK by default.
+	allDeactivated Status
+
+	// soft are the soft dependencies, whose KO status only degrades the overall status.
+	soft map[string]bool
+
+	// groupErrors is true if the errors of the detailed report are grouped by root cause.
+	groupErrors bool
+
+	// slow is the duration above which a test is Degraded, 0 means none. slowBySubsystem overrides it.
+	slow            time.Duration
+	slowBySubsystem map[string]time.Duration
+
+	// runbooks are the runbook URLs, by subsystem and test, e.g. "redis/ping", or by subsystem.
+	runbooks map[string]string
+
+	// slots bounds the number of subsystems checked concurrently, nil means no limit.
+	slots chan struct{}
+
+	started     time.Time
+	gracePeriod time.Duration
+
+	// order is the execution order of the subsystems. The critical subsystems come first, and the others are
+	// skipped when the deadline is closer than margin.
+	order    []string
+	critical map[string]bool
+	margin   time.Duration
+
+	// mutex protects the consecutive failures, the subsystems disabled at runtime and the history.
+	mutex    sync.Mutex
+	failures map[string]int
+	disabled map[string]bool
+
+	// remediation is called when a subsystem transitions to KO, nil when there is none.
+	remediation       Remediation
+	remediationLogger log.Logger
+
+	// history is the history of the statuses of each subsystem, nil when it is not kept.
+	history      map[string]*statusHistory
+	degradedIsUp bool
+
+	// statusGauge is set to the status of each subsystem, nil when the statuses are not exported.
+	statusGauge metrics.Gauge
+
+	// shuttingDown is set by BeginShutdown, it is protected by mutex.
+	shuttingDown bool
+
+	// last are the last reports of each subsystem, before the suppression of the cascading failures. They
+	// complete the detailed reports of HealthChecksDetailed. It is protected by mutex.
+	last map[string]Reports
+}
+
+// ComponentOption is an option of the health component.
+type ComponentOption func(*component) error
+
+// Remediation is a function that attempts to recover a subsystem, e.g. by reconnecting a stale pool.
+type Remediation func(ctx context.Context, subsystem string) error
+
+// RequiredSubsystems marks subsystems as required. A required subsystem must not be deactivated, so
+// if it reports Deactivated it is considered KO. It guards against accidental disables in the configurations.
+func RequiredSubsystems(names ...string) ComponentOption {
+	return func(c *component) error {
+		for _, name := range names {
+			if !isSubsystem(name) {
+				return fmt.Errorf("unknown subsystem '%s'", name)
+			}
+			c.required[name] = true
+		}
+		return nil
+	}
+}
+
+// NilModuleStatus sets the status reported by the given subsystems when their health module is nil. By
+// default it is Deactivated, i.e. a subsystem without health module is not checked. With KO, a missing
+// health module is reported as a failure, so the wiring mistakes are caught. Only Deactivated and KO are
+// allowed.
+func NilModuleStatus(s Status, names ...string) ComponentOption {
+	return func(c *component) error {
+		if s != Deactivated && s != KO {
+			return fmt.Errorf("status of the nil health modules must be Deactivated or KO, got %s", s)
+		}
+		for _, name := range names {
+			if !isSubsystem(name) {
+				return fmt.Errorf("unknown subsystem '%s'", name)
+			}
+			c.nilStatus[name] = s
+		}
+		return nil
+	}
+}
+
+// WithRemediation registers a remediation, invoked when a subsystem transitions to KO. It is invoked once
+// per transition, not at each execution of the health checks, and again only after the subsystem has
+// recovered. It is executed synchronously, before the reply, so it must be quick. Its error is logged, it
+// does not change the reported status. The remediation and the logger must not be nil.
+func WithRemediation(r Remediation, logger log.Logger) ComponentOption {
+	return func(c *component) error {
+		switch {
+		case r == nil:
+			return fmt.Errorf("remediation must not be nil")
+		case logger == nil:
+			return fmt.Errorf("remediation logger must not be nil")
+		}
+		c.remediation = r
+		c.remediationLogger = logger
+		return nil
+	}
+}
+
+// StatusGauge exports the status of each subsystem, after each execution of all the health checks, to the
+// gauge g labelled by subsystem, e.g. the gauge of a StatsDSink. The value is the Status: 0 for OK, 1 for
+// KO, 2 for Degraded and 3 for Deactivated.
+func StatusGauge(g metrics.Gauge) ComponentOption {
+	return func(c *component) error {
+		c.statusGauge = g
+		return nil
+	}
+}
+
+// WithSMTPModule adds the SMTP health module to the component.
+func WithSMTPModule(smtp SMTPModule) ComponentOption {
+	return func(c *component) error {
+		c.smtp = smtp
+		return nil
+	}
+}
+
+// ReadyStatuses sets the overall statuses for which the service is considered ready by WaitUntilReady.
+// The default is OK and Degraded.
+func ReadyStatuses(statuses ...Status) ComponentOption {
+	return func(c *component) error {
+		c.ready = statuses
+		return nil
+	}
+}
+
+// Dependencies sets the dependencies between subsystems, e.g. {"jaeger": {"redis"}} means jaeger depends on redis.
+// When a subsystem is KO, the KO tests of the subsystems that depend on it are reported as Degraded, so the root
+// cause stands out during incidents. A dependency graph with a cycle is rejected.
+func Dependencies(deps map[string][]string) ComponentOption {
+	return func(c *component) error {
+		for name, ds := range deps {
+			if !isSubsystem(name) {
+				return fmt.Errorf("unknown subsystem '%s'", name)
+			}
+			for _, d := range ds {
+				if !isSubsystem(d) {
+					return fmt.Errorf("unknown subsystem '%s'", d)
+				}
+			}
+		}
+
+		if cycle := findCycle(deps); cycle != nil {
+			return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		c.deps = deps
+		return nil
+	}
+}
+
+// SubsystemAggregator sets the aggregation strategy that computes the status of a subsystem from the
+// reports of its tests. The default is the aggregator of the module, see ModuleAggregator, or
+// DefaultAggregator.
+func SubsystemAggregator(name string, a Aggregator) ComponentOption {
+	return func(c *component) error {
+		if !isSubsystem(name) {
+			return fmt.Errorf("unknown subsystem '%s'", name)
+		}
+		c.aggregators[name] = a
+		return nil
+	}
+}
+
+// OverallAggregator sets the aggregation strategy that computes the overall status from the status
+// of the subsystems. The deactivated subsystems are ignored. The default is DefaultAggregator.
+func OverallAggregator(a Aggregator) ComponentOption {
+	return func(c *component) error {
+		c.overall = a
+		return nil
+	}
+}
+
+// AllDeactivatedStatus sets the overall status when all the subsystems are Deactivated, e.g. in a minimal
+// deployment. It must be OK, the default, or Deactivated. With Deactivated, the gRPC health reports
+// UNKNOWN and WaitUntilReady waits unless Deactivated is one of the ReadyStatuses.
+func AllDeactivatedStatus(s Status) ComponentOption {
+	return func(c *component) error {
+		if s != OK && s != Deactivated {
+			return fmt.Errorf("overall status when all the subsystems are deactivated must be OK or Deactivated, got %s", s)
+		}
+		c.allDeactivated = s
+		return nil
+	}
+}
+
+// SoftDependencies classifies subsystems as soft dependencies, e.g. sentry: the service can run without
+// them. A KO soft dependency contributes only Degraded to the overall status, so the service stays ready,
+// while a KO hard dependency makes it KO. The subsystems are hard dependencies by default.
+func SoftDependencies(names ...string) ComponentOption {
+	return func(c *component) error {
+		for _, name := range names {
+			if !isSubsystem(name) {
+				return fmt.Errorf("unknown subsystem '%s'", name)
+			}
+			c.soft[name] = true
+		}
+		return nil
+	}
+}
+
+// WithDockerModule adds the Docker health module to the component.
+func WithDockerModule(docker DockerModule) ComponentOption {
+	return func(c *component) error {
+		c.docker = docker
+		return nil
+	}
+}
+
+// WithLogSinkModule adds the log sink health module to the component.
+func WithLogSinkModule(logSink LogSinkModule) ComponentOption {
+	return func(c *component) error {
+		c.logSink = logSink
+		return nil
+	}
+}
+
+// MaxConcurrentChecks bounds the number of subsystems checked concurrently, across all the callers
+// of the component, so the health checks never exceed a fixed outbound fan-out. A subsystem waits
+// for a free slot until the context is done, in which case it is reported as KO.
+func MaxConcurrentChecks(n int) ComponentOption {
+	return func(c *component) error {
+		if n <= 0 {
+			return fmt.Errorf("max concurrent checks must be positive, got %d", n)
+		}
+		c.slots = make(chan struct{}, n)
+		return nil
+	}
+}
+
+// WithE2EModule adds the end-to-end health module to the component.
+func WithE2EModule(e2e E2EModule) ComponentOption {
+	return func(c *component) error {
+		c.e2e = e2e
+		return nil
+	}
+}
+
+// StartupGracePeriod sets the period after the creation of the component during which the KO tests are
+// reported as Degraded, with the note "starting up". Right after the start, the dependencies may not be
+// connected yet, and the KO readiness responses would trigger restart loops.
+func StartupGracePeriod(d time.Duration) ComponentOption {
+	return func(c *component) error {
+		c.gracePeriod = d
+		return nil
+	}
+}
+
+// WithGRPCReflectionModule adds the gRPC reflection health module to the component.
+func WithGRPCReflectionModule(grpc GRPCReflectionModule) ComponentOption {
+	return func(c *component) error {
+		c.grpc = grpc
+		return nil
+	}
+}
+
+// WithEgressModule adds the egress health module to the component.
+func WithEgressModule(egress EgressModule) ComponentOption {
+	return func(c *component) error {
+		c.egress = egress
+		return nil
+	}
+}
+
+// WithFDModule adds the file descriptors health module to the component.
+func WithFDModule(fd FDModule) ComponentOption {
+	return func(c *component) error {
+		c.fd = fd
+		return nil
+	}
+}
+
+// WithIDGenModule adds the ID generator health module to the component.
+func WithIDGenModule(idgen IDGenModule) ComponentOption {
+	return func(c *component) error {
+		c.idgen = idgen
+		return nil
+	}
+}
+
+// WithFeatureFlagModule adds the feature flag health module to the component.
+func WithFeatureFlagModule(featureFlag FeatureFlagModule) ComponentOption {
+	return func(c *component) error {
+		c.featureFlag = featureFlag
+		return nil
+	}
+}
+
+// WithReplicaModule adds the SQL replica health module to the component.
+func WithReplicaModule(replica ReplicaModule) ComponentOption {
+	return func(c *component) error {
+		c.replica = replica
+		return nil
+	}
+}
+
+// WithCommandModule adds the external command health module to the component.
+func WithCommandModule(command CommandModule) ComponentOption {
+	return func(c *component) error {
+		c.command = command
+		return nil
+	}
+}
+
+// WithSelfModule adds the self HTTP listener health module to the component.
+func WithSelfModule(self SelfModule) ComponentOption {
+	return func(c *component) error {
+		c.self = self
+		return nil
+	}
+}
+
+// WithAuditLogModule adds the audit log health module to the component.
+func WithAuditLogModule(auditLog AuditLogModule) ComponentOption {
+	return func(c *component) error {
+		c.auditLog = auditLog
+		return nil
+	}
+}
+
+// WithCompositeModule adds the composite health module to the component.
+func WithCompositeModule(composite CompositeModule) ComponentOption {
+	return func(c *component) error {
+		c.composite = composite
+		return nil
+	}
+}
+
+// PriorityOrder executes the health checks of the critical subsystems first, in the given order, before
+// the other subsystems. When the context deadline is closer than margin, the remaining non-critical
+// subsystems are skipped and reported as Deactivated, with the error "skipped, deadline", rather than
+// risking a probe that times out before the critical subsystems are checked. A skipped subsystem that is
+// required is reported as KO, see RequiredSubsystems, as it was not checked.
+func PriorityOrder(margin time.Duration, critical ...string) ComponentOption {
+	return func(c *component) error {
+		if margin < 0 {
+			return fmt.Errorf("deadline margin must not be negative, got %v", margin)
+		}
+
+		var order = []string{}
+		var isCritical = map[string]bool{}
+		for _, name := range critical {
+			if !isSubsystem(name) {
+				return fmt.Errorf("unknown subsystem '%s'", name)
+			}
+			if !isCritical[name] {
+				order = append(order, name)
+				isCritical[name] = true
+			}
+		}
+		for _, name := range subsystems {
+			if !isCritical[name] {
+				order = append(order, name)
+			}
+		}
+
+		c.order = order
+		c.critical = isCritical
+		c.margin = margin
+		return nil
+	}
+}
+
+// GroupErrors groups the errors of the detailed report that share the same root cause, e.g. when a network
+// partition makes several subsystems fail with "connection refused". The root cause is the last part of the
+// error chain. The tests that fail with the same root cause are reported on one line, annotated with their
+// number and names, e.g. "connection refused (3 tests: influx/ping, redis/ping, sentry/ping)". The errors
+// are listed one per test by default.
+func GroupErrors() ComponentOption {
+	return func(c *component) error {
+		c.groupErrors = true
+		return nil
+	}
+}
+
+// SlowThreshold sets the default duration above which a passing test is Degraded, e.g. a ping that takes
+// seconds. The subsystems inherit it, unless it is overridden with SubsystemSlowThreshold. The effective
+// threshold is reported with the result of each test. 0, the default, means no threshold.
+func SlowThreshold(d time.Duration) ComponentOption {
+	return func(c *component) error {
+		if d < 0 {
+			return fmt.Errorf("slow threshold must not be negative, got %v", d)
+		}
+		c.slow = d
+		return nil
+	}
+}
+
+// SubsystemSlowThreshold overrides the slow threshold of a subsystem, see SlowThreshold. 0 means no
+// threshold for the subsystem.
+func SubsystemSlowThreshold(name string, d time.Duration) ComponentOption {
+	return func(c *component) error {
+		if !isSubsystem(name) {
+			return fmt.Errorf("unknown subsystem '%s'", name)
+		}
+		if d < 0 {
+			return fmt.Errorf("slow threshold of '%s' must not be negative, got %v", name, d)
+		}
+		c.slowBySubsystem[name] = d
+		return nil
+	}
+}
+
+// RunbookURLs sets the runbooks of the tests, reported with their results so that an alert links to the
+// procedure to follow. The keys are a subsystem and a test, e.g. "redis/ping", or a subsystem alone for all
+// its tests. The runbook of the test wins over the one of its subsystem.
+func RunbookURLs(urls map[string]string) ComponentOption {
+	return func(c *component) error {
+		for key, u := range urls {
+			if !isSubsystem(strings.SplitN(key, "/", 2)[0]) {
+				return fmt.Errorf("unknown subsystem in runbook '%s'", key)
+			}
+			if u == "" {
+				return fmt.Errorf("runbook url of '%s' must not be empty", key)
+			}
+			if err := validateURL(u); err != nil {
+				return fmt.Errorf("invalid runbook url of '%s': %v", key, err)
+			}
+			c.runbooks[key] = u
+		}
+		return nil
+	}
 }
 
 // NewComponent returns the health component.
-func NewComponent(influx InfluxModule, jaeger JaegerModule, redis RedisModule, sentry SentryModule) Component {
-	return &component{
-		influx: influx,
-		jaeger: jaeger,
-		redis:  redis,
-		sentry: sentry,
+func NewComponent(influx InfluxModule, jaeger JaegerModule, redis RedisModule, sentry SentryModule, options ...ComponentOption) (Component, error) {
+	var c = &component{
+		influx:   influx,
+		jaeger:   jaeger,
+		redis:    redis,
+		sentry:   sentry,
+		required: map[string]bool{},
+		soft:     map[string]bool{},
+		ready:    []Status{OK, Degraded},
+
+		nilStatus: map[string]Status{},
+
+		deps:     map[string][]string{},
+		failures: map[string]int{},
+
+		slowBySubsystem: map[string]time.Duration{},
+		runbooks:        map[string]string{},
+		disabled:        map[string]bool{},
+		last:            map[string]Reports{},
+
+		aggregators: map[string]Aggregator{},
+		overall:     DefaultAggregator{},
+
+		started: time.Now(),
+
+		order:    subsystems,
+		critical: map[string]bool{},
+	}
+
+	// Apply options.
+	for _, opt := range options {
+		var err = opt(c)
+		if err != nil {
+			return nil, err
+		}
 	}
+
+	// Reject the invalid configurations of the modules.
+	var modules = c.modules()
+	for _, name := range subsystems {
+		if err := validate(modules[name]); err != nil {
+			return nil, fmt.Errorf("%s health module: %v", name, err)
+		}
+	}
+
+	c.checks = map[string]func(context.Context) []Report{}
+	c.deactivated = map[string]bool{}
+	for name, m := range modules {
+		if checks := moduleChecks(m); checks != nil {
+			c.checks[name] = checks
+			c.deactivated[name] = !isEnabled(m)
+		}
+		if _, ok := c.aggregators[name]; !ok {
+			if a := moduleAggregator(m); a != nil {
+				c.aggregators[name] = a
+			}
+		}
+	}
+
+	return c, nil
 }
 
 // InfluxHealthChecks uses the health component to test the Influx health.
 func (c *component) InfluxHealthChecks(ctx context.Context) Reports {
-	var reports = c.influx.HealthChecks(ctx)
-	var hr = Reports{}
-	for _, r := range reports {
-		hr.Reports = append(hr.Reports, Report(r))
-	}
-	return hr
+	return c.healthChecks(ctx, "influx")
 }
 
 // JaegerHealthChecks uses the health component to test the Jaeger health.
 func (c *component) JaegerHealthChecks(ctx context.Context) Reports {
-	var reports = c.jaeger.HealthChecks(ctx)
-	var hr = Reports{}
-	for _, r := range reports {
-		hr.Reports = append(hr.Reports, Report(r))
-	}
-	return hr
+	return c.healthChecks(ctx, "jaeger")
 }
 
 // RedisHealthChecks uses the health component to test the Redis health.
 func (c *component) RedisHealthChecks(ctx context.Context) Reports {
-	var reports = c.redis.HealthChecks(ctx)
-	var hr = Reports{}
-	for _, r := range reports {
-		hr.Reports = append(hr.Reports, Report(r))
-	}
-	return hr
+	return c.healthChecks(ctx, "redis")
 }
 
 // SentryHealthChecks uses the health component to test the Sentry health.
 func (c *component) SentryHealthChecks(ctx context.Context) Reports {
-	var reports = c.sentry.HealthChecks(ctx)
-	var hr = Reports{}
-	for _, r := range reports {
-		hr.Reports = append(hr.Reports, Report(r))
+	return c.healthChecks(ctx, "sentry")
+}
+
+// SMTPHealthChecks uses the health component to test the SMTP health.
+func (c *component) SMTPHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "smtp")
+}
+
+// DockerHealthChecks uses the health component to test the Docker health.
+func (c *component) DockerHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "docker")
+}
+
+// LogSinkHealthChecks uses the health component to test the log sink health.
+func (c *component) LogSinkHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "logsink")
+}
+
+// E2EHealthChecks uses the health component to test the end-to-end health.
+func (c *component) E2EHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "e2e")
+}
+
+// GRPCReflectionHealthChecks uses the health component to test the health of the gRPC server.
+func (c *component) GRPCReflectionHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "grpc")
+}
+
+// EgressHealthChecks uses the health component to test the outbound connectivity.
+func (c *component) EgressHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "egress")
+}
+
+// FDHealthChecks uses the health component to test the file descriptors health.
+func (c *component) FDHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "fd")
+}
+
+// IDGenHealthChecks uses the health component to test the ID generator health.
+func (c *component) IDGenHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "idgen")
+}
+
+// FeatureFlagHealthChecks uses the health component to test the feature flag health.
+func (c *component) FeatureFlagHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "featureflag")
+}
+
+// ReplicaHealthChecks uses the health component to test the SQL replica health.
+func (c *component) ReplicaHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "replica")
+}
+
+// CommandHealthChecks uses the health component to test the external command health.
+func (c *component) CommandHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "command")
+}
+
+// SelfHealthChecks uses the health component to test the self HTTP listener health.
+func (c *component) SelfHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "self")
+}
+
+// AuditLogHealthChecks uses the health component to test the audit log health.
+func (c *component) AuditLogHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "auditlog")
+}
+
+// CompositeHealthChecks uses the health component to test the composite health.
+func (c *component) CompositeHealthChecks(ctx context.Context) Reports {
+	return c.healthChecks(ctx, "composite")
+}
+
+// healthChecks executes the health checks of the subsystem. A subsystem disabled at runtime or whose
+// module is not configured or deactivated is not checked. Otherwise the checks wait for a check slot, and their reports
+// are adjusted for the slow threshold, the startup grace period, the required subsystems and the runbooks.
+func (c *component) healthChecks(ctx context.Context, subsystem string) Reports {
+	if c.isDisabled(subsystem) {
+		return disabledAtRuntime()
+	}
+	var checks, ok = c.checks[subsystem]
+	if !ok {
+		return c.withRunbooks(subsystem, c.checkRequired(subsystem, c.notConfigured(subsystem)))
+	}
+	if c.deactivated[subsystem] {
+		return c.withRunbooks(subsystem, c.checkRequired(subsystem, deactivated()))
+	}
+
+	var err = c.acquire(ctx)
+	if err != nil {
+		return notExecuted(err)
+	}
+	defer c.release()
+
+	// The reports are copied, the adjustments modify them.
+	var hr = Reports{Reports: append([]Report{}, checks(ctx)...)}
+	return c.withRunbooks(subsystem, c.checkRequired(subsystem, c.checkStartup(c.checkSlow(subsystem, checkExecuted(hr)))))
+}
+
+// Enabler is implemented by the health modules that can be deactivated by configuration, and by their
+// middlewares. The health checks of a deactivated module are not executed, the component reports its
+// subsystem Deactivated without calling the module nor its middlewares.
+type Enabler interface {
+	Enabled() bool
+}
+
+// isEnabled returns false if the module m is deactivated by configuration. The modules that do not
+// implement Enabler are enabled.
+func isEnabled(m interface{}) bool {
+	if e, ok := m.(Enabler); ok {
+		return e.Enabled()
+	}
+	return true
+}
+
+// ModuleAggregator is implemented by the health modules whose reports need their own aggregation, and by
+// their middlewares. The aggregator of the module computes the status of its subsystem, unless another one
+// is set with SubsystemAggregator.
+type ModuleAggregator interface {
+	Aggregator() Aggregator
+}
+
+// moduleAggregator returns the aggregator of the module m, nil if it has none.
+func moduleAggregator(m interface{}) Aggregator {
+	if a, ok := m.(ModuleAggregator); ok {
+		return a.Aggregator()
 	}
-	return hr
+	return nil
+}
+
+// moduleChecks returns the health checks of the module m as Reports, nil if m is nil. The specific fields
+// of the influx and redis reports are dropped.
+func moduleChecks(m interface{}) func(context.Context) []Report {
+	switch m := m.(type) {
+	case InfluxModule:
+		return func(ctx context.Context) []Report {
+			var reports = []Report{}
+			for _, r := range m.HealthChecks(ctx) {
+				reports = append(reports, r.Report)
+			}
+			return reports
+		}
+	case RedisModule:
+		return func(ctx context.Context) []Report {
+			var reports = []Report{}
+			for _, r := range m.HealthChecks(ctx) {
+				reports = append(reports, r.Report)
+			}
+			return reports
+		}
+	case interface {
+		HealthChecks(context.Context) []Report
+	}:
+		return m.HealthChecks
+	}
+	return nil
 }
 
 // AllChecks call all component checks and build a general health report.
 func (c *component) AllHealthChecks(ctx context.Context) map[string]string {
 	var reports = map[string]string{}
 
-	reports["influx"] = determineStatus(c.InfluxHealthChecks(ctx))
-	reports["jaeger"] = determineStatus(c.JaegerHealthChecks(ctx))
-	reports["redis"] = determineStatus(c.RedisHealthChecks(ctx))
-	reports["sentry"] = determineStatus(c.SentryHealthChecks(ctx))
+	for name, s := range c.allStatuses(ctx) {
+		reports[name] = s.String()
+	}
 
 	return reports
 }
 
-// determineStatus parse all the tests reports and output a global status.
-func determineStatus(reports Reports) string {
-	var degraded = false
+// AllHealthChecksDetailed call all component checks and build a detailed health report.
+func (c *component) AllHealthChecksDetailed(ctx context.Context) DetailedReport {
+	var now = time.Now()
+	var report = c.detailedReport(c.allReports(ctx))
+	report.TotalDuration = time.Since(now)
+	return report
+}
+
+// HealthChecksDetailed executes the health checks of the given subsystems only, e.g. to check the cheap
+// subsystems more often than the expensive ones. The other subsystems are reported with the reports of
+// their last execution, those never executed are absent. The overall status and the errors cover all the
+// reported subsystems. The unknown subsystems are ignored.
+func (c *component) HealthChecksDetailed(ctx context.Context, subsystems ...string) DetailedReport {
+	var names = map[string]bool{}
+	for _, name := range subsystems {
+		names[name] = true
+	}
+
+	var now = time.Now()
+	var report = c.detailedReport(c.reportsOf(ctx, names))
+	report.TotalDuration = time.Since(now)
+	return report
+}
+
+// detailedReport returns the detailed report of the subsystems reports.
+func (c *component) detailedReport(reports map[string]Reports) DetailedReport {
+	var failures = c.consecutiveFailures()
+	var statuses = map[string]Status{}
+	var subsystems = map[string]SubsystemReport{}
+	for name, r := range reports {
+		var s = c.subsystemReport(name, r)
+		s.ConsecutiveFailures = failures[name]
+		statuses[name] = s.Status
+		subsystems[name] = s
+	}
+
+	var errs []string
+	if c.groupErrors {
+		errs = groupReportErrors(reports)
+	} else {
+		errs = reportErrors(reports)
+	}
+	if c.isShuttingDown() {
+		errs = append([]string{"shutting down"}, errs...)
+	}
+
+	return DetailedReport{
+		Status:     c.overallStatus(statuses),
+		Subsystems: subsystems,
+		Errors:     errs,
+	}
+}
+
+// subsystemReport returns the report of the subsystem, without its consecutive failures.
+func (c *component) subsystemReport(name string, r Reports) SubsystemReport {
+	return SubsystemReport{
+		Status:        c.status(name, r),
+		Reports:       r.Reports,
+		ScrapeError:   r.ScrapeError,
+		Dependency:    c.dependency(name),
+		SlowThreshold: r.SlowThreshold,
+		RunbookURLs:   r.RunbookURLs,
+	}
+}
+
+// OverallStatusWithReason call all component checks and returns the overall status with a concise reason,
+// e.g. "redis: ping KO (could not ping redis: dial tcp: i/o timeout); influx: write KO". The reason lists
+// the tests that are not OK in the subsystems that are not OK, it is empty when everything is OK.
+func (c *component) OverallStatusWithReason(ctx context.Context) (Status, string) {
+	var reports = c.allReports(ctx)
+
+	var statuses = map[string]Status{}
+	var reasons = []string{}
+	if c.isShuttingDown() {
+		reasons = append(reasons, "shutting down")
+	}
+	for _, name := range subsystems {
+		statuses[name] = c.status(name, reports[name])
+		if statuses[name] == OK || statuses[name] == Deactivated {
+			continue
+		}
+
+		for _, r := range reports[name].Reports {
+			if r.Status == OK {
+				continue
+			}
+
+			var reason = fmt.Sprintf("%s: %s %s", name, r.Name, r.Status.String())
+			if r.Error != "" {
+				reason = fmt.Sprintf("%s (%s)", reason, r.Error)
+			}
+			reasons = append(reasons, reason)
+		}
+	}
+
+	return c.overallStatus(statuses), strings.Join(reasons, "; ")
+}
+
+// WaitUntilReady runs all health checks every pollInterval, until the overall status is one of the ready
+// statuses or the context is done. It returns the last overall status. It is a readiness gate for the startup.
+func (c *component) WaitUntilReady(ctx context.Context, pollInterval time.Duration) Status {
+	c.Warmup(ctx)
+
+	var tic = time.NewTicker(pollInterval)
+	defer tic.Stop()
+
+	for {
+		var s = c.overallStatus(c.allStatuses(ctx))
+		if c.isReady(s) {
+			return s
+		}
+
+		select {
+		case <-ctx.Done():
+			return s
+		case <-tic.C:
+		}
+	}
+}
+
+// Warmup warms up the health modules that implement Warmer. The subsystems disabled at runtime are
+// skipped. It is called by WaitUntilReady, before the first health checks.
+func (c *component) Warmup(ctx context.Context) {
+	var modules = c.modules()
+	for _, name := range subsystems {
+		if c.isDisabled(name) {
+			continue
+		}
+		warmup(ctx, modules[name])
+	}
+}
+
+// modules returns the health module of each subsystem.
+func (c *component) modules() map[string]interface{} {
+	return map[string]interface{}{
+		"influx":      c.influx,
+		"jaeger":      c.jaeger,
+		"redis":       c.redis,
+		"sentry":      c.sentry,
+		"smtp":        c.smtp,
+		"docker":      c.docker,
+		"logsink":     c.logSink,
+		"e2e":         c.e2e,
+		"grpc":        c.grpc,
+		"egress":      c.egress,
+		"fd":          c.fd,
+		"idgen":       c.idgen,
+		"featureflag": c.featureFlag,
+		"replica":     c.replica,
+		"command":     c.command,
+		"self":        c.self,
+		"auditlog":    c.auditLog,
+		"composite":   c.composite,
+	}
+}
+
+// Subsystems returns the subsystems whose health module is configured, i.e. not nil, in the order of the
+// reports. The other subsystems are reported as not configured.
+func (c *component) Subsystems() []string {
+	var modules = c.modules()
+	var names = []string{}
+	for _, name := range subsystems {
+		if modules[name] != nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// warmup calls Warmup if m implements Warmer. The middlewares use it to forward the warm up to the
+// module they wrap.
+func warmup(ctx context.Context, m interface{}) {
+	if w, ok := m.(Warmer); ok {
+		w.Warmup(ctx)
+	}
+}
+
+// reset calls Reset if m implements Resetter. The middlewares use it to forward the reset to the module
+// they wrap.
+func reset(m interface{}) {
+	if r, ok := m.(Resetter); ok {
+		r.Reset()
+	}
+}
+
+// Enable re-enables a subsystem disabled at runtime with Disable. The next health checks of the subsystem
+// are executed.
+func (c *component) Enable(subsystem string) error {
+	if !isSubsystem(subsystem) {
+		return fmt.Errorf("unknown subsystem '%s'", subsystem)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.disabled, subsystem)
+	return nil
+}
+
+// Disable disables a subsystem at runtime, e.g. during the maintenance window of a dependency. Until it is
+// enabled again, the health checks of the subsystem are not executed and it is reported as Deactivated,
+// even if it is required.
+func (c *component) Disable(subsystem string) error {
+	if !isSubsystem(subsystem) {
+		return fmt.Errorf("unknown subsystem '%s'", subsystem)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.disabled[subsystem] = true
+	return nil
+}
+
+// BeginShutdown marks the service as shutting down: from then on, the overall status is KO with the reason
+// "shutting down", so the load balancers drain the instance before it stops serving. The health checks are
+// still executed and the status of the subsystems is unchanged, and the liveness is not affected, see
+// MakeLivenessHandler. It cannot be undone.
+func (c *component) BeginShutdown() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.shuttingDown = true
+}
+
+// Reset clears the state accumulated by the health checks, e.g. after a maintenance window, so the service
+// does not keep showing the failures observed during it. It clears the consecutive failures, the last
+// reports of the subsystems, the history of the statuses, and the state of the health modules that
+// implement Resetter: the cached reports of the sampled checks and the durations of the adaptive timeouts.
+// The subsystems disabled at runtime and the shutdown are operator decisions, they are kept.
+func (c *component) Reset() {
+	c.mutex.Lock()
+	c.failures = map[string]int{}
+	c.last = map[string]Reports{}
+	for name, h := range c.history {
+		c.history[name] = newStatusHistory(len(h.entries))
+	}
+	c.mutex.Unlock()
+
+	for _, m := range c.modules() {
+		reset(m)
+	}
+}
+
+// isShuttingDown returns true once BeginShutdown is called.
+func (c *component) isShuttingDown() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.shuttingDown
+}
+
+// isDisabled returns true if the subsystem is disabled at runtime.
+func (c *component) isDisabled(subsystem string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.disabled[subsystem]
+}
+
+// allStatuses executes the health checks of all subsystems and returns their status.
+func (c *component) allStatuses(ctx context.Context) map[string]Status {
+	var statuses = map[string]Status{}
+
+	for name, reports := range c.allReports(ctx) {
+		statuses[name] = c.status(name, reports)
+	}
+
+	return statuses
+}
+
+// allReports executes the health checks of all subsystems and returns their reports.
+func (c *component) allReports(ctx context.Context) map[string]Reports {
+	return c.reportsOf(ctx, nil)
+}
+
+// reportsOf executes the health checks of the given subsystems, all of them if names is nil, and returns
+// their reports completed with the last reports of the other subsystems. The consecutive failures, the
+// history, the statuses gauge and the remediation are updated for the executed subsystems only.
+func (c *component) reportsOf(ctx context.Context, names map[string]bool) map[string]Reports {
+	var executed = map[string]Reports{}
+	var notified = map[string]bool{}
+	for _, name := range c.order {
+		if names != nil && !names[name] {
+			continue
+		}
+		if !c.critical[name] && c.deadlineApproaching(ctx) {
+			executed[name] = c.withRunbooks(name, c.checkRequired(name, skippedDeadline()))
+		} else {
+			executed[name] = c.healthChecks(ctx, name)
+		}
+		c.notifyReports(ctx, names, executed, notified)
+	}
+
+	// The cascading failures are suppressed with the last reports of the dependencies not executed.
+	var reports = c.updateLastReports(executed)
+	reports = c.suppressCascade(reports)
+	for name := range executed {
+		executed[name] = reports[name]
+	}
+
+	var transitions = c.trackFailures(executed)
+	c.recordHistory(executed)
+	c.exportStatuses(executed)
+	c.remediate(ctx, transitions)
+	return reports
+}
+
+// updateLastReports records the reports of the executed subsystems as their last reports, and returns the
+// last reports of all the subsystems executed so far. The returned reports are copies, the suppression of
+// the cascading failures modifies them.
+func (c *component) updateLastReports(executed map[string]Reports) map[string]Reports {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for name, r := range executed {
+		c.last[name] = r
+	}
+
+	var reports = map[string]Reports{}
+	for name, r := range c.last {
+		reports[name] = Reports{
+			Reports:       append([]Report{}, r.Reports...),
+			ScrapeError:   r.ScrapeError,
+			SlowThreshold: r.SlowThreshold,
+			RunbookURLs:   r.RunbookURLs,
+		}
+	}
+	return reports
+}
+
+// deadlineApproaching returns true if the checks are prioritized and the context deadline is closer than
+// the margin.
+func (c *component) deadlineApproaching(ctx context.Context) bool {
+	if len(c.critical) == 0 {
+		return false
+	}
+
+	var deadline, ok = ctx.Deadline()
+	return ok && time.Until(deadline) <= c.margin
+}
+
+// reportErrors returns the non-empty errors of the reports, prefixed with the subsystem and the test, in
+// the order of the subsystems.
+func reportErrors(reports map[string]Reports) []string {
+	var errors = []string{}
+	for _, name := range subsystems {
+		for _, r := range reports[name].Reports {
+			if r.Error != "" {
+				errors = append(errors, fmt.Sprintf("%s/%s: %s", name, r.Name, r.Error))
+			}
+		}
+	}
+	return errors
+}
+
+// groupReportErrors returns the errors of the reports grouped by root cause, in the order of their first
+// occurrence. A root cause shared by several tests is reported once, followed by the number of tests and
+// their names. The error is kept whole if it is identical for all the tests, only the root cause is kept
+// otherwise. The errors whose root cause is unique are formatted as in reportErrors.
+func groupReportErrors(reports map[string]Reports) []string {
+	type group struct {
+		tests  []string
+		errors []string
+	}
+
+	var causes = []string{}
+	var groups = map[string]*group{}
+	for _, name := range subsystems {
+		for _, r := range reports[name].Reports {
+			if r.Error == "" {
+				continue
+			}
+
+			var cause = rootCause(r.Error)
+			var g, ok = groups[cause]
+			if !ok {
+				g = &group{}
+				groups[cause] = g
+				causes = append(causes, cause)
+			}
+			g.tests = append(g.tests, fmt.Sprintf("%s/%s", name, r.Name))
+			g.errors = append(g.errors, r.Error)
+		}
+	}
+
+	var errors = []string{}
+	for _, cause := range causes {
+		var g = groups[cause]
+		if len(g.tests) == 1 {
+			errors = append(errors, fmt.Sprintf("%s: %s", g.tests[0], g.errors[0]))
+			continue
+		}
+
+		var msg = g.errors[0]
+		for _, e := range g.errors[1:] {
+			if e != msg {
+				msg = cause
+				break
+			}
+		}
+		errors = append(errors, fmt.Sprintf("%s (%d tests: %s)", msg, len(g.tests), strings.Join(g.tests, ", ")))
+	}
+	return errors
+}
+
+// rootCause returns the last part of an error chain, e.g. "connection refused" for "could not ping redis:
+// dial tcp 10.0.0.4:6379: connect: connection refused".
+func rootCause(err string) string {
+	if i := strings.LastIndex(err, ": "); i >= 0 {
+		return err[i+2:]
+	}
+	return err
+}
+
+// trackFailures updates the consecutive failures count of each subsystem. It returns the subsystems that
+// transitioned to KO, in the order of the subsystems.
+func (c *component) trackFailures(reports map[string]Reports) []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for name, r := range reports {
+		if c.status(name, r) == KO {
+			c.failures[name]++
+		} else {
+			c.failures[name] = 0
+		}
+	}
+
+	var transitions = []string{}
+	for _, name := range subsystems {
+		if _, ok := reports[name]; ok && c.failures[name] == 1 {
+			transitions = append(transitions, name)
+		}
+	}
+	return transitions
+}
+
+// exportStatuses sets the status gauge of each subsystem, if the statuses are exported.
+func (c *component) exportStatuses(reports map[string]Reports) {
+	if c.statusGauge == nil {
+		return
+	}
+	for name, r := range reports {
+		c.statusGauge.With("subsystem", name).Set(float64(c.status(name, r)))
+	}
+}
+
+// remediate invokes the remediation for each subsystem that transitioned to KO, and logs its result.
+func (c *component) remediate(ctx context.Context, transitions []string) {
+	if c.remediation == nil {
+		return
+	}
+
+	for _, name := range transitions {
+		var err = c.remediation(ctx, name)
+		if err != nil {
+			c.remediationLogger.Log("msg", "remediation failed", "subsystem", name, "error", err)
+		} else {
+			c.remediationLogger.Log("msg", "remediation done", "subsystem", name)
+		}
+	}
+}
+
+// consecutiveFailures returns a copy of the consecutive failures count of each subsystem.
+func (c *component) consecutiveFailures() map[string]int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var failures = map[string]int{}
+	for name, n := range c.failures {
+		failures[name] = n
+	}
+	return failures
+}
+
+// suppressCascade reports the KO tests of a subsystem as Degraded when one of its dependencies is KO.
+func (c *component) suppressCascade(reports map[string]Reports) map[string]Reports {
+	var statuses = map[string]Status{}
+	for name, r := range reports {
+		statuses[name] = c.status(name, r)
+	}
+
+	for name := range c.deps {
+		if r, ok := reports[name]; ok {
+			c.suppress(name, r, statuses)
+		}
+	}
+
+	return reports
+}
+
+// suppress reports the KO tests of the subsystem as Degraded, in place, when one of its dependencies is
+// KO in statuses.
+func (c *component) suppress(name string, reports Reports, statuses map[string]Status) {
+	for _, d := range c.deps[name] {
+		if statuses[d] != KO {
+			continue
+		}
+
+		for i, r := range reports.Reports {
+			if r.Status == KO {
+				reports.Reports[i].Status = Degraded
+				reports.Reports[i].Error = fmt.Sprintf("suppressed due to dependency %s: %s", d, r.Error)
+			}
+		}
+		return
+	}
+}
+
+// notifyReports calls the SubsystemReportFunc of the context, if any, with the report of each executed
+// subsystem not notified yet whose dependencies are executed, see WithSubsystemReportFunc. The cascading
+// failures are suppressed as in the detailed report, on copies of the reports. The dependencies that are
+// not part of the run are not waited for, as they are not suppressed against either.
+func (c *component) notifyReports(ctx context.Context, names map[string]bool, executed map[string]Reports, notified map[string]bool) {
+	var f, ok = ctx.Value(subsystemReportFuncKey).(SubsystemReportFunc)
+	if !ok {
+		return
+	}
+
+	var statuses = map[string]Status{}
+	for name, r := range executed {
+		statuses[name] = c.status(name, r)
+	}
+
+	for _, name := range c.order {
+		var r, ok = executed[name]
+		if !ok || notified[name] || !c.dependenciesExecuted(name, names, executed) {
+			continue
+		}
+
+		r.Reports = append([]Report{}, r.Reports...)
+		c.suppress(name, r, statuses)
+		f(name, c.subsystemReport(name, r))
+		notified[name] = true
+	}
+}
+
+// dependenciesExecuted returns true if the dependencies of the subsystem that are part of the run, all of
+// them if names is nil, are executed.
+func (c *component) dependenciesExecuted(name string, names map[string]bool, executed map[string]Reports) bool {
+	for _, d := range c.deps[name] {
+		if _, ok := executed[d]; !ok && (names == nil || names[d]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isReady returns true if s is one of the ready statuses.
+func (c *component) isReady(s Status) bool {
+	for _, r := range c.ready {
+		if r == s {
+			return true
+		}
+	}
+	return false
+}
+
+// slowThreshold returns the effective slow threshold of the subsystem, 0 if there is none.
+func (c *component) slowThreshold(subsystem string) time.Duration {
+	if d, ok := c.slowBySubsystem[subsystem]; ok {
+		return d
+	}
+	return c.slow
+}
+
+// checkSlow reports the OK tests that took longer than the slow threshold of the subsystem as Degraded.
+func (c *component) checkSlow(subsystem string, reports Reports) Reports {
+	var threshold = c.slowThreshold(subsystem)
+	if threshold == 0 {
+		return reports
+	}
+
+	reports.SlowThreshold = threshold.String()
+	for i, r := range reports.Reports {
+		var d, err = time.ParseDuration(r.Duration)
+		if err != nil {
+			continue
+		}
+		if r.Status == OK && d > threshold {
+			reports.Reports[i].Status = Degraded
+			reports.Reports[i].Error = fmt.Sprintf("slow: took %v, above the threshold of %v", d, threshold)
+			reports.Reports[i].Category = Timeout
+		}
+	}
+	return reports
+}
+
+// checkStartup reports the KO tests as Degraded during the startup grace period.
+func (c *component) checkStartup(reports Reports) Reports {
+	if time.Since(c.started) >= c.gracePeriod {
+		return reports
+	}
+
+	for i, r := range reports.Reports {
+		if r.Status == KO {
+			reports.Reports[i].Status = Degraded
+			reports.Reports[i].Error = fmt.Sprintf("starting up: %s", r.Error)
+		}
+	}
+	return reports
+}
+
+// checkRequired reports the Deactivated tests of a required subsystem as KO. The reason of the
+// deactivation, e.g. "skipped, deadline", is kept in the error and the scrape error.
+func (c *component) checkRequired(subsystem string, reports Reports) Reports {
+	if !c.required[subsystem] {
+		return reports
+	}
+
+	for i, r := range reports.Reports {
+		if r.Status != Deactivated {
+			continue
+		}
+
+		reports.Reports[i].Status = KO
+		reports.Reports[i].Error = "required subsystem deactivated"
+		if r.Error != "" {
+			reports.Reports[i].Error = fmt.Sprintf("required subsystem deactivated: %s", r.Error)
+		}
+		if reports.ScrapeError == "" {
+			reports.ScrapeError = "required subsystem deactivated"
+		}
+	}
+	return reports
+}
+
+// withRunbooks sets the runbooks of the tests of the subsystem, see RunbookURLs.
+func (c *component) withRunbooks(subsystem string, reports Reports) Reports {
 	for _, r := range reports.Reports {
-		switch r.Status {
-		case Deactivated:
-			// If the status is Deactivated, we do not need to go through all tests reports, all
-			// status will be the same.
-			return Deactivated.String()
-		case KO:
-			return KO.String()
-		case Degraded:
-			degraded = true
+		var u, ok = c.runbooks[subsystem+"/"+r.Name]
+		if !ok {
+			u, ok = c.runbooks[subsystem]
+		}
+		if !ok {
+			continue
+		}
+		if reports.RunbookURLs == nil {
+			reports.RunbookURLs = map[string]string{}
+		}
+		reports.RunbookURLs[r.Name] = u
+	}
+	return reports
+}
+
+// findCycle returns a cycle in the dependency graph, or nil if there is none.
+func findCycle(deps map[string][]string) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var state = map[string]int{}
+	var path []string
+	var cycle []string
+
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		switch state[name] {
+		case visiting:
+			// Extract the cycle from the current path.
+			for i, n := range path {
+				if n == name {
+					cycle = append(append([]string{}, path[i:]...), name)
+				}
+			}
+			return true
+		case visited:
+			return false
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, d := range deps[name] {
+			if visit(d) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return false
+	}
+
+	// Visit in a deterministic order.
+	for _, name := range subsystems {
+		if visit(name) {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// acquire waits for a free check slot, until the context is done.
+func (c *component) acquire(ctx context.Context) error {
+	if c.slots == nil {
+		return nil
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the check slot taken by acquire.
+func (c *component) release() {
+	if c.slots != nil {
+		<-c.slots
+	}
+}
+
+// notExecuted returns the reports of a subsystem whose health checks could not be executed, because
+// no check slot was free before the context was done.
+func notExecuted(err error) Reports {
+	return Reports{
+		Reports: []Report{{
+			Name:     "not executed",
+			Duration: "N/A",
+			Status:   KO,
+			Error:    fmt.Sprintf("could not get a check slot: %v", err.Error()),
+			Category: Capacity,
+		}},
+		ScrapeError: "no check slot",
+	}
+}
+
+// checkExecuted returns the reports of a subsystem, or a KO report if its health module returned none.
+// A module that could not even start its tests must not be reported as healthy.
+func checkExecuted(reports Reports) Reports {
+	if len(reports.Reports) > 0 {
+		return reports
+	}
+
+	return Reports{
+		Reports: []Report{{
+			Name:     "no checks executed",
+			Duration: "N/A",
+			Status:   KO,
+			Error:    "the health module returned no report",
+		}},
+		ScrapeError: "no report",
+	}
+}
+
+// notConfigured returns the reports of a subsystem whose health module is not configured. The report is
+// synthesized inline, without any call to a health module, with the status set by NilModuleStatus.
+func (c *component) notConfigured(subsystem string) Reports {
+	var s, ok = c.nilStatus[subsystem]
+	if !ok {
+		s = Deactivated
+	}
+
+	var report = Report{
+		Name:        "not configured",
+		Description: "The health module of the subsystem is not configured, so the subsystem is not checked.",
+		Duration:    "N/A",
+		Status:      s,
+	}
+	if s == KO {
+		report.Error = "health module not configured"
+		return Reports{Reports: []Report{report}, ScrapeError: "not configured"}
+	}
+	return Reports{Reports: []Report{report}}
+}
+
+// deactivated returns the reports of a subsystem whose health module is deactivated by configuration. The
+// Deactivated report is synthesized inline, without any call to the health module.
+func deactivated() Reports {
+	return Reports{
+		Reports: []Report{{
+			Name:        "deactivated",
+			Description: "The health module of the subsystem is deactivated by configuration, so the subsystem is not checked.",
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}},
+	}
+}
+
+// disabledAtRuntime returns the reports of a subsystem disabled at runtime. The Deactivated report is
+// synthesized inline, without any call to a health module.
+func disabledAtRuntime() Reports {
+	return Reports{
+		Reports: []Report{{
+			Name:        "disabled",
+			Description: "The subsystem is disabled at runtime, so the subsystem is not checked until it is enabled again.",
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}},
+	}
+}
+
+// skippedDeadline returns the reports of a non-critical subsystem skipped because the deadline was
+// approaching. The Deactivated report is synthesized inline, without any call to a health module. It is
+// KO once checked by checkRequired if the subsystem is required.
+func skippedDeadline() Reports {
+	return Reports{
+		Reports: []Report{{
+			Name:        "skipped",
+			Description: "The deadline was approaching after the checks of the critical subsystems, so the subsystem is not checked.",
+			Duration:    "N/A",
+			Status:      Deactivated,
+			Error:       "skipped, deadline",
+		}},
+		ScrapeError: "skipped, deadline",
+	}
+}
+
+// isSubsystem returns true if name is a subsystem monitored by the health component.
+func isSubsystem(name string) bool {
+	for _, s := range subsystems {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// status computes the status of a subsystem from its reports, with the aggregator of the subsystem.
+func (c *component) status(subsystem string, reports Reports) Status {
+	var a, ok = c.aggregators[subsystem]
+	if !ok {
+		a = DefaultAggregator{}
+	}
+	return a.Aggregate(reports.Reports)
+}
+
+// dependency returns the classification of the subsystem, "hard" or "soft".
+func (c *component) dependency(subsystem string) string {
+	if c.soft[subsystem] {
+		return "soft"
+	}
+	return "hard"
+}
+
+// overallStatus computes the status of the service from the status of its subsystems, with the
+// overall aggregator. The deactivated subsystems are ignored, and the KO soft dependencies count as
+// Degraded. If all the subsystems are deactivated, the status is the one set with AllDeactivatedStatus.
+// It is KO once the service is shutting down.
+func (c *component) overallStatus(statuses map[string]Status) Status {
+	if c.isShuttingDown() {
+		return KO
+	}
+
+	var reports = []Report{}
+	for _, name := range subsystems {
+		var s, ok = statuses[name]
+		if !ok || s == Deactivated {
+			continue
+		}
+		if s == KO && c.soft[name] {
+			s = Degraded
 		}
+		reports = append(reports, Report{Name: name, Status: s})
 	}
-	if degraded {
-		return Degraded.String()
+	if len(reports) == 0 {
+		return c.allDeactivated
 	}
-	return OK.String()
+	return c.overall.Aggregate(reports)
 }
@@ -2,11 +2,17 @@ package health_test
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	. "github.com/cloudtrust/flaki-service/pkg/health"
 	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/go-kit/kit/log"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -19,12 +25,13 @@ func TestHealthChecks(t *testing.T) {
 	var mockRedisModule = mock.NewRedisModule(mockCtrl)
 	var mockSentryModule = mock.NewSentryModule(mockCtrl)
 
-	mockInfluxModule.EXPECT().HealthChecks(context.Background()).Return([]InfluxReport{{Name: "influx", Duration: time.Duration(1 * time.Second).String(), Status: OK}}).Times(2)
+	mockInfluxModule.EXPECT().HealthChecks(context.Background()).Return([]InfluxReport{{Report: Report{Name: "influx", Duration: time.Duration(1 * time.Second).String(), Status: OK}}}).Times(2)
 	mockJaegerModule.EXPECT().HealthChecks(context.Background()).Return([]JaegerReport{{Name: "jaeger", Duration: time.Duration(1 * time.Second).String(), Status: OK}}).Times(2)
-	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Name: "redis", Duration: time.Duration(1 * time.Second).String(), Status: OK}}).Times(2)
+	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Report: Report{Name: "redis", Duration: time.Duration(1 * time.Second).String(), Status: OK}}}).Times(2)
 	mockSentryModule.EXPECT().HealthChecks(context.Background()).Return([]SentryReport{{Name: "sentry", Duration: time.Duration(1 * time.Second).String(), Status: OK}}).Times(2)
 
-	var c = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule)
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule)
+	assert.Nil(t, err)
 
 	// Influx.
 	{
@@ -79,12 +86,13 @@ func TestHealthChecksFail(t *testing.T) {
 	var mockRedisModule = mock.NewRedisModule(mockCtrl)
 	var mockSentryModule = mock.NewSentryModule(mockCtrl)
 
-	mockInfluxModule.EXPECT().HealthChecks(context.Background()).Return([]InfluxReport{{Name: "influx", Duration: time.Duration(1 * time.Second).String(), Status: Deactivated}}).Times(2)
+	mockInfluxModule.EXPECT().HealthChecks(context.Background()).Return([]InfluxReport{{Report: Report{Name: "influx", Duration: time.Duration(1 * time.Second).String(), Status: Deactivated}}}).Times(2)
 	mockJaegerModule.EXPECT().HealthChecks(context.Background()).Return([]JaegerReport{{Name: "jaeger", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(2)
-	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Name: "redis", Duration: time.Duration(1 * time.Second).String(), Status: Degraded, Error: "fail"}}).Times(2)
+	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Report: Report{Name: "redis", Duration: time.Duration(1 * time.Second).String(), Status: Degraded, Error: "fail"}}}).Times(2)
 	mockSentryModule.EXPECT().HealthChecks(context.Background()).Return([]SentryReport{{Name: "sentry", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(2)
 
-	var c = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule)
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule)
+	assert.Nil(t, err)
 
 	// Influx.
 	{
@@ -131,3 +139,1440 @@ func TestHealthChecksFail(t *testing.T) {
 		assert.Equal(t, "KO", reply["sentry"])
 	}
 }
+
+func TestRequiredSubsystems(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	mockInfluxModule.EXPECT().HealthChecks(context.Background()).Return([]InfluxReport{{Report: Report{Name: "influx", Duration: "N/A", Status: Deactivated}}}).Times(2)
+	mockJaegerModule.EXPECT().HealthChecks(context.Background()).Return([]JaegerReport{{Name: "jaeger", Duration: "N/A", Status: Deactivated}}).Times(1)
+	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Report: Report{Name: "redis", Duration: "N/A", Status: Deactivated}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(context.Background()).Return([]SentryReport{{Name: "sentry", Duration: time.Duration(1 * time.Second).String(), Status: OK}}).Times(1)
+
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule, RequiredSubsystems("influx", "sentry"))
+	assert.Nil(t, err)
+
+	// Required and deactivated.
+	{
+		var report = c.InfluxHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "required subsystem deactivated", report.Error)
+	}
+
+	// All.
+	{
+		var reply = c.AllHealthChecks(context.Background())
+		assert.Equal(t, "KO", reply["influx"])
+		assert.Equal(t, "Deactivated", reply["jaeger"])
+		assert.Equal(t, "Deactivated", reply["redis"])
+		assert.Equal(t, "OK", reply["sentry"])
+	}
+}
+
+func TestRequiredSubsystemsUnknown(t *testing.T) {
+	var c, err = NewComponent(nil, nil, nil, nil, RequiredSubsystems("unknown"))
+	assert.NotNil(t, err)
+	assert.Nil(t, c)
+}
+
+func TestNilModuleStatus(t *testing.T) {
+	var c, err = NewComponent(nil, nil, nil, nil, NilModuleStatus(KO, "influx", "smtp"), NilModuleStatus(Deactivated, "smtp"))
+	assert.Nil(t, err)
+
+	// KO.
+	{
+		var report = c.InfluxHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "health module not configured", report.Error)
+	}
+
+	// Default and overridden.
+	{
+		assert.Equal(t, Deactivated, c.JaegerHealthChecks(context.Background()).Reports[0].Status)
+		assert.Equal(t, Deactivated, c.SMTPHealthChecks(context.Background()).Reports[0].Status)
+	}
+}
+
+func TestScrapeError(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, mockRedisModule, mockSentryModule, NilModuleStatus(KO, "influx"), RequiredSubsystems("sentry"))
+	assert.Nil(t, err)
+
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: KO, Error: "fail"}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: Deactivated}}).Times(1)
+	var report = c.AllHealthChecksDetailed(context.Background())
+
+	// The target is down.
+	assert.Equal(t, KO, report.Subsystems["redis"].Status)
+	assert.Zero(t, report.Subsystems["redis"].ScrapeError)
+
+	// The checks could not run.
+	assert.Equal(t, KO, report.Subsystems["influx"].Status)
+	assert.Equal(t, "not configured", report.Subsystems["influx"].ScrapeError)
+	assert.Equal(t, KO, report.Subsystems["sentry"].Status)
+	assert.Equal(t, "required subsystem deactivated", report.Subsystems["sentry"].ScrapeError)
+
+	// Deactivated by configuration is not an error.
+	assert.Equal(t, Deactivated, report.Subsystems["jaeger"].Status)
+	assert.Zero(t, report.Subsystems["jaeger"].ScrapeError)
+}
+
+func TestAllDeactivatedStatus(t *testing.T) {
+	// OK by default.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, OK, c.AllHealthChecksDetailed(context.Background()).Status)
+	}
+
+	// Deactivated.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, AllDeactivatedStatus(Deactivated))
+		assert.Nil(t, err)
+		assert.Equal(t, Deactivated, c.AllHealthChecksDetailed(context.Background()).Status)
+		var s, reason = c.OverallStatusWithReason(context.Background())
+		assert.Equal(t, Deactivated, s)
+		assert.Zero(t, reason)
+	}
+
+	// The aggregator is used as soon as a subsystem is not deactivated.
+	{
+		var mockCtrl = gomock.NewController(t)
+		defer mockCtrl.Finish()
+		var mockRedisModule = mock.NewRedisModule(mockCtrl)
+
+		var c, err = NewComponent(nil, nil, mockRedisModule, nil, AllDeactivatedStatus(Deactivated))
+		assert.Nil(t, err)
+		mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: OK}}}).Times(1)
+		assert.Equal(t, OK, c.AllHealthChecksDetailed(context.Background()).Status)
+	}
+
+	// Invalid status.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, AllDeactivatedStatus(KO))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+}
+
+func TestSoftDependencies(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, mockRedisModule, mockSentryModule, SoftDependencies("sentry"))
+	assert.Nil(t, err)
+
+	// A KO soft dependency only degrades the service.
+	{
+		mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: OK}}}).Times(1)
+		mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: KO}}).Times(1)
+
+		var report = c.AllHealthChecksDetailed(context.Background())
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, KO, report.Subsystems["sentry"].Status)
+		assert.Equal(t, "soft", report.Subsystems["sentry"].Dependency)
+		assert.Equal(t, "hard", report.Subsystems["redis"].Dependency)
+	}
+
+	// A KO hard dependency makes the service KO.
+	{
+		mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: KO}}}).Times(1)
+		mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: OK}}).Times(1)
+
+		assert.Equal(t, KO, c.AllHealthChecksDetailed(context.Background()).Status)
+	}
+
+	// Unknown subsystem.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, SoftDependencies("unknown"))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+}
+
+func TestGroupErrors(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(mockInfluxModule, nil, mockRedisModule, mockSentryModule, GroupErrors())
+	assert.Nil(t, err)
+
+	mockInfluxModule.EXPECT().HealthChecks(gomock.Any()).Return([]InfluxReport{{Report: Report{Name: "ping", Status: KO, Error: "dial tcp 10.0.0.2:8086: connect: connection refused"}}}).Times(1)
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: KO, Error: "dial tcp 10.0.0.4:6379: connect: connection refused"}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: KO, Error: "timeout"}, {Name: "quota", Status: KO, Error: "timeout"}}).Times(1)
+
+	var report = c.AllHealthChecksDetailed(context.Background())
+	assert.Equal(t, []string{
+		"connection refused (2 tests: influx/ping, redis/ping)",
+		"timeout (2 tests: sentry/ping, sentry/quota)",
+	}, report.Errors)
+
+	// A root cause unique to one test is reported whole.
+	mockInfluxModule.EXPECT().HealthChecks(gomock.Any()).Return([]InfluxReport{{Report: Report{Name: "ping", Status: OK}}}).Times(1)
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: KO, Error: "dial tcp 10.0.0.4:6379: connect: connection refused"}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: OK}}).Times(1)
+
+	report = c.AllHealthChecksDetailed(context.Background())
+	assert.Equal(t, []string{"redis/ping: dial tcp 10.0.0.4:6379: connect: connection refused"}, report.Errors)
+}
+
+func TestHealthChecksDetailed(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, mockRedisModule, mockSentryModule)
+	assert.Nil(t, err)
+
+	// The subsystems never executed are absent.
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: OK}}}).Times(1)
+	var report = c.HealthChecksDetailed(context.Background(), "redis")
+	assert.Equal(t, OK, report.Status)
+	assert.Contains(t, report.Subsystems, "redis")
+	assert.NotContains(t, report.Subsystems, "sentry")
+
+	// The other subsystems are reported with their last reports.
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: KO, Error: "fail"}}).Times(1)
+	report = c.HealthChecksDetailed(context.Background(), "sentry")
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, OK, report.Subsystems["redis"].Status)
+	assert.Equal(t, KO, report.Subsystems["sentry"].Status)
+	assert.Equal(t, []string{"sentry/ping: fail"}, report.Errors)
+
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: OK}}}).Times(1)
+	report = c.HealthChecksDetailed(context.Background(), "redis", "unknown")
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, 1, report.Subsystems["sentry"].ConsecutiveFailures)
+}
+
+func TestTotalDuration(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, mockRedisModule, mockSentryModule)
+	assert.Nil(t, err)
+
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).DoAndReturn(func(context.Context) []RedisReport {
+		time.Sleep(20 * time.Millisecond)
+		return []RedisReport{{Report: Report{Name: "ping", Duration: "20ms", Status: OK}}}
+	}).Times(2)
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).DoAndReturn(func(context.Context) []SentryReport {
+		time.Sleep(20 * time.Millisecond)
+		return []SentryReport{{Name: "ping", Duration: "20ms", Status: OK}}
+	}).Times(1)
+
+	// The total duration covers the whole run.
+	var report = c.AllHealthChecksDetailed(context.Background())
+	assert.True(t, report.TotalDuration >= 40*time.Millisecond)
+	assert.True(t, report.TotalDuration < 1*time.Second)
+
+	// Only the executed subsystems count.
+	report = c.HealthChecksDetailed(context.Background(), "redis")
+	assert.True(t, report.TotalDuration >= 20*time.Millisecond)
+}
+
+func TestNilModuleStatusInvalid(t *testing.T) {
+	// Invalid status.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, NilModuleStatus(Degraded, "influx"))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+
+	// Unknown subsystem.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, NilModuleStatus(KO, "unknown"))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+}
+
+func TestSMTPHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSMTPModule = mock.NewSMTPModule(mockCtrl)
+
+	mockSMTPModule.EXPECT().HealthChecks(context.Background()).Return([]SMTPReport{{Name: "smtp", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.SMTPHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithSMTPModule(mockSMTPModule))
+		assert.Nil(t, err)
+		var report = c.SMTPHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "smtp", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestWaitUntilReady(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule)
+	assert.Nil(t, err)
+
+	mockInfluxModule.EXPECT().HealthChecks(gomock.Any()).Return([]InfluxReport{{Report: Report{Name: "influx", Status: OK}}}).AnyTimes()
+	mockJaegerModule.EXPECT().HealthChecks(gomock.Any()).Return([]JaegerReport{{Name: "jaeger", Status: Deactivated}}).AnyTimes()
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "sentry", Status: Degraded}}).AnyTimes()
+
+	// Redis is KO on the first two polls, then OK.
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "redis", Status: KO}}}).Times(2)
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "redis", Status: OK}}}).Times(1)
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Equal(t, Degraded, c.WaitUntilReady(ctx, time.Millisecond))
+}
+
+func TestWaitUntilReadyTimeout(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	// Only OK is considered ready.
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule, ReadyStatuses(OK))
+	assert.Nil(t, err)
+
+	mockInfluxModule.EXPECT().HealthChecks(gomock.Any()).Return([]InfluxReport{{Report: Report{Name: "influx", Status: OK}}}).AnyTimes()
+	mockJaegerModule.EXPECT().HealthChecks(gomock.Any()).Return([]JaegerReport{{Name: "jaeger", Status: OK}}).AnyTimes()
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "redis", Status: OK}}}).AnyTimes()
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "sentry", Status: Degraded}}).AnyTimes()
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, Degraded, c.WaitUntilReady(ctx, time.Millisecond))
+}
+
+func TestWaitUntilReadyWarmup(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedis = mock.NewRedis(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, NewRedisModule(mockRedis, true), nil)
+	assert.Nil(t, err)
+
+	// One ping for the warm up, then one for the health check.
+	mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(2)
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Equal(t, OK, c.WaitUntilReady(ctx, time.Millisecond))
+}
+
+func TestWarmupDisabled(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedis = mock.NewRedis(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, NewRedisModule(mockRedis, true), nil)
+	assert.Nil(t, err)
+	assert.Nil(t, c.Disable("redis"))
+
+	// The subsystem disabled at runtime is not warmed up.
+	mockRedis.EXPECT().Do("PING").Times(0)
+	c.(Warmer).Warmup(context.Background())
+}
+
+func TestDependencies(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	mockInfluxModule.EXPECT().HealthChecks(context.Background()).Return([]InfluxReport{{Report: Report{Name: "influx", Status: KO, Error: "fail"}}}).Times(1)
+	mockJaegerModule.EXPECT().HealthChecks(context.Background()).Return([]JaegerReport{{Name: "jaeger", Status: KO, Error: "fail"}}).Times(1)
+	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Report: Report{Name: "redis", Status: KO, Error: "fail"}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(context.Background()).Return([]SentryReport{{Name: "sentry", Status: KO, Error: "fail"}}).Times(1)
+
+	// Influx and jaeger depend on redis, redis depends on nothing, sentry depends on smtp which is not KO.
+	var deps = map[string][]string{"influx": {"redis"}, "jaeger": {"influx"}, "sentry": {"smtp"}}
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule, Dependencies(deps))
+	assert.Nil(t, err)
+
+	var reply = c.AllHealthChecks(context.Background())
+	assert.Equal(t, "Degraded", reply["influx"])
+	assert.Equal(t, "Degraded", reply["jaeger"])
+	assert.Equal(t, "KO", reply["redis"])
+	assert.Equal(t, "KO", reply["sentry"])
+}
+
+func TestDependenciesInvalid(t *testing.T) {
+	var tsts = []map[string][]string{
+		// Unknown subsystems.
+		{"unknown": {"redis"}},
+		{"redis": {"unknown"}},
+		// Cycles.
+		{"redis": {"redis"}},
+		{"influx": {"redis"}, "redis": {"jaeger"}, "jaeger": {"influx"}},
+	}
+
+	for _, deps := range tsts {
+		var c, err = NewComponent(nil, nil, nil, nil, Dependencies(deps))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+}
+
+func TestNoChecksExecuted(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+
+	var c, err = NewComponent(mockInfluxModule, nil, mockRedisModule, nil)
+	assert.Nil(t, err)
+
+	// An enabled module that returns no report is not healthy.
+	mockInfluxModule.EXPECT().HealthChecks(gomock.Any()).Return([]InfluxReport{}).Times(2)
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: OK}}}).Times(1)
+
+	var report = c.InfluxHealthChecks(context.Background()).Reports[0]
+	assert.Equal(t, "no checks executed", report.Name)
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, "the health module returned no report", report.Error)
+
+	var reply = c.AllHealthChecks(context.Background())
+	assert.Equal(t, "KO", reply["influx"])
+	assert.Equal(t, "OK", reply["redis"])
+}
+
+func TestRemediation(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+
+	var remediated = []string{}
+	var remediation = func(ctx context.Context, subsystem string) error {
+		remediated = append(remediated, subsystem)
+		return fmt.Errorf("fail")
+	}
+
+	var c, err = NewComponent(nil, nil, mockRedisModule, nil, WithRemediation(remediation, log.NewNopLogger()))
+	assert.Nil(t, err)
+
+	// Invoked once per transition to KO, and the status is unchanged.
+	for _, s := range []Status{OK, KO, KO, OK, KO} {
+		mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "redis", Status: s}}}).Times(1)
+		var reply = c.AllHealthChecks(context.Background())
+		assert.Equal(t, s.String(), reply["redis"])
+	}
+	assert.Equal(t, []string{"redis", "redis"}, remediated)
+}
+
+func TestRemediationNil(t *testing.T) {
+	var remediation = func(ctx context.Context, subsystem string) error {
+		return nil
+	}
+
+	// Nil remediation.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithRemediation(nil, log.NewNopLogger()))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+
+	// Nil logger.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithRemediation(remediation, nil))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+}
+
+func TestConsecutiveFailures(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule)
+	assert.Nil(t, err)
+
+	mockInfluxModule.EXPECT().HealthChecks(context.Background()).Return([]InfluxReport{{Report: Report{Name: "influx", Status: OK}}}).Times(3)
+	mockJaegerModule.EXPECT().HealthChecks(context.Background()).Return([]JaegerReport{{Name: "jaeger", Status: OK}}).Times(3)
+	mockSentryModule.EXPECT().HealthChecks(context.Background()).Return([]SentryReport{{Name: "sentry", Status: OK}}).Times(3)
+
+	// Redis fails twice, then recovers.
+	gomock.InOrder(
+		mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Report: Report{Name: "redis", Status: KO, Error: "fail"}}}).Times(2),
+		mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Report: Report{Name: "redis", Status: OK}}}).Times(1),
+	)
+
+	c.AllHealthChecks(context.Background())
+	var reply = c.AllHealthChecksDetailed(context.Background())
+	assert.Equal(t, KO, reply.Status)
+	assert.Equal(t, KO, reply.Subsystems["redis"].Status)
+	assert.Equal(t, 2, reply.Subsystems["redis"].ConsecutiveFailures)
+	assert.Equal(t, "fail", reply.Subsystems["redis"].Reports[0].Error)
+	assert.Equal(t, 0, reply.Subsystems["influx"].ConsecutiveFailures)
+	assert.Equal(t, Deactivated, reply.Subsystems["smtp"].Status)
+	assert.Equal(t, []string{"redis/redis: fail"}, reply.Errors)
+
+	reply = c.AllHealthChecksDetailed(context.Background())
+	assert.Equal(t, OK, reply.Status)
+	assert.Equal(t, OK, reply.Subsystems["redis"].Status)
+	assert.Equal(t, 0, reply.Subsystems["redis"].ConsecutiveFailures)
+	assert.Empty(t, reply.Errors)
+}
+
+func TestDockerHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockDockerModule = mock.NewDockerModule(mockCtrl)
+
+	mockDockerModule.EXPECT().HealthChecks(context.Background()).Return([]DockerReport{{Name: "docker", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.DockerHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithDockerModule(mockDockerModule))
+		assert.Nil(t, err)
+		var report = c.DockerHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "docker", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestDeactivatedSubsystemsNoCall(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedis = mock.NewRedis(mockCtrl)
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	// The mocks have no expectations, any call to redis or sentry fails the test.
+	var redis = NewRedisModule(mockRedis, false)
+	var sentry = NewSentryModule(mockSentry, http.DefaultClient, false)
+
+	var c, err = NewComponent(nil, nil, redis, sentry)
+	assert.Nil(t, err)
+
+	var reply = c.AllHealthChecks(context.Background())
+	for _, name := range []string{"influx", "jaeger", "redis", "sentry", "smtp", "docker"} {
+		assert.Equal(t, "Deactivated", reply[name])
+	}
+}
+
+func TestDeactivatedModuleMiddlewaresNoCall(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedis = mock.NewRedis(mockCtrl)
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	// The mocks have no expectations, any call to redis or to the middlewares fails the test.
+	var redis = NewRedisModule(mockRedis, false)
+	redis = MakeRedisModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(redis)
+	redis = MakeRedisModuleLoggingMW(mockLogger)(redis)
+
+	var c, err = NewComponent(nil, nil, redis, nil)
+	assert.Nil(t, err)
+
+	var reports = c.RedisHealthChecks(context.Background()).Reports
+	assert.Equal(t, 1, len(reports))
+	assert.Equal(t, "deactivated", reports[0].Name)
+	assert.Equal(t, Deactivated, reports[0].Status)
+}
+
+func TestAggregators(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	mockInfluxModule.EXPECT().HealthChecks(context.Background()).Return([]InfluxReport{{Report: Report{Name: "ping", Status: OK}}, {Report: Report{Name: "write", Status: Degraded}}}).Times(1)
+	mockJaegerModule.EXPECT().HealthChecks(context.Background()).Return([]JaegerReport{{Name: "ping", Status: OK}}).Times(1)
+	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Report: Report{Name: "ping shard 0", Status: OK}}, {Report: Report{Name: "ping shard 1", Status: KO}}, {Report: Report{Name: "ping shard 2", Status: OK}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(context.Background()).Return([]SentryReport{{Name: "ping", Status: OK}}).Times(1)
+
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule,
+		SubsystemAggregator("influx", AllMustPass{}), SubsystemAggregator("redis", Quorum{Min: 2}), OverallAggregator(AllMustPass{}))
+	assert.Nil(t, err)
+
+	var reply = c.AllHealthChecksDetailed(context.Background())
+	assert.Equal(t, KO, reply.Subsystems["influx"].Status)
+	assert.Equal(t, Degraded, reply.Subsystems["redis"].Status)
+	assert.Equal(t, OK, reply.Subsystems["jaeger"].Status)
+	assert.Equal(t, Deactivated, reply.Subsystems["smtp"].Status)
+	assert.Equal(t, KO, reply.Status)
+}
+
+func TestSubsystemAggregatorUnknown(t *testing.T) {
+	var c, err = NewComponent(nil, nil, nil, nil, SubsystemAggregator("unknown", AllMustPass{}))
+	assert.NotNil(t, err)
+	assert.Nil(t, c)
+}
+
+func TestLogSinkHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogSinkModule = mock.NewLogSinkModule(mockCtrl)
+
+	mockLogSinkModule.EXPECT().HealthChecks(context.Background()).Return([]LogSinkReport{{Name: "logsink", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.LogSinkHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithLogSinkModule(mockLogSinkModule))
+		assert.Nil(t, err)
+		var report = c.LogSinkHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "logsink", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestMaxConcurrentChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, nil, nil, MaxConcurrentChecks(1))
+	assert.Nil(t, err)
+
+	// The influx checks hold the only slot until they are released.
+	var started = make(chan struct{})
+	var release = make(chan struct{})
+	mockInfluxModule.EXPECT().HealthChecks(gomock.Any()).DoAndReturn(func(context.Context) []InfluxReport {
+		close(started)
+		<-release
+		return []InfluxReport{{Report: Report{Name: "influx", Status: OK}}}
+	}).Times(1)
+
+	var done = make(chan Reports)
+	go func() {
+		done <- c.InfluxHealthChecks(context.Background())
+	}()
+	<-started
+
+	// No slot is free before the deadline.
+	{
+		var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		var report = c.JaegerHealthChecks(ctx).Reports[0]
+		assert.Equal(t, "not executed", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not get a check slot: context deadline exceeded", report.Error)
+	}
+
+	// The slot is freed once the influx checks are done.
+	close(release)
+	assert.Equal(t, OK, (<-done).Reports[0].Status)
+
+	mockJaegerModule.EXPECT().HealthChecks(context.Background()).Return([]JaegerReport{{Name: "jaeger", Status: OK}}).Times(1)
+	var report = c.JaegerHealthChecks(context.Background()).Reports[0]
+	assert.Equal(t, OK, report.Status)
+}
+
+func TestMaxConcurrentChecksInvalid(t *testing.T) {
+	var c, err = NewComponent(nil, nil, nil, nil, MaxConcurrentChecks(0))
+	assert.NotNil(t, err)
+	assert.Nil(t, c)
+}
+
+func TestPriorityOrder(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule, PriorityOrder(80*time.Millisecond, "sentry", "redis"))
+	assert.Nil(t, err)
+
+	// Without deadline, the critical subsystems are checked first, then the others.
+	{
+		gomock.InOrder(
+			mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: OK}}).Times(1),
+			mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: OK}}}).Times(1),
+			mockInfluxModule.EXPECT().HealthChecks(gomock.Any()).Return([]InfluxReport{{Report: Report{Name: "ping", Status: OK}}}).Times(1),
+			mockJaegerModule.EXPECT().HealthChecks(gomock.Any()).Return([]JaegerReport{{Name: "ping jaeger collector", Status: OK}}).Times(1),
+		)
+		var report = c.AllHealthChecksDetailed(context.Background())
+		assert.Equal(t, OK, report.Status)
+	}
+
+	// The non-critical subsystems are skipped when the deadline approaches.
+	{
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: OK}}).Times(1)
+		mockRedisModule.EXPECT().HealthChecks(gomock.Any()).DoAndReturn(func(context.Context) []RedisReport {
+			time.Sleep(30 * time.Millisecond)
+			return []RedisReport{{Report: Report{Name: "ping", Status: OK}}}
+		}).Times(1)
+
+		var report = c.AllHealthChecksDetailed(ctx)
+		assert.Equal(t, OK, report.Status)
+		assert.Equal(t, OK, report.Subsystems["redis"].Status)
+		for _, name := range []string{"influx", "jaeger", "smtp"} {
+			var r = report.Subsystems[name]
+			assert.Equal(t, Deactivated, r.Status)
+			assert.Equal(t, "skipped", r.Reports[0].Name)
+			assert.Equal(t, "skipped, deadline", r.Reports[0].Error)
+		}
+	}
+}
+
+func TestPriorityOrderRequired(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(mockInfluxModule, nil, nil, mockSentryModule, PriorityOrder(80*time.Millisecond, "sentry"),
+		RequiredSubsystems("influx"), RunbookURLs(map[string]string{"influx": "https://runbooks/influx"}))
+	assert.Nil(t, err)
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).DoAndReturn(func(context.Context) []SentryReport {
+		time.Sleep(30 * time.Millisecond)
+		return []SentryReport{{Name: "ping", Status: OK}}
+	}).Times(1)
+
+	// The required subsystem that is skipped is KO, it was not checked.
+	var report = c.AllHealthChecksDetailed(ctx)
+	assert.Equal(t, KO, report.Status)
+	var r = report.Subsystems["influx"]
+	assert.Equal(t, KO, r.Status)
+	assert.Equal(t, "skipped", r.Reports[0].Name)
+	assert.Equal(t, "required subsystem deactivated: skipped, deadline", r.Reports[0].Error)
+	assert.Equal(t, "skipped, deadline", r.ScrapeError)
+	assert.Equal(t, "https://runbooks/influx", r.RunbookURLs["skipped"])
+}
+
+func TestPriorityOrderInvalid(t *testing.T) {
+	var c, err = NewComponent(nil, nil, nil, nil, PriorityOrder(time.Second, "postgres"))
+	assert.NotNil(t, err)
+	assert.Nil(t, c)
+
+	c, err = NewComponent(nil, nil, nil, nil, PriorityOrder(-time.Second, "redis"))
+	assert.NotNil(t, err)
+	assert.Nil(t, c)
+}
+
+func TestOverallStatusWithReason(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule)
+	assert.Nil(t, err)
+
+	// Degraded.
+	mockInfluxModule.EXPECT().HealthChecks(context.Background()).Return([]InfluxReport{{Report: Report{Name: "ping", Status: OK}}, {Report: Report{Name: "write", Status: KO}}}).Times(1)
+	mockJaegerModule.EXPECT().HealthChecks(context.Background()).Return([]JaegerReport{{Name: "ping jaeger collector", Status: OK}}).Times(1)
+	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Report: Report{Name: "ping shard 0", Status: OK}}, {Report: Report{Name: "ping shard 1", Status: Degraded, Error: "could not ping redis: fail"}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(context.Background()).Return([]SentryReport{{Name: "ping", Status: OK}}).Times(1)
+
+	var s, reason = c.OverallStatusWithReason(context.Background())
+	assert.Equal(t, KO, s)
+	assert.Equal(t, "influx: write KO; redis: ping shard 1 Degraded (could not ping redis: fail)", reason)
+
+	// OK.
+	mockInfluxModule.EXPECT().HealthChecks(context.Background()).Return([]InfluxReport{{Report: Report{Name: "ping", Status: OK}}}).Times(1)
+	mockJaegerModule.EXPECT().HealthChecks(context.Background()).Return([]JaegerReport{{Name: "ping jaeger collector", Status: OK}}).Times(1)
+	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Report: Report{Name: "ping", Status: OK}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(context.Background()).Return([]SentryReport{{Name: "ping", Status: OK}}).Times(1)
+
+	s, reason = c.OverallStatusWithReason(context.Background())
+	assert.Equal(t, OK, s)
+	assert.Equal(t, "", reason)
+}
+
+func TestE2EHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockE2EModule = mock.NewE2EModule(mockCtrl)
+
+	mockE2EModule.EXPECT().HealthChecks(context.Background()).Return([]E2EReport{{Name: "e2e", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.E2EHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithE2EModule(mockE2EModule))
+		assert.Nil(t, err)
+		var report = c.E2EHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "e2e", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestStartupGracePeriod(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, mockRedisModule, nil, StartupGracePeriod(50*time.Millisecond))
+	assert.Nil(t, err)
+
+	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return([]RedisReport{{Report: Report{Name: "ping", Status: KO, Error: "fail"}}}).Times(2)
+
+	// During the grace period.
+	{
+		var report = c.RedisHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "starting up: fail", report.Error)
+	}
+
+	// After the grace period.
+	time.Sleep(50 * time.Millisecond)
+	{
+		var report = c.RedisHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestHealthChecksModuleReportsUnchanged(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, nil, mockSentryModule, StartupGracePeriod(time.Hour))
+	assert.Nil(t, err)
+
+	// The module returns the same reports each time, e.g. its cached reports.
+	var reports = []SentryReport{{Name: "ping", Status: KO, Error: "fail"}}
+	mockSentryModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(2)
+
+	for i := 0; i < 2; i++ {
+		var report = c.SentryHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "starting up: fail", report.Error)
+	}
+	assert.Equal(t, KO, reports[0].Status)
+	assert.Equal(t, "fail", reports[0].Error)
+}
+
+func TestDisableEnable(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, mockRedisModule, mockSentryModule, RequiredSubsystems("sentry"))
+	assert.Nil(t, err)
+
+	// Enabled.
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Duration: "1s", Status: KO, Error: "fail"}}).Times(1)
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Duration: "1s", Status: OK}}}).Times(1)
+	assert.Equal(t, "KO", c.AllHealthChecks(context.Background())["sentry"])
+
+	// Disabled, the sentry module is not called, even if sentry is required.
+	assert.Nil(t, c.Disable("sentry"))
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Duration: "1s", Status: OK}}}).Times(1)
+	var reports = c.AllHealthChecks(context.Background())
+	assert.Equal(t, "Deactivated", reports["sentry"])
+	assert.Equal(t, "OK", reports["redis"])
+	var report = c.SentryHealthChecks(context.Background()).Reports[0]
+	assert.Equal(t, "disabled", report.Name)
+	assert.Equal(t, Deactivated, report.Status)
+
+	// Enabled again.
+	assert.Nil(t, c.Enable("sentry"))
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Duration: "1s", Status: OK}}).Times(1)
+	assert.Equal(t, OK, c.SentryHealthChecks(context.Background()).Reports[0].Status)
+
+	// Unknown subsystem.
+	assert.NotNil(t, c.Disable("unknown"))
+	assert.NotNil(t, c.Enable("unknown"))
+}
+
+func TestBeginShutdown(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, mockRedisModule, nil)
+	assert.Nil(t, err)
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Duration: "1s", Status: OK}}}).Times(2)
+
+	c.BeginShutdown()
+
+	// The overall status is KO, the subsystems are unchanged.
+	{
+		var report = c.AllHealthChecksDetailed(context.Background())
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, OK, report.Subsystems["redis"].Status)
+		assert.Equal(t, []string{"shutting down"}, report.Errors)
+	}
+
+	// Reason.
+	{
+		var s, reason = c.OverallStatusWithReason(context.Background())
+		assert.Equal(t, KO, s)
+		assert.Equal(t, "shutting down", reason)
+	}
+}
+
+func TestReset(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInflux = mock.NewInflux(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+
+	// The reset is forwarded by the middleware to the influx module, whose ping is sampled.
+	var influxModule = NewInfluxModule(mockInflux, true, InfluxCheckInterval("ping", time.Hour))
+	influxModule = MakeInfluxModuleTracingMW(nil)(influxModule)
+
+	var c, err = NewComponent(influxModule, nil, mockRedisModule, nil, History(4))
+	assert.Nil(t, err)
+
+	mockInflux.EXPECT().Ping(5*time.Second).Return(time.Millisecond, "", fmt.Errorf("fail")).Times(1)
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Duration: "1s", Status: KO}}}).Times(2)
+	c.AllHealthChecksDetailed(context.Background())
+	var report = c.AllHealthChecksDetailed(context.Background())
+	assert.Equal(t, 2, report.Subsystems["redis"].ConsecutiveFailures)
+	assert.True(t, report.Subsystems["influx"].Reports[0].Cached)
+
+	c.Reset()
+
+	// The history is cleared.
+	{
+		var _, err = c.UptimePercent("redis", time.Hour)
+		assert.NotNil(t, err)
+	}
+
+	// The consecutive failures are counted from zero, and the sampled ping is executed again.
+	{
+		mockInflux.EXPECT().Ping(5*time.Second).Return(time.Millisecond, "", nil).Times(1)
+		mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Duration: "1s", Status: KO}}}).Times(1)
+		var report = c.AllHealthChecksDetailed(context.Background())
+		assert.Equal(t, 1, report.Subsystems["redis"].ConsecutiveFailures)
+		assert.Equal(t, OK, report.Subsystems["influx"].Status)
+		assert.False(t, report.Subsystems["influx"].Reports[0].Cached)
+	}
+}
+
+func TestSlowThreshold(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+
+	var c, err = NewComponent(nil, mockJaegerModule, mockRedisModule, mockSentryModule, SlowThreshold(time.Second),
+		SubsystemSlowThreshold("sentry", 5*time.Second), SubsystemSlowThreshold("jaeger", 0))
+	assert.Nil(t, err)
+
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{
+		{Report: Report{Name: "ping", Duration: "2s", Status: OK}},
+		{Report: Report{Name: "set", Duration: "10ms", Status: OK}},
+		{Report: Report{Name: "get", Duration: "3s", Status: KO, Error: "fail"}},
+	}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Duration: "2s", Status: OK}}).Times(1)
+	mockJaegerModule.EXPECT().HealthChecks(gomock.Any()).Return([]JaegerReport{{Name: "ping", Duration: "2s", Status: OK}}).Times(1)
+
+	var report = c.AllHealthChecksDetailed(context.Background())
+
+	// Redis inherits the default threshold, only the passing tests are Degraded.
+	var redis = report.Subsystems["redis"]
+	assert.Equal(t, "1s", redis.SlowThreshold)
+	assert.Equal(t, Degraded, redis.Reports[0].Status)
+	assert.Equal(t, "slow: took 2s, above the threshold of 1s", redis.Reports[0].Error)
+	assert.Equal(t, Timeout, redis.Reports[0].Category)
+	assert.Equal(t, OK, redis.Reports[1].Status)
+	assert.Equal(t, KO, redis.Reports[2].Status)
+	assert.Equal(t, "fail", redis.Reports[2].Error)
+
+	// Sentry overrides it, jaeger has none.
+	assert.Equal(t, "5s", report.Subsystems["sentry"].SlowThreshold)
+	assert.Equal(t, OK, report.Subsystems["sentry"].Reports[0].Status)
+	assert.Zero(t, report.Subsystems["jaeger"].SlowThreshold)
+	assert.Equal(t, OK, report.Subsystems["jaeger"].Reports[0].Status)
+
+	// The deactivated subsystems have no duration.
+	assert.Equal(t, Deactivated, report.Subsystems["influx"].Status)
+
+	var o = c.Options()
+	assert.Equal(t, "1s", o.SlowThreshold)
+	assert.Equal(t, map[string]string{"sentry": "5s", "jaeger": "0s"}, o.SlowThresholds)
+
+	// Invalid thresholds.
+	{
+		var _, err = NewComponent(nil, nil, nil, nil, SlowThreshold(-time.Second))
+		assert.NotNil(t, err)
+		_, err = NewComponent(nil, nil, nil, nil, SubsystemSlowThreshold("unknown", time.Second))
+		assert.NotNil(t, err)
+	}
+}
+
+func TestRunbookURLs(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, mockRedisModule, mockSentryModule, RunbookURLs(map[string]string{
+		"redis":      "https://runbooks.example.com/redis",
+		"redis/ping": "https://runbooks.example.com/redis#ping",
+	}))
+	assert.Nil(t, err)
+
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{
+		{Report: Report{Name: "ping", Status: KO, Error: "fail"}},
+		{Report: Report{Name: "cluster slots", Status: OK}},
+	}).Times(2)
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: OK}}).Times(1)
+
+	// The runbook of the test wins over the one of its subsystem.
+	var report = c.AllHealthChecksDetailed(context.Background())
+	var expected = map[string]string{
+		"ping":          "https://runbooks.example.com/redis#ping",
+		"cluster slots": "https://runbooks.example.com/redis",
+	}
+	assert.Equal(t, expected, report.Subsystems["redis"].RunbookURLs)
+	assert.Nil(t, report.Subsystems["sentry"].RunbookURLs)
+	assert.Equal(t, expected, c.RedisHealthChecks(context.Background()).RunbookURLs)
+
+	// Invalid runbooks.
+	{
+		var _, err = NewComponent(nil, nil, nil, nil, RunbookURLs(map[string]string{"unknown/ping": "https://runbooks.example.com"}))
+		assert.NotNil(t, err)
+		_, err = NewComponent(nil, nil, nil, nil, RunbookURLs(map[string]string{"redis": "runbooks.example.com"}))
+		assert.NotNil(t, err)
+		_, err = NewComponent(nil, nil, nil, nil, RunbookURLs(map[string]string{"redis": ""}))
+		assert.NotNil(t, err)
+	}
+}
+
+func TestDisableConcurrent(t *testing.T) {
+	var c, err = NewComponent(nil, nil, nil, nil)
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				c.Disable("redis")
+			} else {
+				c.Enable("redis")
+			}
+			c.RedisHealthChecks(context.Background())
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestGRPCReflectionHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockGRPCReflectionModule = mock.NewGRPCReflectionModule(mockCtrl)
+
+	mockGRPCReflectionModule.EXPECT().HealthChecks(context.Background()).Return([]GRPCReflectionReport{{Name: "grpc", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.GRPCReflectionHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithGRPCReflectionModule(mockGRPCReflectionModule))
+		assert.Nil(t, err)
+		var report = c.GRPCReflectionHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "grpc", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestEgressHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockEgressModule = mock.NewEgressModule(mockCtrl)
+
+	mockEgressModule.EXPECT().HealthChecks(context.Background()).Return([]EgressReport{{Name: "egress", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.EgressHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithEgressModule(mockEgressModule))
+		assert.Nil(t, err)
+		var report = c.EgressHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "egress", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestFDHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockFDModule = mock.NewFDModule(mockCtrl)
+
+	mockFDModule.EXPECT().HealthChecks(context.Background()).Return([]FDReport{{Name: "fd", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.FDHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithFDModule(mockFDModule))
+		assert.Nil(t, err)
+		var report = c.FDHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "fd", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestIDGenHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockIDGenModule = mock.NewIDGenModule(mockCtrl)
+
+	mockIDGenModule.EXPECT().HealthChecks(context.Background()).Return([]IDGenReport{{Name: "idgen", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.IDGenHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithIDGenModule(mockIDGenModule))
+		assert.Nil(t, err)
+		var report = c.IDGenHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "idgen", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestFeatureFlagHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockFeatureFlagModule = mock.NewFeatureFlagModule(mockCtrl)
+
+	mockFeatureFlagModule.EXPECT().HealthChecks(context.Background()).Return([]FeatureFlagReport{{Name: "featureflag", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.FeatureFlagHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithFeatureFlagModule(mockFeatureFlagModule))
+		assert.Nil(t, err)
+		var report = c.FeatureFlagHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "featureflag", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestReplicaHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockReplicaModule = mock.NewReplicaModule(mockCtrl)
+
+	mockReplicaModule.EXPECT().HealthChecks(context.Background()).Return([]ReplicaReport{{Name: "replica", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.ReplicaHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithReplicaModule(mockReplicaModule))
+		assert.Nil(t, err)
+		var report = c.ReplicaHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "replica", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestCommandHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockCommandModule = mock.NewCommandModule(mockCtrl)
+
+	mockCommandModule.EXPECT().HealthChecks(context.Background()).Return([]CommandReport{{Name: "command", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.CommandHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithCommandModule(mockCommandModule))
+		assert.Nil(t, err)
+		var report = c.CommandHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "command", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+// TestConcurrentHealthChecks fires the health checks from many goroutines, with the modules that keep a
+// state: the sampled checks, the adaptive timeouts, the history, the consecutive failures and the last
+// reports. It is meant to be run with -race.
+func TestConcurrentHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInflux = mock.NewInflux(mockCtrl)
+	var mockSentry = mock.NewSentry(mockCtrl)
+	var mockFlaki = mock.NewFlakiModule(mockCtrl)
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_health":
+			w.Write([]byte("ok"))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer s.Close()
+
+	var influxModule = NewInfluxModule(mockInflux, true,
+		InfluxWriteCheck(s.Client(), s.URL, "metrics", "", ""),
+		InfluxCheckInterval("write", time.Millisecond),
+		InfluxAdaptiveTimeout(3, time.Second, 5*time.Second))
+	influxModule = MakeInfluxModuleTracingMW(nil)(influxModule)
+	mockInflux.EXPECT().Ping(gomock.Any()).Return(time.Millisecond, "", nil).AnyTimes()
+
+	var sentryModule = NewSentryModule(mockSentry, s.Client(), true, SentryAdaptiveTimeout(3, time.Second, 5*time.Second))
+	sentryModule = MakeSentryModuleTracingMW(nil)(sentryModule)
+	mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).AnyTimes()
+
+	// The redis and end-to-end modules share the redis, as in flakid.
+	var pool = newFakeRedisPool()
+	defer pool.Close()
+	var redis = NewRedisPool(pool)
+	var ids int64
+	mockFlaki.EXPECT().NextID(gomock.Any()).DoAndReturn(func(context.Context) (string, error) {
+		return fmt.Sprintf("%d", atomic.AddInt64(&ids, 1)), nil
+	}).AnyTimes()
+
+	var remediations int32
+	var remediation = func(context.Context, string) error {
+		atomic.AddInt32(&remediations, 1)
+		return nil
+	}
+
+	var c, err = NewComponent(influxModule, nil, NewRedisModule(redis, true), sentryModule,
+		WithE2EModule(NewE2EModule(mockFlaki, redis, true)),
+		WithFDModule(NewFDModule(0.9, 0.95, true)),
+		WithIDGenModule(NewIDGenModule(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 41, time.Hour, true)),
+		History(16),
+		GroupErrors(),
+		MaxConcurrentChecks(4),
+		WithRemediation(remediation, log.NewNopLogger()))
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				c.AllHealthChecks(context.Background())
+				c.AllHealthChecksDetailed(context.Background())
+				c.HealthChecksDetailed(context.Background(), "influx", "fd")
+				c.OverallStatusWithReason(context.Background())
+				c.UptimePercent("influx", time.Minute)
+				c.Options()
+				switch (i + j) % 8 {
+				case 0:
+					c.Disable("fd")
+				case 1:
+					c.Enable("fd")
+				case 2:
+					c.Reset()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	c.Enable("fd")
+	var report = c.AllHealthChecksDetailed(context.Background())
+	assert.Equal(t, OK, report.Subsystems["influx"].Status)
+	assert.Equal(t, OK, report.Subsystems["sentry"].Status)
+	assert.Equal(t, OK, report.Subsystems["redis"].Status)
+	assert.Equal(t, OK, report.Subsystems["e2e"].Status)
+	assert.Zero(t, atomic.LoadInt32(&remediations))
+}
+
+func TestSelfHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSelfModule = mock.NewSelfModule(mockCtrl)
+
+	mockSelfModule.EXPECT().HealthChecks(context.Background()).Return([]SelfReport{{Name: "self", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.SelfHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithSelfModule(mockSelfModule))
+		assert.Nil(t, err)
+		var report = c.SelfHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "self", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestAuditLogHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockAuditLogModule = mock.NewAuditLogModule(mockCtrl)
+
+	mockAuditLogModule.EXPECT().HealthChecks(context.Background()).Return([]AuditLogReport{{Name: "auditlog", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.AuditLogHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithAuditLogModule(mockAuditLogModule))
+		assert.Nil(t, err)
+		var report = c.AuditLogHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "auditlog", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestCompositeHealthChecksComponent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockCompositeModule = mock.NewCompositeModule(mockCtrl)
+
+	mockCompositeModule.EXPECT().HealthChecks(context.Background()).Return([]CompositeReport{{Name: "composite", Duration: time.Duration(1 * time.Second).String(), Status: KO, Error: "fail"}}).Times(1)
+
+	// Not configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil)
+		assert.Nil(t, err)
+		var report = c.CompositeHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, Deactivated, report.Status)
+	}
+
+	// Configured.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithCompositeModule(mockCompositeModule))
+		assert.Nil(t, err)
+		var report = c.CompositeHealthChecks(context.Background()).Reports[0]
+		assert.Equal(t, "composite", report.Name)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
@@ -0,0 +1,187 @@
+package health
+
+//go:generate mockgen -destination=./mock/composite.go -package=mock -mock_names=CompositeModule=CompositeModule,CompositeResource=CompositeResource github.com/cloudtrust/flaki-service/pkg/health CompositeModule,CompositeResource
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CompositeModule is the health check module for a stateful dependency whose checks share a resource, e.g.
+// a connection pool, so the checks do not reconnect each time. The module owns the resource: Start opens it,
+// and Stop closes it once the running checks are done.
+type CompositeModule interface {
+	HealthChecks(context.Context) []CompositeReport
+	Start(context.Context) error
+	Stop() error
+}
+
+type compositeModule struct {
+	resource CompositeResource
+	checks   []CompositeCheck
+	enabled  bool
+
+	mutex   sync.RWMutex
+	started bool
+	stopped bool
+}
+
+// CompositeResource is the resource shared by the checks of the composite module.
+type CompositeResource interface {
+	Open(context.Context) error
+	Close() error
+}
+
+// CompositeCheck is a check of the composite module, executed against the shared resource, typically
+// captured by Check. The check is OK when Check returns nil, and KO otherwise. The category of the failure
+// is deduced from the error.
+type CompositeCheck struct {
+	Name        string
+	Description string
+	Check       func(context.Context) error
+}
+
+// CompositeReport is the health report returned by the composite module.
+type CompositeReport = Report
+
+// NewCompositeModule returns the composite health module, that executes checks against resource. The
+// resource is opened by Start, or else by the first health checks. While it cannot be opened, the checks are
+// KO, and it is opened again by the next health checks. After Stop, the checks are Deactivated.
+func NewCompositeModule(resource CompositeResource, checks []CompositeCheck, enabled bool) CompositeModule {
+	return &compositeModule{
+		resource: resource,
+		checks:   checks,
+		enabled:  enabled,
+	}
+}
+
+// Start opens the shared resource. It does nothing if the resource is already open, and fails once the
+// module is stopped.
+func (m *compositeModule) Start(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	switch {
+	case m.stopped:
+		return fmt.Errorf("composite health module is stopped")
+	case m.started:
+		return nil
+	}
+
+	if err := m.resource.Open(ctx); err != nil {
+		return err
+	}
+	m.started = true
+	return nil
+}
+
+// Stop waits for the running checks, and closes the shared resource. The module cannot be started again.
+func (m *compositeModule) Stop() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var started = m.started
+	m.started = false
+	m.stopped = true
+	if !started {
+		return nil
+	}
+	return m.resource.Close()
+}
+
+// HealthChecks executes all health checks against the shared resource, and opens it first if needed.
+func (m *compositeModule) HealthChecks(ctx context.Context) []CompositeReport {
+	var reports = []CompositeReport{}
+
+	if !m.enabled {
+		for _, c := range m.checks {
+			reports = append(reports, CompositeReport{
+				Name:        c.Name,
+				Description: c.Description,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			})
+		}
+		return reports
+	}
+
+	var err = m.start(ctx)
+
+	// The resource is not closed while the checks run.
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, c := range m.checks {
+		switch {
+		case m.stopped:
+			reports = append(reports, CompositeReport{
+				Name:        c.Name,
+				Description: c.Description,
+				Duration:    "N/A",
+				Status:      Deactivated,
+				Error:       "stopped",
+			})
+		case !m.started:
+			reports = append(reports, CompositeReport{
+				Name:        c.Name,
+				Description: c.Description,
+				Duration:    "N/A",
+				Status:      KO,
+				Error:       fmt.Sprintf("could not open the shared resource: %v", err),
+				Category:    errorCategory(err),
+			})
+		default:
+			reports = append(reports, compositeCheck(ctx, c))
+		}
+	}
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *compositeModule) Enabled() bool {
+	return m.enabled
+}
+
+// start opens the shared resource, unless the module is stopped. It returns the error of the opening.
+func (m *compositeModule) start(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.stopped || m.started {
+		return nil
+	}
+
+	var err = m.resource.Open(ctx)
+	m.started = err == nil
+	return err
+}
+
+func compositeCheck(ctx context.Context, c CompositeCheck) CompositeReport {
+	var now = time.Now()
+	var err = c.Check(ctx)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = err.Error()
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return CompositeReport{
+		Name:        c.Name,
+		Description: c.Description,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
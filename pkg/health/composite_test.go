@@ -0,0 +1,114 @@
+package health_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompositeHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockResource = mock.NewCompositeResource(mockCtrl)
+
+	var pings = 0
+	var checks = []CompositeCheck{
+		{
+			Name:        "ping",
+			Description: "Pings the pool.",
+			Check: func(context.Context) error {
+				pings++
+				return nil
+			},
+		},
+		{
+			Name:        "query",
+			Description: "Queries the pool.",
+			Check: func(context.Context) error {
+				return fmt.Errorf("no such table")
+			},
+		},
+	}
+	var m = NewCompositeModule(mockResource, checks, true)
+
+	// The first health checks open the resource, it fails.
+	{
+		mockResource.EXPECT().Open(gomock.Any()).Return(fmt.Errorf("refused")).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 2, len(reports))
+		for _, r := range reports {
+			assert.Equal(t, KO, r.Status)
+			assert.Equal(t, "could not open the shared resource: refused", r.Error)
+		}
+		assert.Equal(t, 0, pings)
+	}
+
+	// The next health checks open it again, and it is shared by the following ones.
+	{
+		mockResource.EXPECT().Open(gomock.Any()).Return(nil).Times(1)
+		for i := 0; i < 2; i++ {
+			var reports = m.HealthChecks(context.Background())
+			assert.Equal(t, "ping", reports[0].Name)
+			assert.Equal(t, "Pings the pool.", reports[0].Description)
+			assert.Equal(t, OK, reports[0].Status)
+			assert.Equal(t, 1, reports[0].Attempts)
+			assert.Equal(t, "query", reports[1].Name)
+			assert.Equal(t, KO, reports[1].Status)
+			assert.Equal(t, "no such table", reports[1].Error)
+		}
+		assert.Equal(t, 2, pings)
+		assert.Nil(t, m.Start(context.Background()))
+	}
+
+	// Stop closes the resource once, the checks are then Deactivated.
+	{
+		mockResource.EXPECT().Close().Return(nil).Times(1)
+		assert.Nil(t, m.Stop())
+		assert.Nil(t, m.Stop())
+		assert.NotNil(t, m.Start(context.Background()))
+
+		var reports = m.HealthChecks(context.Background())
+		for _, r := range reports {
+			assert.Equal(t, Deactivated, r.Status)
+			assert.Equal(t, "stopped", r.Error)
+		}
+		assert.Equal(t, 2, pings)
+	}
+}
+
+func TestCompositeStartStop(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockResource = mock.NewCompositeResource(mockCtrl)
+
+	// The middlewares forward Start and Stop.
+	var m = NewCompositeModule(mockResource, nil, true)
+	m = MakeCompositeModuleInstrumentingMW(nil, nil, nil)(m)
+	m = MakeCompositeModuleLoggingMW(log.NewNopLogger())(m)
+	m = MakeCompositeModuleTracingMW(nil)(m)
+
+	mockResource.EXPECT().Open(gomock.Any()).Return(nil).Times(1)
+	assert.Nil(t, m.Start(context.Background()))
+	assert.Nil(t, m.Start(context.Background()))
+
+	mockResource.EXPECT().Close().Return(fmt.Errorf("fail")).Times(1)
+	assert.Equal(t, "fail", m.Stop().Error())
+}
+
+func TestNoopCompositeHealthChecks(t *testing.T) {
+	var checks = []CompositeCheck{{Name: "ping", Description: "Pings the pool.", Check: func(context.Context) error { return nil }}}
+	var m = NewCompositeModule(nil, checks, false)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "ping", report.Name)
+	assert.NotZero(t, report.Description)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+}
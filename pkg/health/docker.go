@@ -0,0 +1,114 @@
+package health
+
+//go:generate mockgen -destination=./mock/docker.go -package=mock -mock_names=DockerModule=DockerModule  github.com/cloudtrust/flaki-service/pkg/health DockerModule
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DockerModule is the health check module for the docker daemon.
+type DockerModule interface {
+	HealthChecks(context.Context) []DockerReport
+}
+
+type dockerModule struct {
+	httpClient DockerHTTPClient
+	enabled    bool
+}
+
+// DockerReport is the health report returned by the docker module.
+type DockerReport = Report
+
+// DockerHTTPClient is the interface of the http client connected to the docker daemon socket.
+type DockerHTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// NewDockerModule returns the docker health module.
+func NewDockerModule(httpClient DockerHTTPClient, enabled bool) DockerModule {
+	return &dockerModule{
+		httpClient: httpClient,
+		enabled:    enabled,
+	}
+}
+
+// HealthChecks executes all health checks for Docker.
+func (m *dockerModule) HealthChecks(ctx context.Context) []DockerReport {
+	var reports = []DockerReport{}
+	reports = append(reports, m.dockerPingCheck(ctx))
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *dockerModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *dockerModule) dockerPingCheck(ctx context.Context) DockerReport {
+	var healthCheckName = "ping"
+	var healthCheckDescription = "Pings the docker daemon through its socket. The workloads that depend on docker do not work without it."
+
+	if !m.enabled {
+		return DockerReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var err = pingDocker(ctx, m.httpClient)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not ping docker: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return DockerReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// pingDocker queries the docker daemon ping endpoint. The host is ignored, the http client
+// is expected to dial the docker socket.
+func pingDocker(ctx context.Context, httpClient DockerHTTPClient) error {
+	var req, err = http.NewRequest("GET", "http://docker/_ping", nil)
+	if err != nil {
+		return err
+	}
+
+	var res *http.Response
+	res, err = httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// Check response status.
+	if isRedirect(res) {
+		return unexpectedRedirect(res)
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("http response status code: %v", res.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package health_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerHealthChecks(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_ping", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	}))
+	defer s.Close()
+
+	var m = NewDockerModule(dockerClient("tcp", s.Listener.Addr().String()), true)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "ping", report.Name)
+	assert.NotZero(t, report.Description)
+	assert.NotZero(t, report.Duration)
+	assert.Equal(t, OK, report.Status)
+	assert.Zero(t, report.Error)
+	assert.Equal(t, 1, report.Attempts)
+}
+
+func TestDockerHealthChecksFail(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	var m = NewDockerModule(dockerClient("tcp", s.Listener.Addr().String()), true)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "ping", report.Name)
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, "could not ping docker: http response status code: 500 Internal Server Error", report.Error)
+}
+
+func TestDockerHealthChecksMissingSocket(t *testing.T) {
+	var m = NewDockerModule(dockerClient("unix", "/nonexistent/docker.sock"), true)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "ping", report.Name)
+	assert.Equal(t, KO, report.Status)
+	assert.NotZero(t, report.Error)
+	assert.Equal(t, Network, report.Category)
+}
+
+func TestNoopDockerHealthChecks(t *testing.T) {
+	var m = NewDockerModule(http.DefaultClient, false)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "ping", report.Name)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+}
+
+// dockerClient returns a http client that dials addr on the given network.
+func dockerClient(network, addr string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+}
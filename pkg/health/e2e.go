@@ -0,0 +1,136 @@
+package health
+
+//go:generate mockgen -destination=./mock/e2e.go -package=mock -mock_names=E2EModule=E2EModule  github.com/cloudtrust/flaki-service/pkg/health E2EModule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudtrust/flaki-service/pkg/flaki"
+)
+
+const (
+	// e2eKeyPrefix is the prefix of the redis keys written by the end-to-end check.
+	e2eKeyPrefix = "flaki-service:health:e2e:"
+	// e2eKeyTTL is the TTL of the redis keys written by the end-to-end check, in seconds. The keys
+	// are deleted by the check, the TTL guarantees they do not accumulate if the deletion fails.
+	e2eKeyTTL = 60
+)
+
+// E2EModule is the health check module for the end-to-end check. It generates an ID, stores it,
+// reads it back and checks that the round trip preserves it.
+type E2EModule interface {
+	HealthChecks(context.Context) []E2EReport
+}
+
+type e2eModule struct {
+	flaki   flaki.Module
+	redis   Redis
+	enabled bool
+}
+
+// E2EReport is the health report returned by the end-to-end module.
+type E2EReport = Report
+
+// NewE2EModule returns the end-to-end health module. The IDs are generated by the flaki module and
+// stored in redis, the only storage of the service. The check writes to redis, so it is opt-in.
+func NewE2EModule(flaki flaki.Module, redis Redis, enabled bool) E2EModule {
+	return &e2eModule{
+		flaki:   flaki,
+		redis:   redis,
+		enabled: enabled,
+	}
+}
+
+// HealthChecks executes all end-to-end health checks.
+func (m *e2eModule) HealthChecks(ctx context.Context) []E2EReport {
+	var reports = []E2EReport{}
+	reports = append(reports, m.e2eRoundTripCheck(ctx))
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *e2eModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *e2eModule) e2eRoundTripCheck(ctx context.Context) E2EReport {
+	var healthCheckName = "round trip"
+	var healthCheckDescription = "Generates an ID, stores it in Redis, reads it back and compares it. It fails when the generator and the storage do not work together."
+
+	if !m.enabled {
+		return E2EReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var step, err = m.roundTrip(ctx)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("end-to-end check failed at step '%s': %v", step, err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return E2EReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// roundTrip generates an ID, stores it, reads it back and compares it. It returns the step that failed and
+// its error. The redis commands are bounded by the deadline of ctx, so the round trip never outlives it.
+func (m *e2eModule) roundTrip(ctx context.Context) (string, error) {
+	// Generate.
+	var id, err = m.flaki.NextID(ctx)
+	if err != nil {
+		return "generate", err
+	}
+
+	// Store.
+	var key = e2eKeyPrefix + id
+	_, err = redisDo(ctx, m.redis, "SET", key, id, "EX", e2eKeyTTL)
+	if err != nil {
+		return "store", err
+	}
+	// Past the deadline, the deletion fails and the key expires with its TTL.
+	defer redisDo(ctx, m.redis, "DEL", key)
+
+	// Read.
+	var reply interface{}
+	reply, err = redisDo(ctx, m.redis, "GET", key)
+	if err != nil {
+		return "read", err
+	}
+
+	// Compare.
+	var stored string
+	switch r := reply.(type) {
+	case []byte:
+		stored = string(r)
+	case string:
+		stored = r
+	}
+	if stored != id {
+		return "compare", withCategory(DataIntegrity, fmt.Errorf("read '%s' but stored '%s'", stored, id))
+	}
+
+	return "", nil
+}
@@ -0,0 +1,132 @@
+package health_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2EHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockFlaki = mock.NewFlakiModule(mockCtrl)
+	var mockRedis = mock.NewRedis(mockCtrl)
+
+	var m = NewE2EModule(mockFlaki, mockRedis, true)
+
+	var id = "1234"
+	var key = "flaki-service:health:e2e:1234"
+	mockFlaki.EXPECT().NextID(gomock.Any()).Return(id, nil).Times(1)
+	mockRedis.EXPECT().Do("SET", key, id, "EX", 60).Return("OK", nil).Times(1)
+	mockRedis.EXPECT().Do("GET", key).Return([]byte(id), nil).Times(1)
+	mockRedis.EXPECT().Do("DEL", key).Return(int64(1), nil).Times(1)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "round trip", report.Name)
+	assert.NotZero(t, report.Description)
+	assert.NotZero(t, report.Duration)
+	assert.Equal(t, OK, report.Status)
+	assert.Zero(t, report.Error)
+	assert.Equal(t, 1, report.Attempts)
+}
+
+func TestE2EHealthChecksFail(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockFlaki = mock.NewFlakiModule(mockCtrl)
+	var mockRedis = mock.NewRedis(mockCtrl)
+
+	var m = NewE2EModule(mockFlaki, mockRedis, true)
+
+	var id = "1234"
+	var key = "flaki-service:health:e2e:1234"
+
+	// Generate fails.
+	{
+		mockFlaki.EXPECT().NextID(gomock.Any()).Return("", fmt.Errorf("fail")).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "end-to-end check failed at step 'generate': fail", report.Error)
+	}
+
+	// Store fails.
+	{
+		mockFlaki.EXPECT().NextID(gomock.Any()).Return(id, nil).Times(1)
+		mockRedis.EXPECT().Do("SET", key, id, "EX", 60).Return(nil, fmt.Errorf("fail")).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "end-to-end check failed at step 'store': fail", report.Error)
+	}
+
+	// Read back a different value.
+	{
+		mockFlaki.EXPECT().NextID(gomock.Any()).Return(id, nil).Times(1)
+		mockRedis.EXPECT().Do("SET", key, id, "EX", 60).Return("OK", nil).Times(1)
+		mockRedis.EXPECT().Do("GET", key).Return(nil, nil).Times(1)
+		mockRedis.EXPECT().Do("DEL", key).Return(int64(0), nil).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "end-to-end check failed at step 'compare': read '' but stored '1234'", report.Error)
+		assert.Equal(t, DataIntegrity, report.Category)
+	}
+
+	// Generate fails at the deadline.
+	{
+		mockFlaki.EXPECT().NextID(gomock.Any()).DoAndReturn(func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}).Times(1)
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		var report = m.HealthChecks(ctx)[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "end-to-end check failed at step 'generate': context deadline exceeded", report.Error)
+		assert.Equal(t, Timeout, report.Category)
+	}
+}
+
+func TestE2EParentDeadline(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockFlaki = mock.NewFlakiModule(mockCtrl)
+
+	var pool, stop = newSilentRedisPool(t)
+	defer stop()
+
+	// The round trip returns at the deadline, with no command left running.
+	var m = NewE2EModule(mockFlaki, NewRedisPool(pool), true)
+
+	mockFlaki.EXPECT().NextID(gomock.Any()).Return("1234", nil).Times(1)
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var now = time.Now()
+	var report = m.HealthChecks(ctx)[0]
+	assert.True(t, time.Since(now) < 2*time.Second)
+	assert.Equal(t, KO, report.Status)
+	assert.Contains(t, report.Error, "end-to-end check failed at step 'store'")
+	assert.Equal(t, Timeout, report.Category)
+	assert.Equal(t, 0, pool.ActiveCount())
+}
+
+func TestNoopE2EHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockFlaki = mock.NewFlakiModule(mockCtrl)
+	var mockRedis = mock.NewRedis(mockCtrl)
+
+	var m = NewE2EModule(mockFlaki, mockRedis, false)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "round trip", report.Name)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+	assert.Zero(t, report.Attempts)
+}
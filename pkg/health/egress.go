@@ -0,0 +1,129 @@
+package health
+
+//go:generate mockgen -destination=./mock/egress.go -package=mock -mock_names=EgressModule=EgressModule,EgressHTTPClient=EgressHTTPClient  github.com/cloudtrust/flaki-service/pkg/health EgressModule,EgressHTTPClient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// EgressModule is the health check module for the outbound connectivity. It queries an external canary
+// URL, so a blocked egress is told apart from a specific dependency being down.
+type EgressModule interface {
+	HealthChecks(context.Context) []EgressReport
+}
+
+type egressModule struct {
+	canaryURL  string
+	httpClient EgressHTTPClient
+	enabled    bool
+}
+
+// EgressReport is the health report returned by the egress module.
+type EgressReport = Report
+
+// EgressHTTPClient is the interface of the http client.
+type EgressHTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// NewEgressModule returns the egress health module. canaryURL is an external URL that answers with a 2xx
+// status, e.g. https://www.google.com/generate_204.
+func NewEgressModule(canaryURL string, httpClient EgressHTTPClient, enabled bool) EgressModule {
+	return &egressModule{
+		canaryURL:  canaryURL,
+		httpClient: httpClient,
+		enabled:    enabled,
+	}
+}
+
+// HealthChecks executes all health checks for the egress.
+func (m *egressModule) HealthChecks(ctx context.Context) []EgressReport {
+	var reports = []EgressReport{}
+	reports = append(reports, m.egressCanaryCheck(ctx))
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *egressModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *egressModule) egressCanaryCheck(ctx context.Context) EgressReport {
+	var healthCheckName = "canary"
+	var healthCheckDescription = "Queries an external canary URL. If it fails, the egress is blocked, e.g. by a firewall rule, and all the external services, such as Sentry, are unreachable."
+
+	if !m.enabled {
+		return EgressReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	if m.canaryURL == "" {
+		return EgressReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+			Error:       notConfiguredError,
+		}
+	}
+
+	var now = time.Now()
+	var err = queryCanary(ctx, m.canaryURL, m.httpClient)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("egress blocked, could not query canary '%s': %v", m.canaryURL, err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return EgressReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// queryCanary sends a GET request to the canary URL, within the context deadline. The canary must answer
+// with a 2xx status: a redirect or an error status usually comes from a proxy that denies the egress.
+func queryCanary(ctx context.Context, canaryURL string, httpClient EgressHTTPClient) error {
+	var req, err = http.NewRequest("GET", canaryURL, nil)
+	if err != nil {
+		return err
+	}
+
+	var res *http.Response
+	res, err = httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case isRedirect(res):
+		return unexpectedRedirect(res)
+	case res.StatusCode == http.StatusProxyAuthRequired || res.StatusCode == http.StatusForbidden:
+		return withCategory(Auth, fmt.Errorf("http response status code: %v", res.Status))
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		return fmt.Errorf("http response status code: %v", res.Status)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+package health_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEgressHealthChecks(t *testing.T) {
+	var status = http.StatusNoContent
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/generate_204", r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer s.Close()
+
+	var m = NewEgressModule(s.URL+"/generate_204", NewHTTPClient(), true)
+
+	// Egress open.
+	{
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, "canary", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// Denied by a proxy.
+	{
+		status = http.StatusProxyAuthRequired
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, fmt.Sprintf("egress blocked, could not query canary '%s/generate_204': http response status code: 407 Proxy Authentication Required", s.URL), report.Error)
+		assert.Equal(t, Auth, report.Category)
+	}
+
+	// Unexpected status.
+	{
+		status = http.StatusBadGateway
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Contains(t, report.Error, "502 Bad Gateway")
+	}
+}
+
+func TestEgressHealthChecksBlocked(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockHTTPClient = mock.NewEgressHTTPClient(mockCtrl)
+
+	var m = NewEgressModule("https://canary.example.com", mockHTTPClient, true)
+
+	// The firewall drops the packets, the request times out.
+	mockHTTPClient.EXPECT().Do(gomock.Any()).Return(nil, context.DeadlineExceeded).Times(1)
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, "egress blocked, could not query canary 'https://canary.example.com': context deadline exceeded", report.Error)
+	assert.Equal(t, Timeout, report.Category)
+}
+
+func TestNoopEgressHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockHTTPClient = mock.NewEgressHTTPClient(mockCtrl)
+
+	var m = NewEgressModule("https://canary.example.com", mockHTTPClient, false)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "canary", report.Name)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+	assert.Zero(t, report.Attempts)
+}
@@ -2,17 +2,34 @@ package health
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-kit/kit/endpoint"
 )
 
 // Endpoints wraps a service behind a set of endpoints.
 type Endpoints struct {
-	InfluxHealthCheck endpoint.Endpoint
-	JaegerHealthCheck endpoint.Endpoint
-	RedisHealthCheck  endpoint.Endpoint
-	SentryHealthCheck endpoint.Endpoint
-	AllHealthChecks   endpoint.Endpoint
+	InfluxHealthCheck         endpoint.Endpoint
+	JaegerHealthCheck         endpoint.Endpoint
+	RedisHealthCheck          endpoint.Endpoint
+	SentryHealthCheck         endpoint.Endpoint
+	SMTPHealthCheck           endpoint.Endpoint
+	DockerHealthCheck         endpoint.Endpoint
+	LogSinkHealthCheck        endpoint.Endpoint
+	E2EHealthCheck            endpoint.Endpoint
+	GRPCReflectionHealthCheck endpoint.Endpoint
+	EgressHealthCheck         endpoint.Endpoint
+	FDHealthCheck             endpoint.Endpoint
+	IDGenHealthCheck          endpoint.Endpoint
+	FeatureFlagHealthCheck    endpoint.Endpoint
+	ReplicaHealthCheck        endpoint.Endpoint
+	CommandHealthCheck        endpoint.Endpoint
+	SelfHealthCheck           endpoint.Endpoint
+	AuditLogHealthCheck       endpoint.Endpoint
+	CompositeHealthCheck      endpoint.Endpoint
+	AllHealthChecks           endpoint.Endpoint
+	AllHealthChecksDetailed   endpoint.Endpoint
+	Options                   endpoint.Endpoint
 }
 
 // MakeInfluxHealthCheckEndpoint makes the InfluxHealthCheck endpoint.
@@ -43,9 +60,154 @@ func MakeSentryHealthCheckEndpoint(c Component) endpoint.Endpoint {
 	}
 }
 
+// MakeSMTPHealthCheckEndpoint makes the SMTPHealthCheck endpoint.
+func MakeSMTPHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.SMTPHealthChecks(ctx), nil
+	}
+}
+
+// MakeDockerHealthCheckEndpoint makes the DockerHealthCheck endpoint.
+func MakeDockerHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.DockerHealthChecks(ctx), nil
+	}
+}
+
+// MakeLogSinkHealthCheckEndpoint makes the LogSinkHealthCheck endpoint.
+func MakeLogSinkHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.LogSinkHealthChecks(ctx), nil
+	}
+}
+
+// MakeE2EHealthCheckEndpoint makes the E2EHealthCheck endpoint.
+func MakeE2EHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.E2EHealthChecks(ctx), nil
+	}
+}
+
+// MakeGRPCReflectionHealthCheckEndpoint makes the GRPCReflectionHealthCheck endpoint.
+func MakeGRPCReflectionHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.GRPCReflectionHealthChecks(ctx), nil
+	}
+}
+
+// MakeEgressHealthCheckEndpoint makes the EgressHealthCheck endpoint.
+func MakeEgressHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.EgressHealthChecks(ctx), nil
+	}
+}
+
+// MakeFDHealthCheckEndpoint makes the FDHealthCheck endpoint.
+func MakeFDHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.FDHealthChecks(ctx), nil
+	}
+}
+
+// MakeIDGenHealthCheckEndpoint makes the IDGenHealthCheck endpoint.
+func MakeIDGenHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.IDGenHealthChecks(ctx), nil
+	}
+}
+
+// MakeFeatureFlagHealthCheckEndpoint makes the FeatureFlagHealthCheck endpoint.
+func MakeFeatureFlagHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.FeatureFlagHealthChecks(ctx), nil
+	}
+}
+
+// MakeReplicaHealthCheckEndpoint makes the ReplicaHealthCheck endpoint.
+func MakeReplicaHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.ReplicaHealthChecks(ctx), nil
+	}
+}
+
+// MakeCommandHealthCheckEndpoint makes the CommandHealthCheck endpoint.
+func MakeCommandHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.CommandHealthChecks(ctx), nil
+	}
+}
+
+// MakeSelfHealthCheckEndpoint makes the SelfHealthCheck endpoint.
+func MakeSelfHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.SelfHealthChecks(ctx), nil
+	}
+}
+
+// MakeAuditLogHealthCheckEndpoint makes the AuditLogHealthCheck endpoint.
+func MakeAuditLogHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.AuditLogHealthChecks(ctx), nil
+	}
+}
+
+// MakeCompositeHealthCheckEndpoint makes the CompositeHealthCheck endpoint.
+func MakeCompositeHealthCheckEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.CompositeHealthChecks(ctx), nil
+	}
+}
+
 // MakeAllHealthChecksEndpoint makes an endpoint that does all health checks.
 func MakeAllHealthChecksEndpoint(c Component) endpoint.Endpoint {
 	return func(ctx context.Context, req interface{}) (interface{}, error) {
 		return c.AllHealthChecks(ctx), nil
 	}
 }
+
+// MakeAllHealthChecksDetailedEndpoint makes an endpoint that does all health checks and returns a detailed report.
+// If the request is a list of subsystems, only their health checks are executed, see HealthChecksDetailed.
+func MakeAllHealthChecksDetailedEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		if subsystems, ok := req.([]string); ok {
+			return c.HealthChecksDetailed(ctx, subsystems...), nil
+		}
+		return c.AllHealthChecksDetailed(ctx), nil
+	}
+}
+
+// HealthRequest is the request of the health endpoint. Subsystems are the subsystems to check, all of them
+// if it is empty.
+type HealthRequest struct {
+	Subsystems []string
+}
+
+// HealthReply is the reply of the health endpoint.
+type HealthReply struct {
+	Report DetailedReport
+}
+
+// MakeHealthEndpoint makes the health endpoint. Like the flaki endpoints, it has a request and a reply type,
+// *HealthRequest and *HealthReply, so it can be wrapped with the same middlewares, e.g.
+// flaki.MakeEndpointInstrumentingMW. These middlewares need the correlation ID in the context, it must be
+// wrapped with MakeEndpointCorrelationIDMW last.
+func MakeHealthEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		switch r := req.(type) {
+		case *HealthRequest:
+			if len(r.Subsystems) == 0 {
+				return &HealthReply{Report: c.AllHealthChecksDetailed(ctx)}, nil
+			}
+			return &HealthReply{Report: c.HealthChecksDetailed(ctx, r.Subsystems...)}, nil
+		default:
+			return nil, fmt.Errorf("wrong request type: %T", req)
+		}
+	}
+}
+
+// MakeOptionsEndpoint makes the endpoint that returns the effective configuration of the component.
+func MakeOptionsEndpoint(c Component) endpoint.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return c.Options(), nil
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cloudtrust/flaki-service/pkg/flaki"
 	. "github.com/cloudtrust/flaki-service/pkg/health"
 	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/go-kit/kit/log"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -137,3 +139,512 @@ func TestSentryHealthCheckEndpoint(t *testing.T) {
 		assert.Equal(t, "fail", report.Error)
 	}
 }
+
+func TestSMTPHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeSMTPHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().SMTPHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "smtp", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "smtp", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().SMTPHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "smtp", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "smtp", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestDockerHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeDockerHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().DockerHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "docker", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "docker", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().DockerHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "docker", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "docker", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestLogSinkHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeLogSinkHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().LogSinkHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "logsink", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "logsink", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().LogSinkHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "logsink", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "logsink", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestE2EHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeE2EHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().E2EHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "e2e", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "e2e", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().E2EHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "e2e", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "e2e", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestGRPCReflectionHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeGRPCReflectionHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().GRPCReflectionHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "grpc", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "grpc", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().GRPCReflectionHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "grpc", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "grpc", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestEgressHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeEgressHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().EgressHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "egress", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "egress", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().EgressHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "egress", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "egress", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestFDHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeFDHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().FDHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "fd", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "fd", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().FDHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "fd", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "fd", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestIDGenHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeIDGenHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().IDGenHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "idgen", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "idgen", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().IDGenHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "idgen", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "idgen", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestAllHealthChecksDetailedEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeAllHealthChecksDetailedEndpoint(mockComponent)
+
+	// All the subsystems.
+	{
+		mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(DetailedReport{Status: OK}).Times(1)
+		var report, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		assert.Equal(t, OK, report.(DetailedReport).Status)
+	}
+
+	// Some subsystems.
+	{
+		mockComponent.EXPECT().HealthChecksDetailed(context.Background(), "redis", "e2e").Return(DetailedReport{Status: KO}).Times(1)
+		var report, err = e(context.Background(), []string{"redis", "e2e"})
+		assert.Nil(t, err)
+		assert.Equal(t, KO, report.(DetailedReport).Status)
+	}
+}
+
+func TestHealthEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+	var mockFlaki = mock.NewFlakiModule(mockCtrl)
+
+	// The flaki middlewares wrap the health endpoint.
+	var e = MakeHealthEndpoint(mockComponent)
+	e = flaki.MakeEndpointLoggingMW(log.NewNopLogger())(e)
+	e = MakeEndpointCorrelationIDMW(mockFlaki)(e)
+
+	// All the subsystems.
+	{
+		mockFlaki.EXPECT().NextValidID(gomock.Any()).Return("1").Times(1)
+		mockComponent.EXPECT().AllHealthChecksDetailed(gomock.Any()).Return(DetailedReport{Status: OK}).Times(1)
+		var reply, err = e(context.Background(), &HealthRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, OK, reply.(*HealthReply).Report.Status)
+	}
+
+	// Some subsystems.
+	{
+		mockFlaki.EXPECT().NextValidID(gomock.Any()).Return("2").Times(1)
+		mockComponent.EXPECT().HealthChecksDetailed(gomock.Any(), "redis").Return(DetailedReport{Status: KO}).Times(1)
+		var reply, err = e(context.Background(), &HealthRequest{Subsystems: []string{"redis"}})
+		assert.Nil(t, err)
+		assert.Equal(t, KO, reply.(*HealthReply).Report.Status)
+	}
+
+	// Wrong request type.
+	{
+		var reply, err = MakeHealthEndpoint(mockComponent)(context.Background(), nil)
+		assert.NotNil(t, err)
+		assert.Nil(t, reply)
+	}
+}
+
+func TestFeatureFlagHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeFeatureFlagHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().FeatureFlagHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "featureflag", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "featureflag", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().FeatureFlagHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "featureflag", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "featureflag", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestReplicaHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeReplicaHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().ReplicaHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "replica", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "replica", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().ReplicaHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "replica", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "replica", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestCommandHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeCommandHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().CommandHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "command", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "command", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().CommandHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "command", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "command", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestSelfHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeSelfHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().SelfHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "self", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "self", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().SelfHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "self", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "self", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestAuditLogHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeAuditLogHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().AuditLogHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "auditlog", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "auditlog", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().AuditLogHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "auditlog", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "auditlog", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
+
+func TestCompositeHealthCheckEndpoint(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeCompositeHealthCheckEndpoint(mockComponent)
+
+	// Health success.
+	{
+		mockComponent.EXPECT().CompositeHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "composite", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "composite", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health error.
+	{
+		mockComponent.EXPECT().CompositeHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "composite", Duration: (1 * time.Second).String(), Status: KO, Error: "fail"}}}).Times(1)
+		var reports, err = e(context.Background(), nil)
+		assert.Nil(t, err)
+		var report = reports.(Reports).Reports[0]
+		assert.Equal(t, "composite", report.Name)
+		assert.Equal(t, (1 * time.Second).String(), report.Duration)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "fail", report.Error)
+	}
+}
@@ -0,0 +1,177 @@
+package health
+
+//go:generate mockgen -destination=./mock/fd.go -package=mock -mock_names=FDModule=FDModule  github.com/cloudtrust/flaki-service/pkg/health FDModule
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FDModule is the health check module for the file descriptors of the process.
+type FDModule interface {
+	HealthChecks(context.Context) []FDReport
+}
+
+type fdModule struct {
+	warn    float64
+	crit    float64
+	enabled bool
+}
+
+// FDReport is the health report returned by the file descriptors module.
+type FDReport = Report
+
+// NewFDModule returns the file descriptors health module. warn and crit are fractions of the soft limit
+// of open files, e.g. 0.8 and 0.95: the check is Degraded when the process uses warn of the limit, and KO
+// when it uses crit.
+func NewFDModule(warn, crit float64, enabled bool) FDModule {
+	return &fdModule{
+		warn:    warn,
+		crit:    crit,
+		enabled: enabled,
+	}
+}
+
+// HealthChecks executes all health checks for the file descriptors.
+func (m *fdModule) HealthChecks(context.Context) []FDReport {
+	var reports = []FDReport{}
+	reports = append(reports, m.fdUsageCheck())
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *fdModule) Enabled() bool {
+	return m.enabled
+}
+
+// errFDUnavailable is returned when the file descriptors of the process cannot be counted on the platform.
+var errFDUnavailable = errors.New("file descriptors usage unavailable on this platform")
+
+func (m *fdModule) fdUsageCheck() FDReport {
+	var healthCheckName = "usage"
+	var healthCheckDescription = "Compares the open file descriptors of the process to its soft limit. If it fails, a connection leak will soon make all the connections and file opens fail."
+
+	if !m.enabled {
+		return FDReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var open, limit, err = fdUsage()
+	var duration = time.Since(now)
+
+	if err == errFDUnavailable {
+		return FDReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var ratio float64
+	if limit > 0 {
+		ratio = float64(open) / float64(limit)
+	}
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not count file descriptors: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	case limit > 0 && ratio >= m.crit:
+		error = fmt.Sprintf("file descriptors near the limit: %d of %d open", open, limit)
+		s = KO
+		category = Capacity
+	case limit > 0 && ratio >= m.warn:
+		error = fmt.Sprintf("file descriptors high: %d of %d open", open, limit)
+		s = Degraded
+		category = Capacity
+	default:
+		s = OK
+	}
+
+	return FDReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// fdUsage returns the number of open file descriptors of the process and its soft limit, read from
+// /proc/self. The limit is 0 when it is unlimited. It returns errFDUnavailable when there is no /proc,
+// e.g. on macOS.
+func fdUsage() (int, int, error) {
+	var dir, err = os.Open("/proc/self/fd")
+	if os.IsNotExist(err) {
+		return 0, 0, errFDUnavailable
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	defer dir.Close()
+
+	var names []string
+	names, err = dir.Readdirnames(-1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var limit int
+	limit, err = fdSoftLimit("/proc/self/limits")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// The directory read itself uses a file descriptor.
+	return len(names) - 1, limit, nil
+}
+
+// fdSoftLimit returns the soft limit of open files in the limits file, e.g.
+// "Max open files            1024                 4096                 files". It returns 0 when the
+// limit is unlimited.
+func fdSoftLimit(path string) (int, error) {
+	var f, err = os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var scanner = bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line = scanner.Text()
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+
+		var fields = strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) == 0 {
+			break
+		}
+		if fields[0] == "unlimited" {
+			return 0, nil
+		}
+		return strconv.Atoi(fields[0])
+	}
+	if err = scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no open files limit in %s", path)
+}
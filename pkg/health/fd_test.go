@@ -0,0 +1,56 @@
+package health_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFDHealthChecks(t *testing.T) {
+	if _, err := os.Stat("/proc/self/fd"); err != nil {
+		t.Skip("no /proc on this platform")
+	}
+
+	// Below the thresholds.
+	{
+		var m = NewFDModule(0.9, 0.95, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, "usage", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// Above the warning threshold.
+	{
+		var m = NewFDModule(1e-9, 1, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Contains(t, report.Error, "file descriptors high")
+		assert.Equal(t, Capacity, report.Category)
+	}
+
+	// Above the critical threshold.
+	{
+		var m = NewFDModule(1e-9, 1e-9, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Contains(t, report.Error, "file descriptors near the limit")
+		assert.Equal(t, Capacity, report.Category)
+	}
+}
+
+func TestNoopFDHealthChecks(t *testing.T) {
+	var m = NewFDModule(0.8, 0.95, false)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "usage", report.Name)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+}
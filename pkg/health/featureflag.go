@@ -0,0 +1,128 @@
+package health
+
+//go:generate mockgen -destination=./mock/featureflag.go -package=mock -mock_names=FeatureFlagModule=FeatureFlagModule,FeatureFlagHTTPClient=FeatureFlagHTTPClient  github.com/cloudtrust/flaki-service/pkg/health FeatureFlagModule,FeatureFlagHTTPClient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// FeatureFlagModule is the health check module for the feature flag service, e.g. an Unleash proxy or a
+// LaunchDarkly relay. The service falls back to the default flags when it is down, so it is reported as
+// Degraded rather than KO.
+type FeatureFlagModule interface {
+	HealthChecks(context.Context) []FeatureFlagReport
+}
+
+type featureFlagModule struct {
+	url        string
+	httpClient FeatureFlagHTTPClient
+	enabled    bool
+}
+
+// FeatureFlagReport is the health report returned by the feature flag module.
+type FeatureFlagReport = Report
+
+// FeatureFlagHTTPClient is the interface of the http client.
+type FeatureFlagHTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// NewFeatureFlagModule returns the feature flag health module. url is the health or poll endpoint of the
+// provider, e.g. http://unleash-proxy:3063/proxy/health.
+func NewFeatureFlagModule(httpClient FeatureFlagHTTPClient, url string, enabled bool) FeatureFlagModule {
+	return &featureFlagModule{
+		url:        url,
+		httpClient: httpClient,
+		enabled:    enabled,
+	}
+}
+
+// HealthChecks executes all health checks for the feature flag service.
+func (m *featureFlagModule) HealthChecks(ctx context.Context) []FeatureFlagReport {
+	var reports = []FeatureFlagReport{}
+	reports = append(reports, m.featureFlagPollCheck(ctx))
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *featureFlagModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *featureFlagModule) featureFlagPollCheck(ctx context.Context) FeatureFlagReport {
+	var healthCheckName = "poll"
+	var healthCheckDescription = "Queries the health endpoint of the feature flag service. If it fails, the flags are not updated and the service falls back to the default flags."
+
+	if !m.enabled {
+		return FeatureFlagReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	if m.url == "" {
+		return FeatureFlagReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+			Error:       notConfiguredError,
+		}
+	}
+
+	var now = time.Now()
+	var err = pollFeatureFlags(ctx, m.url, m.httpClient)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("feature flags unavailable, falling back to the defaults, could not query '%s': %v", m.url, err.Error())
+		s = Degraded
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return FeatureFlagReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// pollFeatureFlags sends a GET request to the feature flag service, within the context deadline. It must
+// answer with a 2xx status.
+func pollFeatureFlags(ctx context.Context, url string, httpClient FeatureFlagHTTPClient) error {
+	var req, err = http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	var res *http.Response
+	res, err = httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		return withCategory(Auth, fmt.Errorf("http response status code: %v", res.Status))
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		return fmt.Errorf("http response status code: %v", res.Status)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,93 @@
+package health_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureFlagHealthChecks(t *testing.T) {
+	var status = http.StatusOK
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/proxy/health", r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer s.Close()
+
+	var m = NewFeatureFlagModule(NewHTTPClient(), s.URL+"/proxy/health", true)
+
+	// Feature flag service up.
+	{
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, "poll", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// Wrong token, the service falls back to the defaults.
+	{
+		status = http.StatusUnauthorized
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, fmt.Sprintf("feature flags unavailable, falling back to the defaults, could not query '%s/proxy/health': http response status code: 401 Unauthorized", s.URL), report.Error)
+		assert.Equal(t, Auth, report.Category)
+	}
+
+	// Unexpected status.
+	{
+		status = http.StatusServiceUnavailable
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Contains(t, report.Error, "503 Service Unavailable")
+	}
+}
+
+func TestFeatureFlagHealthChecksUnreachable(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockHTTPClient = mock.NewFeatureFlagHTTPClient(mockCtrl)
+
+	var m = NewFeatureFlagModule(mockHTTPClient, "http://unleash-proxy:3063/proxy/health", true)
+
+	// Unreachable, it is not KO since the defaults are used.
+	mockHTTPClient.EXPECT().Do(gomock.Any()).Return(nil, context.DeadlineExceeded).Times(1)
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, Degraded, report.Status)
+	assert.Equal(t, "feature flags unavailable, falling back to the defaults, could not query 'http://unleash-proxy:3063/proxy/health': context deadline exceeded", report.Error)
+	assert.Equal(t, Timeout, report.Category)
+}
+
+func TestNoopFeatureFlagHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockHTTPClient = mock.NewFeatureFlagHTTPClient(mockCtrl)
+
+	// Disabled.
+	{
+		var m = NewFeatureFlagModule(mockHTTPClient, "http://unleash-proxy:3063/proxy/health", false)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, "poll", report.Name)
+		assert.Equal(t, "N/A", report.Duration)
+		assert.Equal(t, Deactivated, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Zero(t, report.Attempts)
+	}
+
+	// Not configured.
+	{
+		var m = NewFeatureFlagModule(mockHTTPClient, "", true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, Deactivated, report.Status)
+		assert.Equal(t, "not configured", report.Error)
+	}
+}
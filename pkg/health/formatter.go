@@ -0,0 +1,147 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Formatter formats the detailed health report for the detailed health checks handler, e.g. as JSON or in
+// the OpenMetrics text format. It returns the formatted report and its content type.
+type Formatter interface {
+	Format(ctx context.Context, report DetailedReport) (data []byte, contentType string, err error)
+}
+
+// jsonContentType is the content type of the JSON replies.
+const jsonContentType = "application/json; charset=utf-8"
+
+// defaultFormat is the name of the formatter used when the request does not select one.
+const defaultFormat = "json"
+
+// WithFormatter registers the formatter f for the detailed health checks handler. It is selected with the
+// query parameter "format=<name>", or with the media type in the Accept header, e.g. "application/json". It
+// replaces the built-in formatter of the same name, i.e. "json", "openmetrics" or "logfmt". The other
+// handlers ignore it.
+func WithFormatter(name, mediaType string, f Formatter) HandlerOption {
+	return func(c *handlerConfig) {
+		c.formatters = append(c.formatters, namedFormatter{name: name, mediaType: mediaTypeOf(mediaType), f: f})
+	}
+}
+
+type namedFormatter struct {
+	name      string
+	mediaType string
+	f         Formatter
+}
+
+// NewJSONFormatter returns the formatter of the detailed JSON reply. The handler options apply.
+func NewJSONFormatter(options ...HandlerOption) Formatter {
+	return &jsonFormatter{config: newHandlerConfig(options...)}
+}
+
+type jsonFormatter struct {
+	config handlerConfig
+}
+
+func (f *jsonFormatter) Format(_ context.Context, report DetailedReport) ([]byte, string, error) {
+	var data, err = json.MarshalIndent(toDetailedReply(report, f.config), "", "  ")
+	return data, jsonContentType, err
+}
+
+// NewOpenMetricsFormatter returns the formatter of the OpenMetrics text format, see WriteOpenMetrics.
+func NewOpenMetricsFormatter() Formatter {
+	return &openMetricsFormatter{}
+}
+
+type openMetricsFormatter struct{}
+
+func (f *openMetricsFormatter) Format(ctx context.Context, report DetailedReport) ([]byte, string, error) {
+	var b = &bytes.Buffer{}
+	var err = WriteOpenMetrics(ctx, b, report)
+	return b.Bytes(), openMetricsContentType, err
+}
+
+// NewLogfmtFormatter returns the formatter of the logfmt reply, see DetailedReport.WriteLogfmt.
+func NewLogfmtFormatter() Formatter {
+	return &logfmtFormatter{}
+}
+
+type logfmtFormatter struct{}
+
+func (f *logfmtFormatter) Format(_ context.Context, report DetailedReport) ([]byte, string, error) {
+	var b = &bytes.Buffer{}
+	var err = report.WriteLogfmt(b)
+	return b.Bytes(), logfmtContentType, err
+}
+
+// formattersOf returns the formatters of the handler, sorted by name: the built-in ones, replaced by the
+// registered ones of the same name.
+func formattersOf(config handlerConfig) []namedFormatter {
+	var byName = map[string]namedFormatter{
+		"json":        {name: "json", mediaType: mediaTypeOf(jsonContentType), f: &jsonFormatter{config: config}},
+		"openmetrics": {name: "openmetrics", mediaType: mediaTypeOf(openMetricsContentType), f: &openMetricsFormatter{}},
+		"logfmt":      {name: "logfmt", mediaType: mediaTypeOf(logfmtContentType), f: &logfmtFormatter{}},
+	}
+	for _, f := range config.formatters {
+		byName[f.name] = f
+	}
+
+	var formatters = []namedFormatter{}
+	for _, f := range byName {
+		formatters = append(formatters, f)
+	}
+	sort.Slice(formatters, func(i, j int) bool { return formatters[i].name < formatters[j].name })
+	return formatters
+}
+
+// fetchHTTPFormat puts the query parameter "format" and the header "Accept" in the context, if they are
+// set, so the encoder can select the formatter.
+func fetchHTTPFormat(ctx context.Context, r *http.Request) context.Context {
+	if format := r.URL.Query().Get("format"); format != "" {
+		ctx = context.WithValue(ctx, "health_format", format)
+	}
+	if accept := r.Header.Get("Accept"); accept != "" {
+		ctx = context.WithValue(ctx, "health_accept", accept)
+	}
+	return ctx
+}
+
+// selectFormatter selects the formatter requested with the query parameter "format", or else the first
+// formatter whose media type matches a media type of the Accept header, in the order of the header. The
+// quality values are not taken into account. It defaults to JSON, but an unknown format is an error.
+func selectFormatter(ctx context.Context, formatters []namedFormatter) (Formatter, error) {
+	if format, _ := ctx.Value("health_format").(string); format != "" {
+		for _, f := range formatters {
+			if f.name == format {
+				return f.f, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown health report format '%s'", format)
+	}
+
+	var accept, _ = ctx.Value("health_accept").(string)
+	for _, mediaRange := range strings.Split(accept, ",") {
+		var mediaType = mediaTypeOf(mediaRange)
+		for _, f := range formatters {
+			if f.mediaType == mediaType {
+				return f.f, nil
+			}
+		}
+	}
+
+	for _, f := range formatters {
+		if f.name == defaultFormat {
+			return f.f, nil
+		}
+	}
+	return nil, fmt.Errorf("no default health report format '%s'", defaultFormat)
+}
+
+// mediaTypeOf returns the media type without its parameters, e.g. "text/plain" for "text/plain; q=0.5".
+func mediaTypeOf(s string) string {
+	return strings.ToLower(strings.TrimSpace(strings.Split(s, ";")[0]))
+}
@@ -0,0 +1,97 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type compactFormatter struct{}
+
+func (f compactFormatter) Format(_ context.Context, report DetailedReport) ([]byte, string, error) {
+	return []byte(fmt.Sprintf("%s %d", report.Status, len(report.Subsystems))), "application/x-health-compact", nil
+}
+
+func TestHealthChecksDetailedHandlerFormat(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeAllHealthChecksDetailedHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent),
+		WithFormatter("compact", "application/x-health-compact", compactFormatter{}))
+
+	var report = DetailedReport{
+		Status: OK,
+		Subsystems: map[string]SubsystemReport{
+			"redis": {Status: OK, Reports: []Report{{Name: "ping", Duration: "1ms", Status: OK, Attempts: 1}}},
+		},
+	}
+	mockComponent.EXPECT().AllHealthChecksDetailed(gomock.Any()).Return(report).AnyTimes()
+
+	var tsts = []struct {
+		query       string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		// Default, JSON.
+		{"", "", http.StatusOK, "application/json; charset=utf-8", `"status": "OK"`},
+		{"", "*/*", http.StatusOK, "application/json; charset=utf-8", `"status": "OK"`},
+		{"", "text/html", http.StatusOK, "application/json; charset=utf-8", `"status": "OK"`},
+		// Query parameter, it has precedence over the Accept header.
+		{"?format=openmetrics", "application/json", http.StatusOK, "application/openmetrics-text; version=1.0.0; charset=utf-8", `health_status{health_status="OK"} 1`},
+		{"?format=logfmt", "", http.StatusOK, "text/plain; charset=utf-8", "subsystem=redis"},
+		{"?format=compact", "", http.StatusOK, "application/x-health-compact", "OK 1"},
+		{"?format=xml", "", http.StatusNotAcceptable, "application/json; charset=utf-8", "unknown health report format 'xml'"},
+		// Accept header, the first known media type is selected.
+		{"", "application/openmetrics-text; version=1.0.0, text/plain; version=0.0.4; q=0.5", http.StatusOK, "application/openmetrics-text; version=1.0.0; charset=utf-8", "# EOF"},
+		{"", "text/html, application/x-health-compact", http.StatusOK, "application/x-health-compact", "OK 1"},
+	}
+
+	for _, tst := range tsts {
+		var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/detailed"+tst.query, nil)
+		if tst.accept != "" {
+			req.Header.Set("Accept", tst.accept)
+		}
+		var w = httptest.NewRecorder()
+
+		h.ServeHTTP(w, req)
+		var resp = w.Result()
+		var body, err = ioutil.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, tst.status, resp.StatusCode, tst.query+tst.accept)
+		assert.Equal(t, tst.contentType, resp.Header.Get("Content-Type"), tst.query+tst.accept)
+		assert.True(t, strings.Contains(string(body), tst.body), string(body))
+	}
+}
+
+func TestJSONFormatter(t *testing.T) {
+	var report = DetailedReport{
+		Status: OK,
+		Subsystems: map[string]SubsystemReport{
+			"redis": {Status: OK, Reports: []Report{{Name: "ping", Duration: "1ms", Status: OK, Attempts: 1}}},
+			"smtp":  {Status: Deactivated},
+		},
+	}
+
+	var data, contentType, err = NewJSONFormatter(OmitDeactivated(true)).Format(context.Background(), report)
+	assert.Nil(t, err)
+	assert.Equal(t, "application/json; charset=utf-8", contentType)
+
+	var r = DetailedReply{}
+	assert.Nil(t, json.Unmarshal(data, &r))
+	assert.Equal(t, "OK", r.Status)
+	assert.Contains(t, r.Subsystems, "redis")
+	assert.NotContains(t, r.Subsystems, "smtp")
+}
@@ -0,0 +1,136 @@
+package health
+
+//go:generate mockgen -destination=./mock/grpc.go -package=mock -mock_names=Handler=Handler github.com/go-kit/kit/transport/grpc Handler
+//go:generate mockgen -destination=./mock/codec.go -package=mock -mock_names=Codec=Codec google.golang.org/grpc Codec
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	grpc_transport "github.com/go-kit/kit/transport/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+type grpcServer struct {
+	allHealthChecks grpc_transport.Handler
+}
+
+// MakeGRPCAllHealthChecksHandler makes a GRPC handler for the AllHealthChecksDetailed endpoint.
+func MakeGRPCAllHealthChecksHandler(e endpoint.Endpoint) *grpc_transport.Server {
+	return grpc_transport.NewServer(
+		e,
+		decodeGRPCRequest,
+		encodeGRPCReply,
+		grpc_transport.ServerBefore(fetchGRPCCorrelationID),
+	)
+}
+
+// NewGRPCServer makes a handler available as a standard grpc.health.v1 HealthServer.
+// The empty service name is the overall health of the service, the other service names
+// are the subsystems names.
+func NewGRPCServer(allHealthChecksHandler grpc_transport.Handler) grpc_health_v1.HealthServer {
+	return &grpcServer{
+		allHealthChecks: allHealthChecksHandler,
+	}
+}
+
+// Implement the grpc.health.v1 HealthServer interface.
+func (s *grpcServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	var _, rep, err = s.allHealthChecks.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, errors.Wrap(err, "grpc server could not return health")
+	}
+
+	var report = rep.(DetailedReport)
+
+	var status = report.Status
+	if req.Service != "" {
+		var subsystem, ok = report.Subsystems[req.Service]
+		if !ok {
+			return nil, grpc_status.Errorf(codes.NotFound, "unknown service %s", req.Service)
+		}
+		status = subsystem.Status
+	}
+
+	return &grpc_health_v1.HealthCheckResponse{
+		Status: servingStatus(status),
+	}, nil
+}
+
+// servingStatus maps the health status to the grpc.health.v1 serving status. Degraded subsystems
+// are still serving.
+func servingStatus(s Status) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	switch s {
+	case OK, Degraded:
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	case KO:
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	default:
+		return grpc_health_v1.HealthCheckResponse_UNKNOWN
+	}
+}
+
+// fetchGRPCCorrelationID reads the correlation ID from the GRPC metadata.
+// If the id is not zero, we put it in the context.
+func fetchGRPCCorrelationID(ctx context.Context, md metadata.MD) context.Context {
+	var val = md["correlation_id"]
+
+	// If there is no id in the metadata, return current context.
+	if val == nil || val[0] == "" {
+		return ctx
+	}
+
+	// If there is an id in the metadata, add it to the context.
+	var id = val[0]
+	return context.WithValue(ctx, "correlation_id", id)
+}
+
+// decodeGRPCRequest decodes the grpc.health.v1 request.
+func decodeGRPCRequest(_ context.Context, req interface{}) (interface{}, error) {
+	return req, nil
+}
+
+// encodeGRPCReply encodes the health report.
+func encodeGRPCReply(_ context.Context, rep interface{}) (interface{}, error) {
+	return rep, nil
+}
+
+type grpcCodec struct {
+	next grpc.Codec
+}
+
+// NewGRPCCodec returns a codec that marshals the protobuf messages of the grpc.health.v1 service
+// and delegates all other messages to next. It allows the health service to be registered on
+// the flaki GRPC server, which uses the flatbuffers codec.
+func NewGRPCCodec(next grpc.Codec) grpc.Codec {
+	return &grpcCodec{
+		next: next,
+	}
+}
+
+// Implement the grpc Codec interface.
+func (c *grpcCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Marshal(m)
+	}
+	return c.next.Marshal(v)
+}
+
+// Implement the grpc Codec interface.
+func (c *grpcCodec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+	return c.next.Unmarshal(data, v)
+}
+
+// Implement the grpc Codec interface.
+func (c *grpcCodec) String() string {
+	return c.next.String()
+}
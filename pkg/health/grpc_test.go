@@ -0,0 +1,126 @@
+package health_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+func TestNewGRPCServer(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var s = NewGRPCServer(MakeGRPCAllHealthChecksHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent)))
+
+	var report = DetailedReport{
+		Status: Degraded,
+		Subsystems: map[string]SubsystemReport{
+			"influx": {Status: OK},
+			"jaeger": {Status: Degraded},
+			"redis":  {Status: KO},
+			"smtp":   {Status: Deactivated},
+		},
+	}
+	mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(report).AnyTimes()
+
+	var tsts = []struct {
+		service string
+		status  grpc_health_v1.HealthCheckResponse_ServingStatus
+	}{
+		{"", grpc_health_v1.HealthCheckResponse_SERVING},
+		{"influx", grpc_health_v1.HealthCheckResponse_SERVING},
+		{"jaeger", grpc_health_v1.HealthCheckResponse_SERVING},
+		{"redis", grpc_health_v1.HealthCheckResponse_NOT_SERVING},
+		{"smtp", grpc_health_v1.HealthCheckResponse_UNKNOWN},
+	}
+
+	for _, tst := range tsts {
+		var rep, err = s.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: tst.service})
+		assert.Nil(t, err)
+		assert.Equal(t, tst.status, rep.Status)
+	}
+
+	// Unknown service.
+	var rep, err = s.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+	assert.Nil(t, rep)
+	assert.Equal(t, codes.NotFound, grpc_status.Code(err))
+}
+
+func TestGRPCErrorHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockHandler = mock.NewHandler(mockCtrl)
+
+	var s = NewGRPCServer(mockHandler)
+
+	var req = &grpc_health_v1.HealthCheckRequest{}
+	mockHandler.EXPECT().ServeGRPC(context.Background(), req).Return(context.Background(), nil, fmt.Errorf("fail")).Times(1)
+	var rep, err = s.Check(context.Background(), req)
+	assert.NotNil(t, err)
+	assert.Nil(t, rep)
+}
+
+func TestFetchGRPCCorrelationID(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var s = NewGRPCServer(MakeGRPCAllHealthChecksHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent)))
+
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var md = metadata.New(map[string]string{"correlation_id": corrID})
+	var ctx = metadata.NewIncomingContext(context.Background(), md)
+
+	mockComponent.EXPECT().AllHealthChecksDetailed(gomock.Any()).Do(func(ctx context.Context) {
+		assert.Equal(t, corrID, ctx.Value("correlation_id").(string))
+	}).Return(DetailedReport{Status: OK}).Times(1)
+	var rep, err = s.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, rep.Status)
+}
+
+func TestGRPCCodec(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockCodec = mock.NewCodec(mockCtrl)
+
+	var c = NewGRPCCodec(mockCodec)
+
+	// Protobuf messages are handled by the codec.
+	{
+		var data, err = c.Marshal(&grpc_health_v1.HealthCheckRequest{Service: "redis"})
+		assert.Nil(t, err)
+		var req = &grpc_health_v1.HealthCheckRequest{}
+		assert.Nil(t, c.Unmarshal(data, req))
+		assert.Equal(t, "redis", req.Service)
+	}
+
+	// Other messages are delegated.
+	{
+		var msg = "flatbuffers"
+		mockCodec.EXPECT().Marshal(msg).Return([]byte(msg), nil).Times(1)
+		var data, err = c.Marshal(msg)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte(msg), data)
+
+		mockCodec.EXPECT().Unmarshal([]byte(msg), &msg).Return(nil).Times(1)
+		assert.Nil(t, c.Unmarshal([]byte(msg), &msg))
+
+		mockCodec.EXPECT().String().Return("flatbuffers").Times(1)
+		assert.Equal(t, "flatbuffers", c.String())
+	}
+}
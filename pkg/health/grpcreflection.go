@@ -0,0 +1,159 @@
+package health
+
+//go:generate mockgen -destination=./mock/grpcreflection.go -package=mock -mock_names=GRPCReflectionModule=GRPCReflectionModule,GRPCReflection=GRPCReflection  github.com/cloudtrust/flaki-service/pkg/health GRPCReflectionModule,GRPCReflection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// GRPCReflectionModule is the health check module for the gRPC dependencies that do not implement the
+// gRPC health checking protocol. The server is checked with server reflection.
+type GRPCReflectionModule interface {
+	HealthChecks(context.Context) []GRPCReflectionReport
+}
+
+type grpcReflectionModule struct {
+	target     string
+	reflection GRPCReflection
+	enabled    bool
+}
+
+// GRPCReflectionReport is the health report returned by the gRPC reflection module.
+type GRPCReflectionReport = Report
+
+// GRPCReflection is the interface of the server reflection client. ListServices connects to the gRPC
+// server at target and returns the services it exposes.
+type GRPCReflection interface {
+	ListServices(ctx context.Context, target string) ([]string, error)
+}
+
+// GRPCReflectionOption is an option of the gRPC reflection health module.
+type GRPCReflectionOption func(*grpcReflectionModule)
+
+// GRPCReflectionClient sets the server reflection client. The default client dials the target without
+// TLS, and uses the v1alpha server reflection service.
+func GRPCReflectionClient(reflection GRPCReflection) GRPCReflectionOption {
+	return func(m *grpcReflectionModule) {
+		m.reflection = reflection
+	}
+}
+
+// NewGRPCReflectionModule returns the gRPC reflection health module. target is the address of the gRPC
+// server, e.g. localhost:5555.
+func NewGRPCReflectionModule(target string, enabled bool, options ...GRPCReflectionOption) GRPCReflectionModule {
+	var m = &grpcReflectionModule{
+		target:     target,
+		reflection: &grpcReflectionClient{},
+		enabled:    enabled,
+	}
+
+	// Apply options.
+	for _, o := range options {
+		o(m)
+	}
+
+	return m
+}
+
+// HealthChecks executes all health checks for the gRPC server.
+func (m *grpcReflectionModule) HealthChecks(ctx context.Context) []GRPCReflectionReport {
+	var reports = []GRPCReflectionReport{}
+	reports = append(reports, m.grpcReflectionCheck(ctx))
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *grpcReflectionModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *grpcReflectionModule) grpcReflectionCheck(ctx context.Context) GRPCReflectionReport {
+	var healthCheckName = "reflection"
+	var healthCheckDescription = "Connects to the gRPC server and lists its services with server reflection. If it fails, the gRPC server is not serving."
+
+	if !m.enabled {
+		return GRPCReflectionReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var _, err = m.reflection.ListServices(ctx, m.target)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not list the services of grpc server '%s': %v", m.target, err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return GRPCReflectionReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// grpcReflectionClient is the default server reflection client.
+type grpcReflectionClient struct{}
+
+// ListServices dials the target and lists its services, within the context deadline. The connection is
+// closed afterwards, so the check does not keep a connection open between two runs.
+func (c *grpcReflectionClient) ListServices(ctx context.Context, target string) ([]string, error) {
+	var conn, err = grpc.DialContext(ctx, target, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return nil, withCategory(errorCategory(err), fmt.Errorf("could not dial: %v", err.Error()))
+	}
+	defer conn.Close()
+
+	var stream grpc_reflection_v1alpha.ServerReflection_ServerReflectionInfoClient
+	stream, err = grpc_reflection_v1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend()
+
+	err = stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_ListServices{ListServices: "*"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res *grpc_reflection_v1alpha.ServerReflectionResponse
+	res, err = stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	switch r := res.MessageResponse.(type) {
+	case *grpc_reflection_v1alpha.ServerReflectionResponse_ListServicesResponse:
+		var services = []string{}
+		for _, s := range r.ListServicesResponse.Service {
+			services = append(services, s.Name)
+		}
+		return services, nil
+	case *grpc_reflection_v1alpha.ServerReflectionResponse_ErrorResponse:
+		return nil, fmt.Errorf("reflection error %d: %s", r.ErrorResponse.ErrorCode, r.ErrorResponse.ErrorMessage)
+	default:
+		return nil, fmt.Errorf("unexpected reflection response: %T", res.MessageResponse)
+	}
+}
@@ -0,0 +1,55 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGRPCReflectionHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockReflection = mock.NewGRPCReflection(mockCtrl)
+
+	var m = NewGRPCReflectionModule("localhost:5555", true, GRPCReflectionClient(mockReflection))
+
+	// Serving.
+	{
+		mockReflection.EXPECT().ListServices(context.Background(), "localhost:5555").Return([]string{"grpc.reflection.v1alpha.ServerReflection"}, nil).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, "reflection", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// Not serving within the deadline.
+	{
+		mockReflection.EXPECT().ListServices(context.Background(), "localhost:5555").Return(nil, context.DeadlineExceeded).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not list the services of grpc server 'localhost:5555': context deadline exceeded", report.Error)
+		assert.Equal(t, Timeout, report.Category)
+	}
+}
+
+func TestNoopGRPCReflectionHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockReflection = mock.NewGRPCReflection(mockCtrl)
+
+	var m = NewGRPCReflectionModule("localhost:5555", false, GRPCReflectionClient(mockReflection))
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "reflection", report.Name)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+	assert.Zero(t, report.Attempts)
+}
@@ -0,0 +1,159 @@
+package health
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MakeHTTPGzipMW makes a middleware that compresses the responses with gzip for the requests whose
+// Accept-Encoding header accepts it, e.g. the frequent scrapes of the detailed health. The beginning of the
+// response is buffered until it reaches minSize bytes: the smaller responses are sent as is, since the
+// compression would not pay off. A response flushed before it reaches minSize, e.g. the first lines of the
+// NDJSON stream, is sent as is too. The responses already encoded by next are not compressed again.
+func MakeHTTPGzipMW(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var gw = &gzipResponseWriter{
+				ResponseWriter: w,
+				minSize:        minSize,
+				status:         http.StatusOK,
+			}
+			defer gw.close()
+
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip returns true if the Accept-Encoding header of the request accepts gzip, i.e. it lists gzip or
+// "*" without a zero quality.
+func acceptsGzip(r *http.Request) bool {
+	for _, coding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		var params = strings.Split(coding, ";")
+		var name = strings.ToLower(strings.TrimSpace(params[0]))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+
+		var accepted = true
+		for _, p := range params[1:] {
+			var kv = strings.SplitN(strings.TrimSpace(p), "=", 2)
+			if len(kv) != 2 || strings.ToLower(kv[0]) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(kv[1], 64); err == nil && q == 0 {
+				accepted = false
+			}
+		}
+		if accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter buffers the beginning of the response, until it can tell whether it is worth
+// compressing. The status code is written once the decision is made.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize int
+	status  int
+	buf     bytes.Buffer
+	decided bool
+	gz      *gzip.Writer
+}
+
+// WriteHeader implements http.ResponseWriter. The status code is written with the first bytes of the body.
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.decided {
+		return
+	}
+	w.status = status
+}
+
+// Write implements http.ResponseWriter.
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if w.decided {
+		return w.write(p)
+	}
+
+	w.buf.Write(p)
+	if w.buf.Len() >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush implements http.Flusher. The response is compressed only if the buffered bytes reached minSize.
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		w.decide(w.buf.Len() >= w.minSize)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close sends the response if it is still buffered, and terminates the gzip stream.
+func (w *gzipResponseWriter) close() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
+
+// decide writes the status code, with the gzip headers if compress is true, and the buffered bytes.
+func (w *gzipResponseWriter) decide(compress bool) error {
+	w.decided = true
+
+	var h = w.Header()
+	if compress && h.Get("Content-Encoding") == "" && bodyAllowed(w.status) {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var _, err = w.write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// write writes p to the response, compressed if the response is compressed.
+func (w *gzipResponseWriter) write(p []byte) (int, error) {
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// bodyAllowed returns true if a response with the status code can have a body.
+func bodyAllowed(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent || status == http.StatusNotModified:
+		return false
+	default:
+		return true
+	}
+}
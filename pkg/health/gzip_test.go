@@ -0,0 +1,101 @@
+package health_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPGzipMW(t *testing.T) {
+	var large = strings.Repeat(`{"name":"ping","status":"OK"}`, 100)
+	var body string
+	var m = MakeHTTPGzipMW(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(body))
+	}))
+
+	// Large response, compressed.
+	{
+		body = large
+		var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/detailed", nil)
+		req.Header.Set("Accept-Encoding", "deflate, gzip;q=0.8")
+		var w = httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.True(t, w.Body.Len() < len(large))
+
+		var r, err = gzip.NewReader(w.Body)
+		assert.Nil(t, err)
+		var data []byte
+		data, err = ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, large, string(data))
+	}
+
+	// Small response, sent as is.
+	{
+		body = `{"status":"OK"}`
+		var req = httptest.NewRequest("GET", "http://cloudtrust.io/health", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		var w = httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Zero(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, body, w.Body.String())
+	}
+
+	// Gzip not accepted.
+	for _, encoding := range []string{"", "deflate", "gzip;q=0", "*;q=0"} {
+		body = large
+		var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/detailed", nil)
+		req.Header.Set("Accept-Encoding", encoding)
+		var w = httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+		assert.Zero(t, w.Header().Get("Content-Encoding"), encoding)
+		assert.Equal(t, large, w.Body.String(), encoding)
+	}
+}
+
+func TestHTTPGzipMWFlush(t *testing.T) {
+	var m = MakeHTTPGzipMW(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{\"subsystem\":\"redis\"}\n"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(strings.Repeat("{\"subsystem\":\"sentry\"}\n", 100)))
+	}))
+
+	// The response is flushed before it reaches the minimum size, it is not compressed.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/detailed", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	var w = httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, w.Flushed)
+	assert.Zero(t, w.Header().Get("Content-Encoding"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "{\"subsystem\":\"redis\"}\n"))
+}
+
+func TestHTTPGzipMWEncoded(t *testing.T) {
+	var body = strings.Repeat("x", 2048)
+	var m = MakeHTTPGzipMW(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		w.Write([]byte(body))
+	}))
+
+	// The response already encoded is not compressed again.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/detailed", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br")
+	var w = httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+	assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, body, w.Body.String())
+}
@@ -0,0 +1,132 @@
+package health
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+// prometheusContentType is the content type of the Prometheus text exposition format.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// LatencyHistogram is a cumulative histogram of the health check durations, in seconds, labelled by
+// subsystem and check. It implements the go-kit Histogram, so it can be given to the instrumenting
+// middlewares, and it is exported in the Prometheus text format. Unlike the histograms of the OpenMetrics
+// route, it accumulates the observations of all the executions, so the latency distribution is visible
+// over time.
+type LatencyHistogram struct {
+	name    string
+	buckets []float64
+	labels  []string
+
+	mutex  *sync.Mutex
+	series map[string]*latencySeries
+}
+
+// latencySeries contains the observations of one set of label values.
+type latencySeries struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewLatencyHistogram returns a latency histogram with the given bucket upper bounds, in seconds. The
+// +Inf bucket is implicit. If no bucket is given, the buckets of the OpenMetrics route are used.
+func NewLatencyHistogram(name string, buckets ...float64) *LatencyHistogram {
+	if len(buckets) == 0 {
+		buckets = openMetricsBuckets
+	}
+	var bs = append([]float64{}, buckets...)
+	sort.Float64s(bs)
+
+	return &LatencyHistogram{
+		name:    name,
+		buckets: bs,
+		mutex:   &sync.Mutex{},
+		series:  map[string]*latencySeries{},
+	}
+}
+
+// With returns a histogram with the additional label values, e.g. With("subsystem", "redis").
+func (h *LatencyHistogram) With(labelValues ...string) metrics.Histogram {
+	return &LatencyHistogram{
+		name:    h.name,
+		buckets: h.buckets,
+		labels:  append(append([]string{}, h.labels...), labelValues...),
+		mutex:   h.mutex,
+		series:  h.series,
+	}
+}
+
+// Observe records the duration v, in seconds.
+func (h *LatencyHistogram) Observe(v float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	var key = strings.Join(h.labels, "\x00")
+	var s, ok = h.series[key]
+	if !ok {
+		s = &latencySeries{
+			labels: h.labels,
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+
+	for i, le := range h.buckets {
+		if v <= le {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+// WritePrometheus writes the histogram in the Prometheus text format.
+func (h *LatencyHistogram) WritePrometheus(w io.Writer) error {
+	h.mutex.Lock()
+	var keys = []string{}
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var bounds = append(append([]float64{}, h.buckets...), math.Inf(1))
+	var b = &bytes.Buffer{}
+	fmt.Fprintf(b, "# HELP %s Duration of the health checks.\n", h.name)
+	fmt.Fprintf(b, "# TYPE %s histogram\n", h.name)
+	for _, k := range keys {
+		var s = h.series[k]
+		for i, le := range bounds {
+			var count = s.count
+			if i < len(s.counts) {
+				count = s.counts[i]
+			}
+			fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, formatLabels(append(append([]string{}, s.labels...), "le", formatFloat(le))), count)
+		}
+		fmt.Fprintf(b, "%s_sum%s %s\n", h.name, formatLabels(s.labels), formatFloat(s.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", h.name, formatLabels(s.labels), s.count)
+	}
+	h.mutex.Unlock()
+
+	var _, err = w.Write(b.Bytes())
+	return err
+}
+
+// MakePrometheusHandler makes a HTTP handler that exports the latency histogram in the Prometheus text
+// format, for the Prometheus scrapes.
+func MakePrometheusHandler(h *LatencyHistogram) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", prometheusContentType)
+		w.WriteHeader(http.StatusOK)
+		h.WritePrometheus(w)
+	}
+}
@@ -0,0 +1,55 @@
+package health_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	var h = NewLatencyHistogram("health_check_latency_seconds", 0.5, 0.1)
+
+	h.With("subsystem", "redis", "check", "ping").Observe(0.05)
+	h.With("subsystem", "redis", "check", "ping").Observe(0.2)
+	h.With("subsystem", "redis").With("check", "ping").Observe(2)
+	h.With("subsystem", "influx", "check", "ping").Observe(0.1)
+
+	var b = &bytes.Buffer{}
+	var err = h.WritePrometheus(b)
+	assert.Nil(t, err)
+	assert.Equal(t, `# HELP health_check_latency_seconds Duration of the health checks.
+# TYPE health_check_latency_seconds histogram
+health_check_latency_seconds_bucket{subsystem="influx",check="ping",le="0.1"} 1
+health_check_latency_seconds_bucket{subsystem="influx",check="ping",le="0.5"} 1
+health_check_latency_seconds_bucket{subsystem="influx",check="ping",le="+Inf"} 1
+health_check_latency_seconds_sum{subsystem="influx",check="ping"} 0.1
+health_check_latency_seconds_count{subsystem="influx",check="ping"} 1
+health_check_latency_seconds_bucket{subsystem="redis",check="ping",le="0.1"} 1
+health_check_latency_seconds_bucket{subsystem="redis",check="ping",le="0.5"} 2
+health_check_latency_seconds_bucket{subsystem="redis",check="ping",le="+Inf"} 3
+health_check_latency_seconds_sum{subsystem="redis",check="ping"} 2.25
+health_check_latency_seconds_count{subsystem="redis",check="ping"} 3
+`, b.String())
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	var h = NewLatencyHistogram("health_check_latency_seconds")
+	h.With("subsystem", "redis", "check", "ping").Observe(0.003)
+
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/metrics", nil)
+	var w = httptest.NewRecorder()
+
+	MakePrometheusHandler(h).ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), "health_check_latency_seconds_bucket{subsystem=\"redis\",check=\"ping\",le=\"0.005\"} 1\n")
+	assert.Contains(t, string(body), "health_check_latency_seconds_bucket{subsystem=\"redis\",check=\"ping\",le=\"10.0\"} 1\n")
+}
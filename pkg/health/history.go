@@ -0,0 +1,122 @@
+package health
+
+import (
+	"fmt"
+	"time"
+)
+
+// historyEntry is the status of a subsystem at the end of an execution of its health checks.
+type historyEntry struct {
+	at     time.Time
+	status Status
+}
+
+// statusHistory is a ring buffer of the last statuses of a subsystem. When it is full, the oldest entry is
+// overwritten.
+type statusHistory struct {
+	entries []historyEntry
+	next    int
+	full    bool
+}
+
+func newStatusHistory(size int) *statusHistory {
+	return &statusHistory{
+		entries: make([]historyEntry, size),
+	}
+}
+
+// add records the status s at time t.
+func (h *statusHistory) add(t time.Time, s Status) {
+	h.entries[h.next] = historyEntry{at: t, status: s}
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// since returns the entries recorded at or after t.
+func (h *statusHistory) since(t time.Time) []historyEntry {
+	var entries = h.entries[:h.next]
+	if h.full {
+		entries = append(append([]historyEntry{}, h.entries[h.next:]...), h.entries[:h.next]...)
+	}
+
+	var res = []historyEntry{}
+	for _, e := range entries {
+		if !e.at.Before(t) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// History keeps the last size statuses of each subsystem, recorded at each execution of the health checks.
+// The history is used to compute the uptime with UptimePercent.
+func History(size int) ComponentOption {
+	return func(c *component) error {
+		if size <= 0 {
+			return fmt.Errorf("history size must be positive, got %d", size)
+		}
+
+		c.history = map[string]*statusHistory{}
+		for _, name := range subsystems {
+			c.history[name] = newStatusHistory(size)
+		}
+		return nil
+	}
+}
+
+// UptimeCountsDegraded counts the Degraded statuses as up in UptimePercent. By default, only OK is up.
+func UptimeCountsDegraded() ComponentOption {
+	return func(c *component) error {
+		c.degradedIsUp = true
+		return nil
+	}
+}
+
+// recordHistory records the status of each subsystem in its history, if the history is kept.
+func (c *component) recordHistory(reports map[string]Reports) {
+	if c.history == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var now = time.Now()
+	for name, r := range reports {
+		c.history[name].add(now, c.status(name, r))
+	}
+}
+
+// UptimePercent returns the percentage of the executions of the health checks, within the window, in which
+// the subsystem was up, e.g. 99.9. The Deactivated statuses are ignored. It returns an error when the
+// history is not kept or when it has no entry within the window.
+func (c *component) UptimePercent(subsystem string, window time.Duration) (float64, error) {
+	if !isSubsystem(subsystem) {
+		return 0, fmt.Errorf("unknown subsystem '%s'", subsystem)
+	}
+	if c.history == nil {
+		return 0, fmt.Errorf("history not kept")
+	}
+
+	c.mutex.Lock()
+	var entries = c.history[subsystem].since(time.Now().Add(-window))
+	c.mutex.Unlock()
+
+	var total, up = 0, 0
+	for _, e := range entries {
+		switch {
+		case e.status == Deactivated:
+			continue
+		case e.status == OK, e.status == Degraded && c.degradedIsUp:
+			up++
+		}
+		total++
+	}
+
+	if total == 0 {
+		return 0, fmt.Errorf("insufficient history for subsystem '%s' within %v", subsystem, window)
+	}
+	return 100 * float64(up) / float64(total), nil
+}
@@ -0,0 +1,90 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUptimePercent(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, mockRedisModule, nil, History(4))
+	assert.Nil(t, err)
+
+	// No history yet.
+	{
+		var _, err = c.UptimePercent("redis", time.Hour)
+		assert.NotNil(t, err)
+	}
+
+	// The ring buffer keeps the last 4 statuses: OK, Degraded, KO, OK.
+	for _, s := range []Status{KO, KO, OK, Degraded, KO, OK} {
+		mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "redis", Status: s}}}).Times(1)
+		c.AllHealthChecks(context.Background())
+	}
+
+	// Only OK is up.
+	{
+		var uptime, err = c.UptimePercent("redis", time.Hour)
+		assert.Nil(t, err)
+		assert.Equal(t, 50.0, uptime)
+	}
+
+	// Deactivated is ignored.
+	{
+		var _, err = c.UptimePercent("influx", time.Hour)
+		assert.NotNil(t, err)
+	}
+
+	// Empty window.
+	{
+		var _, err = c.UptimePercent("redis", 0)
+		assert.NotNil(t, err)
+	}
+
+	// Unknown subsystem.
+	{
+		var _, err = c.UptimePercent("unknown", time.Hour)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestUptimeCountsDegraded(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+
+	var c, err = NewComponent(nil, nil, mockRedisModule, nil, History(10), UptimeCountsDegraded())
+	assert.Nil(t, err)
+
+	for _, s := range []Status{OK, Degraded, KO, Deactivated} {
+		mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "redis", Status: s}}}).Times(1)
+		c.AllHealthChecks(context.Background())
+	}
+
+	var uptime, err2 = c.UptimePercent("redis", time.Hour)
+	assert.Nil(t, err2)
+	assert.InDelta(t, 66.67, uptime, 0.01)
+}
+
+func TestUptimePercentNoHistory(t *testing.T) {
+	var c, err = NewComponent(nil, nil, nil, nil)
+	assert.Nil(t, err)
+
+	var _, err2 = c.UptimePercent("redis", time.Hour)
+	assert.NotNil(t, err2)
+}
+
+func TestHistoryInvalidSize(t *testing.T) {
+	var c, err = NewComponent(nil, nil, nil, nil, History(0))
+	assert.NotNil(t, err)
+	assert.Nil(t, c)
+}
@@ -3,7 +3,12 @@ package health
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	http_transport "github.com/go-kit/kit/transport/http"
@@ -16,10 +21,47 @@ type Reply struct {
 
 // Check is the result of a single healthcheck
 type Check struct {
-	Name     string `json:"name"`
-	Duration string `json:"duration"`
-	Status   string `json:"status"`
-	Error    string `json:"error,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Duration    string `json:"duration"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Attempts    int    `json:"attempts,omitempty"`
+	Cached      bool   `json:"cached,omitempty"`
+	Timeout     string `json:"timeout,omitempty"`
+	RunbookURL  string `json:"runbook url,omitempty"`
+}
+
+// DetailedReply contains the overall health status, the detailed health of each subsystem, and the errors
+// of all the tests. Hostname and InstanceID identify the instance that answered, see Instance.
+// TotalDuration is the wall-clock duration of the health checks run.
+type DetailedReply struct {
+	Status        string                    `json:"status"`
+	Hostname      string                    `json:"hostname,omitempty"`
+	InstanceID    string                    `json:"instance id,omitempty"`
+	TotalDuration string                    `json:"total duration"`
+	Subsystems    map[string]SubsystemReply `json:"subsystems"`
+	Errors        []string                  `json:"errors,omitempty"`
+}
+
+// ReadinessReply contains the overall health status and the errors of all the tests.
+type ReadinessReply struct {
+	Status string   `json:"status"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// SubsystemReply contains the health of a subsystem and the result of its healthchecks. Up is false when
+// the healthchecks could not run, ScrapeError then tells why. Dependency is "hard" or "soft".
+// SlowThreshold is the effective slow threshold of the healthchecks, it is omitted when there is none.
+type SubsystemReply struct {
+	Status              string  `json:"status"`
+	Up                  bool    `json:"up"`
+	ScrapeError         string  `json:"scrape error,omitempty"`
+	Dependency          string  `json:"dependency,omitempty"`
+	SlowThreshold       string  `json:"slow threshold,omitempty"`
+	ConsecutiveFailures int     `json:"consecutive failures"`
+	Reports             []Check `json:"health checks"`
 }
 
 // MakeInfluxHealthCheckHandler makes a HTTP handler for the Influx HealthCheck endpoint.
@@ -58,15 +100,413 @@ func MakeSentryHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
 	)
 }
 
-// MakeAllHealthChecksHandler makes a HTTP handler for all health checks.
-func MakeAllHealthChecksHandler(e endpoint.Endpoint) *http_transport.Server {
+// MakeSMTPHealthCheckHandler makes a HTTP handler for the SMTP HealthCheck endpoint.
+func MakeSMTPHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// HandlerOption is an option of the all health checks HTTP handlers.
+type HandlerOption func(*handlerConfig)
+
+type handlerConfig struct {
+	omitDeactivated bool
+	hostname        string
+	instanceID      string
+	formatters      []namedFormatter
+}
+
+// OmitDeactivated omits the Deactivated subsystems from the JSON reply. They are still taken
+// into account in the overall status. By default, they are included.
+func OmitDeactivated(omit bool) HandlerOption {
+	return func(c *handlerConfig) {
+		c.omitDeactivated = omit
+	}
+}
+
+// Instance identifies the instance that answers, to tell which one is misbehaving behind a load balancer.
+// The hostname and the instance ID are added to the detailed JSON reply, and to the headers
+// "X-Health-Hostname" and "X-Health-Instance-ID" of the all health checks replies, whose JSON is a map of
+// the subsystems. Empty values are omitted.
+func Instance(hostname, instanceID string) HandlerOption {
+	return func(c *handlerConfig) {
+		c.hostname = hostname
+		c.instanceID = instanceID
+	}
+}
+
+// MakeDockerHealthCheckHandler makes a HTTP handler for the Docker HealthCheck endpoint.
+func MakeDockerHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeLogSinkHealthCheckHandler makes a HTTP handler for the log sink HealthCheck endpoint.
+func MakeLogSinkHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeE2EHealthCheckHandler makes a HTTP handler for the end-to-end HealthCheck endpoint.
+func MakeE2EHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeGRPCReflectionHealthCheckHandler makes a HTTP handler for the gRPC reflection HealthCheck endpoint.
+func MakeGRPCReflectionHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeEgressHealthCheckHandler makes a HTTP handler for the egress HealthCheck endpoint.
+func MakeEgressHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeFDHealthCheckHandler makes a HTTP handler for the file descriptors HealthCheck endpoint.
+func MakeFDHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeIDGenHealthCheckHandler makes a HTTP handler for the ID generator HealthCheck endpoint.
+func MakeIDGenHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeFeatureFlagHealthCheckHandler makes a HTTP handler for the feature flag HealthCheck endpoint.
+func MakeFeatureFlagHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeReplicaHealthCheckHandler makes a HTTP handler for the SQL replica HealthCheck endpoint.
+func MakeReplicaHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeCommandHealthCheckHandler makes a HTTP handler for the external command HealthCheck endpoint.
+func MakeCommandHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeSelfHealthCheckHandler makes a HTTP handler for the self HTTP listener HealthCheck endpoint.
+func MakeSelfHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeAuditLogHealthCheckHandler makes a HTTP handler for the audit log HealthCheck endpoint.
+func MakeAuditLogHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeCompositeHealthCheckHandler makes a HTTP handler for the composite HealthCheck endpoint.
+func MakeCompositeHealthCheckHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeHealthCheckReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeAllHealthChecksHandler makes a HTTP handler for all health checks. The health checks are executed with
+// e, which must be the AllHealthChecksDetailed endpoint, the reply is the status of each subsystem and the
+// status code reflects the overall status, like the HEAD requests, see MakeHTTPHeadMW.
+func MakeAllHealthChecksHandler(e endpoint.Endpoint, options ...HandlerOption) *http_transport.Server {
+	var config = newHandlerConfig(options...)
+
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		makeEncodeAllHealthChecksReply(config),
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeOptionsHandler makes a HTTP handler for the Options endpoint. It exposes the effective configuration
+// of the health component, for debugging.
+func MakeOptionsHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeOptionsReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// newHandlerConfig applies the options to the default handler configuration.
+func newHandlerConfig(options ...HandlerOption) handlerConfig {
+	var config = handlerConfig{}
+	for _, o := range options {
+		o(&config)
+	}
+	return config
+}
+
+// MakeReadinessHandler makes a HTTP handler for the readiness probes. The health checks are executed with e,
+// which must be the AllHealthChecksDetailed endpoint, and the status code reflects the overall status: 503
+// when it is KO, e.g. during a shutdown, 200 otherwise.
+func MakeReadinessHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeReadinessReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// MakeLivenessHandler makes a HTTP handler for the liveness probes. It answers 200 with the status "OK" as
+// long as the service is able to serve, the health checks are not executed: a KO dependency or a shutdown
+// in progress make the service unready, not dead, and restarting it would not help.
+func MakeLivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		var data, _ = json.MarshalIndent(map[string]string{"status": OK.String()}, "", "  ")
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	}
+}
+
+// MakeHTTPTimeoutMW makes a middleware that bounds the health checks with a deadline. The timeout
+// can be overridden per request with the query parameter "timeout" or the header "X-Health-Timeout",
+// e.g. ?timeout=5s. It is clamped to maxTimeout, and invalid values fall back to defaultTimeout.
+func MakeHTTPTimeoutMW(defaultTimeout, maxTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var timeout = fetchHTTPTimeout(r, defaultTimeout, maxTimeout)
+
+			var ctx, cancel = context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// MakeHTTPHeadMW makes a middleware that answers the HEAD requests for the load balancers that probe
+// without reading the body. The health checks are executed with e, which must be the AllHealthChecksDetailed
+// endpoint, and the status code is the one of the GET requests, see reportStatusCode. The other requests are
+// passed to next.
+func MakeHTTPHeadMW(e endpoint.Endpoint) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var rep, err = e(r.Context(), nil)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(reportStatusCode(rep.(DetailedReport)))
+		})
+	}
+}
+
+// reportStatusCode returns the status code of the health checks routes for the detailed report: 503 when
+// the overall status is KO, 200 otherwise.
+func reportStatusCode(report DetailedReport) int {
+	if report.Status == KO {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+const ndjsonContentType = "application/x-ndjson"
+
+// NDJSONCheck is a line of the NDJSON stream, the result of a single healthcheck of a subsystem.
+type NDJSONCheck struct {
+	Subsystem string `json:"subsystem"`
+	Check
+}
+
+// MakeHTTPNDJSONMW makes a middleware that streams the health checks as NDJSON, one JSON object per check,
+// for the requests that accept application/x-ndjson. The subsystems are executed in one run with e, which
+// must be the AllHealthChecksDetailed endpoint, and their checks are flushed as soon as they complete, see
+// WithSubsystemReportFunc, so the clients can display the progress of a slow aggregate. The last line is
+// the overall status and the errors of the detailed report. The other requests are passed to next.
+func MakeHTTPNDJSONMW(e endpoint.Endpoint, options ...HandlerOption) func(http.Handler) http.Handler {
+	var config = newHandlerConfig(options...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !acceptsNDJSON(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var flusher, ok = w.(http.Flusher)
+			if !ok {
+				healthCheckErrorHandler(r.Context(), fmt.Errorf("streaming not supported"), w)
+				return
+			}
+
+			setInstanceHeaders(w, config)
+			w.Header().Set("Content-Type", ndjsonContentType)
+			w.WriteHeader(http.StatusOK)
+
+			var enc = json.NewEncoder(w)
+			var mutex sync.Mutex
+			var ctx = WithSubsystemReportFunc(r.Context(), func(name string, s SubsystemReport) {
+				if config.omitDeactivated && s.Status == Deactivated {
+					return
+				}
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				for _, c := range toChecks(s.Reports, s.RunbookURLs) {
+					enc.Encode(NDJSONCheck{Subsystem: name, Check: c})
+				}
+				flusher.Flush()
+			})
+
+			var rep, err = e(ctx, nil)
+			if err != nil {
+				return
+			}
+			var report = rep.(DetailedReport)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			enc.Encode(ReadinessReply{Status: report.Status.String(), Errors: report.Errors})
+			flusher.Flush()
+		})
+	}
+}
+
+// acceptsNDJSON returns true if a media type of the Accept header is application/x-ndjson.
+func acceptsNDJSON(r *http.Request) bool {
+	for _, mediaRange := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaTypeOf(mediaRange) == ndjsonContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchHTTPTimeout reads the timeout from the query parameter "timeout" or the header "X-Health-Timeout".
+func fetchHTTPTimeout(r *http.Request, defaultTimeout, maxTimeout time.Duration) time.Duration {
+	var value = r.URL.Query().Get("timeout")
+	if value == "" {
+		value = r.Header.Get("X-Health-Timeout")
+	}
+
+	var timeout, err = time.ParseDuration(value)
+	switch {
+	case err != nil || timeout <= 0:
+		return defaultTimeout
+	case timeout > maxTimeout:
+		return maxTimeout
+	default:
+		return timeout
+	}
+}
+
+// MakeAllHealthChecksDetailedHandler makes a HTTP handler for all health checks with a detailed report.
+// The report is formatted as JSON by default. Another format is selected with the query parameter
+// "format", e.g. ?format=openmetrics, or with the Accept header, see WithFormatter.
+func MakeAllHealthChecksDetailedHandler(e endpoint.Endpoint, options ...HandlerOption) *http_transport.Server {
+	var config = newHandlerConfig(options...)
+
 	return http_transport.NewServer(e,
 		decodeHealthCheckRequest,
-		encodeAllHealthChecksReply,
+		makeEncodeAllHealthChecksDetailedReply(config),
+		http_transport.ServerBefore(fetchHTTPFormat),
 		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
 	)
 }
 
+// MakeHealthStreamHandler makes a HTTP handler that streams the detailed health reports published by the
+// poller as Server-Sent Events. A comment is sent every keepAlive, so the proxies do not close the idle
+// connections. The subscription is cancelled when the client disconnects.
+func MakeHealthStreamHandler(p *Poller, keepAlive time.Duration, options ...HandlerOption) http.HandlerFunc {
+	var config = newHandlerConfig(options...)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var flusher, ok = w.(http.Flusher)
+		if !ok {
+			healthCheckErrorHandler(r.Context(), fmt.Errorf("streaming not supported"), w)
+			return
+		}
+
+		var reports, unsubscribe = p.Subscribe()
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		var tic = time.NewTicker(keepAlive)
+		defer tic.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case report := <-reports:
+				var data, err = json.Marshal(toDetailedReply(report, config))
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: health\ndata: %s\n\n", data)
+			case <-tic.C:
+				fmt.Fprint(w, ": keepalive\n\n")
+			}
+			flusher.Flush()
+		}
+	}
+}
+
 // decodeHealthCheckRequest decodes the health check request.
 func decodeHealthCheckRequest(_ context.Context, r *http.Request) (rep interface{}, err error) {
 	return nil, nil
@@ -77,14 +517,8 @@ func encodeHealthCheckReply(_ context.Context, w http.ResponseWriter, rep interf
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
 	var reports = rep.(Reports)
-	var reply = Reply{}
-	for _, r := range reports.Reports {
-		reply.Reports = append(reply.Reports, Check{
-			Name:     r.Name,
-			Duration: r.Duration,
-			Status:   r.Status.String(),
-			Error:    r.Error,
-		})
+	var reply = Reply{
+		Reports: toChecks(reports.Reports, reports.RunbookURLs),
 	}
 
 	var data, err = json.MarshalIndent(reply, "", "  ")
@@ -99,13 +533,34 @@ func encodeHealthCheckReply(_ context.Context, w http.ResponseWriter, rep interf
 	return nil
 }
 
-// encodeAllHealthChecksReply encodes the health checks reply.
-func encodeAllHealthChecksReply(_ context.Context, w http.ResponseWriter, rep interface{}) error {
+// encodeReadinessReply encodes the readiness reply, with the status code 503 when the overall status is KO.
+func encodeReadinessReply(_ context.Context, w http.ResponseWriter, rep interface{}) error {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
-	var reply = rep.(map[string]string)
+	var report = rep.(DetailedReport)
+	var reply = ReadinessReply{
+		Status: report.Status.String(),
+		Errors: report.Errors,
+	}
+
 	var data, err = json.MarshalIndent(reply, "", "  ")
 
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		w.WriteHeader(reportStatusCode(report))
+		w.Write(data)
+	}
+
+	return nil
+}
+
+// encodeOptionsReply encodes the effective configuration of the component.
+func encodeOptionsReply(_ context.Context, w http.ResponseWriter, rep interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	var data, err = json.MarshalIndent(rep.(Options), "", "  ")
+
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 	} else {
@@ -116,6 +571,141 @@ func encodeAllHealthChecksReply(_ context.Context, w http.ResponseWriter, rep in
 	return nil
 }
 
+// makeEncodeAllHealthChecksReply makes the encoder of the health checks reply, the status of each subsystem
+// of the detailed report.
+func makeEncodeAllHealthChecksReply(config handlerConfig) http_transport.EncodeResponseFunc {
+	return func(_ context.Context, w http.ResponseWriter, rep interface{}) error {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		setInstanceHeaders(w, config)
+
+		var report = rep.(DetailedReport)
+		var reply = map[string]string{}
+		for name, s := range report.Subsystems {
+			if config.omitDeactivated && s.Status == Deactivated {
+				continue
+			}
+			reply[name] = s.Status.String()
+		}
+
+		var data, err = json.MarshalIndent(reply, "", "  ")
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		} else {
+			w.WriteHeader(reportStatusCode(report))
+			w.Write(data)
+		}
+
+		return nil
+	}
+}
+
+// makeEncodeAllHealthChecksDetailedReply makes the encoder of the detailed health checks reply, with the
+// formatter selected by the request and the status code of reportStatusCode. An unknown format is answered
+// with 406.
+func makeEncodeAllHealthChecksDetailedReply(config handlerConfig) http_transport.EncodeResponseFunc {
+	var formatters = formattersOf(config)
+
+	return func(ctx context.Context, w http.ResponseWriter, rep interface{}) error {
+		setInstanceHeaders(w, config)
+
+		var f, err = selectFormatter(ctx, formatters)
+		if err != nil {
+			w.Header().Set("Content-Type", jsonContentType)
+			var reply, _ = json.MarshalIndent(map[string]string{"error": err.Error()}, "", "  ")
+			w.WriteHeader(http.StatusNotAcceptable)
+			w.Write(reply)
+			return nil
+		}
+
+		var data, contentType, errFormat = f.Format(ctx, rep.(DetailedReport))
+
+		if errFormat != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		} else {
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(reportStatusCode(rep.(DetailedReport)))
+			w.Write(data)
+		}
+
+		return nil
+	}
+}
+
+// EncodeDetailedReport writes the detailed health report to w, as the JSON of the detailed health checks
+// route, e.g. for a one-shot health check from the command line. The handler options apply.
+func EncodeDetailedReport(w io.Writer, report DetailedReport, options ...HandlerOption) error {
+	var data, err = json.MarshalIndent(toDetailedReply(report, newHandlerConfig(options...)), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// toDetailedReply converts the detailed health report to its JSON representation.
+func toDetailedReply(report DetailedReport, config handlerConfig) DetailedReply {
+	var reply = DetailedReply{
+		Status:        report.Status.String(),
+		Hostname:      config.hostname,
+		InstanceID:    config.instanceID,
+		TotalDuration: report.TotalDuration.String(),
+		Subsystems:    map[string]SubsystemReply{},
+		Errors:        report.Errors,
+	}
+	for name, s := range report.Subsystems {
+		if config.omitDeactivated && s.Status == Deactivated {
+			continue
+		}
+		reply.Subsystems[name] = SubsystemReply{
+			Status:              s.Status.String(),
+			Up:                  s.ScrapeError == "",
+			ScrapeError:         s.ScrapeError,
+			Dependency:          s.Dependency,
+			SlowThreshold:       s.SlowThreshold,
+			ConsecutiveFailures: s.ConsecutiveFailures,
+			Reports:             toChecks(s.Reports, s.RunbookURLs),
+		}
+	}
+	return reply
+}
+
+// setInstanceHeaders sets the headers that identify the instance, if configured.
+func setInstanceHeaders(w http.ResponseWriter, config handlerConfig) {
+	if config.hostname != "" {
+		w.Header().Set("X-Health-Hostname", config.hostname)
+	}
+	if config.instanceID != "" {
+		w.Header().Set("X-Health-Instance-ID", config.instanceID)
+	}
+}
+
+// toChecks converts the health test reports to their JSON representation, with their runbooks.
+func toChecks(reports []Report, runbooks map[string]string) []Check {
+	var checks = []Check{}
+	for _, r := range reports {
+		var category string
+		if r.Category != Uncategorized {
+			category = r.Category.String()
+		}
+
+		checks = append(checks, Check{
+			Name:        r.Name,
+			Description: r.Description,
+			Duration:    r.Duration,
+			Status:      r.Status.String(),
+			Error:       r.Error,
+			Category:    category,
+			Attempts:    r.Attempts,
+			Cached:      r.Cached,
+			Timeout:     r.Timeout,
+			RunbookURL:  runbooks[r.Name],
+		})
+	}
+	return checks
+}
+
 // healthCheckErrorHandler encodes the health check reply when there is an error.
 func healthCheckErrorHandler(ctx context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -1,12 +1,14 @@
 package health_test
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,7 +26,7 @@ func TestInfluxHealthCheckHandler(t *testing.T) {
 	var h = MakeInfluxHealthCheckHandler(MakeInfluxHealthCheckEndpoint(mockComponent))
 
 	// Health success.
-	mockComponent.EXPECT().InfluxHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "influx", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+	mockComponent.EXPECT().InfluxHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "influx", Description: "Pings influx.", Duration: (1 * time.Second).String(), Status: OK, Attempts: 2, Cached: true}}}).Times(1)
 
 	// HTTP request.
 	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/influx", nil)
@@ -45,9 +47,12 @@ func TestInfluxHealthCheckHandler(t *testing.T) {
 	{
 		var m = r.(map[string]interface{})
 		assert.Equal(t, "influx", m["name"])
+		assert.Equal(t, "Pings influx.", m["description"])
 		assert.Equal(t, (1 * time.Second).String(), m["duration"])
 		assert.Equal(t, "OK", m["status"])
 		assert.Zero(t, m["error"])
+		assert.Equal(t, 2.0, m["attempts"])
+		assert.Equal(t, true, m["cached"])
 	}
 }
 func TestJaegerHealthCheckHandler(t *testing.T) {
@@ -93,7 +98,10 @@ func TestRedisHealthCheckHandler(t *testing.T) {
 	var h = MakeRedisHealthCheckHandler(MakeRedisHealthCheckEndpoint(mockComponent))
 
 	// Health success.
-	mockComponent.EXPECT().RedisHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "redis", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+	mockComponent.EXPECT().RedisHealthChecks(context.Background()).Return(Reports{
+		Reports:     []Report{{Name: "redis", Duration: (1 * time.Second).String(), Status: OK}},
+		RunbookURLs: map[string]string{"redis": "https://runbooks.example.com/redis"},
+	}).Times(1)
 
 	// HTTP request.
 	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/redis", nil)
@@ -117,6 +125,7 @@ func TestRedisHealthCheckHandler(t *testing.T) {
 		assert.Equal(t, (1 * time.Second).String(), m["duration"])
 		assert.Equal(t, "OK", m["status"])
 		assert.Zero(t, m["error"])
+		assert.Equal(t, "https://runbooks.example.com/redis", m["runbook url"])
 	}
 }
 
@@ -160,10 +169,10 @@ func TestHealthChecksHandler(t *testing.T) {
 	defer mockCtrl.Finish()
 	var mockComponent = mock.NewComponent(mockCtrl)
 
-	var h = MakeAllHealthChecksHandler(MakeAllHealthChecksEndpoint(mockComponent))
+	var h = MakeAllHealthChecksHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent))
 
 	// Health success.
-	mockComponent.EXPECT().AllHealthChecks(context.Background()).Return(map[string]string{"influx": OK.String(), "jaeger": OK.String(), "redis": OK.String(), "sentry": OK.String()}).Times(1)
+	mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(detailedReportOf(OK, map[string]Status{"influx": OK, "jaeger": OK, "redis": OK, "sentry": OK, "smtp": Deactivated})).Times(1)
 
 	// HTTP request.
 	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health", nil)
@@ -183,16 +192,17 @@ func TestHealthChecksHandler(t *testing.T) {
 	assert.Equal(t, "OK", m["jaeger"])
 	assert.Equal(t, "OK", m["redis"])
 	assert.Equal(t, "OK", m["sentry"])
+	assert.Equal(t, "Deactivated", m["smtp"])
 }
 func TestHealthChecksHandlerFail(t *testing.T) {
 	var mockCtrl = gomock.NewController(t)
 	defer mockCtrl.Finish()
 	var mockComponent = mock.NewComponent(mockCtrl)
 
-	var h = MakeAllHealthChecksHandler(MakeAllHealthChecksEndpoint(mockComponent))
+	var h = MakeAllHealthChecksHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent))
 
 	// Health success.
-	mockComponent.EXPECT().AllHealthChecks(context.Background()).Return(map[string]string{"influx": KO.String(), "jaeger": Deactivated.String(), "redis": Degraded.String(), "sentry": KO.String()}).Times(1)
+	mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(detailedReportOf(KO, map[string]Status{"influx": KO, "jaeger": Deactivated, "redis": Degraded, "sentry": KO})).Times(1)
 
 	// HTTP request.
 	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health", nil)
@@ -203,7 +213,7 @@ func TestHealthChecksHandlerFail(t *testing.T) {
 	var resp = w.Result()
 	var body, err = ioutil.ReadAll(resp.Body)
 	assert.Nil(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
 	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
 
 	var m = map[string]string{}
@@ -240,3 +250,993 @@ func TestHTTPErrorHandler(t *testing.T) {
 		assert.Equal(t, "fail", m["error"])
 	}
 }
+
+func TestHTTPHeadMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var nextCalled bool
+	var m = MakeHTTPHeadMW(MakeAllHealthChecksDetailedEndpoint(mockComponent))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+	}))
+
+	var tsts = []struct {
+		status Status
+		code   int
+	}{
+		{OK, http.StatusOK},
+		{Degraded, http.StatusOK},
+		{Deactivated, http.StatusOK},
+		{KO, http.StatusServiceUnavailable},
+	}
+
+	// HEAD, the checks are executed and there is no body.
+	for _, tst := range tsts {
+		mockComponent.EXPECT().AllHealthChecksDetailed(gomock.Any()).Return(DetailedReport{Status: tst.status}).Times(1)
+		var w = httptest.NewRecorder()
+		m.ServeHTTP(w, httptest.NewRequest("HEAD", "http://cloudtrust.io/health", nil))
+		assert.Equal(t, tst.code, w.Code, tst.status.String())
+		assert.Zero(t, w.Body.Len())
+		assert.False(t, nextCalled)
+	}
+
+	// GET is passed to the next handler.
+	m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://cloudtrust.io/health", nil))
+	assert.True(t, nextCalled)
+}
+
+func TestHTTPHeadMWMatchesGet(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var e = MakeAllHealthChecksDetailedEndpoint(mockComponent)
+	var handlers = map[string]http.Handler{
+		"http://cloudtrust.io/health":          MakeHTTPHeadMW(e)(MakeAllHealthChecksHandler(e)),
+		"http://cloudtrust.io/health/detailed": MakeHTTPHeadMW(e)(MakeAllHealthChecksDetailedHandler(e)),
+	}
+
+	for _, s := range []Status{OK, Degraded, KO} {
+		mockComponent.EXPECT().AllHealthChecksDetailed(gomock.Any()).Return(detailedReportOf(s, map[string]Status{"redis": s})).Times(4)
+
+		for url, h := range handlers {
+			var get = httptest.NewRecorder()
+			h.ServeHTTP(get, httptest.NewRequest("GET", url, nil))
+			var head = httptest.NewRecorder()
+			h.ServeHTTP(head, httptest.NewRequest("HEAD", url, nil))
+
+			assert.Equal(t, get.Code, head.Code, url)
+			assert.NotZero(t, get.Body.Len(), url)
+			assert.Zero(t, head.Body.Len(), url)
+		}
+	}
+}
+
+// detailedReportOf returns the detailed report with the overall status and the statuses of the subsystems.
+func detailedReportOf(status Status, subsystems map[string]Status) DetailedReport {
+	var report = DetailedReport{Status: status, Subsystems: map[string]SubsystemReport{}}
+	for name, s := range subsystems {
+		report.Subsystems[name] = SubsystemReport{Status: s}
+	}
+	return report
+}
+
+func TestHTTPNDJSONMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var c, err = NewComponent(mockInfluxModule, mockJaegerModule, mockRedisModule, mockSentryModule,
+		Dependencies(map[string][]string{"jaeger": {"redis"}}))
+	assert.Nil(t, err)
+
+	var nextCalled bool
+	var m = MakeHTTPNDJSONMW(MakeAllHealthChecksDetailedEndpoint(c), OmitDeactivated(true))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+	}))
+
+	// All the subsystems are checked in one run.
+	mockInfluxModule.EXPECT().HealthChecks(gomock.Any()).Return([]InfluxReport{{Report: Report{Name: "ping", Status: OK}}, {Report: Report{Name: "write", Status: OK}}}).Times(1)
+	mockJaegerModule.EXPECT().HealthChecks(gomock.Any()).Return([]JaegerReport{{Name: "ping", Status: KO, Error: "timeout"}}).Times(1)
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: KO, Error: "could not ping"}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: Deactivated}}).Times(1)
+
+	var r = httptest.NewRequest("GET", "http://cloudtrust.io/health/detailed", nil)
+	r.Header.Set("Accept", "application/x-ndjson")
+	var w = httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+	assert.False(t, nextCalled)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.True(t, w.Flushed)
+
+	var lines = strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Equal(t, 5, len(lines))
+	var check NDJSONCheck
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &check))
+	assert.Equal(t, "influx", check.Subsystem)
+	assert.Equal(t, "ping", check.Name)
+
+	// Jaeger waits for redis, and its cascading failure is suppressed as in the detailed report.
+	assert.Nil(t, json.Unmarshal([]byte(lines[2]), &check))
+	assert.Equal(t, "jaeger", check.Subsystem)
+	assert.Equal(t, "Degraded", check.Status)
+	assert.Equal(t, "suppressed due to dependency redis: timeout", check.Error)
+	assert.Nil(t, json.Unmarshal([]byte(lines[3]), &check))
+	assert.Equal(t, "redis", check.Subsystem)
+	assert.Equal(t, "KO", check.Status)
+	assert.Equal(t, "could not ping", check.Error)
+
+	// The summary is the overall status of the run.
+	var summary ReadinessReply
+	assert.Nil(t, json.Unmarshal([]byte(lines[4]), &summary))
+	assert.Equal(t, "KO", summary.Status)
+	assert.Contains(t, summary.Errors, "redis/ping: could not ping")
+
+	// The other requests are passed to the next handler.
+	m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://cloudtrust.io/health/detailed", nil))
+	assert.True(t, nextCalled)
+}
+
+func TestHTTPTimeoutMW(t *testing.T) {
+	var deadline time.Time
+	var m = MakeHTTPTimeoutMW(5*time.Second, 30*time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ok bool
+		deadline, ok = r.Context().Deadline()
+		assert.True(t, ok)
+	}))
+
+	var tsts = []struct {
+		url     string
+		header  string
+		timeout time.Duration
+	}{
+		{"http://cloudtrust.io/health", "", 5 * time.Second},
+		{"http://cloudtrust.io/health?timeout=2s", "", 2 * time.Second},
+		{"http://cloudtrust.io/health", "3s", 3 * time.Second},
+		{"http://cloudtrust.io/health?timeout=1h", "", 30 * time.Second},
+		{"http://cloudtrust.io/health?timeout=invalid", "", 5 * time.Second},
+		{"http://cloudtrust.io/health?timeout=-1s", "", 5 * time.Second},
+	}
+
+	for _, tst := range tsts {
+		var req = httptest.NewRequest("GET", tst.url, nil)
+		if tst.header != "" {
+			req.Header.Set("X-Health-Timeout", tst.header)
+		}
+		var begin = time.Now()
+		m.ServeHTTP(httptest.NewRecorder(), req)
+		assert.InDelta(t, tst.timeout.Seconds(), deadline.Sub(begin).Seconds(), 0.5, tst.url)
+	}
+}
+
+func TestSMTPHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeSMTPHealthCheckHandler(MakeSMTPHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().SMTPHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "smtp", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/smtp", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "smtp", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestHealthChecksDetailedHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeAllHealthChecksDetailedHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent))
+
+	var report = DetailedReport{
+		Status: KO,
+		Subsystems: map[string]SubsystemReport{
+			"influx": {Status: OK, Reports: []Report{{Name: "ping", Duration: "1ms", Status: OK, Attempts: 1}}},
+			"redis":  {Status: KO, ConsecutiveFailures: 3, Reports: []Report{{Name: "ping", Duration: "1s", Status: KO, Error: "fail", Category: Network, Attempts: 1}}},
+			"smtp":   {Status: KO, Reports: []Report{{Name: "not configured", Duration: "N/A", Status: KO, Error: "health module not configured"}}, ScrapeError: "not configured"},
+		},
+		Errors: []string{"redis/ping: fail"},
+	}
+	mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(report).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/detailed", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var r = DetailedReply{}
+	json.Unmarshal(body, &r)
+	assert.Equal(t, "KO", r.Status)
+	assert.Equal(t, "OK", r.Subsystems["influx"].Status)
+	assert.Equal(t, 0, r.Subsystems["influx"].ConsecutiveFailures)
+	assert.Equal(t, "KO", r.Subsystems["redis"].Status)
+	assert.Equal(t, 3, r.Subsystems["redis"].ConsecutiveFailures)
+	assert.Equal(t, "ping", r.Subsystems["redis"].Reports[0].Name)
+	assert.Equal(t, "fail", r.Subsystems["redis"].Reports[0].Error)
+	assert.Equal(t, "network", r.Subsystems["redis"].Reports[0].Category)
+	assert.Zero(t, r.Subsystems["influx"].Reports[0].Category)
+	assert.Equal(t, []string{"redis/ping: fail"}, r.Errors)
+
+	// Redis is down, the smtp checks could not run.
+	assert.True(t, r.Subsystems["redis"].Up)
+	assert.Zero(t, r.Subsystems["redis"].ScrapeError)
+	assert.False(t, r.Subsystems["smtp"].Up)
+	assert.Equal(t, "not configured", r.Subsystems["smtp"].ScrapeError)
+}
+
+func TestHealthChecksDetailedHandlerOrder(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeAllHealthChecksDetailedHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent))
+
+	var report = DetailedReport{
+		Status: OK,
+		Subsystems: map[string]SubsystemReport{
+			"sentry": {Status: OK, Reports: []Report{{Name: "ping", Status: OK}, {Name: "quota", Status: OK}}},
+			"redis":  {Status: OK, Reports: []Report{{Name: "ping", Status: OK}}},
+			"jaeger": {Status: OK, Reports: []Report{{Name: "ping", Status: OK}}},
+			"influx": {Status: OK, Reports: []Report{{Name: "write", Status: OK}, {Name: "ping", Status: OK}}},
+		},
+	}
+	mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(report).Times(10)
+
+	var bodies = []string{}
+	for i := 0; i < 10; i++ {
+		var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/detailed", nil)
+		var w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		var body, err = ioutil.ReadAll(w.Result().Body)
+		assert.Nil(t, err)
+		bodies = append(bodies, string(body))
+	}
+
+	// The output is stable.
+	for _, body := range bodies {
+		assert.Equal(t, bodies[0], body)
+	}
+
+	// The subsystems are sorted by name, the tests are in execution order.
+	var body = bodies[0]
+	var last = -1
+	for _, s := range []string{`"influx"`, `"write"`, `"ping"`, `"jaeger"`, `"redis"`, `"sentry"`, `"quota"`} {
+		var idx = strings.Index(body[last+1:], s)
+		assert.NotEqual(t, -1, idx, s)
+		last += idx + 1
+	}
+}
+
+func TestHealthChecksHandlerOmitDeactivated(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeAllHealthChecksHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent), OmitDeactivated(true))
+	var hDetailed = MakeAllHealthChecksDetailedHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent), OmitDeactivated(true))
+
+	// All health checks.
+	{
+		mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(detailedReportOf(KO, map[string]Status{"influx": OK, "redis": KO, "smtp": Deactivated})).Times(1)
+
+		var req = httptest.NewRequest("GET", "http://cloudtrust.io/health", nil)
+		var w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		var body, err = ioutil.ReadAll(w.Result().Body)
+		assert.Nil(t, err)
+
+		var m = map[string]string{}
+		json.Unmarshal(body, &m)
+		assert.Equal(t, map[string]string{"influx": "OK", "redis": "KO"}, m)
+	}
+
+	// Detailed health checks.
+	{
+		var report = DetailedReport{
+			Status: Degraded,
+			Subsystems: map[string]SubsystemReport{
+				"influx": {Status: Degraded},
+				"smtp":   {Status: Deactivated},
+			},
+		}
+		mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(report).Times(1)
+
+		var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/detailed", nil)
+		var w = httptest.NewRecorder()
+		hDetailed.ServeHTTP(w, req)
+		var body, err = ioutil.ReadAll(w.Result().Body)
+		assert.Nil(t, err)
+
+		var r = DetailedReply{}
+		json.Unmarshal(body, &r)
+		assert.Equal(t, "Degraded", r.Status)
+		assert.Len(t, r.Subsystems, 1)
+		assert.Equal(t, "Degraded", r.Subsystems["influx"].Status)
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeReadinessHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent))
+
+	// Ready.
+	{
+		mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(DetailedReport{Status: Degraded}).Times(1)
+
+		var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/ready", nil)
+		var w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	}
+
+	// Not ready.
+	{
+		mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(DetailedReport{Status: KO, Errors: []string{"shutting down"}}).Times(1)
+
+		var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/ready", nil)
+		var w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		var body, err = ioutil.ReadAll(w.Result().Body)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Result().StatusCode)
+
+		var r = ReadinessReply{}
+		json.Unmarshal(body, &r)
+		assert.Equal(t, ReadinessReply{Status: "KO", Errors: []string{"shutting down"}}, r)
+	}
+}
+
+func TestLivenessHandler(t *testing.T) {
+	var h = MakeLivenessHandler()
+
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/live", nil)
+	var w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	var body, err = ioutil.ReadAll(w.Result().Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	var m = map[string]string{}
+	json.Unmarshal(body, &m)
+	assert.Equal(t, map[string]string{"status": "OK"}, m)
+}
+
+func TestHealthChecksHandlerInstance(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeAllHealthChecksHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent), Instance("flaki-7d9f", "eu-west-1a"))
+	var hDetailed = MakeAllHealthChecksDetailedHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent), Instance("flaki-7d9f", ""))
+
+	// All health checks.
+	{
+		mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(detailedReportOf(OK, map[string]Status{"influx": OK})).Times(1)
+
+		var req = httptest.NewRequest("GET", "http://cloudtrust.io/health", nil)
+		var w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, "flaki-7d9f", w.Result().Header.Get("X-Health-Hostname"))
+		assert.Equal(t, "eu-west-1a", w.Result().Header.Get("X-Health-Instance-ID"))
+	}
+
+	// Detailed health checks.
+	{
+		mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(DetailedReport{Status: OK}).Times(1)
+
+		var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/detailed", nil)
+		var w = httptest.NewRecorder()
+		hDetailed.ServeHTTP(w, req)
+		var body, err = ioutil.ReadAll(w.Result().Body)
+		assert.Nil(t, err)
+		assert.Equal(t, "flaki-7d9f", w.Result().Header.Get("X-Health-Hostname"))
+		assert.Zero(t, w.Result().Header.Get("X-Health-Instance-ID"))
+
+		var m = map[string]interface{}{}
+		json.Unmarshal(body, &m)
+		assert.Equal(t, "flaki-7d9f", m["hostname"])
+		assert.NotContains(t, m, "instance id")
+	}
+}
+
+func TestEncodeDetailedReport(t *testing.T) {
+	var report = DetailedReport{
+		Status: KO,
+		Subsystems: map[string]SubsystemReport{
+			"influx": {Status: Deactivated, Reports: []Report{{Name: "ping", Duration: "N/A", Status: Deactivated}}},
+			"redis":  {Status: KO, Reports: []Report{{Name: "ping", Duration: "1s", Status: KO, Error: "fail", Category: Network, Attempts: 1}}},
+		},
+		Errors: []string{"redis/ping: fail"},
+	}
+
+	var b strings.Builder
+	var err = EncodeDetailedReport(&b, report, OmitDeactivated(true))
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(b.String(), "}\n"))
+
+	var r = DetailedReply{}
+	assert.Nil(t, json.Unmarshal([]byte(b.String()), &r))
+	assert.Equal(t, "KO", r.Status)
+	assert.Equal(t, "fail", r.Subsystems["redis"].Reports[0].Error)
+	assert.NotContains(t, r.Subsystems, "influx")
+	assert.Equal(t, []string{"redis/ping: fail"}, r.Errors)
+}
+
+func TestDockerHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeDockerHealthCheckHandler(MakeDockerHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().DockerHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "docker", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/docker", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "docker", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestHealthStreamHandler(t *testing.T) {
+	var e = func(context.Context, interface{}) (interface{}, error) {
+		return DetailedReport{
+			Status: OK,
+			Subsystems: map[string]SubsystemReport{
+				"redis": {Status: OK, Reports: []Report{{Name: "ping", Duration: "1s", Status: OK, Attempts: 1}}},
+				"smtp":  {Status: Deactivated, Reports: []Report{{Name: "not configured", Duration: "N/A", Status: Deactivated}}},
+			},
+		}, nil
+	}
+
+	var p, err = NewPoller(e, time.Hour, time.Second)
+	assert.Nil(t, err)
+	var ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx)
+
+	var s = httptest.NewServer(MakeHealthStreamHandler(p, 10*time.Millisecond, OmitDeactivated(true)))
+	defer s.Close()
+
+	// HTTP request.
+	var req, _ = http.NewRequest("GET", s.URL, nil)
+	var reqCtx, reqCancel = context.WithCancel(context.Background())
+	defer reqCancel()
+	resp, err := http.DefaultClient.Do(req.WithContext(reqCtx))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// Read the events until both a report and a keepalive are received.
+	var r = bufio.NewReader(resp.Body)
+	var report, keepAlive bool
+	for !report || !keepAlive {
+		var line, err = r.ReadString('\n')
+		if !assert.Nil(t, err) {
+			return
+		}
+		switch {
+		case line == ": keepalive\n":
+			keepAlive = true
+		case line == "event: health\n":
+			line, err = r.ReadString('\n')
+			assert.Nil(t, err)
+			assert.Equal(t, `data: {"status":"OK","total duration":"0s","subsystems":{"redis":{"status":"OK","up":true,"consecutive failures":0,"health checks":[{"name":"ping","duration":"1s","status":"OK","attempts":1}]}}}`+"\n", line)
+			report = true
+		}
+	}
+
+	// Client disconnection.
+	reqCancel()
+	resp.Body.Close()
+}
+
+func TestLogSinkHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeLogSinkHealthCheckHandler(MakeLogSinkHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().LogSinkHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "logsink", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/logsink", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "logsink", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestE2EHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeE2EHealthCheckHandler(MakeE2EHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().E2EHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "e2e", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/e2e", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "e2e", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestGRPCReflectionHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeGRPCReflectionHealthCheckHandler(MakeGRPCReflectionHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().GRPCReflectionHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "grpc", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/grpc", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "grpc", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestEgressHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeEgressHealthCheckHandler(MakeEgressHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().EgressHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "egress", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/egress", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "egress", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestOptionsHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeOptionsHandler(MakeOptionsEndpoint(mockComponent))
+
+	mockComponent.EXPECT().Options().Return(Options{
+		ReadyStatuses:      []string{"OK"},
+		StartupGracePeriod: "30s",
+		Modules:            map[string]interface{}{"sentry": SentryOptions{Enabled: true, Projects: 2, Auth: "none"}},
+	}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/debug/options", nil)
+	var w = httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+	assert.Equal(t, []interface{}{"OK"}, m["ready statuses"])
+	assert.Equal(t, "30s", m["startup grace period"])
+	var sentry = m["modules"].(map[string]interface{})["sentry"].(map[string]interface{})
+	assert.Equal(t, 2.0, sentry["projects"])
+	assert.Equal(t, "none", sentry["auth"])
+}
+
+func TestFDHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeFDHealthCheckHandler(MakeFDHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().FDHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "fd", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/fd", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "fd", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestIDGenHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeIDGenHealthCheckHandler(MakeIDGenHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().IDGenHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "idgen", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/idgen", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "idgen", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestFeatureFlagHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeFeatureFlagHealthCheckHandler(MakeFeatureFlagHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().FeatureFlagHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "featureflag", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/featureflag", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "featureflag", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestReplicaHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeReplicaHealthCheckHandler(MakeReplicaHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().ReplicaHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "replica", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/replica", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "replica", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestCommandHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeCommandHealthCheckHandler(MakeCommandHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().CommandHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "command", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/command", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "command", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestSelfHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeSelfHealthCheckHandler(MakeSelfHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().SelfHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "self", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/self", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "self", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestAuditLogHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeAuditLogHealthCheckHandler(MakeAuditLogHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().AuditLogHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "auditlog", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/auditlog", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "auditlog", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
+
+func TestCompositeHealthCheckHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeCompositeHealthCheckHandler(MakeCompositeHealthCheckEndpoint(mockComponent))
+
+	// Health success.
+	mockComponent.EXPECT().CompositeHealthChecks(context.Background()).Return(Reports{Reports: []Report{{Name: "composite", Duration: (1 * time.Second).String(), Status: OK}}}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/composite", nil)
+	var w = httptest.NewRecorder()
+
+	// Health check.
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var m = map[string]interface{}{}
+	json.Unmarshal(body, &m)
+
+	var r = m["health checks"].([]interface{})[0]
+	{
+		var m = r.(map[string]interface{})
+		assert.Equal(t, "composite", m["name"])
+		assert.Equal(t, (1 * time.Second).String(), m["duration"])
+		assert.Equal(t, "OK", m["status"])
+		assert.Zero(t, m["error"])
+	}
+}
@@ -0,0 +1,246 @@
+package health
+
+//go:generate mockgen -destination=./mock/idgen.go -package=mock -mock_names=IDGenModule=IDGenModule,IDGenClock=IDGenClock  github.com/cloudtrust/flaki-service/pkg/health IDGenModule,IDGenClock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IDGenModule is the health check module for the ID generator.
+type IDGenModule interface {
+	HealthChecks(context.Context) []IDGenReport
+}
+
+type idGenModule struct {
+	epoch   time.Time
+	bits    uint
+	horizon time.Duration
+	enabled bool
+	clock   *idGenClockCheck
+}
+
+// idGenClockCheck is the configuration and the state of the clock check. The readings of the clocks are
+// recorded between the checks, to detect the steps of the wall clock.
+type idGenClockCheck struct {
+	clock    IDGenClock
+	maxDrift time.Duration
+
+	mutex      sync.Mutex
+	observed   bool
+	lastWall   time.Time
+	lastMono   time.Duration
+	latestWall time.Time
+}
+
+// IDGenOption is an option of the ID generator health module.
+type IDGenOption func(*idGenModule)
+
+// IDGenClockCheck adds a health check of the clock the IDs are generated from, as a backwards step of the
+// wall clock, e.g. a NTP step, makes the generator produce IDs that collide with the past ones. The wall
+// clock is compared with the monotonic clock since the last check: the check is KO when it stepped back by
+// more than maxDrift, or while it is behind the latest wall time observed by more than maxDrift, and Degraded
+// when it jumped forward by more than maxDrift. The first check only records the clocks.
+func IDGenClockCheck(clock IDGenClock, maxDrift time.Duration) IDGenOption {
+	return func(m *idGenModule) {
+		m.clock = &idGenClockCheck{
+			clock:    clock,
+			maxDrift: maxDrift,
+		}
+	}
+}
+
+// IDGenClock is the interface of the clocks read by the clock check, see NewIDGenSystemClock.
+type IDGenClock interface {
+	// Wall returns the time of the wall clock.
+	Wall() time.Time
+	// Monotonic returns the time elapsed on a monotonic clock since an arbitrary origin.
+	Monotonic() time.Duration
+}
+
+type systemClock struct {
+	origin time.Time
+}
+
+// NewIDGenSystemClock returns the clocks of the system, as read by time.Now.
+func NewIDGenSystemClock() IDGenClock {
+	return &systemClock{origin: time.Now()}
+}
+
+// Wall implements IDGenClock. The monotonic reading is stripped, so the wall times are compared as is.
+func (c *systemClock) Wall() time.Time {
+	return time.Now().Round(0)
+}
+
+// Monotonic implements IDGenClock.
+func (c *systemClock) Monotonic() time.Duration {
+	return time.Since(c.origin)
+}
+
+// IDGenReport is the health report returned by the ID generator module.
+type IDGenReport = Report
+
+// NewIDGenModule returns the ID generator health module. The IDs embed the milliseconds elapsed since epoch on
+// timestampBits bits, so the generator overflows 2^timestampBits milliseconds after epoch. They must match
+// the configuration of the generator, e.g. 41 bits overflow after about 69 years. The check is Degraded
+// when the overflow is closer than horizon, and KO once it is reached.
+func NewIDGenModule(epoch time.Time, timestampBits uint, horizon time.Duration, enabled bool, options ...IDGenOption) IDGenModule {
+	var m = &idGenModule{
+		epoch:   epoch,
+		bits:    timestampBits,
+		horizon: horizon,
+		enabled: enabled,
+	}
+
+	// Apply options.
+	for _, o := range options {
+		o(m)
+	}
+
+	return m
+}
+
+// HealthChecks executes all health checks for the ID generator.
+func (m *idGenModule) HealthChecks(context.Context) []IDGenReport {
+	var reports = []IDGenReport{}
+	reports = append(reports, m.idGenOverflowCheck())
+	if m.clock != nil {
+		reports = append(reports, m.idGenClockCheck())
+	}
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *idGenModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *idGenModule) idGenOverflowCheck() IDGenReport {
+	var healthCheckName = "overflow"
+	var healthCheckDescription = "Computes when the timestamp of the IDs overflows. If it fails, the generator will soon produce IDs that wrap around and collide with the past ones."
+
+	if !m.enabled {
+		return IDGenReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var overflow = flakiOverflow(m.epoch, m.bits)
+	var remaining = overflow.Sub(now)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case remaining <= 0:
+		error = fmt.Sprintf("flaki timestamps overflowed on %s", overflow.UTC().Format(time.RFC3339))
+		s = KO
+		category = Capacity
+	case remaining < m.horizon:
+		error = fmt.Sprintf("flaki timestamps overflow in %d days, on %s", int(remaining.Hours()/24), overflow.UTC().Format(time.RFC3339))
+		s = Degraded
+		category = Capacity
+	default:
+		s = OK
+	}
+
+	return IDGenReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+func (m *idGenModule) idGenClockCheck() IDGenReport {
+	var healthCheckName = "clock"
+	var healthCheckDescription = "Compares the wall clock with the monotonic clock since the last check. If it fails, the wall clock went backwards, e.g. after a NTP step, and the generator may produce IDs that collide with the past ones. Degraded means the wall clock jumped forward."
+
+	if !m.enabled {
+		return IDGenReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var behind, drift = m.clock.observe()
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case behind > m.clock.maxDrift:
+		error = fmt.Sprintf("wall clock is %v behind the latest time observed, the IDs may collide until it catches up", behind)
+		s = KO
+		category = DataIntegrity
+	case drift < -m.clock.maxDrift:
+		error = fmt.Sprintf("wall clock stepped back by %v since the last check, the IDs may have collided", -drift)
+		s = KO
+		category = DataIntegrity
+	case drift > m.clock.maxDrift:
+		error = fmt.Sprintf("wall clock jumped forward by %v since the last check", drift)
+		s = Degraded
+		category = DataIntegrity
+	default:
+		s = OK
+	}
+
+	return IDGenReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// observe reads the clocks and records them. It returns how far the wall clock is behind the latest wall
+// time observed, and the drift of the wall clock from the monotonic clock since the last check, negative
+// when the wall clock went back. Both are 0 on the first check.
+func (c *idGenClockCheck) observe() (time.Duration, time.Duration) {
+	var wall = c.clock.Wall()
+	var mono = c.clock.Monotonic()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var behind, drift time.Duration
+	if c.observed {
+		behind = c.latestWall.Sub(wall)
+		drift = wall.Sub(c.lastWall) - (mono - c.lastMono)
+	}
+
+	c.observed = true
+	c.lastWall = wall
+	c.lastMono = mono
+	if wall.After(c.latestWall) {
+		c.latestWall = wall
+	}
+	return behind, drift
+}
+
+// flakiOverflow returns the time at which the timestamp of bits bits, in milliseconds since epoch, overflows.
+// The computation is in seconds, as 2^bits milliseconds do not fit in a time.Duration beyond 43 bits.
+func flakiOverflow(epoch time.Time, bits uint) time.Time {
+	if bits > 62 {
+		bits = 62
+	}
+	var ms int64 = 1 << bits
+	return time.Unix(epoch.Unix()+ms/1000, int64(epoch.Nanosecond())+(ms%1000)*int64(time.Millisecond))
+}
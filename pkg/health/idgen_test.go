@@ -0,0 +1,152 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDGenHealthChecks(t *testing.T) {
+	var year = 365 * 24 * time.Hour
+	// 2^30 milliseconds is about 12.4 days.
+	var lifetime = time.Duration(1<<30) * time.Millisecond
+
+	// Far from the overflow.
+	{
+		var m = NewIDGenModule(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 41, year, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, "overflow", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// Within the horizon.
+	{
+		var m = NewIDGenModule(time.Now().Add(-lifetime).Add(10*24*time.Hour), 30, year, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Contains(t, report.Error, "flaki timestamps overflow in 9 days")
+		assert.Equal(t, Capacity, report.Category)
+	}
+
+	// Overflowed.
+	{
+		var m = NewIDGenModule(time.Now().Add(-lifetime).Add(-time.Hour), 30, year, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Contains(t, report.Error, "flaki timestamps overflowed")
+		assert.Equal(t, Capacity, report.Category)
+	}
+}
+
+func TestIDGenClockHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockClock = mock.NewIDGenClock(mockCtrl)
+
+	var m = NewIDGenModule(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 41, time.Hour, true, IDGenClockCheck(mockClock, time.Second))
+	var wall = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var mono time.Duration
+	var check = func(wallElapsed, monoElapsed time.Duration) IDGenReport {
+		wall = wall.Add(wallElapsed)
+		mono += monoElapsed
+		mockClock.EXPECT().Wall().Return(wall).Times(1)
+		mockClock.EXPECT().Monotonic().Return(mono).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 2, len(reports))
+		return reports[1]
+	}
+
+	// The first check records the clocks.
+	{
+		var report = check(0, 0)
+		assert.Equal(t, "clock", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// The clocks agree, within the max drift.
+	{
+		var report = check(10*time.Second+500*time.Millisecond, 10*time.Second)
+		assert.Equal(t, OK, report.Status)
+	}
+
+	// The wall clock jumped forward.
+	{
+		var report = check(20*time.Second, 10*time.Second)
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "wall clock jumped forward by 10s since the last check", report.Error)
+		assert.Equal(t, DataIntegrity, report.Category)
+	}
+
+	// The wall clock stepped back, and caught up since.
+	{
+		var report = check(5*time.Second, 10*time.Second)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "wall clock stepped back by 5s since the last check, the IDs may have collided", report.Error)
+		assert.Equal(t, DataIntegrity, report.Category)
+	}
+
+	// The wall clock is behind the latest time observed.
+	{
+		var report = check(-3*time.Second, time.Second)
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "wall clock is 3s behind the latest time observed, the IDs may collide until it catches up", report.Error)
+	}
+
+	// Still behind, although the clocks agree since the last check.
+	{
+		var report = check(time.Second, time.Second)
+		assert.Equal(t, KO, report.Status)
+		assert.Contains(t, report.Error, "wall clock is 2s behind")
+	}
+
+	// Behind, within the max drift.
+	{
+		var report = check(time.Second, time.Second)
+		assert.Equal(t, OK, report.Status)
+	}
+
+	// Caught up.
+	{
+		var report = check(2*time.Second, 2*time.Second)
+		assert.Equal(t, OK, report.Status)
+	}
+
+	// Stepped back, within the max drift, e.g. the NTP slewing.
+	{
+		var report = check(-500*time.Millisecond, 0)
+		assert.Equal(t, OK, report.Status)
+	}
+}
+
+func TestIDGenSystemClock(t *testing.T) {
+	var m = NewIDGenModule(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 41, time.Hour, true, IDGenClockCheck(NewIDGenSystemClock(), time.Second))
+
+	for i := 0; i < 3; i++ {
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, "clock", report.Name)
+		assert.Equal(t, OK, report.Status)
+	}
+}
+
+func TestNoopIDGenHealthChecks(t *testing.T) {
+	var m = NewIDGenModule(time.Time{}, 41, 0, false)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "overflow", report.Name)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+}
@@ -1,10 +1,15 @@
 package health
 
-//go:generate mockgen -destination=./mock/influx.go -package=mock -mock_names=InfluxModule=InfluxModule,Influx=Influx  github.com/cloudtrust/flaki-service/pkg/health InfluxModule,Influx
+//go:generate mockgen -destination=./mock/influx.go -package=mock -mock_names=InfluxModule=InfluxModule,Influx=Influx,InfluxBuffer=InfluxBuffer,InfluxFlush=InfluxFlush  github.com/cloudtrust/flaki-service/pkg/health InfluxModule,Influx,InfluxBuffer,InfluxFlush
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -14,16 +19,170 @@ type InfluxModule interface {
 }
 
 type influxModule struct {
-	influx  Influx
-	enabled bool
+	influx    Influx
+	enabled   bool
+	write     *influxWrite
+	retention *influxRetention
+	buffer    InfluxBuffer
+	flush     *influxFlush
+	v2        *influxV2
+	samplers  map[string]*sampler
+	timeouts  map[string]*adaptiveTimeout
 }
 
-// InfluxReport is the health report returned by the influx module.
+// influxWrite is the configuration of the write check.
+type influxWrite struct {
+	httpClient InfluxHTTPClient
+	addr       string
+	database   string
+	username   string
+	password   string
+}
+
+// influxRetention is the configuration of the retention check.
+type influxRetention struct {
+	influxWrite
+	policy string
+	min    time.Duration
+}
+
+// influxV2 is the configuration of the checks of an InfluxDB 2.x.
+type influxV2 struct {
+	httpClient InfluxHTTPClient
+	addr       string
+	org        string
+	bucket     string
+	token      string
+}
+
+// InfluxOption is an option of the influx health module.
+type InfluxOption func(*influxModule)
+
+// InfluxWriteCheck adds a health check that writes a point to the database, so a lost write
+// permission is detected even when ping succeeds. addr is the influx URL, e.g. http://localhost:8086.
+func InfluxWriteCheck(httpClient InfluxHTTPClient, addr, database, username, password string) InfluxOption {
+	return func(m *influxModule) {
+		m.write = &influxWrite{
+			httpClient: httpClient,
+			addr:       addr,
+			database:   database,
+			username:   username,
+			password:   password,
+		}
+	}
+}
+
+// InfluxRetentionCheck adds a health check that reads the retention policy of the database. It is Degraded
+// when the retention is shorter than min, and KO when the database or the policy does not exist. It catches
+// a misconfigured retention that silently deletes the metrics, which ping never detects. policy is the
+// name of the retention policy, if empty the default policy of the database is checked.
+func InfluxRetentionCheck(httpClient InfluxHTTPClient, addr, database, policy, username, password string, min time.Duration) InfluxOption {
+	return func(m *influxModule) {
+		m.retention = &influxRetention{
+			influxWrite: influxWrite{
+				httpClient: httpClient,
+				addr:       addr,
+				database:   database,
+				username:   username,
+				password:   password,
+			},
+			policy: policy,
+			min:    min,
+		}
+	}
+}
+
+// InfluxV2 checks an InfluxDB 2.x with its HTTP API rather than with the 1.x ping. addr is the influx URL,
+// e.g. http://localhost:8086. The ping queries the /health endpoint, and a read check runs a flux query on
+// the bucket through /api/v2/query, authenticated with the token, so a revoked token is detected. A token
+// rejected with 401 is reported with the category Auth, apart from the connection failures.
+func InfluxV2(httpClient InfluxHTTPClient, addr, org, bucket, token string) InfluxOption {
+	return func(m *influxModule) {
+		m.v2 = &influxV2{
+			httpClient: httpClient,
+			addr:       addr,
+			org:        org,
+			bucket:     bucket,
+			token:      token,
+		}
+	}
+}
+
+// InfluxCheckInterval sets the minimum interval between two executions of the influx check named check,
+// e.g. "write". In between, the check returns its last report, marked as cached. It keeps the expensive
+// checks on a slow cadence while the cheap ones run at every probe.
+func InfluxCheckInterval(check string, interval time.Duration) InfluxOption {
+	return func(m *influxModule) {
+		m.samplers[check] = newSampler(interval)
+	}
+}
+
+// InfluxAdaptiveTimeout replaces the static timeouts of the ping and write checks by adaptive ones. The
+// timeout of each check is the p99 of its last successful durations multiplied by factor, bounded by min
+// and max. The effective timeout is reported with the result of the check.
+func InfluxAdaptiveTimeout(factor float64, min, max time.Duration) InfluxOption {
+	return func(m *influxModule) {
+		m.timeouts["ping"] = newAdaptiveTimeout(factor, min, max)
+		m.timeouts["write"] = newAdaptiveTimeout(factor, min, max)
+	}
+}
+
+// InfluxBufferCheck adds a health check of the buffer of the metrics writes. It is Degraded when the buffer
+// is 80% full, and KO when it is full and the metrics are dropped. It warns before the metrics are lost
+// because the Influx DB is slow.
+func InfluxBufferCheck(buffer InfluxBuffer) InfluxOption {
+	return func(m *influxModule) {
+		m.buffer = buffer
+	}
+}
+
+// influxBufferDegradedRatio is the buffer fill ratio from which the buffer check is Degraded.
+const influxBufferDegradedRatio = 0.8
+
+// influxFlush is the configuration of the flush check. since is the creation of the module, the reference
+// of the age until the first flush.
+type influxFlush struct {
+	flush     InfluxFlush
+	staleness time.Duration
+	since     time.Time
+}
+
+// InfluxFlushCheck adds a health check of the age of the last metrics flush to the Influx DB. It is Degraded
+// when the last flush is older than staleness, and KO when it is older than 3 times staleness. It catches a
+// stalled metrics writer, that the ping to a healthy Influx DB misses. Before the first flush, the age is
+// counted from the creation of the module.
+func InfluxFlushCheck(flush InfluxFlush, staleness time.Duration) InfluxOption {
+	return func(m *influxModule) {
+		m.flush = &influxFlush{
+			flush:     flush,
+			staleness: staleness,
+			since:     time.Now(),
+		}
+	}
+}
+
+// influxFlushKORatio is the multiple of the staleness from which the flush check is KO.
+const influxFlushKORatio = 3
+
+// InfluxReport is the health report returned by the influx module. BufferDepth is set by the buffer check
+// only, it is the number of batches waiting in the write buffer. Retention is set by the retention check
+// only, it is the duration of the retention policy, "INF" when the points are kept forever.
 type InfluxReport struct {
-	Name     string
-	Duration string
-	Status   Status
-	Error    string
+	Report
+	BufferDepth int
+	Retention   string
+}
+
+// InfluxBuffer is the interface of the buffer of the metrics writes. BufferDepth returns the number of
+// batches waiting to be written, and the capacity of the buffer.
+type InfluxBuffer interface {
+	BufferDepth() (int, int)
+}
+
+// InfluxFlush is the interface of the metrics writer. LastFlush returns the time of the last batch
+// successfully written to the Influx DB, or the zero time if none was written yet.
+type InfluxFlush interface {
+	LastFlush() time.Time
 }
 
 // Influx is the interface of the influx client.
@@ -31,48 +190,670 @@ type Influx interface {
 	Ping(timeout time.Duration) (time.Duration, string, error)
 }
 
+// InfluxHTTPClient is the interface of the http client.
+type InfluxHTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
 // NewInfluxModule returns the influx health module.
-func NewInfluxModule(influx Influx, enabled bool) InfluxModule {
-	return &influxModule{
-		influx:  influx,
-		enabled: enabled,
+func NewInfluxModule(influx Influx, enabled bool, options ...InfluxOption) InfluxModule {
+	var m = &influxModule{
+		influx:   influx,
+		enabled:  enabled,
+		samplers: map[string]*sampler{},
+		timeouts: map[string]*adaptiveTimeout{},
+	}
+
+	// Apply options.
+	for _, o := range options {
+		o(m)
 	}
+
+	return m
 }
 
-// HealthChecks executes all health checks for influx.
-func (m *influxModule) HealthChecks(context.Context) []InfluxReport {
+// HealthChecks executes all health checks for influx. The ping and the write are separate
+// checks, so the report pinpoints which capability is broken.
+func (m *influxModule) HealthChecks(ctx context.Context) []InfluxReport {
 	var reports = []InfluxReport{}
-	reports = append(reports, m.influxPing())
+	if m.v2 != nil {
+		reports = append(reports, m.sample("ping", func() InfluxReport { return m.influxV2Ping(ctx) }))
+		reports = append(reports, m.sample("read", func() InfluxReport { return m.influxV2ReadCheck(ctx) }))
+	} else {
+		reports = append(reports, m.sample("ping", func() InfluxReport { return m.influxPing(ctx) }))
+	}
+	if m.write != nil {
+		reports = append(reports, m.sample("write", func() InfluxReport { return m.influxWriteCheck(ctx) }))
+	}
+	if m.retention != nil {
+		reports = append(reports, m.sample("retention", func() InfluxReport { return m.influxRetentionCheck(ctx) }))
+	}
+	if m.buffer != nil {
+		reports = append(reports, m.influxBufferCheck())
+	}
+	if m.flush != nil {
+		reports = append(reports, m.influxFlushCheck())
+	}
 	return reports
 }
 
-func (m *influxModule) influxPing() InfluxReport {
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *influxModule) Enabled() bool {
+	return m.enabled
+}
+
+// Warmup pings influx once, so the connection is established before the first health check. The result
+// is ignored, and the adaptive timeout does not observe the duration.
+func (m *influxModule) Warmup(context.Context) {
+	if !m.enabled {
+		return
+	}
+	m.influx.Ping(5 * time.Second)
+}
+
+// Reset forgets the cached reports of the sampled checks and the durations of the adaptive timeouts.
+func (m *influxModule) Reset() {
+	for _, s := range m.samplers {
+		s.reset()
+	}
+	for _, t := range m.timeouts {
+		t.reset()
+	}
+}
+
+// sample executes the check, unless it has a minimum interval that has not elapsed.
+func (m *influxModule) sample(check string, f func() InfluxReport) InfluxReport {
+	var s, ok = m.samplers[check]
+	if !ok {
+		return f()
+	}
+	var r = s.sample(func() Report { return f().Report })
+	return InfluxReport{
+		Report: Report{
+			Name:        r.Name,
+			Description: r.Description,
+			Duration:    r.Duration,
+			Status:      r.Status,
+			Error:       r.Error,
+			Category:    r.Category,
+			Attempts:    r.Attempts,
+			Cached:      r.Cached,
+			Timeout:     r.Timeout,
+		},
+	}
+}
+
+func (m *influxModule) influxPing(ctx context.Context) InfluxReport {
+	var healthCheckName = "ping"
+	var healthCheckDescription = "Pings the Influx DB that stores the metrics. The service works without it, but the metrics are lost."
+
+	if !m.enabled {
+		return InfluxReport{
+			Report: Report{
+				Name:        healthCheckName,
+				Description: healthCheckDescription,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			},
+		}
+	}
+
+	var timeout = 5 * time.Second
+	var adaptive, ok = m.timeouts["ping"]
+	if ok {
+		timeout = adaptive.timeout()
+	}
+	timeout = childTimeout(ctx, timeout)
+
+	var d, _, err = m.influx.Ping(timeout)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not ping influx: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	var report = InfluxReport{
+		Report: Report{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    d.String(),
+			Status:      s,
+			Error:       error,
+			Category:    category,
+			Attempts:    1,
+		},
+	}
+	if ok {
+		report.Timeout = timeout.String()
+		if s == OK {
+			adaptive.observe(d)
+		}
+	}
+	return report
+}
+
+func (m *influxModule) influxV2Ping(ctx context.Context) InfluxReport {
 	var healthCheckName = "ping"
+	var healthCheckDescription = "Queries the health endpoint of the Influx DB 2.x that stores the metrics. The service works without it, but the metrics are lost."
 
 	if !m.enabled {
 		return InfluxReport{
-			Name:     healthCheckName,
-			Duration: "N/A",
-			Status:   Deactivated,
+			Report: Report{
+				Name:        healthCheckName,
+				Description: healthCheckDescription,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			},
 		}
 	}
 
-	var d, _, err = m.influx.Ping(5 * time.Second)
+	var timeout = 5 * time.Second
+	var adaptive, ok = m.timeouts["ping"]
+	if ok {
+		timeout = adaptive.timeout()
+	}
+	timeout = childTimeout(ctx, timeout)
+	var ctxTimeout, cancel = context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var now = time.Now()
+	var err = influxV2Health(ctxTimeout, m.v2)
+	var duration = time.Since(now)
 
 	var error string
 	var s Status
+	var category Category
 	switch {
 	case err != nil:
 		error = fmt.Sprintf("could not ping influx: %v", err.Error())
 		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	var report = InfluxReport{
+		Report: Report{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    duration.String(),
+			Status:      s,
+			Error:       error,
+			Category:    category,
+			Attempts:    1,
+		},
+	}
+	if ok {
+		report.Timeout = timeout.String()
+		if s == OK {
+			adaptive.observe(duration)
+		}
+	}
+	return report
+}
+
+func (m *influxModule) influxV2ReadCheck(ctx context.Context) InfluxReport {
+	var healthCheckName = "read"
+	var healthCheckDescription = "Runs a flux query on the bucket of the Influx DB 2.x with the token of the service, to detect a revoked token or a deleted bucket. The service works without it, but the metrics are lost."
+
+	if !m.enabled {
+		return InfluxReport{
+			Report: Report{
+				Name:        healthCheckName,
+				Description: healthCheckDescription,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			},
+		}
+	}
+
+	var now = time.Now()
+	var err = queryInfluxV2(ctx, m.v2)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not read from influx: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
 	default:
 		s = OK
 	}
 
 	return InfluxReport{
-		Name:     healthCheckName,
-		Duration: d.String(),
-		Status:   s,
-		Error:    error,
+		Report: Report{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    duration.String(),
+			Status:      s,
+			Error:       error,
+			Category:    category,
+			Attempts:    1,
+		},
+	}
+}
+
+// influxV2Health queries the health endpoint of the influx 2.x API. Influx answers 200 with the status
+// "pass" when it is ready, 503 with the status "fail" otherwise.
+func influxV2Health(ctx context.Context, v2 *influxV2) error {
+	var req, err = http.NewRequest("GET", strings.TrimSuffix(v2.addr, "/")+"/health", nil)
+	if err != nil {
+		return err
+	}
+
+	var res *http.Response
+	res, err = v2.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if isRedirect(res) {
+		return unexpectedRedirect(res)
+	}
+
+	var health struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	json.NewDecoder(res.Body).Decode(&health)
+
+	switch {
+	case res.StatusCode == http.StatusOK && health.Status == "pass":
+		return nil
+	case health.Message != "":
+		return fmt.Errorf("influx is not ready: %v: %s", res.Status, health.Message)
+	default:
+		return fmt.Errorf("http response status code: %v", res.Status)
+	}
+}
+
+// queryInfluxV2 runs a flux query that reads at most one point of the last minute from the bucket. Influx
+// answers 200 with the CSV of the result, which may be empty.
+func queryInfluxV2(ctx context.Context, v2 *influxV2) error {
+	var u = fmt.Sprintf("%s/api/v2/query?org=%s", strings.TrimSuffix(v2.addr, "/"), url.QueryEscape(v2.org))
+	var query = fmt.Sprintf("from(bucket: %q) |> range(start: -1m) |> limit(n: 1)", v2.bucket)
+	var req, err = http.NewRequest("POST", u, strings.NewReader(query))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.flux")
+	req.Header.Set("Accept", "application/csv")
+	req.Header.Set("Authorization", "Token "+v2.token)
+
+	var res *http.Response
+	res, err = v2.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// Check response status.
+	if isRedirect(res) {
+		return unexpectedRedirect(res)
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return withCategory(Auth, fmt.Errorf("invalid token: %v", res.Status))
+	case http.StatusForbidden:
+		return withCategory(Auth, fmt.Errorf("read permission denied: %v", res.Status))
+	case http.StatusNotFound:
+		return withCategory(DataIntegrity, fmt.Errorf("bucket '%s' or organization '%s' not found: %v", v2.bucket, v2.org, res.Status))
+	default:
+		return fmt.Errorf("http response status code: %v", res.Status)
+	}
+}
+
+func (m *influxModule) influxWriteCheck(ctx context.Context) InfluxReport {
+	var healthCheckName = "write"
+	var healthCheckDescription = "Writes a point to the Influx DB, to detect a lost write permission. The service works without it, but the metrics are lost."
+
+	if !m.enabled {
+		return InfluxReport{
+			Report: Report{
+				Name:        healthCheckName,
+				Description: healthCheckDescription,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			},
+		}
+	}
+
+	var adaptive, ok = m.timeouts["write"]
+	var timeout time.Duration
+	if ok {
+		timeout = childTimeout(ctx, adaptive.timeout())
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var now = time.Now()
+	var err = writeInflux(ctx, m.write)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not write to influx: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	var report = InfluxReport{
+		Report: Report{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    duration.String(),
+			Status:      s,
+			Error:       error,
+			Category:    category,
+			Attempts:    1,
+		},
+	}
+	if ok {
+		report.Timeout = timeout.String()
+		if s == OK {
+			adaptive.observe(duration)
+		}
+	}
+	return report
+}
+
+// writeInflux writes a point with the influx HTTP API. Influx accepts the write with
+// the status 204 No Content.
+func writeInflux(ctx context.Context, w *influxWrite) error {
+	var u = fmt.Sprintf("%s/write?db=%s", strings.TrimSuffix(w.addr, "/"), url.QueryEscape(w.database))
+	var req, err = http.NewRequest("POST", u, strings.NewReader("health_check,check=write value=1"))
+	if err != nil {
+		return err
+	}
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	var res *http.Response
+	res, err = w.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// Check response status.
+	if isRedirect(res) {
+		return unexpectedRedirect(res)
+	}
+	switch res.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return withCategory(Auth, fmt.Errorf("write permission denied: %v", res.Status))
+	default:
+		return fmt.Errorf("http response status code: %v", res.Status)
+	}
+}
+
+func (m *influxModule) influxBufferCheck() InfluxReport {
+	var healthCheckName = "write buffer"
+	var healthCheckDescription = "Checks the depth of the buffer of the metrics writes. The service works without it, but when the buffer is full because the Influx DB is slow, the metrics are dropped."
+
+	if !m.enabled {
+		return InfluxReport{
+			Report: Report{
+				Name:        healthCheckName,
+				Description: healthCheckDescription,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			},
+		}
+	}
+
+	var now = time.Now()
+	var depth, capacity = m.buffer.BufferDepth()
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case depth >= capacity:
+		error = fmt.Sprintf("influx write buffer full: %d of %d batches, the metrics are dropped", depth, capacity)
+		s = KO
+		category = Capacity
+	case float64(depth) >= influxBufferDegradedRatio*float64(capacity):
+		error = fmt.Sprintf("influx write buffer almost full: %d of %d batches", depth, capacity)
+		s = Degraded
+		category = Capacity
+	default:
+		s = OK
+	}
+
+	return InfluxReport{
+		Report: Report{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    duration.String(),
+			Status:      s,
+			Error:       error,
+			Category:    category,
+			Attempts:    1,
+		},
+		BufferDepth: depth,
+	}
+}
+
+func (m *influxModule) influxFlushCheck() InfluxReport {
+	var healthCheckName = "last flush"
+	var healthCheckDescription = "Checks the age of the last metrics flush to the Influx DB. The service works without it, but a stalled writer stops sending the metrics while the Influx DB is up."
+
+	if !m.enabled {
+		return InfluxReport{
+			Report: Report{
+				Name:        healthCheckName,
+				Description: healthCheckDescription,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			},
+		}
+	}
+
+	var now = time.Now()
+	var last = m.flush.flush.LastFlush()
+	var duration = time.Since(now)
+
+	// Before the first flush, the age is counted from the creation of the module.
+	var stale string
+	var age time.Duration
+	if last.IsZero() {
+		age = now.Sub(m.flush.since)
+		stale = fmt.Sprintf("no metrics flushed to influx for %s", age)
+	} else {
+		age = now.Sub(last)
+		stale = fmt.Sprintf("last metrics flush to influx %s ago", age)
+	}
+
+	var error string
+	var s Status
+	switch {
+	case age > influxFlushKORatio*m.flush.staleness:
+		error = stale
+		s = KO
+	case age > m.flush.staleness:
+		error = stale
+		s = Degraded
+	default:
+		s = OK
+	}
+
+	return InfluxReport{
+		Report: Report{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    duration.String(),
+			Status:      s,
+			Error:       error,
+			Attempts:    1,
+		},
+	}
+}
+
+func (m *influxModule) influxRetentionCheck(ctx context.Context) InfluxReport {
+	var healthCheckName = "retention"
+	var healthCheckDescription = "Reads the retention policy of the Influx database. The service works without it, but with a too short retention the metrics are deleted before they are used."
+
+	if !m.enabled {
+		return InfluxReport{
+			Report: Report{
+				Name:        healthCheckName,
+				Description: healthCheckDescription,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			},
+		}
+	}
+
+	var now = time.Now()
+	var retention, err = queryInfluxRetention(ctx, m.retention)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not get the influx retention policy: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	case retention != 0 && retention < m.retention.min:
+		error = fmt.Sprintf("influx retention of %v is shorter than the minimum of %v", retention, m.retention.min)
+		s = Degraded
+		category = DataIntegrity
+	default:
+		s = OK
+	}
+
+	var report = InfluxReport{
+		Report: Report{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    duration.String(),
+			Status:      s,
+			Error:       error,
+			Category:    category,
+			Attempts:    1,
+		},
+	}
+	if err == nil {
+		report.Retention = "INF"
+		if retention != 0 {
+			report.Retention = retention.String()
+		}
+	}
+	return report
+}
+
+// influxRetentionPolicies is the reply of the influx query SHOW RETENTION POLICIES.
+type influxRetentionPolicies struct {
+	Results []struct {
+		Error  string `json:"error"`
+		Series []struct {
+			Columns []string        `json:"columns"`
+			Values  [][]interface{} `json:"values"`
+		} `json:"series"`
+	} `json:"results"`
+}
+
+// queryInfluxRetention returns the duration of the retention policy with the influx HTTP API. A duration
+// of 0 means the points are kept forever.
+func queryInfluxRetention(ctx context.Context, r *influxRetention) (time.Duration, error) {
+	var q = fmt.Sprintf("SHOW RETENTION POLICIES ON \"%s\"", strings.Replace(r.database, `"`, `\"`, -1))
+	var u = fmt.Sprintf("%s/query?q=%s", strings.TrimSuffix(r.addr, "/"), url.QueryEscape(q))
+	var req, err = http.NewRequest("GET", u, nil)
+	if err != nil {
+		return 0, err
+	}
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	var res *http.Response
+	res, err = r.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// Check response status.
+	if isRedirect(res) {
+		return 0, unexpectedRedirect(res)
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return 0, withCategory(Auth, fmt.Errorf("read permission denied: %v", res.Status))
+	default:
+		return 0, fmt.Errorf("http response status code: %v", res.Status)
+	}
+
+	var reply influxRetentionPolicies
+	err = json.NewDecoder(res.Body).Decode(&reply)
+	if err != nil {
+		return 0, withCategory(DataIntegrity, fmt.Errorf("could not decode response: %v", err))
+	}
+	if len(reply.Results) == 0 {
+		return 0, withCategory(DataIntegrity, errors.New("empty response"))
+	}
+	if reply.Results[0].Error != "" {
+		return 0, errors.New(reply.Results[0].Error)
+	}
+
+	// The columns are name, duration, shardGroupDuration, replicaN and default.
+	for _, series := range reply.Results[0].Series {
+		var name, dur, def = -1, -1, -1
+		for i, c := range series.Columns {
+			switch c {
+			case "name":
+				name = i
+			case "duration":
+				dur = i
+			case "default":
+				def = i
+			}
+		}
+		if name == -1 || dur == -1 || def == -1 {
+			return 0, withCategory(DataIntegrity, fmt.Errorf("unexpected columns %v", series.Columns))
+		}
+
+		for _, v := range series.Values {
+			if len(v) != len(series.Columns) {
+				continue
+			}
+			var isDefault, _ = v[def].(bool)
+			if (r.policy == "" && isDefault) || (r.policy != "" && v[name] == r.policy) {
+				var d, _ = v[dur].(string)
+				return time.ParseDuration(d)
+			}
+		}
+	}
+
+	if r.policy == "" {
+		return 0, fmt.Errorf("no default retention policy on database %s", r.database)
 	}
+	return 0, fmt.Errorf("retention policy not found: %s", r.policy)
 }
@@ -3,6 +3,9 @@ package health_test
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -24,9 +27,11 @@ func TestInfluxHealthChecks(t *testing.T) {
 		mockInflux.EXPECT().Ping(5*time.Second).Return(1*time.Second, "", nil).Times(1)
 		var report = m.HealthChecks(context.Background())[0]
 		assert.Equal(t, "ping", report.Name)
+		assert.NotZero(t, report.Description)
 		assert.NotZero(t, report.Duration)
 		assert.Equal(t, OK, report.Status)
 		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
 	}
 
 	// HealthChecks error.
@@ -53,4 +58,389 @@ func TestNoopInfluxHealthChecks(t *testing.T) {
 	assert.Equal(t, "N/A", report.Duration)
 	assert.Equal(t, Deactivated, report.Status)
 	assert.Zero(t, report.Error)
+	assert.Zero(t, report.Attempts)
+}
+
+func TestInfluxWriteHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInflux = mock.NewInflux(mockCtrl)
+
+	var status = http.StatusNoContent
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/write", r.URL.Path)
+		assert.Equal(t, "metrics", r.URL.Query().Get("db"))
+		var username, password, _ = r.BasicAuth()
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "secret", password)
+		w.WriteHeader(status)
+	}))
+	defer s.Close()
+
+	var m = NewInfluxModule(mockInflux, true, InfluxWriteCheck(s.Client(), s.URL, "metrics", "user", "secret"))
+
+	// Write accepted.
+	{
+		mockInflux.EXPECT().Ping(5*time.Second).Return(1*time.Second, "", nil).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Len(t, reports, 2)
+		assert.Equal(t, "ping", reports[0].Name)
+		assert.Equal(t, OK, reports[0].Status)
+		assert.Equal(t, "write", reports[1].Name)
+		assert.NotZero(t, reports[1].Duration)
+		assert.Equal(t, OK, reports[1].Status)
+		assert.Zero(t, reports[1].Error)
+		assert.Equal(t, 1, reports[1].Attempts)
+	}
+
+	// Write permission denied, ping still succeeds.
+	for _, status = range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		mockInflux.EXPECT().Ping(5*time.Second).Return(1*time.Second, "", nil).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, OK, reports[0].Status)
+		assert.Equal(t, KO, reports[1].Status)
+		assert.Contains(t, reports[1].Error, "could not write to influx: write permission denied")
+		assert.Equal(t, Auth, reports[1].Category)
+	}
+
+	// Other error.
+	{
+		status = http.StatusInternalServerError
+		mockInflux.EXPECT().Ping(5*time.Second).Return(1*time.Second, "", nil).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, KO, reports[1].Status)
+		assert.Equal(t, "could not write to influx: http response status code: 500 Internal Server Error", reports[1].Error)
+	}
+}
+
+func TestInfluxRetentionHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInflux = mock.NewInflux(mockCtrl)
+
+	var reply string
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/query", r.URL.Path)
+		assert.Equal(t, `SHOW RETENTION POLICIES ON "metrics"`, r.URL.Query().Get("q"))
+		var username, password, _ = r.BasicAuth()
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "secret", password)
+		w.Write([]byte(reply))
+	}))
+	defer s.Close()
+
+	var policies = func(values string) string {
+		return `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[` + values + `]}]}]}`
+	}
+
+	var m = NewInfluxModule(mockInflux, true, InfluxRetentionCheck(s.Client(), s.URL, "metrics", "", "user", "secret", 24*time.Hour))
+	mockInflux.EXPECT().Ping(5*time.Second).Return(1*time.Second, "", nil).AnyTimes()
+
+	// Default policy long enough.
+	{
+		reply = policies(`["autogen","0s","168h0m0s",1,false],["month","720h0m0s","24h0m0s",1,true]`)
+		var reports = m.HealthChecks(context.Background())
+		assert.Len(t, reports, 2)
+		assert.Equal(t, "retention", reports[1].Name)
+		assert.NotZero(t, reports[1].Description)
+		assert.NotZero(t, reports[1].Duration)
+		assert.Equal(t, OK, reports[1].Status)
+		assert.Zero(t, reports[1].Error)
+		assert.Equal(t, "720h0m0s", reports[1].Retention)
+		assert.Equal(t, 1, reports[1].Attempts)
+	}
+
+	// Infinite retention.
+	{
+		reply = policies(`["autogen","0s","168h0m0s",1,true]`)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, OK, reports[1].Status)
+		assert.Equal(t, "INF", reports[1].Retention)
+	}
+
+	// Retention too short.
+	{
+		reply = policies(`["autogen","1h0m0s","1h0m0s",1,true]`)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, Degraded, reports[1].Status)
+		assert.Equal(t, "influx retention of 1h0m0s is shorter than the minimum of 24h0m0s", reports[1].Error)
+		assert.Equal(t, DataIntegrity, reports[1].Category)
+		assert.Equal(t, "1h0m0s", reports[1].Retention)
+	}
+
+	// Database not found.
+	{
+		reply = `{"results":[{"statement_id":0,"error":"database not found: metrics"}]}`
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, KO, reports[1].Status)
+		assert.Equal(t, "could not get the influx retention policy: database not found: metrics", reports[1].Error)
+		assert.Zero(t, reports[1].Retention)
+	}
+
+	// Named policy not found.
+	{
+		var m = NewInfluxModule(mockInflux, true, InfluxRetentionCheck(s.Client(), s.URL, "metrics", "year", "user", "secret", 24*time.Hour))
+		reply = policies(`["autogen","0s","168h0m0s",1,true]`)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, KO, reports[1].Status)
+		assert.Equal(t, "could not get the influx retention policy: retention policy not found: year", reports[1].Error)
+	}
+}
+
+func TestInfluxV2HealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInflux = mock.NewInflux(mockCtrl)
+
+	var health = `{"name":"influxdb", "message":"ready for queries and writes", "status":"pass"}`
+	var healthStatus = http.StatusOK
+	var queryStatus = http.StatusOK
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.WriteHeader(healthStatus)
+			w.Write([]byte(health))
+		case "/api/v2/query":
+			var body, _ = ioutil.ReadAll(r.Body)
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "cloudtrust", r.URL.Query().Get("org"))
+			assert.Equal(t, "Token secret", r.Header.Get("Authorization"))
+			assert.Equal(t, "application/vnd.flux", r.Header.Get("Content-Type"))
+			assert.Equal(t, `from(bucket: "metrics") |> range(start: -1m) |> limit(n: 1)`, string(body))
+			w.WriteHeader(queryStatus)
+		}
+	}))
+	defer s.Close()
+
+	// The 1.x ping is not used.
+	mockInflux.EXPECT().Ping(gomock.Any()).Times(0)
+	var m = NewInfluxModule(mockInflux, true, InfluxV2(s.Client(), s.URL, "cloudtrust", "metrics", "secret"))
+
+	// Up.
+	{
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 2, len(reports))
+		assert.Equal(t, "ping", reports[0].Name)
+		assert.NotZero(t, reports[0].Description)
+		assert.NotZero(t, reports[0].Duration)
+		assert.Equal(t, OK, reports[0].Status)
+		assert.Zero(t, reports[0].Error)
+		assert.Equal(t, 1, reports[0].Attempts)
+		assert.Equal(t, "read", reports[1].Name)
+		assert.NotZero(t, reports[1].Description)
+		assert.Equal(t, OK, reports[1].Status)
+		assert.Zero(t, reports[1].Error)
+		assert.Equal(t, 1, reports[1].Attempts)
+	}
+
+	// Not ready.
+	{
+		health, healthStatus = `{"name":"influxdb", "message":"not ready", "status":"fail"}`, http.StatusServiceUnavailable
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not ping influx: influx is not ready: 503 Service Unavailable: not ready", report.Error)
+		health, healthStatus = `{"status":"pass"}`, http.StatusOK
+	}
+
+	// Bad token, apart from the connection failures.
+	{
+		queryStatus = http.StatusUnauthorized
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not read from influx: invalid token: 401 Unauthorized", report.Error)
+		assert.Equal(t, Auth, report.Category)
+	}
+
+	// Unknown bucket.
+	{
+		queryStatus = http.StatusNotFound
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, DataIntegrity, report.Category)
+		queryStatus = http.StatusOK
+	}
+
+	// Connection failure.
+	{
+		var addr = s.URL
+		s.Close()
+		var m = NewInfluxModule(mockInflux, true, InfluxV2(s.Client(), addr, "cloudtrust", "metrics", "secret"))
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, KO, reports[0].Status)
+		assert.Equal(t, KO, reports[1].Status)
+		assert.NotEqual(t, Auth, reports[1].Category)
+	}
+
+	// Invalid URL.
+	{
+		var _, err = NewComponent(NewInfluxModule(mockInflux, true, InfluxV2(s.Client(), "localhost:8086", "cloudtrust", "metrics", "secret")), nil, nil, nil)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestInfluxCheckInterval(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInflux = mock.NewInflux(mockCtrl)
+
+	var writes = 0
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writes++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	var interval = 100 * time.Millisecond
+	var m = NewInfluxModule(mockInflux, true, InfluxWriteCheck(s.Client(), s.URL, "metrics", "", ""), InfluxCheckInterval("write", interval))
+
+	// First execution.
+	{
+		mockInflux.EXPECT().Ping(5*time.Second).Return(1*time.Second, "", nil).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.False(t, reports[0].Cached)
+		assert.Equal(t, OK, reports[1].Status)
+		assert.False(t, reports[1].Cached)
+		assert.Equal(t, 1, writes)
+	}
+
+	// Within the interval, ping is executed, the write is cached.
+	{
+		mockInflux.EXPECT().Ping(5*time.Second).Return(1*time.Second, "", nil).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.False(t, reports[0].Cached)
+		assert.Equal(t, "write", reports[1].Name)
+		assert.Equal(t, OK, reports[1].Status)
+		assert.True(t, reports[1].Cached)
+		assert.Equal(t, 1, writes)
+	}
+
+	// After the interval, the write is executed again.
+	{
+		time.Sleep(interval)
+		mockInflux.EXPECT().Ping(5*time.Second).Return(1*time.Second, "", nil).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.False(t, reports[1].Cached)
+		assert.Equal(t, 2, writes)
+	}
+}
+
+func TestInfluxAdaptiveTimeout(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInflux = mock.NewInflux(mockCtrl)
+
+	var m = NewInfluxModule(mockInflux, true, InfluxAdaptiveTimeout(2, 10*time.Millisecond, time.Second))
+
+	// Without history, the timeout is the maximum.
+	{
+		mockInflux.EXPECT().Ping(time.Second).Return(100*time.Millisecond, "", nil).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, OK, report.Status)
+		assert.Equal(t, "1s", report.Timeout)
+	}
+
+	// The timeout is the p99 of the successful durations multiplied by the factor.
+	{
+		mockInflux.EXPECT().Ping(200*time.Millisecond).Return(200*time.Millisecond, "", fmt.Errorf("timeout")).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "200ms", report.Timeout)
+	}
+
+	// The failed pings are not observed.
+	{
+		mockInflux.EXPECT().Ping(200*time.Millisecond).Return(time.Millisecond, "", nil).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, "200ms", report.Timeout)
+	}
+}
+
+func TestInfluxBufferCheck(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInflux = mock.NewInflux(mockCtrl)
+	var mockBuffer = mock.NewInfluxBuffer(mockCtrl)
+
+	var m = NewInfluxModule(mockInflux, true, InfluxBufferCheck(mockBuffer))
+	mockInflux.EXPECT().Ping(5*time.Second).Return(1*time.Millisecond, "", nil).AnyTimes()
+
+	// Buffer with free space.
+	{
+		mockBuffer.EXPECT().BufferDepth().Return(3, 10).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 2, len(reports))
+		var report = reports[1]
+		assert.Equal(t, "write buffer", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+		assert.Equal(t, 3, report.BufferDepth)
+	}
+
+	// Buffer almost full.
+	{
+		mockBuffer.EXPECT().BufferDepth().Return(8, 10).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "influx write buffer almost full: 8 of 10 batches", report.Error)
+		assert.Equal(t, Capacity, report.Category)
+		assert.Equal(t, 8, report.BufferDepth)
+	}
+
+	// Buffer full.
+	{
+		mockBuffer.EXPECT().BufferDepth().Return(10, 10).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "influx write buffer full: 10 of 10 batches, the metrics are dropped", report.Error)
+		assert.Equal(t, Capacity, report.Category)
+	}
+}
+
+func TestInfluxFlushCheck(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInflux = mock.NewInflux(mockCtrl)
+	var mockFlush = mock.NewInfluxFlush(mockCtrl)
+
+	var m = NewInfluxModule(mockInflux, true, InfluxFlushCheck(mockFlush, 1*time.Minute))
+	mockInflux.EXPECT().Ping(5*time.Second).Return(1*time.Millisecond, "", nil).AnyTimes()
+
+	// No flush yet, the module was just created.
+	{
+		mockFlush.EXPECT().LastFlush().Return(time.Time{}).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 2, len(reports))
+		var report = reports[1]
+		assert.Equal(t, "last flush", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// Recent flush.
+	{
+		mockFlush.EXPECT().LastFlush().Return(time.Now().Add(-10 * time.Second)).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, OK, report.Status)
+	}
+
+	// Stale flush.
+	{
+		mockFlush.EXPECT().LastFlush().Return(time.Now().Add(-2 * time.Minute)).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Contains(t, report.Error, "last metrics flush to influx 2m")
+	}
+
+	// Far beyond the staleness.
+	{
+		mockFlush.EXPECT().LastFlush().Return(time.Now().Add(-10 * time.Minute)).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+	}
 }
@@ -0,0 +1,812 @@
+package health
+
+//go:generate mockgen -destination=./mock/instrumenting.go -package=mock -mock_names=Counter=Counter,Histogram=Histogram github.com/go-kit/kit/metrics Counter,Histogram
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+// Instrumenting middleware at module level.
+type influxModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      InfluxModule
+}
+
+// MakeInfluxModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeInfluxModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(InfluxModule) InfluxModule {
+	return func(next InfluxModule) InfluxModule {
+		return &influxModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// influxModuleInstrumentingMW implements Module.
+func (m *influxModuleInstrumentingMW) HealthChecks(ctx context.Context) []InfluxReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		var report = r.Report
+		countCheck(m.checks, m.failures, "influx", report)
+		observeDuration(m.durations, "influx", report)
+	}
+	return reports
+}
+
+// influxModuleInstrumentingMW implements Enabler.
+func (m *influxModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// influxModuleInstrumentingMW implements Warmer. The warm up is not instrumented.
+func (m *influxModuleInstrumentingMW) Warmup(ctx context.Context) {
+	warmup(ctx, m.next)
+}
+
+// influxModuleInstrumentingMW implements Validator.
+func (m *influxModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// influxModuleInstrumentingMW implements Resetter. The reset is not instrumented.
+func (m *influxModuleInstrumentingMW) Reset() {
+	reset(m.next)
+}
+
+// influxModuleInstrumentingMW implements Optioner.
+func (m *influxModuleInstrumentingMW) Options() interface{} {
+	var o, _ = moduleOptions(m.next)
+	return o
+}
+
+// Instrumenting middleware at module level.
+type jaegerModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      JaegerModule
+}
+
+// MakeJaegerModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeJaegerModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(JaegerModule) JaegerModule {
+	return func(next JaegerModule) JaegerModule {
+		return &jaegerModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// jaegerModuleInstrumentingMW implements Module.
+func (m *jaegerModuleInstrumentingMW) HealthChecks(ctx context.Context) []JaegerReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "jaeger", r)
+		observeDuration(m.durations, "jaeger", r)
+	}
+	return reports
+}
+
+// jaegerModuleInstrumentingMW implements Enabler.
+func (m *jaegerModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// jaegerModuleInstrumentingMW implements Warmer. The warm up is not instrumented.
+func (m *jaegerModuleInstrumentingMW) Warmup(ctx context.Context) {
+	warmup(ctx, m.next)
+}
+
+// jaegerModuleInstrumentingMW implements Validator.
+func (m *jaegerModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Instrumenting middleware at module level.
+type redisModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      RedisModule
+}
+
+// MakeRedisModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeRedisModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(RedisModule) RedisModule {
+	return func(next RedisModule) RedisModule {
+		return &redisModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// redisModuleInstrumentingMW implements Module.
+func (m *redisModuleInstrumentingMW) HealthChecks(ctx context.Context) []RedisReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		var report = r.Report
+		countCheck(m.checks, m.failures, "redis", report)
+		observeDuration(m.durations, "redis", report)
+	}
+	return reports
+}
+
+// redisModuleInstrumentingMW implements Enabler.
+func (m *redisModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// redisModuleInstrumentingMW implements Warmer. The warm up is not instrumented.
+func (m *redisModuleInstrumentingMW) Warmup(ctx context.Context) {
+	warmup(ctx, m.next)
+}
+
+// redisModuleInstrumentingMW implements Validator.
+func (m *redisModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// redisModuleInstrumentingMW implements ModuleAggregator.
+func (m *redisModuleInstrumentingMW) Aggregator() Aggregator {
+	return moduleAggregator(m.next)
+}
+
+// Instrumenting middleware at module level.
+type sentryModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      SentryModule
+}
+
+// MakeSentryModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeSentryModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(SentryModule) SentryModule {
+	return func(next SentryModule) SentryModule {
+		return &sentryModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// sentryModuleInstrumentingMW implements Module.
+func (m *sentryModuleInstrumentingMW) HealthChecks(ctx context.Context) []SentryReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "sentry", r)
+		observeDuration(m.durations, "sentry", r)
+	}
+	return reports
+}
+
+// sentryModuleInstrumentingMW implements Enabler.
+func (m *sentryModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// sentryModuleInstrumentingMW implements Warmer. The warm up is not instrumented.
+func (m *sentryModuleInstrumentingMW) Warmup(ctx context.Context) {
+	warmup(ctx, m.next)
+}
+
+// sentryModuleInstrumentingMW implements Resetter. The reset is not instrumented.
+func (m *sentryModuleInstrumentingMW) Reset() {
+	reset(m.next)
+}
+
+// sentryModuleInstrumentingMW implements Optioner.
+func (m *sentryModuleInstrumentingMW) Options() interface{} {
+	var o, _ = moduleOptions(m.next)
+	return o
+}
+
+// sentryModuleInstrumentingMW implements Validator.
+func (m *sentryModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Instrumenting middleware at module level.
+type smtpModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      SMTPModule
+}
+
+// MakeSMTPModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeSMTPModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(SMTPModule) SMTPModule {
+	return func(next SMTPModule) SMTPModule {
+		return &smtpModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// smtpModuleInstrumentingMW implements Module.
+func (m *smtpModuleInstrumentingMW) HealthChecks(ctx context.Context) []SMTPReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "smtp", r)
+		observeDuration(m.durations, "smtp", r)
+	}
+	return reports
+}
+
+// smtpModuleInstrumentingMW implements Enabler.
+func (m *smtpModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// smtpModuleInstrumentingMW implements Validator.
+func (m *smtpModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Instrumenting middleware at module level.
+type dockerModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      DockerModule
+}
+
+// MakeDockerModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeDockerModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(DockerModule) DockerModule {
+	return func(next DockerModule) DockerModule {
+		return &dockerModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// dockerModuleInstrumentingMW implements Module.
+func (m *dockerModuleInstrumentingMW) HealthChecks(ctx context.Context) []DockerReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "docker", r)
+		observeDuration(m.durations, "docker", r)
+	}
+	return reports
+}
+
+// dockerModuleInstrumentingMW implements Enabler.
+func (m *dockerModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// Instrumenting middleware at module level.
+type logSinkModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      LogSinkModule
+}
+
+// MakeLogSinkModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeLogSinkModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(LogSinkModule) LogSinkModule {
+	return func(next LogSinkModule) LogSinkModule {
+		return &logSinkModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// logSinkModuleInstrumentingMW implements Module.
+func (m *logSinkModuleInstrumentingMW) HealthChecks(ctx context.Context) []LogSinkReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "logsink", r)
+		observeDuration(m.durations, "logsink", r)
+	}
+	return reports
+}
+
+// logSinkModuleInstrumentingMW implements Enabler.
+func (m *logSinkModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// Instrumenting middleware at module level.
+type e2eModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      E2EModule
+}
+
+// MakeE2EModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeE2EModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(E2EModule) E2EModule {
+	return func(next E2EModule) E2EModule {
+		return &e2eModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// e2eModuleInstrumentingMW implements Module.
+func (m *e2eModuleInstrumentingMW) HealthChecks(ctx context.Context) []E2EReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "e2e", r)
+		observeDuration(m.durations, "e2e", r)
+	}
+	return reports
+}
+
+// e2eModuleInstrumentingMW implements Enabler.
+func (m *e2eModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// Instrumenting middleware at module level.
+type grpcReflectionModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      GRPCReflectionModule
+}
+
+// MakeGRPCReflectionModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeGRPCReflectionModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(GRPCReflectionModule) GRPCReflectionModule {
+	return func(next GRPCReflectionModule) GRPCReflectionModule {
+		return &grpcReflectionModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// grpcReflectionModuleInstrumentingMW implements Module.
+func (m *grpcReflectionModuleInstrumentingMW) HealthChecks(ctx context.Context) []GRPCReflectionReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "grpc", r)
+		observeDuration(m.durations, "grpc", r)
+	}
+	return reports
+}
+
+// grpcReflectionModuleInstrumentingMW implements Enabler.
+func (m *grpcReflectionModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// Instrumenting middleware at module level.
+type egressModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      EgressModule
+}
+
+// MakeEgressModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeEgressModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(EgressModule) EgressModule {
+	return func(next EgressModule) EgressModule {
+		return &egressModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// egressModuleInstrumentingMW implements Module.
+func (m *egressModuleInstrumentingMW) HealthChecks(ctx context.Context) []EgressReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "egress", r)
+		observeDuration(m.durations, "egress", r)
+	}
+	return reports
+}
+
+// egressModuleInstrumentingMW implements Enabler.
+func (m *egressModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// egressModuleInstrumentingMW implements Validator.
+func (m *egressModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Instrumenting middleware at module level.
+type fdModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      FDModule
+}
+
+// MakeFDModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeFDModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(FDModule) FDModule {
+	return func(next FDModule) FDModule {
+		return &fdModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// fdModuleInstrumentingMW implements Module.
+func (m *fdModuleInstrumentingMW) HealthChecks(ctx context.Context) []FDReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "fd", r)
+		observeDuration(m.durations, "fd", r)
+	}
+	return reports
+}
+
+// fdModuleInstrumentingMW implements Enabler.
+func (m *fdModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// fdModuleInstrumentingMW implements Validator.
+func (m *fdModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Instrumenting middleware at module level.
+type idGenModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      IDGenModule
+}
+
+// MakeIDGenModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeIDGenModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(IDGenModule) IDGenModule {
+	return func(next IDGenModule) IDGenModule {
+		return &idGenModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// idGenModuleInstrumentingMW implements Module.
+func (m *idGenModuleInstrumentingMW) HealthChecks(ctx context.Context) []IDGenReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "idgen", r)
+		observeDuration(m.durations, "idgen", r)
+	}
+	return reports
+}
+
+// idGenModuleInstrumentingMW implements Enabler.
+func (m *idGenModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// idGenModuleInstrumentingMW implements Validator.
+func (m *idGenModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Instrumenting middleware at module level.
+type featureFlagModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      FeatureFlagModule
+}
+
+// MakeFeatureFlagModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeFeatureFlagModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(FeatureFlagModule) FeatureFlagModule {
+	return func(next FeatureFlagModule) FeatureFlagModule {
+		return &featureFlagModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// featureFlagModuleInstrumentingMW implements Module.
+func (m *featureFlagModuleInstrumentingMW) HealthChecks(ctx context.Context) []FeatureFlagReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "featureflag", r)
+		observeDuration(m.durations, "featureflag", r)
+	}
+	return reports
+}
+
+// featureFlagModuleInstrumentingMW implements Enabler.
+func (m *featureFlagModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// featureFlagModuleInstrumentingMW implements Validator.
+func (m *featureFlagModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Instrumenting middleware at module level.
+type replicaModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      ReplicaModule
+}
+
+// MakeReplicaModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeReplicaModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(ReplicaModule) ReplicaModule {
+	return func(next ReplicaModule) ReplicaModule {
+		return &replicaModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// replicaModuleInstrumentingMW implements Module.
+func (m *replicaModuleInstrumentingMW) HealthChecks(ctx context.Context) []ReplicaReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "replica", r)
+		observeDuration(m.durations, "replica", r)
+	}
+	return reports
+}
+
+// replicaModuleInstrumentingMW implements Enabler.
+func (m *replicaModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// replicaModuleInstrumentingMW implements Validator.
+func (m *replicaModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Instrumenting middleware at module level.
+type commandModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      CommandModule
+}
+
+// MakeCommandModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeCommandModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(CommandModule) CommandModule {
+	return func(next CommandModule) CommandModule {
+		return &commandModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// commandModuleInstrumentingMW implements Module.
+func (m *commandModuleInstrumentingMW) HealthChecks(ctx context.Context) []CommandReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "command", r)
+		observeDuration(m.durations, "command", r)
+	}
+	return reports
+}
+
+// commandModuleInstrumentingMW implements Enabler.
+func (m *commandModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// commandModuleInstrumentingMW implements Validator.
+func (m *commandModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Instrumenting middleware at module level.
+type selfModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      SelfModule
+}
+
+// MakeSelfModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeSelfModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(SelfModule) SelfModule {
+	return func(next SelfModule) SelfModule {
+		return &selfModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// selfModuleInstrumentingMW implements Module.
+func (m *selfModuleInstrumentingMW) HealthChecks(ctx context.Context) []SelfReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "self", r)
+		observeDuration(m.durations, "self", r)
+	}
+	return reports
+}
+
+// selfModuleInstrumentingMW implements Enabler.
+func (m *selfModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// selfModuleInstrumentingMW implements Validator.
+func (m *selfModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Instrumenting middleware at module level.
+type auditLogModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      AuditLogModule
+}
+
+// MakeAuditLogModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeAuditLogModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(AuditLogModule) AuditLogModule {
+	return func(next AuditLogModule) AuditLogModule {
+		return &auditLogModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// auditLogModuleInstrumentingMW implements Module.
+func (m *auditLogModuleInstrumentingMW) HealthChecks(ctx context.Context) []AuditLogReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "auditlog", r)
+		observeDuration(m.durations, "auditlog", r)
+	}
+	return reports
+}
+
+// auditLogModuleInstrumentingMW implements Enabler.
+func (m *auditLogModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// auditLogModuleInstrumentingMW implements Validator.
+func (m *auditLogModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Instrumenting middleware at module level.
+type compositeModuleInstrumentingMW struct {
+	checks    metrics.Counter
+	failures  metrics.Counter
+	durations metrics.Histogram
+	next      CompositeModule
+}
+
+// MakeCompositeModuleInstrumentingMW makes an instrumenting middleware at module level.
+func MakeCompositeModuleInstrumentingMW(checks, failures metrics.Counter, durations metrics.Histogram) func(CompositeModule) CompositeModule {
+	return func(next CompositeModule) CompositeModule {
+		return &compositeModuleInstrumentingMW{
+			checks:    checks,
+			failures:  failures,
+			durations: durations,
+			next:      next,
+		}
+	}
+}
+
+// compositeModuleInstrumentingMW implements Module.
+func (m *compositeModuleInstrumentingMW) HealthChecks(ctx context.Context) []CompositeReport {
+	var reports = m.next.HealthChecks(ctx)
+
+	for _, r := range reports {
+		countCheck(m.checks, m.failures, "composite", r)
+		observeDuration(m.durations, "composite", r)
+	}
+	return reports
+}
+
+// compositeModuleInstrumentingMW implements Enabler.
+func (m *compositeModuleInstrumentingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// compositeModuleInstrumentingMW implements Module. The start is not instrumented.
+func (m *compositeModuleInstrumentingMW) Start(ctx context.Context) error {
+	return m.next.Start(ctx)
+}
+
+// compositeModuleInstrumentingMW implements Module. The stop is not instrumented.
+func (m *compositeModuleInstrumentingMW) Stop() error {
+	return m.next.Stop()
+}
+
+// compositeModuleInstrumentingMW implements Validator.
+func (m *compositeModuleInstrumentingMW) Validate() error {
+	return validate(m.next)
+}
+
+// observeDuration records the duration of the check in the histogram, labelled by subsystem and check.
+// The checks that were not executed, e.g. deactivated or cached, are not recorded.
+func observeDuration(durations metrics.Histogram, subsystem string, r Report) {
+	if r.Cached {
+		return
+	}
+
+	var d, err = time.ParseDuration(r.Duration)
+	if err != nil {
+		return
+	}
+	durations.With("subsystem", subsystem, "check", r.Name).Observe(d.Seconds())
+}
+
+// countCheck increments the checks counter, and the failures counter if the check is KO.
+// The counters are at module level, so every executed health check is counted.
+func countCheck(checks, failures metrics.Counter, subsystem string, r Report) {
+	var labels = []string{"subsystem", subsystem, "check", r.Name, "status", r.Status.String()}
+
+	checks.With(labels...).Add(1)
+	if r.Status == KO {
+		failures.With(labels...).Add(1)
+	}
+}
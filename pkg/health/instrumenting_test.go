@@ -0,0 +1,396 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+)
+
+func TestInfluxModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeInfluxModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockInfluxModule)
+
+	var reports = []InfluxReport{{Report: Report{Name: "ok", Status: OK}}, {Report: Report{Name: "ko", Status: KO}}}
+	mockInfluxModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "influx", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "influx", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "influx", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestJaegerModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeJaegerModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockJaegerModule)
+
+	var reports = []JaegerReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockJaegerModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "jaeger", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "jaeger", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "jaeger", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestRedisModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeRedisModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockRedisModule)
+
+	var reports = []RedisReport{{Report: Report{Name: "ok", Status: OK}}, {Report: Report{Name: "ko", Status: KO}}}
+	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "redis", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "redis", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "redis", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestSentryModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeSentryModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockSentryModule)
+
+	var reports = []SentryReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockSentryModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "sentry", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "sentry", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "sentry", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestSMTPModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSMTPModule = mock.NewSMTPModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeSMTPModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockSMTPModule)
+
+	var reports = []SMTPReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockSMTPModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "smtp", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "smtp", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "smtp", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestDockerModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockDockerModule = mock.NewDockerModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeDockerModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockDockerModule)
+
+	var reports = []DockerReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockDockerModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "docker", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "docker", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "docker", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestLogSinkModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogSinkModule = mock.NewLogSinkModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeLogSinkModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockLogSinkModule)
+
+	var reports = []LogSinkReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockLogSinkModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "logsink", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "logsink", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "logsink", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestE2EModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockE2EModule = mock.NewE2EModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeE2EModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockE2EModule)
+
+	var reports = []E2EReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockE2EModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "e2e", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "e2e", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "e2e", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestGRPCReflectionModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockGRPCReflectionModule = mock.NewGRPCReflectionModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeGRPCReflectionModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockGRPCReflectionModule)
+
+	var reports = []GRPCReflectionReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockGRPCReflectionModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "grpc", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "grpc", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "grpc", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestEgressModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockEgressModule = mock.NewEgressModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeEgressModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockEgressModule)
+
+	var reports = []EgressReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockEgressModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "egress", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "egress", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "egress", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestModuleInstrumentingMWDurations(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeRedisModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockRedisModule)
+
+	// The deactivated and cached checks are not observed.
+	var reports = []RedisReport{
+		{Report: Report{Name: "ping", Duration: "250ms", Status: OK}},
+		{Report: Report{Name: "cluster slots", Duration: "N/A", Status: Deactivated}},
+		{Report: Report{Name: "write", Duration: "1s", Status: OK, Cached: true}},
+	}
+	mockRedisModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "redis", "check", "ping", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "redis", "check", "cluster slots", "status", "Deactivated").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "redis", "check", "write", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(3)
+	mockDurations.EXPECT().With("subsystem", "redis", "check", "ping").Return(mockDurations).Times(1)
+	mockDurations.EXPECT().Observe(0.25).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestFDModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockFDModule = mock.NewFDModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeFDModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockFDModule)
+
+	var reports = []FDReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockFDModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "fd", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "fd", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "fd", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestIDGenModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockIDGenModule = mock.NewIDGenModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeIDGenModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockIDGenModule)
+
+	var reports = []IDGenReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockIDGenModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "idgen", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "idgen", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "idgen", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestFeatureFlagModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockFeatureFlagModule = mock.NewFeatureFlagModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeFeatureFlagModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockFeatureFlagModule)
+
+	var reports = []FeatureFlagReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockFeatureFlagModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "featureflag", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "featureflag", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "featureflag", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestReplicaModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockReplicaModule = mock.NewReplicaModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeReplicaModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockReplicaModule)
+
+	var reports = []ReplicaReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockReplicaModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "replica", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "replica", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "replica", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestCommandModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockCommandModule = mock.NewCommandModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeCommandModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockCommandModule)
+
+	var reports = []CommandReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockCommandModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "command", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "command", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "command", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestSelfModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSelfModule = mock.NewSelfModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeSelfModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockSelfModule)
+
+	var reports = []SelfReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockSelfModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "self", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "self", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "self", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestAuditLogModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockAuditLogModule = mock.NewAuditLogModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeAuditLogModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockAuditLogModule)
+
+	var reports = []AuditLogReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockAuditLogModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "auditlog", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "auditlog", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "auditlog", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
+
+func TestCompositeModuleInstrumentingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockCompositeModule = mock.NewCompositeModule(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+	var mockFailures = mock.NewCounter(mockCtrl)
+	var mockDurations = mock.NewHistogram(mockCtrl)
+
+	var m = MakeCompositeModuleInstrumentingMW(mockChecks, mockFailures, mockDurations)(mockCompositeModule)
+
+	var reports = []CompositeReport{{Name: "ok", Status: OK}, {Name: "ko", Status: KO}}
+	mockCompositeModule.EXPECT().HealthChecks(context.Background()).Return(reports).Times(1)
+	mockChecks.EXPECT().With("subsystem", "composite", "check", "ok", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "composite", "check", "ko", "status", "KO").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	mockFailures.EXPECT().With("subsystem", "composite", "check", "ko", "status", "KO").Return(mockFailures).Times(1)
+	mockFailures.EXPECT().Add(float64(1)).Return().Times(1)
+	m.HealthChecks(context.Background())
+}
@@ -4,8 +4,11 @@ package health
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/coreos/go-systemd/dbus"
@@ -13,6 +16,8 @@ import (
 
 const (
 	agentSystemDUnitName = "agent.service"
+	// agentProbeTimeout is how long the UDP probe waits for the ICMP port unreachable of a stopped agent.
+	agentProbeTimeout = 100 * time.Millisecond
 )
 
 // JaegerModule is the health check module for jaeger.
@@ -25,16 +30,73 @@ type jaegerModule struct {
 	collectorHealthCheckURL string
 	httpClient              JaegerHTTPClient
 	enabled                 bool
+	agent                   *jaegerAgent
+	sampling                *jaegerSampling
+	auth                    httpAuth
 }
 
-// JaegerReport is the health report returned by the jaeger module.
-type JaegerReport struct {
-	Name     string
-	Duration string
-	Status   Status
-	Error    string
+// jaegerAgent is the configuration of the agent UDP check.
+type jaegerAgent struct {
+	hostPort   string
+	metricsURL string
+}
+
+// jaegerSampling is the configuration of the sampling strategy check.
+type jaegerSampling struct {
+	serverURL   string
+	service     string
+	defaultRate float64
+}
+
+// JaegerOption is an option of the jaeger health module.
+type JaegerOption func(*jaegerModule)
+
+// JaegerBasicAuth sets the credentials sent with basic auth to the jaeger collector health endpoint. They
+// are scrubbed from the errors of the check.
+func JaegerBasicAuth(username, password string) JaegerOption {
+	return func(m *jaegerModule) {
+		m.auth = httpAuth{username: username, password: password}
+	}
+}
+
+// JaegerBearerToken sets the bearer token sent to the jaeger collector health endpoint. It is scrubbed from
+// the errors of the check.
+func JaegerBearerToken(token string) JaegerOption {
+	return func(m *jaegerModule) {
+		m.auth = httpAuth{token: token}
+	}
+}
+
+// JaegerAgentUDPCheck adds a health check that sends a probe packet to the UDP port of the Jaeger agent,
+// e.g. localhost:6831. UDP is fire-and-forget, so the probe only detects a stopped agent through the ICMP
+// port unreachable returned by its host. If metricsURL is not empty, e.g. http://localhost:14271/metrics,
+// the check also queries the agent metrics to confirm the agent is running.
+func JaegerAgentUDPCheck(hostPort, metricsURL string) JaegerOption {
+	return func(m *jaegerModule) {
+		m.agent = &jaegerAgent{
+			hostPort:   hostPort,
+			metricsURL: metricsURL,
+		}
+	}
+}
+
+// JaegerSamplingCheck adds a health check that fetches the sampling strategy of service from the sampling
+// server of the Jaeger agent, e.g. http://localhost:5778/sampling, as the remote sampler of the tracer does,
+// and validates it. If the strategy can't be fetched, the tracer silently keeps its initial probabilistic
+// sampling at defaultRate, so the check is Degraded. The effective sampling is added to the description.
+func JaegerSamplingCheck(samplingServerURL, service string, defaultRate float64) JaegerOption {
+	return func(m *jaegerModule) {
+		m.sampling = &jaegerSampling{
+			serverURL:   samplingServerURL,
+			service:     service,
+			defaultRate: defaultRate,
+		}
+	}
 }
 
+// JaegerReport is the health report returned by the jaeger module.
+type JaegerReport = Report
+
 // SystemDConn is interface of systemd D-Bus connection.
 type SystemDConn interface {
 	ListUnitsByNames(units []string) ([]dbus.UnitStatus, error)
@@ -42,35 +104,67 @@ type SystemDConn interface {
 
 // JaegerHTTPClient is the interface of the http client.
 type JaegerHTTPClient interface {
-	Get(string) (*http.Response, error)
+	Do(*http.Request) (*http.Response, error)
 }
 
 // NewJaegerModule returns the jaeger health module.
-func NewJaegerModule(conn SystemDConn, httpClient JaegerHTTPClient, collectorHealthCheckURL string, enabled bool) JaegerModule {
-	return &jaegerModule{
+func NewJaegerModule(conn SystemDConn, httpClient JaegerHTTPClient, collectorHealthCheckURL string, enabled bool, options ...JaegerOption) JaegerModule {
+	var m = &jaegerModule{
 		conn:                    conn,
 		httpClient:              httpClient,
 		collectorHealthCheckURL: collectorHealthCheckURL,
 		enabled:                 enabled,
 	}
+
+	// Apply options.
+	for _, o := range options {
+		o(m)
+	}
+
+	return m
 }
 
 // HealthChecks executes all health checks for Jaeger.
-func (m *jaegerModule) HealthChecks(context.Context) []JaegerReport {
+func (m *jaegerModule) HealthChecks(ctx context.Context) []JaegerReport {
 	var reports = []JaegerReport{}
 	reports = append(reports, m.jaegerSystemDCheck())
-	reports = append(reports, m.jaegerCollectorPing())
+	reports = append(reports, m.jaegerCollectorPing(ctx))
+	if m.agent != nil {
+		reports = append(reports, m.jaegerAgentUDPCheck(ctx))
+	}
+	if m.sampling != nil {
+		reports = append(reports, m.jaegerSamplingCheck(ctx))
+	}
 	return reports
 }
 
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *jaegerModule) Enabled() bool {
+	return m.enabled
+}
+
+// Warmup queries the Jaeger collector once, so the connection is established before the first health
+// check. The result is ignored.
+func (m *jaegerModule) Warmup(ctx context.Context) {
+	if !m.enabled {
+		return
+	}
+	var res, err = m.get(ctx, "http://"+m.collectorHealthCheckURL, m.auth)
+	if err == nil {
+		res.Body.Close()
+	}
+}
+
 func (m *jaegerModule) jaegerSystemDCheck() JaegerReport {
 	var healthCheckName = "jaeger agent systemd unit check"
+	var healthCheckDescription = "Checks that the systemd unit of the Jaeger agent is active. The service works without it, but the traces are lost."
 
 	if !m.enabled {
 		return JaegerReport{
-			Name:     healthCheckName,
-			Duration: "N/A",
-			Status:   Deactivated,
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
 		}
 	}
 
@@ -80,10 +174,12 @@ func (m *jaegerModule) jaegerSystemDCheck() JaegerReport {
 
 	var error string
 	var s Status
+	var category Category
 	switch {
 	case err != nil:
 		error = fmt.Sprintf("could not list systemd unit for name '%s': %v", agentSystemDUnitName, err.Error())
 		s = KO
+		category = errorCategory(err)
 	case len(units) == 0:
 		error = fmt.Sprintf("systemd unit '%s' not found: %v", agentSystemDUnitName, err.Error())
 		s = KO
@@ -95,34 +191,57 @@ func (m *jaegerModule) jaegerSystemDCheck() JaegerReport {
 	}
 
 	return JaegerReport{
-		Name:     healthCheckName,
-		Duration: duration.String(),
-		Status:   s,
-		Error:    error,
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
 	}
 }
 
-func (m *jaegerModule) jaegerCollectorPing() JaegerReport {
+func (m *jaegerModule) jaegerCollectorPing(ctx context.Context) JaegerReport {
 	var healthCheckName = "ping jaeger collector"
+	var healthCheckDescription = "Pings the Jaeger collector. The service works without it, but the traces are not stored."
 
 	if !m.enabled {
 		return JaegerReport{
-			Name:     healthCheckName,
-			Duration: "N/A",
-			Status:   Deactivated,
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	if m.collectorHealthCheckURL == "" {
+		return JaegerReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+			Error:       notConfiguredError,
 		}
 	}
 
 	// query jaeger collector health check URL
 	var now = time.Now()
-	var res, err = m.httpClient.Get("http://" + m.collectorHealthCheckURL)
+	var res, err = m.get(ctx, "http://"+m.collectorHealthCheckURL, m.auth)
 	var duration = time.Since(now)
+	if err == nil {
+		defer res.Body.Close()
+	}
 
 	var error string
 	var s Status
+	var category Category
 	switch {
 	case err != nil:
-		error = fmt.Sprintf("could not query jaeger collector health check service: %v", err.Error())
+		error = m.auth.scrub(fmt.Sprintf("could not query jaeger collector health check service: %v", err.Error()))
+		s = KO
+		category = errorCategory(err)
+	case isRedirect(res):
+		error = fmt.Sprintf("could not query jaeger collector health check service: %v", unexpectedRedirect(res).Error())
 		s = KO
 	case res.StatusCode != 204:
 		error = fmt.Sprintf("jaeger health check service returned invalid status code: %v", res.StatusCode)
@@ -132,9 +251,221 @@ func (m *jaegerModule) jaegerCollectorPing() JaegerReport {
 	}
 
 	return JaegerReport{
-		Name:     healthCheckName,
-		Duration: duration.String(),
-		Status:   s,
-		Error:    error,
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+func (m *jaegerModule) jaegerAgentUDPCheck(ctx context.Context) JaegerReport {
+	var healthCheckName = "jaeger agent udp"
+	var healthCheckDescription = "Sends a probe packet to the UDP port of the Jaeger agent and queries its metrics. The service works without it, but the spans are silently dropped."
+
+	if !m.enabled {
+		return JaegerReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var err = probeUDP(ctx, m.agent.hostPort)
+	var metricsErr error
+	if err == nil && m.agent.metricsURL != "" {
+		metricsErr = m.queryAgentMetrics(ctx)
+	}
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("jaeger agent '%s' is unreachable: %v", m.agent.hostPort, err.Error())
+		s = KO
+		category = errorCategory(err)
+	case metricsErr != nil:
+		// The probe was not rejected, but nothing confirms the agent received it.
+		error = fmt.Sprintf("could not query jaeger agent metrics: %v", metricsErr.Error())
+		s = Degraded
+		category = errorCategory(metricsErr)
+	default:
+		s = OK
+	}
+
+	return JaegerReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+func (m *jaegerModule) queryAgentMetrics(ctx context.Context) error {
+	var res, err = m.get(ctx, m.agent.metricsURL, httpAuth{})
+	if err == nil {
+		defer res.Body.Close()
+	}
+
+	switch {
+	case err != nil:
+		return err
+	case isRedirect(res):
+		return unexpectedRedirect(res)
+	case res.StatusCode != http.StatusOK:
+		return fmt.Errorf("invalid status code: %v", res.StatusCode)
+	default:
+		return nil
+	}
+}
+
+func (m *jaegerModule) jaegerSamplingCheck(ctx context.Context) JaegerReport {
+	var healthCheckName = "jaeger sampling strategy"
+	var healthCheckDescription = "Fetches the sampling strategy of the service from the Jaeger agent. The service works without it, but the tracer silently falls back to its default sampling."
+
+	if !m.enabled {
+		return JaegerReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var strategy, err = m.fetchSamplingStrategy(ctx)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	var sampling string
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not fetch the sampling strategy of '%s', the tracer falls back to the default sampling: %v", m.sampling.service, err.Error())
+		s = Degraded
+		category = errorCategory(err)
+		sampling = fmt.Sprintf("probabilistic, rate %v (default)", m.sampling.defaultRate)
+	default:
+		s = OK
+		sampling = strategy
+	}
+
+	return JaegerReport{
+		Name:        healthCheckName,
+		Description: fmt.Sprintf("%s Effective sampling: %s.", healthCheckDescription, sampling),
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// samplingStrategy is the sampling strategy returned by the sampling server of the Jaeger agent. The
+// strategy type is a string, e.g. "PROBABILISTIC", or its number with the older agents.
+type samplingStrategy struct {
+	StrategyType          json.RawMessage `json:"strategyType"`
+	ProbabilisticSampling *struct {
+		SamplingRate float64 `json:"samplingRate"`
+	} `json:"probabilisticSampling"`
+	RateLimitingSampling *struct {
+		MaxTracesPerSecond int `json:"maxTracesPerSecond"`
+	} `json:"rateLimitingSampling"`
+	OperationSampling *struct {
+		DefaultSamplingProbability float64 `json:"defaultSamplingProbability"`
+	} `json:"operationSampling"`
+}
+
+// fetchSamplingStrategy queries the sampling server and returns a description of the effective sampling,
+// e.g. "probabilistic, rate 0.001". It returns an error if the strategy is not well-formed.
+func (m *jaegerModule) fetchSamplingStrategy(ctx context.Context) (string, error) {
+	var res, err = m.get(ctx, m.sampling.serverURL+"?service="+url.QueryEscape(m.sampling.service), httpAuth{})
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case isRedirect(res):
+		return "", unexpectedRedirect(res)
+	case res.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("invalid status code: %v", res.StatusCode)
+	}
+
+	var strategy samplingStrategy
+	if err := json.NewDecoder(res.Body).Decode(&strategy); err != nil {
+		return "", fmt.Errorf("malformed sampling strategy: %v", err)
+	}
+
+	var strategyType = string(strategy.StrategyType)
+	switch {
+	case strategy.OperationSampling != nil:
+		var p = strategy.OperationSampling.DefaultSamplingProbability
+		if p < 0 || p > 1 {
+			return "", fmt.Errorf("malformed sampling strategy: default sampling probability %v is not in [0, 1]", p)
+		}
+		return fmt.Sprintf("per operation, default probability %v", p), nil
+	case (strategyType == `"PROBABILISTIC"` || strategyType == "0") && strategy.ProbabilisticSampling != nil:
+		var rate = strategy.ProbabilisticSampling.SamplingRate
+		if rate < 0 || rate > 1 {
+			return "", fmt.Errorf("malformed sampling strategy: sampling rate %v is not in [0, 1]", rate)
+		}
+		return fmt.Sprintf("probabilistic, rate %v", rate), nil
+	case (strategyType == `"RATE_LIMITING"` || strategyType == "1") && strategy.RateLimitingSampling != nil:
+		var max = strategy.RateLimitingSampling.MaxTracesPerSecond
+		if max < 0 {
+			return "", fmt.Errorf("malformed sampling strategy: max traces per second %v is negative", max)
+		}
+		return fmt.Sprintf("rate limiting, %d traces per second", max), nil
+	default:
+		return "", fmt.Errorf("malformed sampling strategy: unknown strategy type %s", strategyType)
+	}
+}
+
+// get sends a GET request to url, authenticated with auth, within the context deadline.
+func (m *jaegerModule) get(ctx context.Context, url string, auth httpAuth) (*http.Response, error) {
+	var req, err = http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	auth.apply(req)
+	return m.httpClient.Do(req.WithContext(ctx))
+}
+
+// probeUDP sends a packet to hostPort. The packet is not a valid span batch, the agent drops it. A
+// stopped agent is detected when the read returns the ICMP port unreachable, while a read timeout
+// means the packet was not rejected. The probe waits agentProbeTimeout at most, less if the context
+// deadline is sooner, and then fails with the context error.
+func probeUDP(ctx context.Context, hostPort string) error {
+	var timeout = childTimeout(ctx, agentProbeTimeout)
+	var conn, err = (&net.Dialer{Timeout: timeout}).DialContext(ctx, "udp", hostPort)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("health"))
+	if err != nil {
+		return err
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	_, err = conn.Read(make([]byte, 1))
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		if timeout < agentProbeTimeout {
+			return context.DeadlineExceeded
+		}
+		return nil
 	}
+	return err
 }
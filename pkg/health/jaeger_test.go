@@ -3,9 +3,11 @@ package health_test
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	. "github.com/cloudtrust/flaki-service/pkg/health"
 	"github.com/cloudtrust/flaki-service/pkg/health/mock"
@@ -33,9 +35,11 @@ func TestJaegerHealthChecks(t *testing.T) {
 		mockSystemDConn.EXPECT().ListUnitsByNames([]string{"agent.service"}).Return(units, nil).Times(1)
 		var report = m.HealthChecks(context.Background())[0]
 		assert.Equal(t, "jaeger agent systemd unit check", report.Name)
+		assert.NotZero(t, report.Description)
 		assert.NotZero(t, report.Duration)
 		assert.Equal(t, OK, report.Status)
 		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
 	}
 
 	// SystemD fail.
@@ -49,6 +53,94 @@ func TestJaegerHealthChecks(t *testing.T) {
 	}
 }
 
+func TestJaegerHealthChecksRedirect(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSystemDConn = mock.NewSystemDConn(mockCtrl)
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.Redirect(w, r, "/login", http.StatusFound)
+	}))
+	defer s.Close()
+
+	var m = NewJaegerModule(mockSystemDConn, NewHTTPClient(), s.Listener.Addr().String(), true)
+
+	var units = []dbus.UnitStatus{{Name: "agent.service", ActiveState: "active"}}
+	mockSystemDConn.EXPECT().ListUnitsByNames([]string{"agent.service"}).Return(units, nil).Times(1)
+	var report = m.HealthChecks(context.Background())[1]
+	assert.Equal(t, "ping jaeger collector", report.Name)
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, "could not query jaeger collector health check service: unexpected redirect: 302 Found to '/login'", report.Error)
+}
+
+func TestJaegerParentDeadline(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSystemDConn = mock.NewSystemDConn(mockCtrl)
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	// The http client times out after 30s, the deadline of the caller wins.
+	var m = NewJaegerModule(mockSystemDConn, NewHTTPClient(), s.Listener.Addr().String(), true)
+
+	var units = []dbus.UnitStatus{{Name: "agent.service", ActiveState: "active"}}
+	mockSystemDConn.EXPECT().ListUnitsByNames([]string{"agent.service"}).Return(units, nil).Times(1)
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var now = time.Now()
+	var report = m.HealthChecks(ctx)[1]
+	assert.True(t, time.Since(now) < 2*time.Second)
+	assert.Equal(t, "ping jaeger collector", report.Name)
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, Timeout, report.Category)
+}
+
+func TestJaegerBasicAuth(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSystemDConn = mock.NewSystemDConn(mockCtrl)
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var username, password, ok = r.BasicAuth()
+		if !ok || username != "health" || password != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	var units = []dbus.UnitStatus{{Name: "agent.service", ActiveState: "active"}}
+	mockSystemDConn.EXPECT().ListUnitsByNames([]string{"agent.service"}).Return(units, nil).Times(2)
+
+	// Valid credentials.
+	{
+		var m = NewJaegerModule(mockSystemDConn, s.Client(), s.Listener.Addr().String(), true, JaegerBasicAuth("health", "s3cr3t"))
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, "ping jaeger collector", report.Name)
+		assert.Equal(t, OK, report.Status)
+	}
+
+	// No credentials.
+	{
+		var m = NewJaegerModule(mockSystemDConn, s.Client(), s.Listener.Addr().String(), true)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "jaeger health check service returned invalid status code: 401", report.Error)
+	}
+}
+
 func TestNoopJaegerHealthChecks(t *testing.T) {
 	var mockCtrl = gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -66,4 +158,130 @@ func TestNoopJaegerHealthChecks(t *testing.T) {
 	assert.Equal(t, "N/A", report.Duration)
 	assert.Equal(t, Deactivated, report.Status)
 	assert.Zero(t, report.Error)
+	assert.Zero(t, report.Attempts)
+}
+
+func TestJaegerAgentUDPCheck(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSystemDConn = mock.NewSystemDConn(mockCtrl)
+	mockSystemDConn.EXPECT().ListUnitsByNames([]string{"agent.service"}).Return([]dbus.UnitStatus{{Name: "agent.service", ActiveState: "active"}}, nil).AnyTimes()
+
+	var metricsStatus = http.StatusOK
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(metricsStatus)
+	}))
+	defer s.Close()
+
+	var agent, err = net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer agent.Close()
+
+	// Agent up.
+	{
+		var m = NewJaegerModule(mockSystemDConn, s.Client(), s.Listener.Addr().String(), true, JaegerAgentUDPCheck(agent.LocalAddr().String(), s.URL+"/metrics"))
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 3, len(reports))
+		var report = reports[2]
+		assert.Equal(t, "jaeger agent udp", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+
+		var b = make([]byte, 16)
+		var n, _, err = agent.ReadFrom(b)
+		assert.Nil(t, err)
+		assert.Equal(t, "health", string(b[:n]))
+	}
+
+	// Metrics unavailable.
+	{
+		metricsStatus = http.StatusInternalServerError
+		var m = NewJaegerModule(mockSystemDConn, s.Client(), s.Listener.Addr().String(), true, JaegerAgentUDPCheck(agent.LocalAddr().String(), s.URL+"/metrics"))
+		var report = m.HealthChecks(context.Background())[2]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "could not query jaeger agent metrics: invalid status code: 500", report.Error)
+		metricsStatus = http.StatusOK
+	}
+
+	// Agent down.
+	{
+		var closed, err = net.ListenPacket("udp", "127.0.0.1:0")
+		assert.Nil(t, err)
+		var hostPort = closed.LocalAddr().String()
+		closed.Close()
+
+		var m = NewJaegerModule(mockSystemDConn, s.Client(), s.Listener.Addr().String(), true, JaegerAgentUDPCheck(hostPort, ""))
+		var report = m.HealthChecks(context.Background())[2]
+		assert.Equal(t, KO, report.Status)
+		assert.Contains(t, report.Error, "jaeger agent '"+hostPort+"' is unreachable")
+		assert.Equal(t, Network, report.Category)
+	}
+
+	// Without the option, there is no agent check.
+	{
+		var m = NewJaegerModule(mockSystemDConn, s.Client(), s.Listener.Addr().String(), true)
+		assert.Equal(t, 2, len(m.HealthChecks(context.Background())))
+	}
+}
+
+func TestJaegerSamplingCheck(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSystemDConn = mock.NewSystemDConn(mockCtrl)
+	mockSystemDConn.EXPECT().ListUnitsByNames([]string{"agent.service"}).Return([]dbus.UnitStatus{{Name: "agent.service", ActiveState: "active"}}, nil).AnyTimes()
+
+	var status = http.StatusOK
+	var strategy string
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sampling" {
+			assert.Equal(t, "flaki-service", r.URL.Query().Get("service"))
+			w.WriteHeader(status)
+			w.Write([]byte(strategy))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	var m = NewJaegerModule(mockSystemDConn, s.Client(), s.Listener.Addr().String(), true, JaegerSamplingCheck(s.URL+"/sampling", "flaki-service", 0.5))
+
+	var tsts = []struct {
+		status   int
+		strategy string
+		expected Status
+		sampling string
+		error    string
+	}{
+		{http.StatusOK, `{"strategyType":"PROBABILISTIC","probabilisticSampling":{"samplingRate":0.001}}`, OK, "probabilistic, rate 0.001", ""},
+		{http.StatusOK, `{"strategyType":1,"rateLimitingSampling":{"maxTracesPerSecond":10}}`, OK, "rate limiting, 10 traces per second", ""},
+		{http.StatusOK, `{"strategyType":0,"probabilisticSampling":{"samplingRate":1},"operationSampling":{"defaultSamplingProbability":0.2}}`, OK, "per operation, default probability 0.2", ""},
+		{http.StatusOK, `{"strategyType":"PROBABILISTIC","probabilisticSampling":{"samplingRate":2}}`, Degraded, "probabilistic, rate 0.5 (default)", "sampling rate 2 is not in [0, 1]"},
+		{http.StatusOK, `{"strategyType":"LOWER_BOUND"}`, Degraded, "probabilistic, rate 0.5 (default)", "unknown strategy type \"LOWER_BOUND\""},
+		{http.StatusOK, `not json`, Degraded, "probabilistic, rate 0.5 (default)", "malformed sampling strategy"},
+		{http.StatusInternalServerError, `collector error`, Degraded, "probabilistic, rate 0.5 (default)", "invalid status code: 500"},
+	}
+
+	for _, tst := range tsts {
+		status, strategy = tst.status, tst.strategy
+
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 3, len(reports))
+		var report = reports[2]
+		assert.Equal(t, "jaeger sampling strategy", report.Name)
+		assert.Contains(t, report.Description, "Effective sampling: "+tst.sampling+".")
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, tst.expected, report.Status, tst.strategy)
+		assert.Contains(t, report.Error, tst.error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// Invalid sampling server URL.
+	{
+		var m = NewJaegerModule(mockSystemDConn, s.Client(), s.Listener.Addr().String(), true, JaegerSamplingCheck("localhost:5778", "flaki-service", 1))
+		var _, err = NewComponent(nil, m, nil, nil)
+		assert.NotNil(t, err)
+	}
 }
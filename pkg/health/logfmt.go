@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	http_transport "github.com/go-kit/kit/transport/http"
+)
+
+// logfmtContentType is the content type of the logfmt reply.
+const logfmtContentType = "text/plain; charset=utf-8"
+
+// MakeLogfmtHandler makes a HTTP handler that returns the detailed health in logfmt, one line per check.
+// It must be used with the AllHealthChecksDetailed endpoint.
+func MakeLogfmtHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeLogfmtReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// encodeLogfmtReply encodes the detailed health report in logfmt.
+func encodeLogfmtReply(_ context.Context, w http.ResponseWriter, rep interface{}) error {
+	var report = rep.(DetailedReport)
+
+	w.Header().Set("Content-Type", logfmtContentType)
+	w.WriteHeader(http.StatusOK)
+	return report.WriteLogfmt(w)
+}
+
+// WriteLogfmt writes the report in logfmt, one line per check, e.g.
+// subsystem=redis check=ping status=OK duration_ms=3 attempts=1
+// so it can be piped to a collector that ingests one log line per event. The subsystem status is on each
+// line, and the empty fields are omitted.
+func (r DetailedReport) WriteLogfmt(w io.Writer) error {
+	var logger = log.NewLogfmtLogger(w)
+
+	for _, name := range subsystems {
+		var sr, ok = r.Subsystems[name]
+		if !ok {
+			continue
+		}
+		for _, c := range sr.Reports {
+			var keyvals = []interface{}{"subsystem", name, "subsystem_status", sr.Status.String(), "check", c.Name, "status", c.Status.String()}
+			if d, err := time.ParseDuration(c.Duration); err == nil {
+				keyvals = append(keyvals, "duration_ms", float64(d)/float64(time.Millisecond))
+			}
+			keyvals = append(keyvals, "attempts", c.Attempts)
+			if c.Category != Uncategorized {
+				keyvals = append(keyvals, "category", c.Category.String())
+			}
+			if c.Cached {
+				keyvals = append(keyvals, "cached", true)
+			}
+			if c.Timeout != "" {
+				keyvals = append(keyvals, "timeout", c.Timeout)
+			}
+			if sr.SlowThreshold != "" {
+				keyvals = append(keyvals, "slow_threshold", sr.SlowThreshold)
+			}
+			if c.Error != "" {
+				keyvals = append(keyvals, "error", c.Error)
+			}
+
+			var err = logger.Log(keyvals...)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package health_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteLogfmt(t *testing.T) {
+	var report = DetailedReport{
+		Status: KO,
+		Subsystems: map[string]SubsystemReport{
+			"influx": {Status: Deactivated, Reports: []Report{{Name: "ping", Duration: "N/A", Status: Deactivated}}},
+			"redis": {Status: KO, Reports: []Report{
+				{Name: "ping", Duration: "3ms", Status: OK, Attempts: 1, Timeout: "500ms"},
+				{Name: "write", Duration: "1.5s", Status: KO, Error: "could not write", Category: Timeout, Attempts: 2, Cached: true},
+			}},
+		},
+	}
+
+	var b = &bytes.Buffer{}
+	var err = report.WriteLogfmt(b)
+	assert.Nil(t, err)
+
+	var lines = strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	assert.Equal(t, []string{
+		"subsystem=influx subsystem_status=Deactivated check=ping status=Deactivated attempts=0",
+		"subsystem=redis subsystem_status=KO check=ping status=OK duration_ms=3 attempts=1 timeout=500ms",
+		"subsystem=redis subsystem_status=KO check=write status=KO duration_ms=1500 attempts=2 category=timeout cached=true error=\"could not write\"",
+	}, lines)
+}
+
+func TestLogfmtHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeLogfmtHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent))
+
+	mockComponent.EXPECT().AllHealthChecksDetailed(gomock.Any()).Return(DetailedReport{
+		Status:     OK,
+		Subsystems: map[string]SubsystemReport{"redis": {Status: OK, Reports: []Report{{Name: "ping", Duration: "1ms", Status: OK, Attempts: 1}}}},
+	}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/logfmt", nil)
+	var w = httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "subsystem=redis subsystem_status=OK check=ping status=OK duration_ms=1 attempts=1\n", string(body))
+}
@@ -75,6 +75,132 @@ func (m *componentLoggingMW) SentryHealthChecks(ctx context.Context) Reports {
 	return m.next.SentryHealthChecks(ctx)
 }
 
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) SMTPHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "SMTPHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.SMTPHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) DockerHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "DockerHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.DockerHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) LogSinkHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "LogSinkHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.LogSinkHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) E2EHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "E2EHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.E2EHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) GRPCReflectionHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "GRPCReflectionHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.GRPCReflectionHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) EgressHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "EgressHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.EgressHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) FDHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "FDHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.FDHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) IDGenHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "IDGenHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.IDGenHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) FeatureFlagHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "FeatureFlagHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.FeatureFlagHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) ReplicaHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "ReplicaHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.ReplicaHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) CommandHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "CommandHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.CommandHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) SelfHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "SelfHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.SelfHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) AuditLogHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "AuditLogHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.AuditLogHealthChecks(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) CompositeHealthChecks(ctx context.Context) Reports {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "CompositeHealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.CompositeHealthChecks(ctx)
+}
+
 // componentLoggingMW implements Component.
 func (m *componentLoggingMW) AllHealthChecks(ctx context.Context) map[string]string {
 	defer func(begin time.Time) {
@@ -84,6 +210,119 @@ func (m *componentLoggingMW) AllHealthChecks(ctx context.Context) map[string]str
 	return m.next.AllHealthChecks(ctx)
 }
 
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) AllHealthChecksDetailed(ctx context.Context) DetailedReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "AllHealthChecksDetailed", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.AllHealthChecksDetailed(ctx)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) HealthChecksDetailed(ctx context.Context, subsystems ...string) DetailedReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecksDetailed", "subsystems", subsystems, "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecksDetailed(ctx, subsystems...)
+}
+
+// componentLoggingMW implements Component.
+func (m *componentLoggingMW) OverallStatusWithReason(ctx context.Context) (Status, string) {
+	var s Status
+	var reason string
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "OverallStatusWithReason", "correlation_id", ctx.Value("correlation_id").(string), "status", s.String(), "reason", reason, "took", time.Since(begin))
+	}(time.Now())
+
+	s, reason = m.next.OverallStatusWithReason(ctx)
+	return s, reason
+}
+
+// componentLoggingMW implements Component. WaitUntilReady is called at startup, outside of any request,
+// so there is no correlation ID.
+func (m *componentLoggingMW) WaitUntilReady(ctx context.Context, pollInterval time.Duration) Status {
+	var s Status
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "WaitUntilReady", "status", s.String(), "took", time.Since(begin))
+	}(time.Now())
+
+	s = m.next.WaitUntilReady(ctx, pollInterval)
+	return s
+}
+
+// componentLoggingMW implements Warmer. The warm up is called at startup, outside of any request, so
+// there is no correlation ID.
+func (m *componentLoggingMW) Warmup(ctx context.Context) {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "Warmup", "took", time.Since(begin))
+	}(time.Now())
+
+	warmup(ctx, m.next)
+}
+
+// componentLoggingMW implements Component. The toggles are operator actions, outside of any request,
+// so there is no correlation ID.
+func (m *componentLoggingMW) Enable(subsystem string) error {
+	var err error
+	defer func() {
+		m.logger.Log("unit", "Enable", "subsystem", subsystem, "error", err)
+	}()
+
+	err = m.next.Enable(subsystem)
+	return err
+}
+
+// componentLoggingMW implements Component. The toggles are operator actions, outside of any request,
+// so there is no correlation ID.
+func (m *componentLoggingMW) Disable(subsystem string) error {
+	var err error
+	defer func() {
+		m.logger.Log("unit", "Disable", "subsystem", subsystem, "error", err)
+	}()
+
+	err = m.next.Disable(subsystem)
+	return err
+}
+
+// componentLoggingMW implements Component. The shutdown is not a health request, so there is no
+// correlation ID.
+func (m *componentLoggingMW) BeginShutdown() {
+	m.logger.Log("unit", "BeginShutdown")
+	m.next.BeginShutdown()
+}
+
+// componentLoggingMW implements Component. The reset is an operator action, outside of any request, so
+// there is no correlation ID.
+func (m *componentLoggingMW) Reset() {
+	m.logger.Log("unit", "Reset")
+	m.next.Reset()
+}
+
+// componentLoggingMW implements Component. The uptime is not computed for a health request, so there is
+// no correlation ID.
+func (m *componentLoggingMW) UptimePercent(subsystem string, window time.Duration) (float64, error) {
+	var uptime float64
+	var err error
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "UptimePercent", "subsystem", subsystem, "window", window, "uptime", uptime, "error", err, "took", time.Since(begin))
+	}(time.Now())
+
+	uptime, err = m.next.UptimePercent(subsystem, window)
+	return uptime, err
+}
+
+// componentLoggingMW implements Component. The introspection is not logged.
+func (m *componentLoggingMW) Options() Options {
+	return m.next.Options()
+}
+
+// componentLoggingMW implements Component. The introspection is not logged.
+func (m *componentLoggingMW) Subsystems() []string {
+	return m.next.Subsystems()
+}
+
 // Logging middleware at module level.
 type influxModuleLoggingMW struct {
 	logger log.Logger
@@ -106,7 +345,44 @@ func (m *influxModuleLoggingMW) HealthChecks(ctx context.Context) []InfluxReport
 		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
 	}(time.Now())
 
-	return m.next.HealthChecks(ctx)
+	var reports = m.next.HealthChecks(ctx)
+	for _, r := range reports {
+		logReport(ctx, m.logger, "influx", r.Report)
+	}
+	return reports
+}
+
+// influxModuleLoggingMW implements Enabler.
+func (m *influxModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// influxModuleLoggingMW implements Warmer. The warm up is called at startup, outside of any request, so
+// there is no correlation ID.
+func (m *influxModuleLoggingMW) Warmup(ctx context.Context) {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "Warmup", "took", time.Since(begin))
+	}(time.Now())
+
+	warmup(ctx, m.next)
+}
+
+// influxModuleLoggingMW implements Validator. The validation is not logged.
+func (m *influxModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// influxModuleLoggingMW implements Resetter. The reset is an operator action, outside of any request, so there
+// is no correlation ID.
+func (m *influxModuleLoggingMW) Reset() {
+	m.logger.Log("unit", "Reset")
+	reset(m.next)
+}
+
+// influxModuleLoggingMW implements Optioner. The introspection is not logged.
+func (m *influxModuleLoggingMW) Options() interface{} {
+	var o, _ = moduleOptions(m.next)
+	return o
 }
 
 // Logging middleware at module level.
@@ -131,7 +407,31 @@ func (m *jaegerModuleLoggingMW) HealthChecks(ctx context.Context) []JaegerReport
 		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
 	}(time.Now())
 
-	return m.next.HealthChecks(ctx)
+	var reports = m.next.HealthChecks(ctx)
+	for _, r := range reports {
+		logReport(ctx, m.logger, "jaeger", r)
+	}
+	return reports
+}
+
+// jaegerModuleLoggingMW implements Enabler.
+func (m *jaegerModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// jaegerModuleLoggingMW implements Warmer. The warm up is called at startup, outside of any request, so
+// there is no correlation ID.
+func (m *jaegerModuleLoggingMW) Warmup(ctx context.Context) {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "Warmup", "took", time.Since(begin))
+	}(time.Now())
+
+	warmup(ctx, m.next)
+}
+
+// jaegerModuleLoggingMW implements Validator. The validation is not logged.
+func (m *jaegerModuleLoggingMW) Validate() error {
+	return validate(m.next)
 }
 
 // Logging middleware at module level.
@@ -156,7 +456,36 @@ func (m *redisModuleLoggingMW) HealthChecks(ctx context.Context) []RedisReport {
 		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
 	}(time.Now())
 
-	return m.next.HealthChecks(ctx)
+	var reports = m.next.HealthChecks(ctx)
+	for _, r := range reports {
+		logReport(ctx, m.logger, "redis", r.Report)
+	}
+	return reports
+}
+
+// redisModuleLoggingMW implements Enabler.
+func (m *redisModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// redisModuleLoggingMW implements Warmer. The warm up is called at startup, outside of any request, so
+// there is no correlation ID.
+func (m *redisModuleLoggingMW) Warmup(ctx context.Context) {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "Warmup", "took", time.Since(begin))
+	}(time.Now())
+
+	warmup(ctx, m.next)
+}
+
+// redisModuleLoggingMW implements Validator. The validation is not logged.
+func (m *redisModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// redisModuleLoggingMW implements ModuleAggregator.
+func (m *redisModuleLoggingMW) Aggregator() Aggregator {
+	return moduleAggregator(m.next)
 }
 
 // Logging middleware at module level.
@@ -181,5 +510,559 @@ func (m *sentryModuleLoggingMW) HealthChecks(ctx context.Context) []SentryReport
 		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
 	}(time.Now())
 
+	var reports = m.next.HealthChecks(ctx)
+	for _, r := range reports {
+		logReport(ctx, m.logger, "sentry", r)
+	}
+	return reports
+}
+
+// sentryModuleLoggingMW implements Enabler.
+func (m *sentryModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// sentryModuleLoggingMW implements Warmer. The warm up is called at startup, outside of any request, so
+// there is no correlation ID.
+func (m *sentryModuleLoggingMW) Warmup(ctx context.Context) {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "Warmup", "took", time.Since(begin))
+	}(time.Now())
+
+	warmup(ctx, m.next)
+}
+
+// sentryModuleLoggingMW implements Resetter. The reset is an operator action, outside of any request, so there
+// is no correlation ID.
+func (m *sentryModuleLoggingMW) Reset() {
+	m.logger.Log("unit", "Reset")
+	reset(m.next)
+}
+
+// sentryModuleLoggingMW implements Optioner. The introspection is not logged.
+func (m *sentryModuleLoggingMW) Options() interface{} {
+	var o, _ = moduleOptions(m.next)
+	return o
+}
+
+// sentryModuleLoggingMW implements Validator. The validation is not logged.
+func (m *sentryModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Logging middleware at module level.
+type smtpModuleLoggingMW struct {
+	logger log.Logger
+	next   SMTPModule
+}
+
+// MakeSMTPModuleLoggingMW makes a logging middleware at module level.
+func MakeSMTPModuleLoggingMW(logger log.Logger) func(SMTPModule) SMTPModule {
+	return func(next SMTPModule) SMTPModule {
+		return &smtpModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// smtpModuleLoggingMW implements Module.
+func (m *smtpModuleLoggingMW) HealthChecks(ctx context.Context) []SMTPReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	var reports = m.next.HealthChecks(ctx)
+	for _, r := range reports {
+		logReport(ctx, m.logger, "smtp", r)
+	}
+	return reports
+}
+
+// smtpModuleLoggingMW implements Enabler.
+func (m *smtpModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// smtpModuleLoggingMW implements Validator. The validation is not logged.
+func (m *smtpModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Logging middleware at module level.
+type dockerModuleLoggingMW struct {
+	logger log.Logger
+	next   DockerModule
+}
+
+// MakeDockerModuleLoggingMW makes a logging middleware at module level.
+func MakeDockerModuleLoggingMW(logger log.Logger) func(DockerModule) DockerModule {
+	return func(next DockerModule) DockerModule {
+		return &dockerModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// dockerModuleLoggingMW implements Module.
+func (m *dockerModuleLoggingMW) HealthChecks(ctx context.Context) []DockerReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	var reports = m.next.HealthChecks(ctx)
+	for _, r := range reports {
+		logReport(ctx, m.logger, "docker", r)
+	}
+	return reports
+}
+
+// dockerModuleLoggingMW implements Enabler.
+func (m *dockerModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// logReport logs the result of a health check as discrete key/values, so the logs can be queried.
+func logReport(ctx context.Context, logger log.Logger, subsystem string, r Report) {
+	var keyvals = []interface{}{"unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "subsystem", subsystem, "check", r.Name, "status", r.Status.String()}
+
+	// The duration of a deactivated health check is "N/A".
+	if d, err := time.ParseDuration(r.Duration); err == nil {
+		keyvals = append(keyvals, "duration_ms", d.Seconds()*1000)
+	}
+	if r.Error != "" {
+		keyvals = append(keyvals, "error", r.Error)
+	}
+
+	logger.Log(keyvals...)
+}
+
+// Logging middleware at module level.
+type logsinkModuleLoggingMW struct {
+	logger log.Logger
+	next   LogSinkModule
+}
+
+// MakeLogSinkModuleLoggingMW makes a logging middleware at module level.
+func MakeLogSinkModuleLoggingMW(logger log.Logger) func(LogSinkModule) LogSinkModule {
+	return func(next LogSinkModule) LogSinkModule {
+		return &logsinkModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// logsinkModuleLoggingMW implements Module.
+func (m *logsinkModuleLoggingMW) HealthChecks(ctx context.Context) []LogSinkReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecks(ctx)
+}
+
+// logsinkModuleLoggingMW implements Enabler.
+func (m *logsinkModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// Logging middleware at module level.
+type e2eModuleLoggingMW struct {
+	logger log.Logger
+	next   E2EModule
+}
+
+// MakeE2EModuleLoggingMW makes a logging middleware at module level.
+func MakeE2EModuleLoggingMW(logger log.Logger) func(E2EModule) E2EModule {
+	return func(next E2EModule) E2EModule {
+		return &e2eModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// e2eModuleLoggingMW implements Module.
+func (m *e2eModuleLoggingMW) HealthChecks(ctx context.Context) []E2EReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecks(ctx)
+}
+
+// e2eModuleLoggingMW implements Enabler.
+func (m *e2eModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// Logging middleware at module level.
+type grpcModuleLoggingMW struct {
+	logger log.Logger
+	next   GRPCReflectionModule
+}
+
+// MakeGRPCReflectionModuleLoggingMW makes a logging middleware at module level.
+func MakeGRPCReflectionModuleLoggingMW(logger log.Logger) func(GRPCReflectionModule) GRPCReflectionModule {
+	return func(next GRPCReflectionModule) GRPCReflectionModule {
+		return &grpcModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// grpcModuleLoggingMW implements Module.
+func (m *grpcModuleLoggingMW) HealthChecks(ctx context.Context) []GRPCReflectionReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecks(ctx)
+}
+
+// grpcModuleLoggingMW implements Enabler.
+func (m *grpcModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// Logging middleware at module level.
+type egressModuleLoggingMW struct {
+	logger log.Logger
+	next   EgressModule
+}
+
+// MakeEgressModuleLoggingMW makes a logging middleware at module level.
+func MakeEgressModuleLoggingMW(logger log.Logger) func(EgressModule) EgressModule {
+	return func(next EgressModule) EgressModule {
+		return &egressModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// egressModuleLoggingMW implements Module.
+func (m *egressModuleLoggingMW) HealthChecks(ctx context.Context) []EgressReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecks(ctx)
+}
+
+// egressModuleLoggingMW implements Enabler.
+func (m *egressModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// egressModuleLoggingMW implements Validator. The validation is not logged.
+func (m *egressModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Logging middleware at module level.
+type fdModuleLoggingMW struct {
+	logger log.Logger
+	next   FDModule
+}
+
+// MakeFDModuleLoggingMW makes a logging middleware at module level.
+func MakeFDModuleLoggingMW(logger log.Logger) func(FDModule) FDModule {
+	return func(next FDModule) FDModule {
+		return &fdModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// fdModuleLoggingMW implements Module.
+func (m *fdModuleLoggingMW) HealthChecks(ctx context.Context) []FDReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecks(ctx)
+}
+
+// fdModuleLoggingMW implements Enabler.
+func (m *fdModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// fdModuleLoggingMW implements Validator. The validation is not logged.
+func (m *fdModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Logging middleware at module level.
+type idGenModuleLoggingMW struct {
+	logger log.Logger
+	next   IDGenModule
+}
+
+// MakeIDGenModuleLoggingMW makes a logging middleware at module level.
+func MakeIDGenModuleLoggingMW(logger log.Logger) func(IDGenModule) IDGenModule {
+	return func(next IDGenModule) IDGenModule {
+		return &idGenModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// idGenModuleLoggingMW implements Module.
+func (m *idGenModuleLoggingMW) HealthChecks(ctx context.Context) []IDGenReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecks(ctx)
+}
+
+// idGenModuleLoggingMW implements Enabler.
+func (m *idGenModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// idGenModuleLoggingMW implements Validator. The validation is not logged.
+func (m *idGenModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Logging middleware at module level.
+type featureFlagModuleLoggingMW struct {
+	logger log.Logger
+	next   FeatureFlagModule
+}
+
+// MakeFeatureFlagModuleLoggingMW makes a logging middleware at module level.
+func MakeFeatureFlagModuleLoggingMW(logger log.Logger) func(FeatureFlagModule) FeatureFlagModule {
+	return func(next FeatureFlagModule) FeatureFlagModule {
+		return &featureFlagModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// featureFlagModuleLoggingMW implements Module.
+func (m *featureFlagModuleLoggingMW) HealthChecks(ctx context.Context) []FeatureFlagReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
 	return m.next.HealthChecks(ctx)
 }
+
+// featureFlagModuleLoggingMW implements Enabler.
+func (m *featureFlagModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// featureFlagModuleLoggingMW implements Validator. The validation is not logged.
+func (m *featureFlagModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Logging middleware at module level.
+type replicaModuleLoggingMW struct {
+	logger log.Logger
+	next   ReplicaModule
+}
+
+// MakeReplicaModuleLoggingMW makes a logging middleware at module level.
+func MakeReplicaModuleLoggingMW(logger log.Logger) func(ReplicaModule) ReplicaModule {
+	return func(next ReplicaModule) ReplicaModule {
+		return &replicaModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// replicaModuleLoggingMW implements Module.
+func (m *replicaModuleLoggingMW) HealthChecks(ctx context.Context) []ReplicaReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecks(ctx)
+}
+
+// replicaModuleLoggingMW implements Enabler.
+func (m *replicaModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// replicaModuleLoggingMW implements Validator. The validation is not logged.
+func (m *replicaModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Logging middleware at module level.
+type commandModuleLoggingMW struct {
+	logger log.Logger
+	next   CommandModule
+}
+
+// MakeCommandModuleLoggingMW makes a logging middleware at module level.
+func MakeCommandModuleLoggingMW(logger log.Logger) func(CommandModule) CommandModule {
+	return func(next CommandModule) CommandModule {
+		return &commandModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// commandModuleLoggingMW implements Module.
+func (m *commandModuleLoggingMW) HealthChecks(ctx context.Context) []CommandReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecks(ctx)
+}
+
+// commandModuleLoggingMW implements Enabler.
+func (m *commandModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// commandModuleLoggingMW implements Validator. The validation is not logged.
+func (m *commandModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Logging middleware at module level.
+type selfModuleLoggingMW struct {
+	logger log.Logger
+	next   SelfModule
+}
+
+// MakeSelfModuleLoggingMW makes a logging middleware at module level.
+func MakeSelfModuleLoggingMW(logger log.Logger) func(SelfModule) SelfModule {
+	return func(next SelfModule) SelfModule {
+		return &selfModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// selfModuleLoggingMW implements Module.
+func (m *selfModuleLoggingMW) HealthChecks(ctx context.Context) []SelfReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecks(ctx)
+}
+
+// selfModuleLoggingMW implements Enabler.
+func (m *selfModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// selfModuleLoggingMW implements Validator. The validation is not logged.
+func (m *selfModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Logging middleware at module level.
+type auditLogModuleLoggingMW struct {
+	logger log.Logger
+	next   AuditLogModule
+}
+
+// MakeAuditLogModuleLoggingMW makes a logging middleware at module level.
+func MakeAuditLogModuleLoggingMW(logger log.Logger) func(AuditLogModule) AuditLogModule {
+	return func(next AuditLogModule) AuditLogModule {
+		return &auditLogModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// auditLogModuleLoggingMW implements Module.
+func (m *auditLogModuleLoggingMW) HealthChecks(ctx context.Context) []AuditLogReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecks(ctx)
+}
+
+// auditLogModuleLoggingMW implements Enabler.
+func (m *auditLogModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// auditLogModuleLoggingMW implements Validator. The validation is not logged.
+func (m *auditLogModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Logging middleware at module level.
+type compositeModuleLoggingMW struct {
+	logger log.Logger
+	next   CompositeModule
+}
+
+// MakeCompositeModuleLoggingMW makes a logging middleware at module level.
+func MakeCompositeModuleLoggingMW(logger log.Logger) func(CompositeModule) CompositeModule {
+	return func(next CompositeModule) CompositeModule {
+		return &compositeModuleLoggingMW{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+// compositeModuleLoggingMW implements Module.
+func (m *compositeModuleLoggingMW) HealthChecks(ctx context.Context) []CompositeReport {
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "HealthChecks", "correlation_id", ctx.Value("correlation_id").(string), "took", time.Since(begin))
+	}(time.Now())
+
+	return m.next.HealthChecks(ctx)
+}
+
+// compositeModuleLoggingMW implements Enabler.
+func (m *compositeModuleLoggingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// compositeModuleLoggingMW implements Module. The start is called at startup, outside of any request, so
+// there is no correlation ID.
+func (m *compositeModuleLoggingMW) Start(ctx context.Context) error {
+	var err error
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "Start", "error", err, "took", time.Since(begin))
+	}(time.Now())
+
+	err = m.next.Start(ctx)
+	return err
+}
+
+// compositeModuleLoggingMW implements Module. The stop is called at shutdown, outside of any request, so
+// there is no correlation ID.
+func (m *compositeModuleLoggingMW) Stop() error {
+	var err error
+	defer func(begin time.Time) {
+		m.logger.Log("unit", "Stop", "error", err, "took", time.Since(begin))
+	}(time.Now())
+
+	err = m.next.Stop()
+	return err
+}
+
+// compositeModuleLoggingMW implements Validator. The validation is not logged.
+func (m *compositeModuleLoggingMW) Validate() error {
+	return validate(m.next)
+}
@@ -111,6 +111,202 @@ func TestComponentLoggingMW(t *testing.T) {
 		assert.Panics(t, f)
 	}
 
+	// SMTPHealthChecks.
+	{
+		mockComponent.EXPECT().SMTPHealthChecks(ctx).Return(rep("smtp")).Times(1)
+		mockLogger.EXPECT().Log("unit", "SMTPHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.SMTPHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().SMTPHealthChecks(context.Background()).Return(rep("smtp")).Times(1)
+		var f = func() {
+			m.SMTPHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// DockerHealthChecks.
+	{
+		mockComponent.EXPECT().DockerHealthChecks(ctx).Return(rep("docker")).Times(1)
+		mockLogger.EXPECT().Log("unit", "DockerHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.DockerHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().DockerHealthChecks(context.Background()).Return(rep("docker")).Times(1)
+		var f = func() {
+			m.DockerHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// LogSinkHealthChecks.
+	{
+		mockComponent.EXPECT().LogSinkHealthChecks(ctx).Return(rep("logsink")).Times(1)
+		mockLogger.EXPECT().Log("unit", "LogSinkHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.LogSinkHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().LogSinkHealthChecks(context.Background()).Return(rep("logsink")).Times(1)
+		var f = func() {
+			m.LogSinkHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// E2EHealthChecks.
+	{
+		mockComponent.EXPECT().E2EHealthChecks(ctx).Return(rep("e2e")).Times(1)
+		mockLogger.EXPECT().Log("unit", "E2EHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.E2EHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().E2EHealthChecks(context.Background()).Return(rep("e2e")).Times(1)
+		var f = func() {
+			m.E2EHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// GRPCReflectionHealthChecks.
+	{
+		mockComponent.EXPECT().GRPCReflectionHealthChecks(ctx).Return(rep("grpc")).Times(1)
+		mockLogger.EXPECT().Log("unit", "GRPCReflectionHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.GRPCReflectionHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().GRPCReflectionHealthChecks(context.Background()).Return(rep("grpc")).Times(1)
+		var f = func() {
+			m.GRPCReflectionHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// EgressHealthChecks.
+	{
+		mockComponent.EXPECT().EgressHealthChecks(ctx).Return(rep("egress")).Times(1)
+		mockLogger.EXPECT().Log("unit", "EgressHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.EgressHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().EgressHealthChecks(context.Background()).Return(rep("egress")).Times(1)
+		var f = func() {
+			m.EgressHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// FDHealthChecks.
+	{
+		mockComponent.EXPECT().FDHealthChecks(ctx).Return(rep("fd")).Times(1)
+		mockLogger.EXPECT().Log("unit", "FDHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.FDHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().FDHealthChecks(context.Background()).Return(rep("fd")).Times(1)
+		var f = func() {
+			m.FDHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// IDGenHealthChecks.
+	{
+		mockComponent.EXPECT().IDGenHealthChecks(ctx).Return(rep("idgen")).Times(1)
+		mockLogger.EXPECT().Log("unit", "IDGenHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.IDGenHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().IDGenHealthChecks(context.Background()).Return(rep("idgen")).Times(1)
+		var f = func() {
+			m.IDGenHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// FeatureFlagHealthChecks.
+	{
+		mockComponent.EXPECT().FeatureFlagHealthChecks(ctx).Return(rep("featureflag")).Times(1)
+		mockLogger.EXPECT().Log("unit", "FeatureFlagHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.FeatureFlagHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().FeatureFlagHealthChecks(context.Background()).Return(rep("featureflag")).Times(1)
+		var f = func() {
+			m.FeatureFlagHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// ReplicaHealthChecks.
+	{
+		mockComponent.EXPECT().ReplicaHealthChecks(ctx).Return(rep("replica")).Times(1)
+		mockLogger.EXPECT().Log("unit", "ReplicaHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.ReplicaHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().ReplicaHealthChecks(context.Background()).Return(rep("replica")).Times(1)
+		var f = func() {
+			m.ReplicaHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// CommandHealthChecks.
+	{
+		mockComponent.EXPECT().CommandHealthChecks(ctx).Return(rep("command")).Times(1)
+		mockLogger.EXPECT().Log("unit", "CommandHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.CommandHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().CommandHealthChecks(context.Background()).Return(rep("command")).Times(1)
+		var f = func() {
+			m.CommandHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// SelfHealthChecks.
+	{
+		mockComponent.EXPECT().SelfHealthChecks(ctx).Return(rep("self")).Times(1)
+		mockLogger.EXPECT().Log("unit", "SelfHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.SelfHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().SelfHealthChecks(context.Background()).Return(rep("self")).Times(1)
+		var f = func() {
+			m.SelfHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// AuditLogHealthChecks.
+	{
+		mockComponent.EXPECT().AuditLogHealthChecks(ctx).Return(rep("auditlog")).Times(1)
+		mockLogger.EXPECT().Log("unit", "AuditLogHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.AuditLogHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().AuditLogHealthChecks(context.Background()).Return(rep("auditlog")).Times(1)
+		var f = func() {
+			m.AuditLogHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
+	// CompositeHealthChecks.
+	{
+		mockComponent.EXPECT().CompositeHealthChecks(ctx).Return(rep("composite")).Times(1)
+		mockLogger.EXPECT().Log("unit", "CompositeHealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.CompositeHealthChecks(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().CompositeHealthChecks(context.Background()).Return(rep("composite")).Times(1)
+		var f = func() {
+			m.CompositeHealthChecks(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+
 	// AllHealthChecks.
 	{
 		var reply = map[string]string{"influx": "OK", "jaeger": "OK", "redis": "OK", "sentry": "OK"}
@@ -125,6 +321,72 @@ func TestComponentLoggingMW(t *testing.T) {
 		}
 		assert.Panics(t, f)
 	}
+
+	// AllHealthChecksDetailed.
+	{
+		var reply = DetailedReport{Status: OK, Subsystems: map[string]SubsystemReport{"influx": {Status: OK}}}
+		mockComponent.EXPECT().AllHealthChecksDetailed(ctx).Return(reply).Times(1)
+		mockLogger.EXPECT().Log("unit", "AllHealthChecksDetailed", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+		m.AllHealthChecksDetailed(ctx)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().AllHealthChecksDetailed(context.Background()).Return(reply).Times(1)
+		var f = func() {
+			m.AllHealthChecksDetailed(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+	// OverallStatusWithReason.
+	{
+		mockComponent.EXPECT().OverallStatusWithReason(ctx).Return(Degraded, "redis: ping Degraded").Times(1)
+		mockLogger.EXPECT().Log("unit", "OverallStatusWithReason", "correlation_id", corrID, "status", "Degraded", "reason", "redis: ping Degraded", "took", gomock.Any()).Return(nil).Times(1)
+		var s, reason = m.OverallStatusWithReason(ctx)
+		assert.Equal(t, Degraded, s)
+		assert.Equal(t, "redis: ping Degraded", reason)
+
+		// Without correlation ID.
+		mockComponent.EXPECT().OverallStatusWithReason(context.Background()).Return(OK, "").Times(1)
+		var f = func() {
+			m.OverallStatusWithReason(context.Background())
+		}
+		assert.Panics(t, f)
+	}
+}
+
+func TestComponentLoggingMWWaitUntilReady(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var m = MakeComponentLoggingMW(mockLogger)(mockComponent)
+
+	// There is no correlation ID at startup.
+	mockComponent.EXPECT().WaitUntilReady(context.Background(), time.Second).Return(Degraded).Times(1)
+	mockLogger.EXPECT().Log("unit", "WaitUntilReady", "status", "Degraded", "took", gomock.Any()).Return(nil).Times(1)
+	assert.Equal(t, Degraded, m.WaitUntilReady(context.Background(), time.Second))
+}
+
+func TestComponentLoggingMWToggles(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var m = MakeComponentLoggingMW(mockLogger)(mockComponent)
+
+	// There is no correlation ID for the operator actions.
+	mockComponent.EXPECT().Disable("sentry").Return(nil).Times(1)
+	mockLogger.EXPECT().Log("unit", "Disable", "subsystem", "sentry", "error", nil).Return(nil).Times(1)
+	assert.Nil(t, m.Disable("sentry"))
+
+	mockComponent.EXPECT().Enable("sentry").Return(nil).Times(1)
+	mockLogger.EXPECT().Log("unit", "Enable", "subsystem", "sentry", "error", nil).Return(nil).Times(1)
+	assert.Nil(t, m.Enable("sentry"))
+
+	mockComponent.EXPECT().BeginShutdown().Times(1)
+	mockLogger.EXPECT().Log("unit", "BeginShutdown").Return(nil).Times(1)
+	m.BeginShutdown()
 }
 
 func TestInfluxModuleLoggingMW(t *testing.T) {
@@ -139,10 +401,11 @@ func TestInfluxModuleLoggingMW(t *testing.T) {
 	rand.Seed(time.Now().UnixNano())
 	var corrID = strconv.FormatUint(rand.Uint64(), 10)
 	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
-	var rep = []InfluxReport{{Name: "influx", Duration: (1 * time.Second).String(), Status: OK}}
+	var rep = []InfluxReport{{Report: Report{Name: "influx", Duration: (1 * time.Second).String(), Status: OK}}}
 
 	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
 	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "subsystem", "influx", "check", "influx", "status", "OK", "duration_ms", float64(1000)).Return(nil).Times(1)
 	m.HealthChecks(ctx)
 
 	// Without correlation ID.
@@ -169,6 +432,7 @@ func TestJaegerModuleLoggingMW(t *testing.T) {
 
 	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
 	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "subsystem", "jaeger", "check", "jaeger", "status", "OK", "duration_ms", float64(1000)).Return(nil).Times(1)
 	m.HealthChecks(ctx)
 
 	// Without correlation ID.
@@ -191,10 +455,11 @@ func TestRedisModuleLoggingMW(t *testing.T) {
 	rand.Seed(time.Now().UnixNano())
 	var corrID = strconv.FormatUint(rand.Uint64(), 10)
 	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
-	var rep = []RedisReport{{Name: "redis", Duration: (1 * time.Second).String(), Status: OK}}
+	var rep = []RedisReport{{Report: Report{Name: "redis", Duration: (1 * time.Second).String(), Status: OK}}}
 
 	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
 	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "subsystem", "redis", "check", "redis", "status", "OK", "duration_ms", float64(1000)).Return(nil).Times(1)
 	m.HealthChecks(ctx)
 
 	// Without correlation ID.
@@ -219,6 +484,395 @@ func TestSentryModuleLoggingMW(t *testing.T) {
 	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
 	var rep = []SentryReport{{Name: "sentry", Duration: (1 * time.Second).String(), Status: OK}}
 
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "subsystem", "sentry", "check", "sentry", "status", "OK", "duration_ms", float64(1000)).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestSMTPModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewSMTPModule(mockCtrl)
+
+	var m = MakeSMTPModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []SMTPReport{{Name: "smtp", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "subsystem", "smtp", "check", "smtp", "status", "OK", "duration_ms", float64(1000)).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestDockerModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewDockerModule(mockCtrl)
+
+	var m = MakeDockerModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []DockerReport{{Name: "docker", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "subsystem", "docker", "check", "docker", "status", "OK", "duration_ms", float64(1000)).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestModuleLoggingMWReportFields(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewRedisModule(mockCtrl)
+
+	var m = MakeRedisModuleLoggingMW(mockLogger)(mockModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []RedisReport{
+		{Report: Report{Name: "ping shard 0", Duration: (500 * time.Millisecond).String(), Status: KO, Error: "fail"}},
+		{Report: Report{Name: "ping shard 1", Duration: "N/A", Status: Deactivated}},
+	}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "subsystem", "redis", "check", "ping shard 0", "status", "KO", "duration_ms", float64(500), "error", "fail").Return(nil).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "subsystem", "redis", "check", "ping shard 1", "status", "Deactivated").Return(nil).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestLogSinkModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewLogSinkModule(mockCtrl)
+
+	var m = MakeLogSinkModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []LogSinkReport{{Name: "logsink", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestE2EModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewE2EModule(mockCtrl)
+
+	var m = MakeE2EModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []E2EReport{{Name: "e2e", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestGRPCReflectionModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewGRPCReflectionModule(mockCtrl)
+
+	var m = MakeGRPCReflectionModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []GRPCReflectionReport{{Name: "grpc", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestEgressModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewEgressModule(mockCtrl)
+
+	var m = MakeEgressModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []EgressReport{{Name: "egress", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestFDModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewFDModule(mockCtrl)
+
+	var m = MakeFDModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []FDReport{{Name: "fd", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestIDGenModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewIDGenModule(mockCtrl)
+
+	var m = MakeIDGenModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []IDGenReport{{Name: "idgen", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestFeatureFlagModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewFeatureFlagModule(mockCtrl)
+
+	var m = MakeFeatureFlagModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []FeatureFlagReport{{Name: "featureflag", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestReplicaModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewReplicaModule(mockCtrl)
+
+	var m = MakeReplicaModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []ReplicaReport{{Name: "replica", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestCommandModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewCommandModule(mockCtrl)
+
+	var m = MakeCommandModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []CommandReport{{Name: "command", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestSelfModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewSelfModule(mockCtrl)
+
+	var m = MakeSelfModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []SelfReport{{Name: "self", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestAuditLogModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewAuditLogModule(mockCtrl)
+
+	var m = MakeAuditLogModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []AuditLogReport{{Name: "auditlog", Duration: (1 * time.Second).String(), Status: OK}}
+
+	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
+	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
+	m.HealthChecks(ctx)
+
+	// Without correlation ID.
+	mockModule.EXPECT().HealthChecks(context.Background()).Return(rep).Times(1)
+	var f = func() {
+		m.HealthChecks(context.Background())
+	}
+	assert.Panics(t, f)
+}
+
+func TestCompositeModuleLoggingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogger = mock.NewLogger(mockCtrl)
+	var mockModule = mock.NewCompositeModule(mockCtrl)
+
+	var m = MakeCompositeModuleLoggingMW(mockLogger)(mockModule)
+
+	// Context with correlation ID.
+	rand.Seed(time.Now().UnixNano())
+	var corrID = strconv.FormatUint(rand.Uint64(), 10)
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+	var rep = []CompositeReport{{Name: "composite", Duration: (1 * time.Second).String(), Status: OK}}
+
 	mockModule.EXPECT().HealthChecks(ctx).Return(rep).Times(1)
 	mockLogger.EXPECT().Log("unit", "HealthChecks", "correlation_id", corrID, "took", gomock.Any()).Return(nil).Times(1)
 	m.HealthChecks(ctx)
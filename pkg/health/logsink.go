@@ -0,0 +1,116 @@
+package health
+
+//go:generate mockgen -destination=./mock/logsink.go -package=mock -mock_names=LogSinkModule=LogSinkModule  github.com/cloudtrust/flaki-service/pkg/health LogSinkModule
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// LogSinkModule is the health check module for the log output.
+type LogSinkModule interface {
+	HealthChecks(context.Context) []LogSinkReport
+}
+
+type logSinkModule struct {
+	logger  log.Logger
+	enabled bool
+}
+
+// LogSinkReport is the health report returned by the log sink module.
+type LogSinkReport = Report
+
+// NewLogSinkModule returns the log sink health module. The writer is the log output, e.g. os.Stdout
+// or a log file.
+func NewLogSinkModule(writer io.Writer, enabled bool) LogSinkModule {
+	return &logSinkModule{
+		logger:  log.NewJSONLogger(writer),
+		enabled: enabled,
+	}
+}
+
+// HealthChecks executes all health checks for the log output.
+func (m *logSinkModule) HealthChecks(ctx context.Context) []LogSinkReport {
+	var reports = []LogSinkReport{}
+	reports = append(reports, m.logSinkWriteCheck(ctx))
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *logSinkModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *logSinkModule) logSinkWriteCheck(ctx context.Context) LogSinkReport {
+	var healthCheckName = "write"
+	var healthCheckDescription = "Writes a log line to the log output. If it fails, the logs are lost. Degraded means the disk of the log output is full."
+
+	if !m.enabled {
+		return LogSinkReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var err = writeLogSink(ctx, m.logger)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil && isDiskFull(err):
+		error = fmt.Sprintf("log output disk is full: %v", err.Error())
+		s = Degraded
+		category = Capacity
+	case err != nil:
+		error = fmt.Sprintf("could not write to log output: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return LogSinkReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// writeLogSink writes a log line, within the context deadline. A write that blocks past the deadline
+// is reported as an error, but it is not interrupted.
+func writeLogSink(ctx context.Context, logger log.Logger) error {
+	var errc = make(chan error, 1)
+	go func() {
+		errc <- logger.Log("msg", "log output health check")
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isDiskFull returns true if err is caused by a full disk.
+func isDiskFull(err error) bool {
+	if pe, ok := err.(*os.PathError); ok {
+		err = pe.Err
+	}
+	return err == syscall.ENOSPC
+}
@@ -0,0 +1,93 @@
+package health_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+type errWriter struct {
+	err error
+}
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+type blockingWriter struct {
+	c chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.c
+	return len(p), nil
+}
+
+func TestLogSinkHealthChecks(t *testing.T) {
+	var buf = &bytes.Buffer{}
+	var m = NewLogSinkModule(buf, true)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "write", report.Name)
+	assert.NotZero(t, report.Description)
+	assert.NotZero(t, report.Duration)
+	assert.Equal(t, OK, report.Status)
+	assert.Zero(t, report.Error)
+	assert.Equal(t, 1, report.Attempts)
+
+	// The log line is in the format of the service logs.
+	var line = map[string]string{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "log output health check", line["msg"])
+}
+
+func TestLogSinkHealthChecksFail(t *testing.T) {
+	var m = NewLogSinkModule(&errWriter{err: fmt.Errorf("fail")}, true)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "write", report.Name)
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, "could not write to log output: fail", report.Error)
+}
+
+func TestLogSinkHealthChecksDiskFull(t *testing.T) {
+	var m = NewLogSinkModule(&errWriter{err: &os.PathError{Op: "write", Path: "/var/log/flakid.log", Err: syscall.ENOSPC}}, true)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "write", report.Name)
+	assert.Equal(t, Degraded, report.Status)
+	assert.Contains(t, report.Error, "log output disk is full")
+	assert.Equal(t, Capacity, report.Category)
+}
+
+func TestLogSinkHealthChecksTimeout(t *testing.T) {
+	var w = &blockingWriter{c: make(chan struct{})}
+	defer close(w.c)
+	var m = NewLogSinkModule(w, true)
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var report = m.HealthChecks(ctx)[0]
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, "could not write to log output: context deadline exceeded", report.Error)
+}
+
+func TestNoopLogSinkHealthChecks(t *testing.T) {
+	var m = NewLogSinkModule(&errWriter{err: fmt.Errorf("fail")}, false)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "write", report.Name)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+	assert.Zero(t, report.Attempts)
+}
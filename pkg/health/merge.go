@@ -0,0 +1,381 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CollisionPolicy tells how MergeComponents handles a subsystem configured in several components.
+type CollisionPolicy int
+
+const (
+	// RejectCollisions fails the merge when a subsystem is configured in several components.
+	RejectCollisions CollisionPolicy = iota
+	// FirstWins checks the subsystem with the first component, in the order of the merge, that configures
+	// it. It is ignored in the other components.
+	FirstWins
+)
+
+// mergedComponent is the Component that delegates each subsystem to the component that owns it.
+type mergedComponent struct {
+	components []Component
+	owners     map[string]Component
+
+	mutex        sync.Mutex
+	shuttingDown bool
+}
+
+// MergeComponents returns a Component that combines components, e.g. the components of several teams
+// sharing a service, so its health checks cover the subsystems of all of them. Each subsystem is checked
+// by the component that configures it, see Component.Subsystems, and the subsystems configured nowhere by
+// the first component. policy tells how a subsystem configured in several components is handled.
+//
+// The overall status is the worst overall status of the components, Deactivated only if all of them are
+// Deactivated. Each component keeps its own options, e.g. its required subsystems and its aggregator, and
+// the errors of the merged detailed report are not grouped.
+func MergeComponents(policy CollisionPolicy, components ...Component) (Component, error) {
+	if len(components) == 0 {
+		return nil, fmt.Errorf("no component to merge")
+	}
+
+	var owners = map[string]Component{}
+	for i, c := range components {
+		for _, name := range c.Subsystems() {
+			if _, ok := owners[name]; !ok {
+				owners[name] = c
+				continue
+			}
+			if policy == RejectCollisions {
+				return nil, fmt.Errorf("subsystem '%s' of component %d is already configured in another component", name, i)
+			}
+		}
+	}
+	for _, name := range subsystems {
+		if _, ok := owners[name]; !ok {
+			owners[name] = components[0]
+		}
+	}
+
+	return &mergedComponent{
+		components: components,
+		owners:     owners,
+	}, nil
+}
+
+// InfluxHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) InfluxHealthChecks(ctx context.Context) Reports {
+	return m.owners["influx"].InfluxHealthChecks(ctx)
+}
+
+// JaegerHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) JaegerHealthChecks(ctx context.Context) Reports {
+	return m.owners["jaeger"].JaegerHealthChecks(ctx)
+}
+
+// RedisHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) RedisHealthChecks(ctx context.Context) Reports {
+	return m.owners["redis"].RedisHealthChecks(ctx)
+}
+
+// SentryHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) SentryHealthChecks(ctx context.Context) Reports {
+	return m.owners["sentry"].SentryHealthChecks(ctx)
+}
+
+// SMTPHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) SMTPHealthChecks(ctx context.Context) Reports {
+	return m.owners["smtp"].SMTPHealthChecks(ctx)
+}
+
+// DockerHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) DockerHealthChecks(ctx context.Context) Reports {
+	return m.owners["docker"].DockerHealthChecks(ctx)
+}
+
+// LogSinkHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) LogSinkHealthChecks(ctx context.Context) Reports {
+	return m.owners["logsink"].LogSinkHealthChecks(ctx)
+}
+
+// E2EHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) E2EHealthChecks(ctx context.Context) Reports {
+	return m.owners["e2e"].E2EHealthChecks(ctx)
+}
+
+// GRPCReflectionHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) GRPCReflectionHealthChecks(ctx context.Context) Reports {
+	return m.owners["grpc"].GRPCReflectionHealthChecks(ctx)
+}
+
+// EgressHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) EgressHealthChecks(ctx context.Context) Reports {
+	return m.owners["egress"].EgressHealthChecks(ctx)
+}
+
+// FDHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) FDHealthChecks(ctx context.Context) Reports {
+	return m.owners["fd"].FDHealthChecks(ctx)
+}
+
+// IDGenHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) IDGenHealthChecks(ctx context.Context) Reports {
+	return m.owners["idgen"].IDGenHealthChecks(ctx)
+}
+
+// FeatureFlagHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) FeatureFlagHealthChecks(ctx context.Context) Reports {
+	return m.owners["featureflag"].FeatureFlagHealthChecks(ctx)
+}
+
+// ReplicaHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) ReplicaHealthChecks(ctx context.Context) Reports {
+	return m.owners["replica"].ReplicaHealthChecks(ctx)
+}
+
+// CommandHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) CommandHealthChecks(ctx context.Context) Reports {
+	return m.owners["command"].CommandHealthChecks(ctx)
+}
+
+// SelfHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) SelfHealthChecks(ctx context.Context) Reports {
+	return m.owners["self"].SelfHealthChecks(ctx)
+}
+
+// AuditLogHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) AuditLogHealthChecks(ctx context.Context) Reports {
+	return m.owners["auditlog"].AuditLogHealthChecks(ctx)
+}
+
+// CompositeHealthChecks uses the component that owns the subsystem.
+func (m *mergedComponent) CompositeHealthChecks(ctx context.Context) Reports {
+	return m.owners["composite"].CompositeHealthChecks(ctx)
+}
+
+// AllHealthChecks executes the health checks of all subsystems, each with the component that owns it.
+func (m *mergedComponent) AllHealthChecks(ctx context.Context) map[string]string {
+	var statuses = map[string]string{}
+	for name, r := range m.HealthChecksDetailed(ctx, subsystems...).Subsystems {
+		statuses[name] = r.Status.String()
+	}
+	return statuses
+}
+
+// AllHealthChecksDetailed executes the health checks of all subsystems, each with the component that owns
+// it, and merges the detailed reports.
+func (m *mergedComponent) AllHealthChecksDetailed(ctx context.Context) DetailedReport {
+	return m.HealthChecksDetailed(ctx, subsystems...)
+}
+
+// HealthChecksDetailed executes the health checks of the given subsystems, each with the component that
+// owns it. The components are executed concurrently, and their detailed reports merged: each subsystem is
+// taken from the report of its owner.
+func (m *mergedComponent) HealthChecksDetailed(ctx context.Context, names ...string) DetailedReport {
+	var now = time.Now()
+
+	var owned = map[Component][]string{}
+	for _, name := range names {
+		if c, ok := m.owners[name]; ok {
+			owned[c] = append(owned[c], name)
+		}
+	}
+
+	var details = make([]DetailedReport, len(m.components))
+	var wg sync.WaitGroup
+	for i, c := range m.components {
+		if len(owned[c]) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, c Component) {
+			defer wg.Done()
+			details[i] = c.HealthChecksDetailed(ctx, owned[c]...)
+		}(i, c)
+	}
+	wg.Wait()
+
+	var statuses = []Status{}
+	var reports = map[string]Reports{}
+	var merged = map[string]SubsystemReport{}
+	for i, c := range m.components {
+		if len(owned[c]) == 0 {
+			continue
+		}
+		statuses = append(statuses, details[i].Status)
+		for name, r := range details[i].Subsystems {
+			if m.owners[name] != c {
+				continue
+			}
+			merged[name] = r
+			reports[name] = Reports{Reports: r.Reports}
+		}
+	}
+
+	var errs = reportErrors(reports)
+	if m.isShuttingDown() {
+		errs = append([]string{"shutting down"}, errs...)
+	}
+
+	return DetailedReport{
+		Status:        worstStatus(statuses),
+		Subsystems:    merged,
+		Errors:        errs,
+		TotalDuration: time.Since(now),
+	}
+}
+
+// OverallStatusWithReason returns the worst overall status of the components, with their reasons.
+func (m *mergedComponent) OverallStatusWithReason(ctx context.Context) (Status, string) {
+	var statuses = make([]Status, len(m.components))
+	var reasons = make([]string, len(m.components))
+	var wg sync.WaitGroup
+	for i, c := range m.components {
+		wg.Add(1)
+		go func(i int, c Component) {
+			defer wg.Done()
+			statuses[i], reasons[i] = c.OverallStatusWithReason(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	return worstStatus(statuses), joinReasons(reasons)
+}
+
+// WaitUntilReady waits until all the components are ready, and returns the worst of their overall status.
+func (m *mergedComponent) WaitUntilReady(ctx context.Context, pollInterval time.Duration) Status {
+	var statuses = make([]Status, len(m.components))
+	var wg sync.WaitGroup
+	for i, c := range m.components {
+		wg.Add(1)
+		go func(i int, c Component) {
+			defer wg.Done()
+			statuses[i] = c.WaitUntilReady(ctx, pollInterval)
+		}(i, c)
+	}
+	wg.Wait()
+
+	return worstStatus(statuses)
+}
+
+// Enable enables the subsystem in the component that owns it.
+func (m *mergedComponent) Enable(subsystem string) error {
+	var c, ok = m.owners[subsystem]
+	if !ok {
+		return fmt.Errorf("unknown subsystem '%s'", subsystem)
+	}
+	return c.Enable(subsystem)
+}
+
+// Disable disables the subsystem in the component that owns it.
+func (m *mergedComponent) Disable(subsystem string) error {
+	var c, ok = m.owners[subsystem]
+	if !ok {
+		return fmt.Errorf("unknown subsystem '%s'", subsystem)
+	}
+	return c.Disable(subsystem)
+}
+
+// BeginShutdown marks all the components as shutting down.
+func (m *mergedComponent) BeginShutdown() {
+	m.mutex.Lock()
+	m.shuttingDown = true
+	m.mutex.Unlock()
+
+	for _, c := range m.components {
+		c.BeginShutdown()
+	}
+}
+
+// Reset resets all the components.
+func (m *mergedComponent) Reset() {
+	for _, c := range m.components {
+		c.Reset()
+	}
+}
+
+// UptimePercent returns the uptime of the subsystem in the component that owns it.
+func (m *mergedComponent) UptimePercent(subsystem string, window time.Duration) (float64, error) {
+	var c, ok = m.owners[subsystem]
+	if !ok {
+		return 0, fmt.Errorf("unknown subsystem '%s'", subsystem)
+	}
+	return c.UptimePercent(subsystem, window)
+}
+
+// Options returns the options of the first component, with the configuration of each module taken from
+// the component that owns it.
+func (m *mergedComponent) Options() Options {
+	var o = m.components[0].Options()
+
+	var modules = map[string]interface{}{}
+	for _, c := range m.components {
+		var opts = c.Options()
+		for name, mo := range opts.Modules {
+			if m.owners[name] == c {
+				modules[name] = mo
+			}
+		}
+	}
+	o.Modules = modules
+	return o
+}
+
+// Subsystems returns the subsystems configured in at least one of the components.
+func (m *mergedComponent) Subsystems() []string {
+	var configured = map[string]bool{}
+	for _, c := range m.components {
+		for _, name := range c.Subsystems() {
+			configured[name] = true
+		}
+	}
+
+	var names = []string{}
+	for _, name := range subsystems {
+		if configured[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isShuttingDown returns true once BeginShutdown is called.
+func (m *mergedComponent) isShuttingDown() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.shuttingDown
+}
+
+// worstStatus returns the worst of the statuses: KO, then Degraded, then OK. The Deactivated statuses are
+// ignored, unless all of them are Deactivated.
+func worstStatus(statuses []Status) Status {
+	var worst = Deactivated
+	for _, s := range statuses {
+		switch {
+		case s == KO:
+			return KO
+		case s == Degraded:
+			worst = Degraded
+		case s == OK && worst == Deactivated:
+			worst = OK
+		}
+	}
+	return worst
+}
+
+// joinReasons joins the non empty reasons with "; ".
+func joinReasons(reasons []string) string {
+	var reason string
+	for _, r := range reasons {
+		switch {
+		case r == "":
+		case reason == "":
+			reason = r
+		default:
+			reason = reason + "; " + r
+		}
+	}
+	return reason
+}
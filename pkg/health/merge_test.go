@@ -0,0 +1,118 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeComponents(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var redis, err = NewComponent(nil, nil, mockRedisModule, nil)
+	assert.Nil(t, err)
+	var sentry Component
+	sentry, err = NewComponent(nil, nil, nil, mockSentryModule)
+	assert.Nil(t, err)
+
+	var c Component
+	c, err = MergeComponents(RejectCollisions, redis, sentry)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"redis", "sentry"}, c.Subsystems())
+
+	// Each subsystem is checked by the component that configures it.
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: KO, Error: "fail"}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: OK}}).Times(1)
+	var report = c.AllHealthChecksDetailed(context.Background())
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, KO, report.Subsystems["redis"].Status)
+	assert.Equal(t, OK, report.Subsystems["sentry"].Status)
+	assert.Equal(t, "not configured", report.Subsystems["influx"].Reports[0].Name)
+	assert.Equal(t, []string{"redis/ping: fail"}, report.Errors)
+
+	// The statuses of all subsystems.
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: KO, Error: "fail"}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: OK}}).Times(1)
+	var statuses = c.AllHealthChecks(context.Background())
+	assert.Equal(t, "OK", statuses["sentry"])
+	assert.Equal(t, "KO", statuses["redis"])
+
+	// The toggles are forwarded to the owner.
+	assert.Nil(t, c.Disable("redis"))
+	assert.Equal(t, Deactivated, c.RedisHealthChecks(context.Background()).Reports[0].Status)
+	assert.Nil(t, c.Enable("redis"))
+	assert.NotNil(t, c.Enable("unknown"))
+}
+
+func TestMergeComponentsCollisions(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockFirstRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSecondRedisModule = mock.NewRedisModule(mockCtrl)
+
+	var first, err = NewComponent(nil, nil, mockFirstRedisModule, nil)
+	assert.Nil(t, err)
+	var second Component
+	second, err = NewComponent(nil, nil, mockSecondRedisModule, nil)
+	assert.Nil(t, err)
+
+	// Rejected.
+	{
+		var _, err = MergeComponents(RejectCollisions, first, second)
+		assert.NotNil(t, err)
+	}
+
+	// The first component wins, the second is not executed.
+	{
+		var c, err = MergeComponents(FirstWins, first, second)
+		assert.Nil(t, err)
+		mockFirstRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: Degraded}}}).Times(1)
+		var report = c.AllHealthChecksDetailed(context.Background())
+		assert.Equal(t, Degraded, report.Subsystems["redis"].Status)
+		assert.Equal(t, []string{"redis"}, c.Subsystems())
+	}
+
+	// No component.
+	{
+		var _, err = MergeComponents(FirstWins)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestMergeComponentsOverallStatus(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+
+	var redis, err = NewComponent(nil, nil, mockRedisModule, nil)
+	assert.Nil(t, err)
+	var sentry Component
+	sentry, err = NewComponent(nil, nil, nil, mockSentryModule)
+	assert.Nil(t, err)
+	var c Component
+	c, err = MergeComponents(RejectCollisions, redis, sentry)
+	assert.Nil(t, err)
+
+	// The worst status, with the reasons of all components.
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: Degraded}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: Deactivated}}).Times(1)
+	var s, reason = c.OverallStatusWithReason(context.Background())
+	assert.Equal(t, Degraded, s)
+	assert.Equal(t, "redis: ping Degraded", reason)
+
+	// The shutdown is forwarded.
+	c.BeginShutdown()
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "ping", Status: OK}}}).Times(1)
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "ping", Status: OK}}).Times(1)
+	var report = c.AllHealthChecksDetailed(context.Background())
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, []string{"shutting down"}, report.Errors)
+}
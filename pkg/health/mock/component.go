@@ -9,6 +9,7 @@ import (
 	health "github.com/cloudtrust/flaki-service/pkg/health"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // Component is a mock of Component interface
@@ -46,6 +47,189 @@ func (mr *ComponentMockRecorder) AllHealthChecks(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllHealthChecks", reflect.TypeOf((*Component)(nil).AllHealthChecks), arg0)
 }
 
+// AllHealthChecksDetailed mocks base method
+func (m *Component) AllHealthChecksDetailed(arg0 context.Context) health.DetailedReport {
+	ret := m.ctrl.Call(m, "AllHealthChecksDetailed", arg0)
+	ret0, _ := ret[0].(health.DetailedReport)
+	return ret0
+}
+
+// AllHealthChecksDetailed indicates an expected call of AllHealthChecksDetailed
+func (mr *ComponentMockRecorder) AllHealthChecksDetailed(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllHealthChecksDetailed", reflect.TypeOf((*Component)(nil).AllHealthChecksDetailed), arg0)
+}
+
+// AuditLogHealthChecks mocks base method
+func (m *Component) AuditLogHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "AuditLogHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// AuditLogHealthChecks indicates an expected call of AuditLogHealthChecks
+func (mr *ComponentMockRecorder) AuditLogHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLogHealthChecks", reflect.TypeOf((*Component)(nil).AuditLogHealthChecks), arg0)
+}
+
+// BeginShutdown mocks base method
+func (m *Component) BeginShutdown() {
+	m.ctrl.Call(m, "BeginShutdown")
+}
+
+// BeginShutdown indicates an expected call of BeginShutdown
+func (mr *ComponentMockRecorder) BeginShutdown() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginShutdown", reflect.TypeOf((*Component)(nil).BeginShutdown))
+}
+
+// CommandHealthChecks mocks base method
+func (m *Component) CommandHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "CommandHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// CommandHealthChecks indicates an expected call of CommandHealthChecks
+func (mr *ComponentMockRecorder) CommandHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommandHealthChecks", reflect.TypeOf((*Component)(nil).CommandHealthChecks), arg0)
+}
+
+// CompositeHealthChecks mocks base method
+func (m *Component) CompositeHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "CompositeHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// CompositeHealthChecks indicates an expected call of CompositeHealthChecks
+func (mr *ComponentMockRecorder) CompositeHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompositeHealthChecks", reflect.TypeOf((*Component)(nil).CompositeHealthChecks), arg0)
+}
+
+// Disable mocks base method
+func (m *Component) Disable(arg0 string) error {
+	ret := m.ctrl.Call(m, "Disable", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable
+func (mr *ComponentMockRecorder) Disable(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*Component)(nil).Disable), arg0)
+}
+
+// DockerHealthChecks mocks base method
+func (m *Component) DockerHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "DockerHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// DockerHealthChecks indicates an expected call of DockerHealthChecks
+func (mr *ComponentMockRecorder) DockerHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DockerHealthChecks", reflect.TypeOf((*Component)(nil).DockerHealthChecks), arg0)
+}
+
+// E2EHealthChecks mocks base method
+func (m *Component) E2EHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "E2EHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// E2EHealthChecks indicates an expected call of E2EHealthChecks
+func (mr *ComponentMockRecorder) E2EHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "E2EHealthChecks", reflect.TypeOf((*Component)(nil).E2EHealthChecks), arg0)
+}
+
+// EgressHealthChecks mocks base method
+func (m *Component) EgressHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "EgressHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// EgressHealthChecks indicates an expected call of EgressHealthChecks
+func (mr *ComponentMockRecorder) EgressHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EgressHealthChecks", reflect.TypeOf((*Component)(nil).EgressHealthChecks), arg0)
+}
+
+// Enable mocks base method
+func (m *Component) Enable(arg0 string) error {
+	ret := m.ctrl.Call(m, "Enable", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable
+func (mr *ComponentMockRecorder) Enable(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*Component)(nil).Enable), arg0)
+}
+
+// FDHealthChecks mocks base method
+func (m *Component) FDHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "FDHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// FDHealthChecks indicates an expected call of FDHealthChecks
+func (mr *ComponentMockRecorder) FDHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FDHealthChecks", reflect.TypeOf((*Component)(nil).FDHealthChecks), arg0)
+}
+
+// FeatureFlagHealthChecks mocks base method
+func (m *Component) FeatureFlagHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "FeatureFlagHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// FeatureFlagHealthChecks indicates an expected call of FeatureFlagHealthChecks
+func (mr *ComponentMockRecorder) FeatureFlagHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeatureFlagHealthChecks", reflect.TypeOf((*Component)(nil).FeatureFlagHealthChecks), arg0)
+}
+
+// GRPCReflectionHealthChecks mocks base method
+func (m *Component) GRPCReflectionHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "GRPCReflectionHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// GRPCReflectionHealthChecks indicates an expected call of GRPCReflectionHealthChecks
+func (mr *ComponentMockRecorder) GRPCReflectionHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GRPCReflectionHealthChecks", reflect.TypeOf((*Component)(nil).GRPCReflectionHealthChecks), arg0)
+}
+
+// HealthChecksDetailed mocks base method
+func (m *Component) HealthChecksDetailed(arg0 context.Context, arg1 ...string) health.DetailedReport {
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "HealthChecksDetailed", varargs...)
+	ret0, _ := ret[0].(health.DetailedReport)
+	return ret0
+}
+
+// HealthChecksDetailed indicates an expected call of HealthChecksDetailed
+func (mr *ComponentMockRecorder) HealthChecksDetailed(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthChecksDetailed", reflect.TypeOf((*Component)(nil).HealthChecksDetailed), varargs...)
+}
+
+// IDGenHealthChecks mocks base method
+func (m *Component) IDGenHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "IDGenHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// IDGenHealthChecks indicates an expected call of IDGenHealthChecks
+func (mr *ComponentMockRecorder) IDGenHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IDGenHealthChecks", reflect.TypeOf((*Component)(nil).IDGenHealthChecks), arg0)
+}
+
 // InfluxHealthChecks mocks base method
 func (m *Component) InfluxHealthChecks(arg0 context.Context) health.Reports {
 	ret := m.ctrl.Call(m, "InfluxHealthChecks", arg0)
@@ -70,6 +254,43 @@ func (mr *ComponentMockRecorder) JaegerHealthChecks(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JaegerHealthChecks", reflect.TypeOf((*Component)(nil).JaegerHealthChecks), arg0)
 }
 
+// LogSinkHealthChecks mocks base method
+func (m *Component) LogSinkHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "LogSinkHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// LogSinkHealthChecks indicates an expected call of LogSinkHealthChecks
+func (mr *ComponentMockRecorder) LogSinkHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogSinkHealthChecks", reflect.TypeOf((*Component)(nil).LogSinkHealthChecks), arg0)
+}
+
+// Options mocks base method
+func (m *Component) Options() health.Options {
+	ret := m.ctrl.Call(m, "Options")
+	ret0, _ := ret[0].(health.Options)
+	return ret0
+}
+
+// Options indicates an expected call of Options
+func (mr *ComponentMockRecorder) Options() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Options", reflect.TypeOf((*Component)(nil).Options))
+}
+
+// OverallStatusWithReason mocks base method
+func (m *Component) OverallStatusWithReason(arg0 context.Context) (health.Status, string) {
+	ret := m.ctrl.Call(m, "OverallStatusWithReason", arg0)
+	ret0, _ := ret[0].(health.Status)
+	ret1, _ := ret[1].(string)
+	return ret0, ret1
+}
+
+// OverallStatusWithReason indicates an expected call of OverallStatusWithReason
+func (mr *ComponentMockRecorder) OverallStatusWithReason(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OverallStatusWithReason", reflect.TypeOf((*Component)(nil).OverallStatusWithReason), arg0)
+}
+
 // RedisHealthChecks mocks base method
 func (m *Component) RedisHealthChecks(arg0 context.Context) health.Reports {
 	ret := m.ctrl.Call(m, "RedisHealthChecks", arg0)
@@ -82,6 +303,52 @@ func (mr *ComponentMockRecorder) RedisHealthChecks(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedisHealthChecks", reflect.TypeOf((*Component)(nil).RedisHealthChecks), arg0)
 }
 
+// ReplicaHealthChecks mocks base method
+func (m *Component) ReplicaHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "ReplicaHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// ReplicaHealthChecks indicates an expected call of ReplicaHealthChecks
+func (mr *ComponentMockRecorder) ReplicaHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplicaHealthChecks", reflect.TypeOf((*Component)(nil).ReplicaHealthChecks), arg0)
+}
+
+// Reset mocks base method
+func (m *Component) Reset() {
+	m.ctrl.Call(m, "Reset")
+}
+
+// Reset indicates an expected call of Reset
+func (mr *ComponentMockRecorder) Reset() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*Component)(nil).Reset))
+}
+
+// SMTPHealthChecks mocks base method
+func (m *Component) SMTPHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "SMTPHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// SMTPHealthChecks indicates an expected call of SMTPHealthChecks
+func (mr *ComponentMockRecorder) SMTPHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SMTPHealthChecks", reflect.TypeOf((*Component)(nil).SMTPHealthChecks), arg0)
+}
+
+// SelfHealthChecks mocks base method
+func (m *Component) SelfHealthChecks(arg0 context.Context) health.Reports {
+	ret := m.ctrl.Call(m, "SelfHealthChecks", arg0)
+	ret0, _ := ret[0].(health.Reports)
+	return ret0
+}
+
+// SelfHealthChecks indicates an expected call of SelfHealthChecks
+func (mr *ComponentMockRecorder) SelfHealthChecks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelfHealthChecks", reflect.TypeOf((*Component)(nil).SelfHealthChecks), arg0)
+}
+
 // SentryHealthChecks mocks base method
 func (m *Component) SentryHealthChecks(arg0 context.Context) health.Reports {
 	ret := m.ctrl.Call(m, "SentryHealthChecks", arg0)
@@ -93,3 +360,40 @@ func (m *Component) SentryHealthChecks(arg0 context.Context) health.Reports {
 func (mr *ComponentMockRecorder) SentryHealthChecks(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SentryHealthChecks", reflect.TypeOf((*Component)(nil).SentryHealthChecks), arg0)
 }
+
+// Subsystems mocks base method
+func (m *Component) Subsystems() []string {
+	ret := m.ctrl.Call(m, "Subsystems")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// Subsystems indicates an expected call of Subsystems
+func (mr *ComponentMockRecorder) Subsystems() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subsystems", reflect.TypeOf((*Component)(nil).Subsystems))
+}
+
+// UptimePercent mocks base method
+func (m *Component) UptimePercent(arg0 string, arg1 time.Duration) (float64, error) {
+	ret := m.ctrl.Call(m, "UptimePercent", arg0, arg1)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UptimePercent indicates an expected call of UptimePercent
+func (mr *ComponentMockRecorder) UptimePercent(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UptimePercent", reflect.TypeOf((*Component)(nil).UptimePercent), arg0, arg1)
+}
+
+// WaitUntilReady mocks base method
+func (m *Component) WaitUntilReady(arg0 context.Context, arg1 time.Duration) health.Status {
+	ret := m.ctrl.Call(m, "WaitUntilReady", arg0, arg1)
+	ret0, _ := ret[0].(health.Status)
+	return ret0
+}
+
+// WaitUntilReady indicates an expected call of WaitUntilReady
+func (mr *ComponentMockRecorder) WaitUntilReady(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitUntilReady", reflect.TypeOf((*Component)(nil).WaitUntilReady), arg0, arg1)
+}
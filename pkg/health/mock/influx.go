@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/cloudtrust/flaki-service/pkg/health (interfaces: InfluxModule,Influx)
+// Source: github.com/cloudtrust/flaki-service/pkg/health (interfaces: InfluxModule,Influx,InfluxBuffer,InfluxFlush)
 
 // Package mock is a generated GoMock package.
 package mock
@@ -83,3 +83,74 @@ func (m *Influx) Ping(arg0 time.Duration) (time.Duration, string, error) {
 func (mr *InfluxMockRecorder) Ping(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*Influx)(nil).Ping), arg0)
 }
+
+// InfluxBuffer is a mock of InfluxBuffer interface
+type InfluxBuffer struct {
+	ctrl     *gomock.Controller
+	recorder *InfluxBufferMockRecorder
+}
+
+// InfluxBufferMockRecorder is the mock recorder for InfluxBuffer
+type InfluxBufferMockRecorder struct {
+	mock *InfluxBuffer
+}
+
+// NewInfluxBuffer creates a new mock instance
+func NewInfluxBuffer(ctrl *gomock.Controller) *InfluxBuffer {
+	mock := &InfluxBuffer{ctrl: ctrl}
+	mock.recorder = &InfluxBufferMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *InfluxBuffer) EXPECT() *InfluxBufferMockRecorder {
+	return m.recorder
+}
+
+// BufferDepth mocks base method
+func (m *InfluxBuffer) BufferDepth() (int, int) {
+	ret := m.ctrl.Call(m, "BufferDepth")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	return ret0, ret1
+}
+
+// BufferDepth indicates an expected call of BufferDepth
+func (mr *InfluxBufferMockRecorder) BufferDepth() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BufferDepth", reflect.TypeOf((*InfluxBuffer)(nil).BufferDepth))
+}
+
+// InfluxFlush is a mock of InfluxFlush interface
+type InfluxFlush struct {
+	ctrl     *gomock.Controller
+	recorder *InfluxFlushMockRecorder
+}
+
+// InfluxFlushMockRecorder is the mock recorder for InfluxFlush
+type InfluxFlushMockRecorder struct {
+	mock *InfluxFlush
+}
+
+// NewInfluxFlush creates a new mock instance
+func NewInfluxFlush(ctrl *gomock.Controller) *InfluxFlush {
+	mock := &InfluxFlush{ctrl: ctrl}
+	mock.recorder = &InfluxFlushMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *InfluxFlush) EXPECT() *InfluxFlushMockRecorder {
+	return m.recorder
+}
+
+// LastFlush mocks base method
+func (m *InfluxFlush) LastFlush() time.Time {
+	ret := m.ctrl.Call(m, "LastFlush")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// LastFlush indicates an expected call of LastFlush
+func (mr *InfluxFlushMockRecorder) LastFlush() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastFlush", reflect.TypeOf((*InfluxFlush)(nil).LastFlush))
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/cloudtrust/flaki-service/pkg/health (interfaces: SentryModule,Sentry)
+// Source: github.com/cloudtrust/flaki-service/pkg/health (interfaces: SentryModule,Sentry,SentryTags)
 
 // Package mock is a generated GoMock package.
 package mock
@@ -80,3 +80,50 @@ func (m *Sentry) URL() string {
 func (mr *SentryMockRecorder) URL() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "URL", reflect.TypeOf((*Sentry)(nil).URL))
 }
+
+// SentryTags is a mock of SentryTags interface
+type SentryTags struct {
+	ctrl     *gomock.Controller
+	recorder *SentryTagsMockRecorder
+}
+
+// SentryTagsMockRecorder is the mock recorder for SentryTags
+type SentryTagsMockRecorder struct {
+	mock *SentryTags
+}
+
+// NewSentryTags creates a new mock instance
+func NewSentryTags(ctrl *gomock.Controller) *SentryTags {
+	mock := &SentryTags{ctrl: ctrl}
+	mock.recorder = &SentryTagsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *SentryTags) EXPECT() *SentryTagsMockRecorder {
+	return m.recorder
+}
+
+// Environment mocks base method
+func (m *SentryTags) Environment() string {
+	ret := m.ctrl.Call(m, "Environment")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Environment indicates an expected call of Environment
+func (mr *SentryTagsMockRecorder) Environment() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Environment", reflect.TypeOf((*SentryTags)(nil).Environment))
+}
+
+// Release mocks base method
+func (m *SentryTags) Release() string {
+	ret := m.ctrl.Call(m, "Release")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Release indicates an expected call of Release
+func (mr *SentryTagsMockRecorder) Release() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*SentryTags)(nil).Release))
+}
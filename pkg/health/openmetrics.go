@@ -0,0 +1,200 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	http_transport "github.com/go-kit/kit/transport/http"
+	opentracing "github.com/opentracing/opentracing-go"
+	jaeger "github.com/uber/jaeger-client-go"
+)
+
+// openMetricsContentType is the content type of the OpenMetrics text format.
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// openMetricsBuckets are the upper bounds of the health check duration histogram buckets, in seconds.
+var openMetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// openMetricsStates are the states of the health status statesets.
+var openMetricsStates = []Status{OK, KO, Degraded, Deactivated}
+
+// MakeOpenMetricsHandler makes a HTTP handler that exports the detailed health in the OpenMetrics text
+// format. It must be used with the AllHealthChecksDetailed endpoint.
+func MakeOpenMetricsHandler(e endpoint.Endpoint) *http_transport.Server {
+	return http_transport.NewServer(e,
+		decodeHealthCheckRequest,
+		encodeOpenMetricsReply,
+		http_transport.ServerErrorEncoder(healthCheckErrorHandler),
+	)
+}
+
+// encodeOpenMetricsReply encodes the detailed health report in the OpenMetrics text format.
+func encodeOpenMetricsReply(ctx context.Context, w http.ResponseWriter, rep interface{}) error {
+	var report = rep.(DetailedReport)
+
+	w.Header().Set("Content-Type", openMetricsContentType)
+	w.WriteHeader(http.StatusOK)
+	return WriteOpenMetrics(ctx, w, report)
+}
+
+// WriteOpenMetrics writes the health report in the OpenMetrics text format. The overall, subsystem and
+// check statuses are statesets. The duration of each check is a histogram with a single observation, whose
+// bucket carries the trace ID of the span in ctx as exemplar, so a failed check links to the trace of the
+// probe that observed it. The cached reports were observed by a previous probe, so they have no exemplar.
+func WriteOpenMetrics(ctx context.Context, w io.Writer, report DetailedReport) error {
+	var traceID = traceIDFromContext(ctx)
+	var b = &bytes.Buffer{}
+
+	// Overall status.
+	fmt.Fprint(b, "# TYPE health_status stateset\n")
+	fmt.Fprint(b, "# HELP health_status Overall health status.\n")
+	writeStateSet(b, "health_status", nil, report.Status)
+
+	// Total duration of the health checks run.
+	fmt.Fprint(b, "# TYPE health_total_duration_seconds gauge\n")
+	fmt.Fprint(b, "# UNIT health_total_duration_seconds seconds\n")
+	fmt.Fprint(b, "# HELP health_total_duration_seconds Wall-clock duration of the health checks run.\n")
+	fmt.Fprintf(b, "health_total_duration_seconds %v\n", report.TotalDuration.Seconds())
+
+	// Subsystem status.
+	fmt.Fprint(b, "# TYPE health_subsystem_status stateset\n")
+	fmt.Fprint(b, "# HELP health_subsystem_status Health status of the subsystem.\n")
+	for _, name := range subsystems {
+		if r, ok := report.Subsystems[name]; ok {
+			writeStateSet(b, "health_subsystem_status", []string{"subsystem", name}, r.Status)
+		}
+	}
+
+	// Subsystem up, 0 when the checks could not run, so a target that is down is told apart from a check
+	// that could not run.
+	fmt.Fprint(b, "# TYPE health_subsystem_up gauge\n")
+	fmt.Fprint(b, "# HELP health_subsystem_up 1 if the health checks of the subsystem ran, 0 if they could not run.\n")
+	for _, name := range subsystems {
+		if r, ok := report.Subsystems[name]; ok {
+			var up = 0
+			if r.ScrapeError == "" {
+				up = 1
+			}
+			fmt.Fprintf(b, "health_subsystem_up%s %d\n", formatLabels([]string{"subsystem", name}), up)
+		}
+	}
+
+	// Check status.
+	fmt.Fprint(b, "# TYPE health_check_status stateset\n")
+	fmt.Fprint(b, "# HELP health_check_status Status of the health check.\n")
+	for _, name := range subsystems {
+		for _, r := range report.Subsystems[name].Reports {
+			writeStateSet(b, "health_check_status", []string{"subsystem", name, "check", r.Name}, r.Status)
+		}
+	}
+
+	// Check duration.
+	fmt.Fprint(b, "# TYPE health_check_duration_seconds histogram\n")
+	fmt.Fprint(b, "# UNIT health_check_duration_seconds seconds\n")
+	fmt.Fprint(b, "# HELP health_check_duration_seconds Duration of the last execution of the health check.\n")
+	for _, name := range subsystems {
+		for _, r := range report.Subsystems[name].Reports {
+			var d, err = time.ParseDuration(r.Duration)
+			if err != nil {
+				// The deactivated checks have no duration.
+				continue
+			}
+			var exemplar string
+			if traceID != "" && !r.Cached {
+				exemplar = traceID
+			}
+			writeDurationHistogram(b, []string{"subsystem", name, "check", r.Name}, d.Seconds(), exemplar)
+		}
+	}
+
+	fmt.Fprint(b, "# EOF\n")
+
+	var _, err = w.Write(b.Bytes())
+	return err
+}
+
+// writeStateSet writes one sample per state, 1 for the current state and 0 for the others.
+func writeStateSet(b *bytes.Buffer, metric string, labels []string, status Status) {
+	for _, s := range openMetricsStates {
+		var value = 0
+		if s == status {
+			value = 1
+		}
+		fmt.Fprintf(b, "%s%s %d\n", metric, formatLabels(append(labels, metric, s.String())), value)
+	}
+}
+
+// writeDurationHistogram writes a histogram with the single observation v. The exemplar, if any, is
+// attached to the first bucket that contains the observation.
+func writeDurationHistogram(b *bytes.Buffer, labels []string, v float64, exemplar string) {
+	var metric = "health_check_duration_seconds"
+	var attached bool
+
+	for _, le := range append(openMetricsBuckets, math.Inf(1)) {
+		var count = 0
+		if v <= le {
+			count = 1
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d", metric, formatLabels(append(labels, "le", formatFloat(le))), count)
+		if count == 1 && !attached && exemplar != "" {
+			fmt.Fprintf(b, " # %s %s", formatLabels([]string{"trace_id", exemplar}), formatFloat(v))
+			attached = true
+		}
+		fmt.Fprint(b, "\n")
+	}
+	fmt.Fprintf(b, "%s_count%s 1\n", metric, formatLabels(labels))
+	fmt.Fprintf(b, "%s_sum%s %s\n", metric, formatLabels(labels), formatFloat(v))
+}
+
+// formatLabels formats the label pairs, e.g. {subsystem="redis",check="ping"}.
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	var pairs = []string{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labels[i], escapeLabelValue(labels[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escapeLabelValue escapes the backslashes, double quotes and line feeds of a label value.
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// formatFloat formats a float in the canonical OpenMetrics form, e.g. 1.0, 0.005 or +Inf.
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case f == math.Trunc(f):
+		return strconv.FormatFloat(f, 'f', 1, 64)
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+// traceIDFromContext returns the trace ID of the span in ctx, or an empty string if there is no span
+// or if it is not a jaeger span.
+func traceIDFromContext(ctx context.Context) string {
+	var span = opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return ""
+	}
+
+	var sc, ok = span.Context().(jaeger.SpanContext)
+	if !ok || !sc.IsValid() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
@@ -0,0 +1,115 @@
+package health_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	jaeger "github.com/uber/jaeger-client-go"
+)
+
+func TestWriteOpenMetrics(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSpan = mock.NewSpan(mockCtrl)
+
+	var report = DetailedReport{
+		Status:        KO,
+		TotalDuration: 1500 * time.Millisecond,
+		Subsystems: map[string]SubsystemReport{
+			"influx": {Status: Deactivated, Reports: []Report{{Name: "ping", Duration: "N/A", Status: Deactivated}}},
+			"smtp":   {Status: KO, Reports: []Report{{Name: "not configured", Duration: "N/A", Status: KO}}, ScrapeError: "not configured"},
+			"redis": {Status: KO, Reports: []Report{
+				{Name: "ping", Duration: "20ms", Status: KO, Error: "fail", Attempts: 1},
+				{Name: "write \"key\"", Duration: "2s", Status: OK, Attempts: 1, Cached: true},
+			}},
+		},
+	}
+
+	// With jaeger span.
+	{
+		var sc = jaeger.NewSpanContext(jaeger.TraceID{Low: 0xabc}, jaeger.SpanID(1), jaeger.SpanID(0), true, nil)
+		mockSpan.EXPECT().Context().Return(sc).Times(1)
+		var ctx = opentracing.ContextWithSpan(context.Background(), mockSpan)
+
+		var b = &bytes.Buffer{}
+		var err = WriteOpenMetrics(ctx, b, report)
+		assert.Nil(t, err)
+		var out = b.String()
+
+		assert.Contains(t, out, "# TYPE health_status stateset\n")
+		assert.Contains(t, out, "health_status{health_status=\"KO\"} 1\n")
+		assert.Contains(t, out, "health_status{health_status=\"OK\"} 0\n")
+		assert.Contains(t, out, "# TYPE health_total_duration_seconds gauge\n")
+		assert.Contains(t, out, "health_total_duration_seconds 1.5\n")
+		assert.Contains(t, out, "health_subsystem_status{subsystem=\"influx\",health_subsystem_status=\"Deactivated\"} 1\n")
+		assert.Contains(t, out, "health_check_status{subsystem=\"redis\",check=\"ping\",health_check_status=\"KO\"} 1\n")
+
+		// The redis checks ran and redis is KO, the smtp checks could not run.
+		assert.Contains(t, out, "# TYPE health_subsystem_up gauge\n")
+		assert.Contains(t, out, "health_subsystem_up{subsystem=\"redis\"} 1\n")
+		assert.Contains(t, out, "health_subsystem_up{subsystem=\"smtp\"} 0\n")
+		assert.Contains(t, out, "health_check_status{subsystem=\"redis\",check=\"write \\\"key\\\"\",health_check_status=\"OK\"} 1\n")
+
+		// The deactivated check has no duration.
+		assert.NotContains(t, out, "health_check_duration_seconds_count{subsystem=\"influx\"")
+
+		// The exemplar is attached to the first bucket containing the observation.
+		assert.Contains(t, out, "health_check_duration_seconds_bucket{subsystem=\"redis\",check=\"ping\",le=\"0.01\"} 0\n")
+		assert.Contains(t, out, "health_check_duration_seconds_bucket{subsystem=\"redis\",check=\"ping\",le=\"0.025\"} 1 # {trace_id=\"abc\"} 0.02\n")
+		assert.Contains(t, out, "health_check_duration_seconds_bucket{subsystem=\"redis\",check=\"ping\",le=\"0.05\"} 1\n")
+		assert.Contains(t, out, "health_check_duration_seconds_count{subsystem=\"redis\",check=\"ping\"} 1\n")
+		assert.Contains(t, out, "health_check_duration_seconds_sum{subsystem=\"redis\",check=\"ping\"} 0.02\n")
+
+		// The cached report has no exemplar.
+		assert.Contains(t, out, "health_check_duration_seconds_bucket{subsystem=\"redis\",check=\"write \\\"key\\\"\",le=\"2.5\"} 1\n")
+		assert.Equal(t, 1, strings.Count(out, "trace_id"))
+
+		assert.True(t, strings.HasSuffix(out, "# EOF\n"))
+	}
+
+	// Without span.
+	{
+		var b = &bytes.Buffer{}
+		var err = WriteOpenMetrics(context.Background(), b, report)
+		assert.Nil(t, err)
+		assert.NotContains(t, b.String(), "trace_id")
+		assert.Contains(t, b.String(), "health_check_duration_seconds_bucket{subsystem=\"redis\",check=\"ping\",le=\"+Inf\"} 1\n")
+	}
+}
+
+func TestOpenMetricsHandler(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockComponent = mock.NewComponent(mockCtrl)
+
+	var h = MakeOpenMetricsHandler(MakeAllHealthChecksDetailedEndpoint(mockComponent))
+
+	mockComponent.EXPECT().AllHealthChecksDetailed(gomock.Any()).Return(DetailedReport{
+		Status:     OK,
+		Subsystems: map[string]SubsystemReport{"redis": {Status: OK, Reports: []Report{{Name: "ping", Duration: "1ms", Status: OK, Attempts: 1}}}},
+	}).Times(1)
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health/openmetrics", nil)
+	var w = httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+	var resp = w.Result()
+	var body, err = ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/openmetrics-text; version=1.0.0; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), "health_subsystem_status{subsystem=\"redis\",health_subsystem_status=\"OK\"} 1\n")
+	assert.Contains(t, string(body), "health_check_duration_seconds_sum{subsystem=\"redis\",check=\"ping\"} 0.001\n")
+}
@@ -0,0 +1,209 @@
+package health
+
+import (
+	"sort"
+)
+
+// Options is the effective configuration of the health component, returned by Component.Options so the
+// operators can check what a running instance uses, e.g. whether an override took effect. The durations
+// are formatted, e.g. "5s".
+type Options struct {
+	Required             []string               `json:"required subsystems,omitempty"`
+	ReadyStatuses        []string               `json:"ready statuses"`
+	NilModuleStatus      map[string]string      `json:"nil module status,omitempty"`
+	Dependencies         map[string][]string    `json:"dependencies,omitempty"`
+	SoftDependencies     []string               `json:"soft dependencies,omitempty"`
+	GroupErrors          bool                   `json:"group errors"`
+	SlowThreshold        string                 `json:"slow threshold"`
+	SlowThresholds       map[string]string      `json:"subsystem slow thresholds,omitempty"`
+	RunbookURLs          map[string]string      `json:"runbook urls,omitempty"`
+	MaxConcurrentChecks  int                    `json:"max concurrent checks"`
+	StartupGracePeriod   string                 `json:"startup grace period"`
+	Critical             []string               `json:"critical subsystems,omitempty"`
+	PriorityMargin       string                 `json:"priority margin"`
+	HistorySize          int                    `json:"history size"`
+	UptimeCountsDegraded bool                   `json:"uptime counts degraded"`
+	Remediation          bool                   `json:"remediation"`
+	DisabledAtRuntime    []string               `json:"disabled at runtime,omitempty"`
+	Modules              map[string]interface{} `json:"modules,omitempty"`
+}
+
+// Optioner is implemented by the health modules that expose their effective configuration. It is
+// optional, the modules without it are not listed in Options.Modules.
+type Optioner interface {
+	Options() interface{}
+}
+
+// AdaptiveTimeoutOptions is the configuration of an adaptive timeout, see SentryAdaptiveTimeout. Current is
+// the currently effective timeout.
+type AdaptiveTimeoutOptions struct {
+	Factor  float64 `json:"factor"`
+	Min     string  `json:"min"`
+	Max     string  `json:"max"`
+	Current string  `json:"current"`
+}
+
+// SentryOptions is the effective configuration of the sentry health module.
+type SentryOptions struct {
+	Enabled         bool                    `json:"enabled"`
+	Projects        int                     `json:"projects"`
+	MaxBodySize     int64                   `json:"max body size"`
+	ResponseSnippet int                     `json:"response snippet"`
+	AdaptiveTimeout *AdaptiveTimeoutOptions `json:"adaptive timeout,omitempty"`
+	Auth            string                  `json:"auth"`
+	QuotaCheck      bool                    `json:"quota check"`
+	QuotaInterval   string                  `json:"quota interval,omitempty"`
+	TagsCheck       bool                    `json:"tags check"`
+}
+
+// InfluxOptions is the effective configuration of the influx health module. The credentials are not
+// exposed.
+type InfluxOptions struct {
+	Enabled          bool                               `json:"enabled"`
+	WriteCheck       bool                               `json:"write check"`
+	RetentionCheck   bool                               `json:"retention check"`
+	RetentionMin     string                             `json:"retention min,omitempty"`
+	BufferCheck      bool                               `json:"buffer check"`
+	FlushStaleness   string                             `json:"flush staleness,omitempty"`
+	V2               bool                               `json:"v2"`
+	CheckIntervals   map[string]string                  `json:"check intervals,omitempty"`
+	AdaptiveTimeouts map[string]*AdaptiveTimeoutOptions `json:"adaptive timeouts,omitempty"`
+}
+
+// Options returns the effective configuration of the component and of the health modules that implement
+// Optioner.
+func (c *component) Options() Options {
+	var o = Options{
+		Required:             sortedKeys(c.required),
+		ReadyStatuses:        []string{},
+		NilModuleStatus:      map[string]string{},
+		Dependencies:         c.deps,
+		SoftDependencies:     sortedKeys(c.soft),
+		GroupErrors:          c.groupErrors,
+		SlowThreshold:        c.slow.String(),
+		SlowThresholds:       map[string]string{},
+		RunbookURLs:          c.runbooks,
+		StartupGracePeriod:   c.gracePeriod.String(),
+		Critical:             sortedKeys(c.critical),
+		PriorityMargin:       c.margin.String(),
+		UptimeCountsDegraded: c.degradedIsUp,
+		Remediation:          c.remediation != nil,
+		Modules:              map[string]interface{}{},
+	}
+
+	for _, s := range c.ready {
+		o.ReadyStatuses = append(o.ReadyStatuses, s.String())
+	}
+	for name, s := range c.nilStatus {
+		o.NilModuleStatus[name] = s.String()
+	}
+	for name, d := range c.slowBySubsystem {
+		o.SlowThresholds[name] = d.String()
+	}
+	if c.slots != nil {
+		o.MaxConcurrentChecks = cap(c.slots)
+	}
+
+	c.mutex.Lock()
+	o.DisabledAtRuntime = sortedKeys(c.disabled)
+	for _, h := range c.history {
+		o.HistorySize = len(h.entries)
+		break
+	}
+	c.mutex.Unlock()
+
+	for name, m := range c.modules() {
+		if opts, ok := moduleOptions(m); ok {
+			o.Modules[name] = opts
+		}
+	}
+	return o
+}
+
+// moduleOptions returns the configuration of m if it implements Optioner. The middlewares use it to
+// forward the call to the module they wrap.
+func moduleOptions(m interface{}) (interface{}, bool) {
+	if o, ok := m.(Optioner); ok {
+		return o.Options(), true
+	}
+	return nil, false
+}
+
+// Options returns the effective configuration of the sentry module.
+func (m *sentryModule) Options() interface{} {
+	var auth = "none"
+	switch {
+	case m.auth.token != "":
+		auth = "bearer"
+	case m.auth.username != "":
+		auth = "basic"
+	}
+
+	var o = SentryOptions{
+		Enabled:         m.enabled,
+		Projects:        len(m.projects),
+		MaxBodySize:     m.maxBodySize,
+		ResponseSnippet: m.snippetSize,
+		AdaptiveTimeout: m.timeout.options(),
+		Auth:            auth,
+		QuotaCheck:      m.quota != nil,
+		TagsCheck:       m.tags != nil,
+	}
+	if m.quota != nil {
+		o.QuotaInterval = m.quota.interval.String()
+	}
+	return o
+}
+
+// Options returns the effective configuration of the influx module.
+func (m *influxModule) Options() interface{} {
+	var o = InfluxOptions{
+		Enabled:          m.enabled,
+		WriteCheck:       m.write != nil,
+		RetentionCheck:   m.retention != nil,
+		BufferCheck:      m.buffer != nil,
+		V2:               m.v2 != nil,
+		CheckIntervals:   map[string]string{},
+		AdaptiveTimeouts: map[string]*AdaptiveTimeoutOptions{},
+	}
+
+	if m.retention != nil {
+		o.RetentionMin = m.retention.min.String()
+	}
+	if m.flush != nil {
+		o.FlushStaleness = m.flush.staleness.String()
+	}
+	for check, s := range m.samplers {
+		o.CheckIntervals[check] = s.interval.String()
+	}
+	for check, t := range m.timeouts {
+		o.AdaptiveTimeouts[check] = t.options()
+	}
+	return o
+}
+
+// options returns the configuration of the adaptive timeout, nil if there is none.
+func (a *adaptiveTimeout) options() *AdaptiveTimeoutOptions {
+	if a == nil {
+		return nil
+	}
+
+	return &AdaptiveTimeoutOptions{
+		Factor:  a.factor,
+		Min:     a.min.String(),
+		Max:     a.max.String(),
+		Current: a.timeout().String(),
+	}
+}
+
+// sortedKeys returns the keys of m whose value is true, sorted.
+func sortedKeys(m map[string]bool) []string {
+	var keys = []string{}
+	for k, v := range m {
+		if v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
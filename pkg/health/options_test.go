@@ -0,0 +1,65 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInflux = mock.NewInflux(mockCtrl)
+	var mockSentry = mock.NewSentry(mockCtrl)
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+
+	var influxModule = NewInfluxModule(mockInflux, true, InfluxCheckInterval("write", time.Minute), InfluxAdaptiveTimeout(3, time.Second, 5*time.Second))
+	influxModule = MakeInfluxModuleLoggingMW(log.NewNopLogger())(influxModule)
+	influxModule = MakeInfluxModuleTracingMW(nil)(influxModule)
+
+	var sentryModule = NewSentryModule(mockSentry, NewHTTPClient(), true, SentryMaxBodySize(1024), SentryBearerToken("secret"))
+	sentryModule = MakeSentryModuleLoggingMW(log.NewNopLogger())(sentryModule)
+	mockSentry.EXPECT().URL().Return("https://sentry.example.com/api/42/store/").Times(1)
+
+	var c, err = NewComponent(influxModule, nil, mockRedisModule, sentryModule,
+		RequiredSubsystems("redis", "influx"),
+		MaxConcurrentChecks(2),
+		StartupGracePeriod(30*time.Second),
+		History(10),
+	)
+	assert.Nil(t, err)
+	c = MakeComponentLoggingMW(log.NewNopLogger())(c)
+	assert.Nil(t, c.Disable("sentry"))
+
+	var o = c.Options()
+	assert.Equal(t, []string{"influx", "redis"}, o.Required)
+	assert.Equal(t, []string{"OK", "Degraded"}, o.ReadyStatuses)
+	assert.Equal(t, 2, o.MaxConcurrentChecks)
+	assert.Equal(t, "30s", o.StartupGracePeriod)
+	assert.Equal(t, 10, o.HistorySize)
+	assert.False(t, o.Remediation)
+	assert.Equal(t, []string{"sentry"}, o.DisabledAtRuntime)
+
+	// Only the modules that implement Optioner are listed.
+	assert.Len(t, o.Modules, 2)
+	{
+		var influx = o.Modules["influx"].(InfluxOptions)
+		assert.True(t, influx.Enabled)
+		assert.False(t, influx.WriteCheck)
+		assert.Equal(t, map[string]string{"write": "1m0s"}, influx.CheckIntervals)
+		assert.Equal(t, &AdaptiveTimeoutOptions{Factor: 3, Min: "1s", Max: "5s", Current: "5s"}, influx.AdaptiveTimeouts["ping"])
+	}
+	{
+		var sentry = o.Modules["sentry"].(SentryOptions)
+		assert.Equal(t, 1, sentry.Projects)
+		assert.Equal(t, int64(1024), sentry.MaxBodySize)
+		assert.Nil(t, sentry.AdaptiveTimeout)
+		assert.Equal(t, "bearer", sentry.Auth)
+		assert.False(t, sentry.QuotaCheck)
+	}
+}
@@ -0,0 +1,223 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// Poller executes the detailed health checks periodically and publishes the reports to its subscribers,
+// so the consumers of the live health do not trigger the health checks themselves. The health checks are
+// only executed while there are subscribers.
+type Poller struct {
+	e        endpoint.Endpoint
+	interval time.Duration
+	timeout  time.Duration
+
+	// intervals are the subsystems checked on their own schedule, with their interval.
+	intervals map[string]time.Duration
+
+	mutex       sync.Mutex
+	subscribers map[chan DetailedReport]struct{}
+	last        *DetailedReport
+
+	// ctx is the context of Run, nil when the poller does not run. stop cancels the polling in progress,
+	// nil when there is none.
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+// PollerOption is an option of the poller.
+type PollerOption func(*Poller) error
+
+// SubsystemInterval checks the subsystem every interval, on its own schedule, rather than with the other
+// subsystems, e.g. to check e2e rarely because it is expensive, or redis often because it is cheap. Each
+// run publishes the report of the subsystem merged with the last reports of the others.
+func SubsystemInterval(name string, interval time.Duration) PollerOption {
+	return func(p *Poller) error {
+		if !isSubsystem(name) {
+			return fmt.Errorf("unknown subsystem '%s'", name)
+		}
+		if interval <= 0 {
+			return fmt.Errorf("interval of subsystem '%s' must be positive, got %v", name, interval)
+		}
+		p.intervals[name] = interval
+		return nil
+	}
+}
+
+// NewPoller returns a poller that calls the detailed health checks endpoint e every interval, with the
+// deadline timeout. With SubsystemInterval, the endpoint is called with the list of the subsystems to
+// check, see MakeAllHealthChecksDetailedEndpoint.
+func NewPoller(e endpoint.Endpoint, interval, timeout time.Duration, options ...PollerOption) (*Poller, error) {
+	var p = &Poller{
+		e:           e,
+		interval:    interval,
+		timeout:     timeout,
+		intervals:   map[string]time.Duration{},
+		subscribers: map[chan DetailedReport]struct{}{},
+	}
+
+	for _, o := range options {
+		if err := o(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Run runs the poller until the context is done. The health checks are executed every interval from the
+// first subscription until the last subscriber leaves.
+func (p *Poller) Run(ctx context.Context) {
+	p.mutex.Lock()
+	p.ctx = ctx
+	if len(p.subscribers) > 0 {
+		p.start()
+	}
+	p.mutex.Unlock()
+
+	<-ctx.Done()
+
+	p.mutex.Lock()
+	p.ctx = nil
+	if p.stop != nil {
+		p.stop()
+		p.stop = nil
+	}
+	p.mutex.Unlock()
+
+	p.wg.Wait()
+}
+
+// start starts the polling, with a context that is cancelled when the last subscriber leaves. The caller
+// must hold the mutex.
+func (p *Poller) start() {
+	var ctx, cancel = context.WithCancel(p.ctx)
+	p.stop = cancel
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.pollAll(ctx)
+	}()
+}
+
+// pollAll executes the health checks every interval, until the context is done. The subsystems with their
+// own interval are checked concurrently, each on its own schedule.
+func (p *Poller) pollAll(ctx context.Context) {
+	if len(p.intervals) == 0 {
+		p.schedule(ctx, p.interval, nil)
+		return
+	}
+
+	var others = []string{}
+	for _, name := range subsystems {
+		if _, ok := p.intervals[name]; !ok {
+			others = append(others, name)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for name, interval := range p.intervals {
+		wg.Add(1)
+		go func(name string, interval time.Duration) {
+			defer wg.Done()
+			p.schedule(ctx, interval, []string{name})
+		}(name, interval)
+	}
+	if len(others) > 0 {
+		p.schedule(ctx, p.interval, others)
+	}
+	wg.Wait()
+}
+
+// schedule executes the health checks of the subsystems every interval, until the context is done. A nil
+// list of subsystems means all of them.
+func (p *Poller) schedule(ctx context.Context, interval time.Duration, subsystems []string) {
+	var tic = time.NewTicker(interval)
+	defer tic.Stop()
+
+	for {
+		p.poll(ctx, subsystems)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tic.C:
+		}
+	}
+}
+
+// Subscribe returns a channel on which the reports are published, and a function that cancels the
+// subscription. The first subscriber starts the health checks, and the last report, if any, is published
+// immediately. A subscriber that is too slow misses the intermediate reports, it only gets the latest one.
+// When the last subscriber leaves, the health checks stop and the last report is dropped, as it gets stale.
+func (p *Poller) Subscribe() (<-chan DetailedReport, func()) {
+	var reports = make(chan DetailedReport, 1)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.subscribers[reports] = struct{}{}
+	if p.last != nil {
+		reports <- *p.last
+	}
+	if p.ctx != nil && p.stop == nil {
+		p.start()
+	}
+
+	var once sync.Once
+	var unsubscribe = func() {
+		once.Do(func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+
+			delete(p.subscribers, reports)
+			if len(p.subscribers) == 0 && p.stop != nil {
+				p.stop()
+				p.stop = nil
+				p.last = nil
+			}
+		})
+	}
+	return reports, unsubscribe
+}
+
+// poll executes the health checks of the subsystems once, all of them if subsystems is nil, and publishes
+// the report. The report is dropped on error, or if the polling stopped in the meantime.
+func (p *Poller) poll(ctx context.Context, subsystems []string) {
+	var pollCtx, cancel = context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var req interface{}
+	if subsystems != nil {
+		req = subsystems
+	}
+	var rep, err = p.e(pollCtx, req)
+	if err != nil {
+		return
+	}
+	var report = rep.(DetailedReport)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// The polling was stopped during the health checks, the report is incomplete.
+	if ctx.Err() != nil {
+		return
+	}
+
+	p.last = &report
+	for s := range p.subscribers {
+		// Replace the report not yet consumed, so the publication never blocks.
+		select {
+		case <-s:
+		default:
+		}
+		s <- report
+	}
+}
@@ -0,0 +1,145 @@
+package health_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoller(t *testing.T) {
+	var calls = make(chan struct{}, 100)
+	var e = func(context.Context, interface{}) (interface{}, error) {
+		calls <- struct{}{}
+		return DetailedReport{Status: OK}, nil
+	}
+
+	var p, err = NewPoller(e, 10*time.Millisecond, time.Second)
+	assert.Nil(t, err)
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	var done = make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	// Without subscriber, the health checks are not executed.
+	time.Sleep(30 * time.Millisecond)
+	assert.Zero(t, len(calls))
+
+	// The first subscriber starts the health checks, the reports are published to the subscribers.
+	var reports, unsubscribe = p.Subscribe()
+	var report = <-reports
+	assert.Equal(t, OK, report.Status)
+
+	// A new subscriber gets the last report immediately.
+	var reports2, unsubscribe2 = p.Subscribe()
+	select {
+	case report = <-reports2:
+		assert.Equal(t, OK, report.Status)
+	default:
+		assert.Fail(t, "the last report should be published on subscription")
+	}
+
+	// When the last subscriber leaves, the health checks stop.
+	unsubscribe()
+	unsubscribe2()
+	time.Sleep(30 * time.Millisecond)
+	for len(calls) > 0 {
+		<-calls
+	}
+	time.Sleep(30 * time.Millisecond)
+	assert.Zero(t, len(calls))
+
+	// Run returns when the context is done.
+	reports, unsubscribe = p.Subscribe()
+	defer unsubscribe()
+	<-reports
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the poller should stop when the context is done")
+	}
+}
+
+func TestPollerFail(t *testing.T) {
+	var e = func(context.Context, interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("fail")
+	}
+
+	var p, err = NewPoller(e, 10*time.Millisecond, time.Second)
+	assert.Nil(t, err)
+	var reports, unsubscribe = p.Subscribe()
+	defer unsubscribe()
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	p.Run(ctx)
+
+	// The reports are dropped on error.
+	select {
+	case <-reports:
+		assert.Fail(t, "no report should be published on error")
+	default:
+	}
+}
+
+func TestPollerSubsystemInterval(t *testing.T) {
+	var calls = make(chan interface{}, 100)
+	var e = func(_ context.Context, req interface{}) (interface{}, error) {
+		calls <- req
+		return DetailedReport{Status: OK}, nil
+	}
+
+	var p, err = NewPoller(e, time.Hour, time.Second, SubsystemInterval("redis", 10*time.Millisecond), SubsystemInterval("e2e", time.Hour))
+	assert.Nil(t, err)
+	var _, unsubscribe = p.Subscribe()
+	defer unsubscribe()
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	p.Run(ctx)
+	close(calls)
+
+	// Each schedule executes its subsystems only, redis is checked on each tick.
+	var counts = map[string]int{}
+	for req := range calls {
+		var names = req.([]string)
+		switch {
+		case len(names) == 1:
+			counts[names[0]]++
+		default:
+			assert.NotContains(t, names, "redis")
+			assert.NotContains(t, names, "e2e")
+			counts["others"]++
+		}
+	}
+	assert.Equal(t, 1, counts["e2e"])
+	assert.Equal(t, 1, counts["others"])
+	assert.True(t, counts["redis"] > 1)
+}
+
+func TestPollerSubsystemIntervalInvalid(t *testing.T) {
+	var e = func(context.Context, interface{}) (interface{}, error) {
+		return DetailedReport{Status: OK}, nil
+	}
+
+	// Unknown subsystem.
+	{
+		var p, err = NewPoller(e, time.Hour, time.Second, SubsystemInterval("unknown", time.Second))
+		assert.NotNil(t, err)
+		assert.Nil(t, p)
+	}
+
+	// Invalid interval.
+	{
+		var p, err = NewPoller(e, time.Hour, time.Second, SubsystemInterval("redis", 0))
+		assert.NotNil(t, err)
+		assert.Nil(t, p)
+	}
+}
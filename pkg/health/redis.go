@@ -5,7 +5,19 @@ package health
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+)
+
+const (
+	// redisClusterSlots is the number of hash slots of a redis cluster.
+	redisClusterSlots = 16384
+	// redisTimeout is the timeout of a redis command of the health checks, or the time remaining before the
+	// deadline of the caller if it is sooner.
+	redisTimeout = 5 * time.Second
 )
 
 // RedisModule is the health check module for redis.
@@ -14,16 +26,90 @@ type RedisModule interface {
 }
 
 type redisModule struct {
-	redis   Redis
-	enabled bool
+	shards      []Redis
+	minHealthy  int
+	enabled     bool
+	cluster     *redisCluster
+	auth        *redisAuth
+	persistence *redisPersistence
+}
+
+// redisCluster is the configuration of the cluster check.
+type redisCluster struct {
+	replicas int
+}
+
+// redisAuth is the configuration of the authentication check.
+type redisAuth struct {
+	user string
+}
+
+// redisPersistence is the configuration of the persistence check.
+type redisPersistence struct {
+	maxSaveAge time.Duration
+}
+
+// RedisOption is an option of the redis health module.
+type RedisOption func(*redisModule)
+
+// RedisClusterCheck adds a health check that verifies, on the first node, that the redis cluster covers
+// all hash slots. A partial failure can leave slots uncovered, which a ping to one node does not reveal.
+// replicas is the expected number of replicas per master, the cluster is Degraded when a slot range has
+// fewer replicas.
+func RedisClusterCheck(replicas int) RedisOption {
+	return func(m *redisModule) {
+		m.cluster = &redisCluster{
+			replicas: replicas,
+		}
+	}
 }
 
-// RedisReport is the health report returned by the redis module.
+// RedisAuthCheck adds a health check that verifies, on the first node, that the credentials are still
+// accepted. A PING may be answered before authentication, e.g. by a proxy, so after a credential rotation
+// the client still connects but fails on the first real command. If user is set, ACL WHOAMI must return
+// it, which requires Redis 6. Otherwise DBSIZE is run, it requires authentication on all versions.
+func RedisAuthCheck(user string) RedisOption {
+	return func(m *redisModule) {
+		m.auth = &redisAuth{
+			user: user,
+		}
+	}
+}
+
+// RedisPersistenceCheck adds a health check that reads INFO persistence on the first node. A redis whose
+// last background save or AOF write failed loses data on restart, while it still answers the pings, so it is
+// Degraded. It is also Degraded when there are unsaved changes and the last successful save is older than
+// maxSaveAge. 0 disables the age check, e.g. when the RDB snapshots are disabled.
+func RedisPersistenceCheck(maxSaveAge time.Duration) RedisOption {
+	return func(m *redisModule) {
+		m.persistence = &redisPersistence{
+			maxSaveAge: maxSaveAge,
+		}
+	}
+}
+
+// RedisMinHealthyShards sets the number of shards that must answer the ping for redis to be OK. Below it,
+// redis is Degraded, and it is KO when all shards are down. The shards that are down are reported as such
+// whether the quorum holds or not, the quorum only decides the status of the subsystem, see Aggregator.
+// The default is the number of shards, i.e. redis is Degraded as soon as a shard is down. NewComponent rejects a minimum that is not between 1 and the number of shards.
+func RedisMinHealthyShards(min int) RedisOption {
+	return func(m *redisModule) {
+		m.minHealthy = min
+	}
+}
+
+// RedisReport is the health report returned by the redis module. ClusterState and CoveredSlots are set
+// by the cluster check only, they are the cluster_state of CLUSTER INFO and the number of hash slots
+// served by a master in CLUSTER SLOTS. RDBLastBgsaveStatus, AOFLastWriteStatus and LastSave are set by the
+// persistence check only, they are the rdb_last_bgsave_status, aof_last_write_status and
+// rdb_last_save_time of INFO persistence.
 type RedisReport struct {
-	Name     string
-	Duration string
-	Status   Status
-	Error    string
+	Report
+	ClusterState        string
+	CoveredSlots        int
+	RDBLastBgsaveStatus string
+	AOFLastWriteStatus  string
+	LastSave            time.Time
 }
 
 // Redis is the interface of the redis client.
@@ -31,50 +117,552 @@ type Redis interface {
 	Do(cmd string, args ...interface{}) (interface{}, error)
 }
 
+// redisPool is a Redis that executes each command on a connection of the pool.
+type redisPool struct {
+	pool *redigo.Pool
+}
+
+// NewRedisPool returns a Redis that executes each command on a connection of the pool. Unlike a redis
+// connection, it is safe for concurrent use, so the modules that run concurrently, e.g. the redis and
+// end-to-end modules, can share it.
+func NewRedisPool(pool *redigo.Pool) Redis {
+	return &redisPool{
+		pool: pool,
+	}
+}
+
+// Do executes the command on a connection of the pool.
+func (p *redisPool) Do(cmd string, args ...interface{}) (interface{}, error) {
+	var c = p.pool.Get()
+	defer c.Close()
+	return c.Do(cmd, args...)
+}
+
+// DoWithTimeout executes the command on a connection of the pool, with the given read timeout.
+func (p *redisPool) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	var c = p.pool.Get()
+	defer c.Close()
+	return redigo.DoWithTimeout(c, timeout, cmd, args...)
+}
+
+// redisWithTimeout is implemented by the Redis whose commands can time out, e.g. the redis pool and the
+// redigo connections.
+type redisWithTimeout interface {
+	DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error)
+}
+
+// redisDo executes the command within the deadline of ctx, and at most redisTimeout. The Redis that cannot
+// time out, e.g. the no-op one, execute it without timeout.
+func redisDo(ctx context.Context, redis Redis, cmd string, args ...interface{}) (interface{}, error) {
+	if r, ok := redis.(redisWithTimeout); ok {
+		return r.DoWithTimeout(childTimeout(ctx, redisTimeout), cmd, args...)
+	}
+	return redis.Do(cmd, args...)
+}
+
 // NewRedisModule returns the redis health module.
-func NewRedisModule(redis Redis, enabled bool) RedisModule {
-	return &redisModule{
-		redis:   redis,
-		enabled: enabled,
+func NewRedisModule(redis Redis, enabled bool, options ...RedisOption) RedisModule {
+	return NewRedisShardsModule([]Redis{redis}, enabled, options...)
+}
+
+// NewRedisShardsModule returns the redis health module for a sharded redis. Each shard is pinged,
+// the failed pings are reported as Degraded when some shards are still up, and KO when all shards are down.
+// See RedisMinHealthyShards to tolerate some shards down.
+func NewRedisShardsModule(shards []Redis, enabled bool, options ...RedisOption) RedisModule {
+	var m = &redisModule{
+		shards:     shards,
+		minHealthy: len(shards),
+		enabled:    enabled,
+	}
+
+	// Apply options.
+	for _, o := range options {
+		o(m)
 	}
+
+	return m
 }
 
 // HealthChecks executes all health checks for Redis.
-func (m *redisModule) HealthChecks(context.Context) []RedisReport {
+func (m *redisModule) HealthChecks(ctx context.Context) []RedisReport {
+	var reports = m.redisPingChecks(ctx)
+	if m.cluster != nil {
+		reports = append(reports, m.redisClusterCheck(ctx))
+	}
+	if m.auth != nil {
+		reports = append(reports, m.redisAuthCheck(ctx))
+	}
+	if m.persistence != nil {
+		reports = append(reports, m.redisPersistenceCheck(ctx))
+	}
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *redisModule) Enabled() bool {
+	return m.enabled
+}
+
+// Warmup pings each shard once, so the connections of the pool are established before the first health
+// check. The result is ignored.
+func (m *redisModule) Warmup(ctx context.Context) {
+	if !m.enabled {
+		return
+	}
+	for _, shard := range m.shards {
+		redisDo(ctx, shard, "PING")
+	}
+}
+
+// redisPingChecks pings each shard.
+func (m *redisModule) redisPingChecks(ctx context.Context) []RedisReport {
+	if len(m.shards) == 1 {
+		return []RedisReport{m.redisPingCheck(ctx, "ping", m.shards[0])}
+	}
+
 	var reports = []RedisReport{}
-	reports = append(reports, m.redisPingCheck())
+	var failures = 0
+	for i, shard := range m.shards {
+		var r = m.redisPingCheck(ctx, fmt.Sprintf("ping shard %d", i), shard)
+		if r.Status == KO {
+			failures++
+		}
+		reports = append(reports, r)
+	}
+
+	// If some shards are still up, the shards down are only degraded. Whether redis is OK is decided by the
+	// quorum, see Aggregator.
+	if failures == len(m.shards) {
+		return reports
+	}
+	for i := range reports {
+		if reports[i].Status == KO {
+			reports[i].Status = Degraded
+		}
+	}
+
 	return reports
 }
 
-func (m *redisModule) redisPingCheck() RedisReport {
-	var healthCheckName = "ping"
+// Aggregator returns the aggregator of the redis reports: with shards, redis is OK as long as the minimum
+// of healthy shards is up, see RedisMinHealthyShards. It is nil without shards.
+func (m *redisModule) Aggregator() Aggregator {
+	if len(m.shards) < 2 {
+		return nil
+	}
+	return redisShardsAggregator{minHealthy: m.minHealthy}
+}
+
+// redisShardsAggregator aggregates the redis reports. The shards pings count as OK when at least minHealthy
+// shards are up, the reports are then aggregated with DefaultAggregator.
+type redisShardsAggregator struct {
+	minHealthy int
+}
+
+// Aggregate implements Aggregator.
+func (a redisShardsAggregator) Aggregate(reports []Report) Status {
+	var healthy = 0
+	for _, r := range reports {
+		if isShardPing(r) && r.Status == OK {
+			healthy++
+		}
+	}
+	if healthy < a.minHealthy {
+		return DefaultAggregator{}.Aggregate(reports)
+	}
+
+	var others = []Report{}
+	for _, r := range reports {
+		if !isShardPing(r) {
+			others = append(others, r)
+		}
+	}
+	return DefaultAggregator{}.Aggregate(append(others, Report{Status: OK}))
+}
+
+// isShardPing returns true if r is the report of the ping of a shard.
+func isShardPing(r Report) bool {
+	return strings.HasPrefix(r.Name, "ping shard ")
+}
+
+func (m *redisModule) redisPingCheck(ctx context.Context, healthCheckName string, redis Redis) RedisReport {
+	var healthCheckDescription = "Pings the Redis that stores the logs. The service works without it, but the logs are only written to stdout. With shards, Degraded means fewer shards than the quorum are up."
 
 	if !m.enabled {
 		return RedisReport{
-			Name:     healthCheckName,
-			Duration: "N/A",
-			Status:   Deactivated,
+			Report: Report{
+				Name:        healthCheckName,
+				Description: healthCheckDescription,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			},
 		}
 	}
 
 	var now = time.Now()
-	var _, err = m.redis.Do("PING")
+	var _, err = redisDo(ctx, redis, "PING")
 	var duration = time.Since(now)
 
 	var error string
 	var s Status
+	var category Category
 	switch {
 	case err != nil:
 		error = fmt.Sprintf("could not ping redis: %v", err.Error())
 		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return RedisReport{
+		Report: Report{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    duration.String(),
+			Status:      s,
+			Error:       error,
+			Category:    category,
+			Attempts:    1,
+		},
+	}
+}
+
+func (m *redisModule) redisClusterCheck(ctx context.Context) RedisReport {
+	var healthCheckName = "cluster slots"
+	var healthCheckDescription = "Checks that the Redis cluster state is ok and that all hash slots are served. If it fails, the logs of the uncovered slots are lost. Degraded means some replicas are down."
+
+	if !m.enabled {
+		return RedisReport{
+			Report: Report{
+				Name:        healthCheckName,
+				Description: healthCheckDescription,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			},
+		}
+	}
+
+	var now = time.Now()
+	var state, err = redisClusterState(ctx, m.shards[0])
+	var slots redisSlots
+	if err == nil {
+		slots, err = redisClusterSlotRanges(ctx, m.shards[0])
+	}
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not get redis cluster state: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	case state != "ok":
+		error = fmt.Sprintf("redis cluster state is '%s', %d of %d slots covered", state, slots.covered, redisClusterSlots)
+		s = KO
+		category = Capacity
+	case slots.covered < redisClusterSlots:
+		error = fmt.Sprintf("redis cluster slots not covered: %d of %d slots covered", slots.covered, redisClusterSlots)
+		s = KO
+		category = Capacity
+	case slots.minReplicas < m.cluster.replicas:
+		error = fmt.Sprintf("redis cluster replicas down: a slot range has %d of %d replicas", slots.minReplicas, m.cluster.replicas)
+		s = Degraded
+		category = Capacity
 	default:
 		s = OK
 	}
 
 	return RedisReport{
-		Name:     healthCheckName,
-		Duration: duration.String(),
-		Status:   s,
-		Error:    error,
+		Report: Report{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    duration.String(),
+			Status:      s,
+			Error:       error,
+			Category:    category,
+			Attempts:    1,
+		},
+		ClusterState: state,
+		CoveredSlots: slots.covered,
+	}
+}
+
+// redisClusterState returns the cluster_state field of CLUSTER INFO.
+func redisClusterState(ctx context.Context, redis Redis) (string, error) {
+	var reply, err = redisDo(ctx, redis, "CLUSTER", "INFO")
+	if err != nil {
+		return "", err
+	}
+
+	var fields map[string]string
+	fields, err = redisInfoFields(reply, "CLUSTER INFO")
+	if err != nil {
+		return "", err
+	}
+
+	var state, ok = fields["cluster_state"]
+	if !ok {
+		return "", fmt.Errorf("no cluster_state in CLUSTER INFO reply")
+	}
+	return state, nil
+}
+
+// redisInfoFields parses the reply of an INFO command, made of "field:value" lines and of comments.
+func redisInfoFields(reply interface{}, command string) (map[string]string, error) {
+	var info string
+	switch r := reply.(type) {
+	case []byte:
+		info = string(r)
+	case string:
+		info = r
+	default:
+		return nil, fmt.Errorf("unexpected %s reply: %T", command, reply)
+	}
+
+	var fields = map[string]string{}
+	for _, line := range strings.Split(info, "\n") {
+		var kv = strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(kv) == 2 && !strings.HasPrefix(kv[0], "#") {
+			fields[kv[0]] = kv[1]
+		}
+	}
+	return fields, nil
+}
+
+// redisSlots is the summary of CLUSTER SLOTS: the number of slots served by a master, and the lowest
+// number of replicas of a slot range.
+type redisSlots struct {
+	covered     int
+	minReplicas int
+}
+
+// redisClusterSlotRanges summarises CLUSTER SLOTS. Each slot range is an array whose elements are the
+// first slot, the last slot, the master and the replicas. The failed replicas are not listed.
+func redisClusterSlotRanges(ctx context.Context, redis Redis) (redisSlots, error) {
+	var reply, err = redisDo(ctx, redis, "CLUSTER", "SLOTS")
+	if err != nil {
+		return redisSlots{}, err
+	}
+
+	var ranges, ok = reply.([]interface{})
+	if !ok {
+		return redisSlots{}, fmt.Errorf("unexpected CLUSTER SLOTS reply: %T", reply)
+	}
+
+	var slots = redisSlots{}
+	for i, r := range ranges {
+		var fields, ok = r.([]interface{})
+		if !ok || len(fields) < 3 {
+			return redisSlots{}, fmt.Errorf("unexpected CLUSTER SLOTS slot range: %v", r)
+		}
+
+		var start, startOK = fields[0].(int64)
+		var end, endOK = fields[1].(int64)
+		if !startOK || !endOK {
+			return redisSlots{}, fmt.Errorf("unexpected CLUSTER SLOTS slot range: %v", r)
+		}
+		slots.covered += int(end - start + 1)
+
+		var replicas = len(fields) - 3
+		if i == 0 || replicas < slots.minReplicas {
+			slots.minReplicas = replicas
+		}
+	}
+	return slots, nil
+}
+
+func (m *redisModule) redisAuthCheck(ctx context.Context) RedisReport {
+	var healthCheckName = "auth"
+	var healthCheckDescription = "Runs a command that requires authentication on the Redis that stores the logs. If it fails, the credentials were rotated without updating the service, and the logs are lost."
+
+	if !m.enabled {
+		return RedisReport{
+			Report: Report{
+				Name:        healthCheckName,
+				Description: healthCheckDescription,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			},
+		}
+	}
+
+	var now = time.Now()
+	var err = redisAuthenticated(ctx, m.shards[0], m.auth.user)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil && errorCategory(err) == Auth:
+		error = fmt.Sprintf("authentication failed: %v", err.Error())
+		s = KO
+		category = Auth
+	case err != nil:
+		error = fmt.Sprintf("could not check redis authentication: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return RedisReport{
+		Report: Report{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    duration.String(),
+			Status:      s,
+			Error:       error,
+			Category:    category,
+			Attempts:    1,
+		},
+	}
+}
+
+// redisAuthErrors are the prefixes of the redis errors caused by missing or rejected credentials.
+var redisAuthErrors = []string{"NOAUTH", "WRONGPASS", "NOPERM", "ERR invalid password"}
+
+// redisAuthenticated runs a command that requires authentication. If user is set, it checks that ACL
+// WHOAMI returns it. The errors caused by the credentials have the category Auth.
+func redisAuthenticated(ctx context.Context, redis Redis, user string) error {
+	var reply interface{}
+	var err error
+	if user != "" {
+		reply, err = redisDo(ctx, redis, "ACL", "WHOAMI")
+	} else {
+		reply, err = redisDo(ctx, redis, "DBSIZE")
+	}
+
+	if err != nil {
+		for _, prefix := range redisAuthErrors {
+			if strings.HasPrefix(err.Error(), prefix) {
+				return withCategory(Auth, err)
+			}
+		}
+		return err
+	}
+
+	if user == "" {
+		return nil
+	}
+
+	var whoami string
+	switch r := reply.(type) {
+	case []byte:
+		whoami = string(r)
+	case string:
+		whoami = r
+	default:
+		return fmt.Errorf("unexpected ACL WHOAMI reply: %T", reply)
+	}
+	if whoami != user {
+		return withCategory(Auth, fmt.Errorf("authenticated as '%s', expected '%s'", whoami, user))
+	}
+	return nil
+}
+
+func (m *redisModule) redisPersistenceCheck(ctx context.Context) RedisReport {
+	var healthCheckName = "persistence"
+	var healthCheckDescription = "Checks that the last background save and AOF write of the Redis that stores the logs succeeded. If it fails, the logs are lost when Redis restarts."
+
+	if !m.enabled {
+		return RedisReport{
+			Report: Report{
+				Name:        healthCheckName,
+				Description: healthCheckDescription,
+				Duration:    "N/A",
+				Status:      Deactivated,
+			},
+		}
+	}
+
+	var now = time.Now()
+	var p, err = redisPersistenceInfo(ctx, m.shards[0])
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not get redis persistence: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	case p.rdbStatus == "err":
+		error = "redis last background save failed"
+		s = Degraded
+		category = DataIntegrity
+	case p.aofStatus == "err":
+		error = "redis last AOF write failed"
+		s = Degraded
+		category = DataIntegrity
+	case m.persistence.maxSaveAge > 0 && p.changes > 0 && now.Sub(p.lastSave) > m.persistence.maxSaveAge:
+		error = fmt.Sprintf("redis last save is %v old, above %v, with %d unsaved changes", now.Sub(p.lastSave).Truncate(time.Second), m.persistence.maxSaveAge, p.changes)
+		s = Degraded
+		category = DataIntegrity
+	default:
+		s = OK
+	}
+
+	return RedisReport{
+		Report: Report{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    duration.String(),
+			Status:      s,
+			Error:       error,
+			Category:    category,
+			Attempts:    1,
+		},
+		RDBLastBgsaveStatus: p.rdbStatus,
+		AOFLastWriteStatus:  p.aofStatus,
+		LastSave:            p.lastSave,
+	}
+}
+
+// redisPersistenceState is the summary of INFO persistence.
+type redisPersistenceState struct {
+	rdbStatus string
+	aofStatus string
+	lastSave  time.Time
+	changes   int64
+}
+
+// redisPersistenceInfo returns the status of the last background save and AOF write, the time of the last
+// successful save, and the number of changes since, from INFO persistence.
+func redisPersistenceInfo(ctx context.Context, redis Redis) (redisPersistenceState, error) {
+	var reply, err = redisDo(ctx, redis, "INFO", "persistence")
+	if err != nil {
+		return redisPersistenceState{}, err
+	}
+
+	var fields map[string]string
+	fields, err = redisInfoFields(reply, "INFO persistence")
+	if err != nil {
+		return redisPersistenceState{}, err
+	}
+
+	var p = redisPersistenceState{
+		rdbStatus: fields["rdb_last_bgsave_status"],
+		aofStatus: fields["aof_last_write_status"],
+	}
+	if p.rdbStatus == "" {
+		return redisPersistenceState{}, fmt.Errorf("no rdb_last_bgsave_status in INFO persistence reply")
+	}
+
+	var lastSave int64
+	lastSave, err = strconv.ParseInt(fields["rdb_last_save_time"], 10, 64)
+	if err != nil {
+		return redisPersistenceState{}, fmt.Errorf("malformed rdb_last_save_time in INFO persistence reply: %v", err)
+	}
+	p.lastSave = time.Unix(lastSave, 0)
+
+	p.changes, err = strconv.ParseInt(fields["rdb_changes_since_last_save"], 10, 64)
+	if err != nil {
+		return redisPersistenceState{}, fmt.Errorf("malformed rdb_changes_since_last_save in INFO persistence reply: %v", err)
 	}
+	return p, nil
 }
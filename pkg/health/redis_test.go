@@ -3,10 +3,15 @@ package health_test
 import (
 	"context"
 	"fmt"
+	"net"
+	"sync"
 	"testing"
+	"time"
 
 	. "github.com/cloudtrust/flaki-service/pkg/health"
 	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/go-kit/kit/log"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -23,9 +28,11 @@ func TestRedisHealthChecks(t *testing.T) {
 		mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(1)
 		var report = m.HealthChecks(context.Background())[0]
 		assert.Equal(t, "ping", report.Name)
+		assert.NotZero(t, report.Description)
 		assert.NotZero(t, report.Duration)
 		assert.Equal(t, OK, report.Status)
 		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
 	}
 
 	// Redis fail.
@@ -38,6 +45,107 @@ func TestRedisHealthChecks(t *testing.T) {
 		assert.NotZero(t, report.Error)
 	}
 }
+
+func TestRedisShardsHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockShard0 = mock.NewRedis(mockCtrl)
+	var mockShard1 = mock.NewRedis(mockCtrl)
+
+	var m = NewRedisShardsModule([]Redis{mockShard0, mockShard1}, true)
+
+	// All shards up.
+	{
+		mockShard0.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockShard1.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Len(t, reports, 2)
+		assert.Equal(t, "ping shard 0", reports[0].Name)
+		assert.Equal(t, OK, reports[0].Status)
+		assert.Equal(t, "ping shard 1", reports[1].Name)
+		assert.Equal(t, OK, reports[1].Status)
+	}
+
+	// Some shards down.
+	{
+		mockShard0.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockShard1.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, OK, reports[0].Status)
+		assert.Equal(t, Degraded, reports[1].Status)
+		assert.Equal(t, "could not ping redis: fail", reports[1].Error)
+	}
+
+	// All shards down.
+	{
+		mockShard0.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		mockShard1.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, KO, reports[0].Status)
+		assert.Equal(t, KO, reports[1].Status)
+	}
+}
+
+func TestRedisMinHealthyShards(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockShard0 = mock.NewRedis(mockCtrl)
+	var mockShard1 = mock.NewRedis(mockCtrl)
+	var mockShard2 = mock.NewRedis(mockCtrl)
+
+	var m = NewRedisShardsModule([]Redis{mockShard0, mockShard1, mockShard2}, true, RedisMinHealthyShards(2))
+	var c, err = NewComponent(nil, nil, m, nil)
+	assert.Nil(t, err)
+
+	// The quorum holds, redis is OK but the shard down is reported as such.
+	{
+		mockShard0.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockShard1.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		mockShard2.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		var report = c.AllHealthChecksDetailed(context.Background()).Subsystems["redis"]
+		assert.Equal(t, OK, report.Status)
+		assert.Len(t, report.Reports, 3)
+		assert.Equal(t, OK, report.Reports[0].Status)
+		assert.Equal(t, Degraded, report.Reports[1].Status)
+		assert.Equal(t, "could not ping redis: fail", report.Reports[1].Error)
+		assert.Equal(t, OK, report.Reports[2].Status)
+	}
+
+	// Below the quorum.
+	{
+		mockShard0.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockShard1.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		mockShard2.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		var report = c.AllHealthChecksDetailed(context.Background()).Subsystems["redis"]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, OK, report.Reports[0].Status)
+		assert.Equal(t, Degraded, report.Reports[1].Status)
+		assert.Equal(t, Degraded, report.Reports[2].Status)
+	}
+
+	// All shards down.
+	{
+		mockShard0.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		mockShard1.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		mockShard2.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		var report = c.AllHealthChecksDetailed(context.Background()).Subsystems["redis"]
+		assert.Equal(t, KO, report.Status)
+		for _, r := range report.Reports {
+			assert.Equal(t, KO, r.Status)
+		}
+	}
+
+	// The aggregator of the subsystem overrides the quorum.
+	{
+		c, err = NewComponent(nil, nil, m, nil, SubsystemAggregator("redis", AllMustPass{}))
+		assert.Nil(t, err)
+		mockShard0.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockShard1.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		mockShard2.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		assert.Equal(t, KO, c.AllHealthChecksDetailed(context.Background()).Subsystems["redis"].Status)
+	}
+}
+
 func TestNoopRedisHealthChecks(t *testing.T) {
 	var m = NewRedisModule(nil, false)
 
@@ -46,4 +154,413 @@ func TestNoopRedisHealthChecks(t *testing.T) {
 	assert.NotZero(t, report.Duration)
 	assert.Equal(t, Deactivated, report.Status)
 	assert.Zero(t, report.Error)
+	assert.Zero(t, report.Attempts)
+}
+
+func TestRedisWarmup(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedis1 = mock.NewRedis(mockCtrl)
+	var mockRedis2 = mock.NewRedis(mockCtrl)
+
+	// The errors are ignored.
+	mockRedis1.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+	mockRedis2.EXPECT().Do("PING").Return(nil, nil).Times(1)
+
+	var m = NewRedisShardsModule([]Redis{mockRedis1, mockRedis2}, true)
+	m = MakeRedisModuleLoggingMW(log.NewNopLogger())(m)
+	m = MakeRedisModuleTracingMW(nil)(m)
+
+	var w, ok = m.(Warmer)
+	assert.True(t, ok)
+	w.Warmup(context.Background())
+
+	// Deactivated.
+	NewRedisModule(nil, false).(Warmer).Warmup(context.Background())
+}
+
+func TestRedisClusterHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedis = mock.NewRedis(mockCtrl)
+
+	var m = NewRedisModule(mockRedis, true, RedisClusterCheck(1))
+
+	var info = func(state string) []byte {
+		return []byte("cluster_state:" + state + "\r\ncluster_slots_assigned:16384\r\ncluster_known_nodes:6\r\n")
+	}
+	var slotRange = func(start, end int64, replicas int) []interface{} {
+		var r = []interface{}{start, end, []interface{}{[]byte("10.0.0.1"), int64(6379)}}
+		for i := 0; i < replicas; i++ {
+			r = append(r, []interface{}{[]byte("10.0.0.2"), int64(6379)})
+		}
+		return r
+	}
+
+	// All slots covered.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockRedis.EXPECT().Do("CLUSTER", "INFO").Return(info("ok"), nil).Times(1)
+		mockRedis.EXPECT().Do("CLUSTER", "SLOTS").Return([]interface{}{slotRange(0, 8191, 1), slotRange(8192, 16383, 1)}, nil).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Len(t, reports, 2)
+		var report = reports[1]
+		assert.Equal(t, "cluster slots", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+		assert.Equal(t, "ok", report.ClusterState)
+		assert.Equal(t, 16384, report.CoveredSlots)
+	}
+
+	// Replica down.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockRedis.EXPECT().Do("CLUSTER", "INFO").Return(info("ok"), nil).Times(1)
+		mockRedis.EXPECT().Do("CLUSTER", "SLOTS").Return([]interface{}{slotRange(0, 8191, 1), slotRange(8192, 16383, 0)}, nil).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "redis cluster replicas down: a slot range has 0 of 1 replicas", report.Error)
+	}
+
+	// Slots not covered.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockRedis.EXPECT().Do("CLUSTER", "INFO").Return(info("ok"), nil).Times(1)
+		mockRedis.EXPECT().Do("CLUSTER", "SLOTS").Return([]interface{}{slotRange(0, 8191, 1)}, nil).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "redis cluster slots not covered: 8192 of 16384 slots covered", report.Error)
+		assert.Equal(t, 8192, report.CoveredSlots)
+	}
+
+	// Cluster state fail.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockRedis.EXPECT().Do("CLUSTER", "INFO").Return(info("fail"), nil).Times(1)
+		mockRedis.EXPECT().Do("CLUSTER", "SLOTS").Return([]interface{}{slotRange(0, 8191, 1)}, nil).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "redis cluster state is 'fail', 8192 of 16384 slots covered", report.Error)
+		assert.Equal(t, "fail", report.ClusterState)
+	}
+
+	// Redis fail.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		mockRedis.EXPECT().Do("CLUSTER", "INFO").Return(nil, fmt.Errorf("fail")).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not get redis cluster state: fail", report.Error)
+	}
+}
+
+func TestRedisPersistenceHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedis = mock.NewRedis(mockCtrl)
+
+	var m = NewRedisModule(mockRedis, true, RedisPersistenceCheck(time.Hour))
+
+	var lastSave = time.Now().Add(-2 * time.Hour).Unix()
+	var info = func(rdb, aof string, changes int) []byte {
+		return []byte(fmt.Sprintf("# Persistence\r\nloading:0\r\nrdb_changes_since_last_save:%d\r\nrdb_last_save_time:%d\r\nrdb_last_bgsave_status:%s\r\naof_enabled:1\r\naof_last_write_status:%s\r\n", changes, lastSave, rdb, aof))
+	}
+
+	// The last save is old, but there is nothing to save.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockRedis.EXPECT().Do("INFO", "persistence").Return(info("ok", "ok", 0), nil).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Len(t, reports, 2)
+		var report = reports[1]
+		assert.Equal(t, "persistence", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+		assert.Equal(t, "ok", report.RDBLastBgsaveStatus)
+		assert.Equal(t, "ok", report.AOFLastWriteStatus)
+		assert.Equal(t, lastSave, report.LastSave.Unix())
+	}
+
+	// The last save is too old.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockRedis.EXPECT().Do("INFO", "persistence").Return(info("ok", "ok", 12), nil).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, DataIntegrity, report.Category)
+		assert.Contains(t, report.Error, "above 1h0m0s, with 12 unsaved changes")
+	}
+
+	// Background save failed.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockRedis.EXPECT().Do("INFO", "persistence").Return(info("err", "ok", 0), nil).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "redis last background save failed", report.Error)
+		assert.Equal(t, "err", report.RDBLastBgsaveStatus)
+	}
+
+	// AOF write failed.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockRedis.EXPECT().Do("INFO", "persistence").Return(info("ok", "err", 0), nil).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "redis last AOF write failed", report.Error)
+	}
+
+	// Malformed reply.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(1)
+		mockRedis.EXPECT().Do("INFO", "persistence").Return([]byte("# Persistence\r\nloading:0\r\n"), nil).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not get redis persistence: no rdb_last_bgsave_status in INFO persistence reply", report.Error)
+	}
+
+	// Redis fail.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		mockRedis.EXPECT().Do("INFO", "persistence").Return(nil, fmt.Errorf("fail")).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not get redis persistence: fail", report.Error)
+	}
 }
+
+func TestRedisAuthHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedis = mock.NewRedis(mockCtrl)
+
+	var m = NewRedisModule(mockRedis, true, RedisAuthCheck(""))
+
+	// Authenticated.
+	{
+		mockRedis.EXPECT().Do("PING").Return([]byte("PONG"), nil).Times(1)
+		mockRedis.EXPECT().Do("DBSIZE").Return(int64(12), nil).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Len(t, reports, 2)
+		var report = reports[1]
+		assert.Equal(t, "auth", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// The proxy answers the ping, but the credentials were rotated.
+	{
+		mockRedis.EXPECT().Do("PING").Return([]byte("PONG"), nil).Times(1)
+		mockRedis.EXPECT().Do("DBSIZE").Return(nil, fmt.Errorf("NOAUTH Authentication required.")).Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, OK, reports[0].Status)
+		assert.Equal(t, KO, reports[1].Status)
+		assert.Equal(t, "authentication failed: NOAUTH Authentication required.", reports[1].Error)
+		assert.Equal(t, Auth, reports[1].Category)
+	}
+
+	// Connection error.
+	{
+		mockRedis.EXPECT().Do("PING").Return(nil, fmt.Errorf("fail")).Times(1)
+		mockRedis.EXPECT().Do("DBSIZE").Return(nil, fmt.Errorf("fail")).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not check redis authentication: fail", report.Error)
+		assert.Equal(t, Uncategorized, report.Category)
+	}
+}
+
+func TestRedisAuthHealthChecksACL(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedis = mock.NewRedis(mockCtrl)
+
+	var m = NewRedisModule(mockRedis, true, RedisAuthCheck("flaki"))
+
+	// Authenticated as the expected user.
+	{
+		mockRedis.EXPECT().Do("PING").Return([]byte("PONG"), nil).Times(1)
+		mockRedis.EXPECT().Do("ACL", "WHOAMI").Return([]byte("flaki"), nil).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, OK, report.Status)
+	}
+
+	// Fell back to the default user.
+	{
+		mockRedis.EXPECT().Do("PING").Return([]byte("PONG"), nil).Times(1)
+		mockRedis.EXPECT().Do("ACL", "WHOAMI").Return([]byte("default"), nil).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "authentication failed: authenticated as 'default', expected 'flaki'", report.Error)
+		assert.Equal(t, Auth, report.Category)
+	}
+
+	// Wrong password.
+	{
+		mockRedis.EXPECT().Do("PING").Return([]byte("PONG"), nil).Times(1)
+		mockRedis.EXPECT().Do("ACL", "WHOAMI").Return(nil, fmt.Errorf("WRONGPASS invalid username-password pair")).Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, Auth, report.Category)
+	}
+
+	// Deactivated.
+	{
+		var report = NewRedisModule(mockRedis, false, RedisAuthCheck("flaki")).HealthChecks(context.Background())[1]
+		assert.Equal(t, "auth", report.Name)
+		assert.Equal(t, Deactivated, report.Status)
+	}
+}
+
+func TestRedisPool(t *testing.T) {
+	var pool = newFakeRedisPool()
+	defer pool.Close()
+
+	var r = NewRedisPool(pool)
+
+	// The commands executed concurrently run on distinct connections.
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var key = fmt.Sprintf("key%d", i)
+			var _, err = r.Do("SET", key, key)
+			assert.Nil(t, err)
+			var reply, _ = r.Do("GET", key)
+			assert.Equal(t, key, reply)
+		}(i)
+	}
+	wg.Wait()
+
+	var reply, err = r.Do("PING")
+	assert.Equal(t, "PONG", reply)
+	assert.Nil(t, err)
+}
+
+func TestRedisParentDeadline(t *testing.T) {
+	var pool, stop = newSilentRedisPool(t)
+	defer stop()
+
+	// The commands time out after 5s, the deadline of the caller wins.
+	var m = NewRedisModule(NewRedisPool(pool), true, RedisClusterCheck(0), RedisAuthCheck(""), RedisPersistenceCheck(0))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var now = time.Now()
+	var reports = m.HealthChecks(ctx)
+	assert.True(t, time.Since(now) < 2*time.Second)
+	assert.Len(t, reports, 4)
+	for _, report := range reports {
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, Timeout, report.Category)
+	}
+}
+
+// newSilentRedisPool returns a pool of connections to a redis server that accepts the connections but never
+// replies, and the function that closes them.
+func newSilentRedisPool(t *testing.T) (*redigo.Pool, func()) {
+	var l, err = net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	var mutex sync.Mutex
+	var conns = []net.Conn{}
+	go func() {
+		for {
+			var conn, err = l.Accept()
+			if err != nil {
+				return
+			}
+			mutex.Lock()
+			conns = append(conns, conn)
+			mutex.Unlock()
+		}
+	}()
+
+	var pool = &redigo.Pool{
+		Dial: func() (redigo.Conn, error) {
+			return redigo.Dial("tcp", l.Addr().String())
+		},
+	}
+
+	return pool, func() {
+		pool.Close()
+		l.Close()
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+}
+
+// newFakeRedisPool returns a pool of connections to a fake redis server.
+func newFakeRedisPool() *redigo.Pool {
+	var store = &fakeRedisStore{data: map[string]interface{}{}}
+	return &redigo.Pool{
+		MaxIdle: 2,
+		Dial: func() (redigo.Conn, error) {
+			return &fakeRedisConn{store: store}, nil
+		},
+	}
+}
+
+// fakeRedisStore is the data of the fake redis server, shared by its connections.
+type fakeRedisStore struct {
+	mu   sync.Mutex
+	data map[string]interface{}
+}
+
+// fakeRedisConn is a connection to the fake redis server. Like a redis connection, it is not safe for
+// concurrent use: the race detector reports the commands executed concurrently on it.
+type fakeRedisConn struct {
+	store    *fakeRedisStore
+	commands int
+}
+
+func (c *fakeRedisConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	c.commands++
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	switch cmd {
+	case "PING":
+		return "PONG", nil
+	case "SET":
+		c.store.data[args[0].(string)] = args[1]
+		return "OK", nil
+	case "GET":
+		return c.store.data[args[0].(string)], nil
+	case "DEL":
+		delete(c.store.data, args[0].(string))
+		return int64(1), nil
+	}
+	return nil, nil
+}
+
+func (c *fakeRedisConn) DoWithTimeout(_ time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	return c.Do(cmd, args...)
+}
+
+func (c *fakeRedisConn) Send(string, ...interface{}) error {
+	c.commands++
+	return nil
+}
+
+func (c *fakeRedisConn) ReceiveWithTimeout(time.Duration) (interface{}, error) {
+	return nil, nil
+}
+
+func (c *fakeRedisConn) Close() error                  { return nil }
+func (c *fakeRedisConn) Err() error                    { return nil }
+func (c *fakeRedisConn) Flush() error                  { return nil }
+func (c *fakeRedisConn) Receive() (interface{}, error) { return nil, nil }
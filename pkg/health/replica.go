@@ -0,0 +1,299 @@
+package health
+
+//go:generate mockgen -destination=./mock/replica.go -package=mock -mock_names=ReplicaModule=ReplicaModule github.com/cloudtrust/flaki-service/pkg/health ReplicaModule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReplicaModule is the health check module for a SQL replica, Postgres or MySQL. A replica that is
+// reachable but lagging serves stale data, so it checks the replication lag rather than the connectivity.
+type ReplicaModule interface {
+	HealthChecks(context.Context) []ReplicaReport
+}
+
+type replicaModule struct {
+	db      ReplicaDB
+	primary ReplicaDB
+	dialect string
+	warn    time.Duration
+	crit    time.Duration
+	enabled bool
+}
+
+// ReplicaOption is an option of the replica health module.
+type ReplicaOption func(*replicaModule)
+
+// ReplicaPrimary adds the health checks "primary-ping" and "replica-ping", that ping the primary and the
+// replica separately, before the lag check. A replica down is then reported apart from the primary. The
+// status of the subsystem is computed from the three checks by its aggregator, see SubsystemAggregator,
+// e.g. with Quorum{Min: 1} it is Degraded rather than KO while the primary or the replica is still up.
+func ReplicaPrimary(primary ReplicaDB) ReplicaOption {
+	return func(m *replicaModule) {
+		m.primary = primary
+	}
+}
+
+// ReplicaReport is the health report returned by the replica module.
+type ReplicaReport = Report
+
+// ReplicaDB is the interface of the replica database, e.g. *sql.DB.
+type ReplicaDB interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// The queries of the replication lag, in seconds. On Postgres, the lag is 0 when the replica replayed all
+// it received, otherwise an idle primary would look like a lagging replica. As a stopped replica also
+// replayed all it received, the query reports whether the WAL receiver is streaming from the primary.
+const (
+	postgresLagQuery = "SELECT pg_is_in_recovery() AS in_recovery, " +
+		"EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status = 'streaming') AS streaming, " +
+		"CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END AS lag"
+	mysqlLagQuery = "SHOW SLAVE STATUS"
+)
+
+var (
+	// errNotReplica is returned when the database is not a replica, e.g. the DSN targets the primary.
+	errNotReplica = errors.New("database is not a replica")
+	// errNoReplayedTransaction is returned when the replica did not replay any transaction yet, so its lag
+	// is unknown, e.g. it is still catching up after its creation.
+	errNoReplayedTransaction = errors.New("replica did not replay any transaction yet")
+)
+
+// NewReplicaModule returns the replica health module. dialect is "postgres" or "mysql". The replica is
+// Degraded when it lags by more than warn, and KO when it lags by more than crit or the replication is
+// stopped.
+func NewReplicaModule(db ReplicaDB, dialect string, warn, crit time.Duration, enabled bool, options ...ReplicaOption) ReplicaModule {
+	var m = &replicaModule{
+		db:      db,
+		dialect: dialect,
+		warn:    warn,
+		crit:    crit,
+		enabled: enabled,
+	}
+
+	// Apply options.
+	for _, o := range options {
+		o(m)
+	}
+
+	return m
+}
+
+// HealthChecks executes all health checks for the replica.
+func (m *replicaModule) HealthChecks(ctx context.Context) []ReplicaReport {
+	var reports = []ReplicaReport{}
+	if m.primary != nil {
+		reports = append(reports, m.replicaPingCheck(ctx, "primary", m.primary))
+		reports = append(reports, m.replicaPingCheck(ctx, "replica", m.db))
+	}
+	reports = append(reports, m.replicaLagCheck(ctx))
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *replicaModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *replicaModule) replicaPingCheck(ctx context.Context, role string, db ReplicaDB) ReplicaReport {
+	var healthCheckName = fmt.Sprintf("%s-ping", role)
+	var healthCheckDescription = fmt.Sprintf("Executes a trivial query on the %s database. If it fails, the %s is down or unreachable.", role, role)
+
+	if !m.enabled {
+		return ReplicaReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var err = pingDB(ctx, db)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not ping %s: %v", role, err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return ReplicaReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// pingDB executes a trivial query, within the context deadline.
+func pingDB(ctx context.Context, db ReplicaDB) error {
+	var rows, err = db.QueryContext(ctx, "SELECT 1")
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+func (m *replicaModule) replicaLagCheck(ctx context.Context) ReplicaReport {
+	var healthCheckName = "lag"
+	var healthCheckDescription = "Queries the replication lag of the replica. If it fails, the replica serves stale data, or no data at all if the replication is stopped."
+
+	if !m.enabled {
+		return ReplicaReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var lag, err = m.replicationLag(ctx)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err == errNoReplayedTransaction:
+		error = err.Error()
+		s = Degraded
+		category = DataIntegrity
+	case err != nil:
+		error = fmt.Sprintf("could not query replication lag: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	case lag >= m.crit:
+		error = fmt.Sprintf("replica lagging by %v, the data is stale", lag)
+		s = KO
+		category = DataIntegrity
+	case lag >= m.warn:
+		error = fmt.Sprintf("replica lagging by %v", lag)
+		s = Degraded
+		category = DataIntegrity
+	default:
+		s = OK
+	}
+
+	return ReplicaReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// replicationLag queries the replication lag, within the context deadline.
+func (m *replicaModule) replicationLag(ctx context.Context) (time.Duration, error) {
+	switch m.dialect {
+	case "postgres":
+		return queryPostgresLag(ctx, m.db)
+	case "mysql":
+		return queryLag(ctx, m.db, mysqlLagQuery, "Seconds_Behind_Master")
+	default:
+		return 0, fmt.Errorf("unknown sql dialect '%s'", m.dialect)
+	}
+}
+
+// queryPostgresLag executes the Postgres lag query and returns the lag. The replication is stopped when the
+// WAL receiver is not streaming, whatever the lag.
+func queryPostgresLag(ctx context.Context, db ReplicaDB) (time.Duration, error) {
+	var rows, err = db.QueryContext(ctx, postgresLagQuery)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, errors.New("no replication status")
+	}
+
+	var inRecovery, streaming bool
+	var lag sql.NullFloat64
+	if err = rows.Scan(&inRecovery, &streaming, &lag); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case !inRecovery:
+		return 0, errNotReplica
+	case !streaming:
+		return 0, withCategory(DataIntegrity, errors.New("replication stopped, the WAL receiver is not streaming"))
+	case !lag.Valid:
+		return 0, errNoReplayedTransaction
+	default:
+		return time.Duration(lag.Float64 * float64(time.Second)), nil
+	}
+}
+
+// queryLag executes the query and returns the lag in the column named column. The lag is in seconds. There
+// is no row when the database is not a replica, and the lag is NULL when the replication is stopped.
+func queryLag(ctx context.Context, db ReplicaDB, query, column string) (time.Duration, error) {
+	var rows, err = db.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	columns, err = rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	var index = -1
+	for i, c := range columns {
+		if c == column {
+			index = i
+		}
+	}
+	if index < 0 {
+		return 0, fmt.Errorf("no column '%s' in the replication status", column)
+	}
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, errNotReplica
+	}
+
+	var values = make([]interface{}, len(columns))
+	var lag sql.NullFloat64
+	for i := range values {
+		if i == index {
+			values[i] = &lag
+		} else {
+			values[i] = new(sql.RawBytes)
+		}
+	}
+	if err = rows.Scan(values...); err != nil {
+		return 0, err
+	}
+
+	if !lag.Valid {
+		return 0, withCategory(DataIntegrity, errors.New("replication stopped"))
+	}
+	return time.Duration(lag.Float64 * float64(time.Second)), nil
+}
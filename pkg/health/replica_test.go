@@ -0,0 +1,248 @@
+package health_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+// replicaDriver is a SQL driver that answers all the queries with the configured columns and rows.
+type replicaDriver struct {
+	columns []string
+	rows    [][]driver.Value
+	err     error
+}
+
+func (d *replicaDriver) Open(string) (driver.Conn, error) { return &replicaConn{d}, nil }
+
+type replicaConn struct{ d *replicaDriver }
+
+func (c *replicaConn) Prepare(string) (driver.Stmt, error) { return &replicaStmt{c.d}, nil }
+func (c *replicaConn) Close() error                        { return nil }
+func (c *replicaConn) Begin() (driver.Tx, error)           { return nil, fmt.Errorf("not supported") }
+
+type replicaStmt struct{ d *replicaDriver }
+
+func (s *replicaStmt) Close() error  { return nil }
+func (s *replicaStmt) NumInput() int { return -1 }
+func (s *replicaStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("not supported")
+}
+func (s *replicaStmt) Query([]driver.Value) (driver.Rows, error) {
+	if s.d.err != nil {
+		return nil, s.d.err
+	}
+	return &replicaRows{d: s.d}, nil
+}
+
+type replicaRows struct {
+	d *replicaDriver
+	i int
+}
+
+func (r *replicaRows) Columns() []string { return r.d.columns }
+func (r *replicaRows) Close() error      { return nil }
+func (r *replicaRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.d.rows) {
+		return io.EOF
+	}
+	copy(dest, r.d.rows[r.i])
+	r.i++
+	return nil
+}
+
+var testReplicaDriver = &replicaDriver{}
+var testPrimaryDriver = &replicaDriver{}
+
+func init() {
+	sql.Register("replicatest", testReplicaDriver)
+	sql.Register("primarytest", testPrimaryDriver)
+}
+
+func TestReplicaHealthChecksPostgres(t *testing.T) {
+	var db, err = sql.Open("replicatest", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	var m = NewReplicaModule(db, "postgres", 10*time.Second, time.Minute, true)
+	testReplicaDriver.columns = []string{"in_recovery", "streaming", "lag"}
+	testReplicaDriver.err = nil
+
+	// No lag.
+	{
+		testReplicaDriver.rows = [][]driver.Value{{true, true, 0.5}}
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, "lag", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// Lagging.
+	{
+		testReplicaDriver.rows = [][]driver.Value{{true, true, 12.0}}
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "replica lagging by 12s", report.Error)
+		assert.Equal(t, DataIntegrity, report.Category)
+	}
+
+	// Stale.
+	{
+		testReplicaDriver.rows = [][]driver.Value{{true, true, 90.0}}
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "replica lagging by 1m30s, the data is stale", report.Error)
+	}
+
+	// Replication stopped, the replica replayed all it received but the WAL receiver is down.
+	{
+		testReplicaDriver.rows = [][]driver.Value{{true, false, 0.0}}
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not query replication lag: replication stopped, the WAL receiver is not streaming", report.Error)
+		assert.Equal(t, DataIntegrity, report.Category)
+	}
+
+	// No replayed transaction yet.
+	{
+		testReplicaDriver.rows = [][]driver.Value{{true, true, nil}}
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "replica did not replay any transaction yet", report.Error)
+		assert.Equal(t, DataIntegrity, report.Category)
+	}
+
+	// Not a replica.
+	{
+		testReplicaDriver.rows = [][]driver.Value{{false, false, nil}}
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not query replication lag: database is not a replica", report.Error)
+	}
+
+	// Query error.
+	{
+		testReplicaDriver.err = fmt.Errorf("fail")
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not query replication lag: fail", report.Error)
+	}
+}
+
+func TestReplicaHealthChecksMySQL(t *testing.T) {
+	var db, err = sql.Open("replicatest", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	var m = NewReplicaModule(db, "mysql", 10*time.Second, time.Minute, true)
+	testReplicaDriver.columns = []string{"Slave_IO_State", "Seconds_Behind_Master"}
+	testReplicaDriver.err = nil
+
+	// Lagging.
+	{
+		testReplicaDriver.rows = [][]driver.Value{{[]byte("Waiting for master to send event"), []byte("15")}}
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "replica lagging by 15s", report.Error)
+	}
+
+	// Replication stopped.
+	{
+		testReplicaDriver.rows = [][]driver.Value{{[]byte(""), nil}}
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not query replication lag: replication stopped", report.Error)
+		assert.Equal(t, DataIntegrity, report.Category)
+	}
+
+	// Not a replica, there is no row.
+	{
+		testReplicaDriver.rows = nil
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not query replication lag: database is not a replica", report.Error)
+	}
+}
+
+func TestReplicaPrimaryHealthChecks(t *testing.T) {
+	var replica, err = sql.Open("replicatest", "")
+	assert.Nil(t, err)
+	defer replica.Close()
+	var primary *sql.DB
+	primary, err = sql.Open("primarytest", "")
+	assert.Nil(t, err)
+	defer primary.Close()
+
+	var m = NewReplicaModule(replica, "postgres", 10*time.Second, time.Minute, true, ReplicaPrimary(primary))
+	testReplicaDriver.columns = []string{"in_recovery", "streaming", "lag"}
+	testReplicaDriver.rows = [][]driver.Value{{true, true, 0.5}}
+	testPrimaryDriver.columns = []string{"?column?"}
+	testPrimaryDriver.rows = [][]driver.Value{{int64(1)}}
+
+	// Primary and replica up.
+	{
+		testReplicaDriver.err = nil
+		testPrimaryDriver.err = nil
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 3, len(reports))
+		assert.Equal(t, "primary-ping", reports[0].Name)
+		assert.NotZero(t, reports[0].Description)
+		assert.Equal(t, OK, reports[0].Status)
+		assert.Equal(t, 1, reports[0].Attempts)
+		assert.Equal(t, "replica-ping", reports[1].Name)
+		assert.Equal(t, OK, reports[1].Status)
+		assert.Equal(t, "lag", reports[2].Name)
+		assert.Equal(t, OK, reports[2].Status)
+	}
+
+	// Primary down.
+	{
+		testPrimaryDriver.err = fmt.Errorf("fail")
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, KO, reports[0].Status)
+		assert.Equal(t, "could not ping primary: fail", reports[0].Error)
+		assert.Equal(t, OK, reports[1].Status)
+		assert.Equal(t, OK, reports[2].Status)
+	}
+
+	// Replica down, the status of the subsystem depends on its aggregator.
+	{
+		testPrimaryDriver.err = nil
+		testReplicaDriver.err = fmt.Errorf("fail")
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, OK, reports[0].Status)
+		assert.Equal(t, KO, reports[1].Status)
+		assert.Equal(t, "could not ping replica: fail", reports[1].Error)
+		assert.Equal(t, KO, reports[2].Status)
+
+		var c, err = NewComponent(nil, nil, nil, nil, WithReplicaModule(m))
+		assert.Nil(t, err)
+		assert.Equal(t, KO, c.AllHealthChecksDetailed(context.Background()).Subsystems["replica"].Status)
+
+		c, err = NewComponent(nil, nil, nil, nil, WithReplicaModule(m), SubsystemAggregator("replica", Quorum{Min: 1}))
+		assert.Nil(t, err)
+		assert.Equal(t, Degraded, c.AllHealthChecksDetailed(context.Background()).Subsystems["replica"].Status)
+	}
+	testReplicaDriver.err = nil
+}
+
+func TestNoopReplicaHealthChecks(t *testing.T) {
+	var m = NewReplicaModule(nil, "postgres", 10*time.Second, time.Minute, false)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "lag", report.Name)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+	assert.Zero(t, report.Attempts)
+}
@@ -0,0 +1,49 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// sampler executes a health check at most once per interval. In between, it returns the last report
+// of the check, marked as cached.
+type sampler struct {
+	interval time.Duration
+
+	mutex  sync.Mutex
+	last   time.Time
+	report Report
+}
+
+func newSampler(interval time.Duration) *sampler {
+	return &sampler{
+		interval: interval,
+	}
+}
+
+// sample returns the report of check. The check is executed only if the interval elapsed since its
+// last execution. Concurrent callers wait for the execution in progress, so the check is not executed
+// several times.
+func (s *sampler) sample(check func() Report) Report {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.last.IsZero() && time.Since(s.last) < s.interval {
+		var r = s.report
+		r.Cached = true
+		return r
+	}
+
+	s.report = check()
+	s.last = time.Now()
+	return s.report
+}
+
+// reset forgets the last report, so the next call executes the check.
+func (s *sampler) reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.last = time.Time{}
+	s.report = Report{}
+}
@@ -0,0 +1,223 @@
+package health
+
+//go:generate mockgen -destination=./mock/self.go -package=mock -mock_names=SelfModule=SelfModule github.com/cloudtrust/flaki-service/pkg/health SelfModule
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// SelfModule is the health check module for the HTTP listener of the service itself. A deadlocked
+// handler pool can stop the service from accepting connections, while the health checks still run.
+type SelfModule interface {
+	HealthChecks(context.Context) []SelfReport
+}
+
+type selfModule struct {
+	addr    string
+	enabled bool
+	request *selfRequest
+}
+
+// selfRequest is the configuration of the request check.
+type selfRequest struct {
+	httpClient SelfHTTPClient
+	path       string
+}
+
+// SelfOption is an option of the self health module.
+type SelfOption func(*selfModule)
+
+// SelfRequest adds a health check that sends a GET request to path on the listener, and expects a 2xx
+// status, so a listener that accepts the connections but whose handlers are wedged is detected. path must
+// be a cheap route that does not execute the health checks, e.g. the liveness route.
+func SelfRequest(httpClient SelfHTTPClient, path string) SelfOption {
+	return func(m *selfModule) {
+		m.request = &selfRequest{
+			httpClient: httpClient,
+			path:       path,
+		}
+	}
+}
+
+// SelfReport is the health report returned by the self module.
+type SelfReport = Report
+
+// SelfHTTPClient is the interface of the http client.
+type SelfHTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// NewSelfModule returns the self health module. addr is the listen address of the service, e.g. ":8888".
+// A listen address without host, or with an unspecified host, is reached on localhost.
+func NewSelfModule(addr string, enabled bool, options ...SelfOption) SelfModule {
+	var m = &selfModule{
+		addr:    addr,
+		enabled: enabled,
+	}
+
+	// Apply options.
+	for _, o := range options {
+		o(m)
+	}
+
+	return m
+}
+
+// HealthChecks executes all health checks for the listener of the service.
+func (m *selfModule) HealthChecks(ctx context.Context) []SelfReport {
+	var reports = []SelfReport{}
+	reports = append(reports, m.selfAcceptCheck(ctx))
+	if m.request != nil {
+		reports = append(reports, m.selfRequestCheck(ctx))
+	}
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *selfModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *selfModule) selfAcceptCheck(ctx context.Context) SelfReport {
+	var healthCheckName = "accept"
+	var healthCheckDescription = "Connects to the HTTP listener of the service. If it fails, the service no longer accepts connections, e.g. because its handlers are deadlocked."
+
+	if !m.enabled {
+		return SelfReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var err = dialSelf(ctx, m.addr)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not connect to the listener: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return SelfReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+func (m *selfModule) selfRequestCheck(ctx context.Context) SelfReport {
+	var healthCheckName = "request"
+	var healthCheckDescription = fmt.Sprintf("Sends a request to '%s' on the HTTP listener of the service. If it fails, the service accepts the connections but its handlers are wedged.", m.request.path)
+
+	if !m.enabled {
+		return SelfReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var err = querySelf(ctx, m.addr, m.request.path, m.request.httpClient)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not query the listener: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return SelfReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// selfAddress returns the address to reach the listen address addr, with localhost as host if addr has no
+// host or an unspecified one, e.g. ":8888" or "0.0.0.0:8888".
+func selfAddress(addr string) (string, error) {
+	var host, port, err = net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// dialSelf opens a TCP connection to the listener, within the context deadline, and closes it.
+func dialSelf(ctx context.Context, addr string) error {
+	var target, err = selfAddress(addr)
+	if err != nil {
+		return err
+	}
+
+	var conn net.Conn
+	conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", target)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// querySelf sends a GET request to path on the listener, within the context deadline, and expects a 2xx
+// status.
+func querySelf(ctx context.Context, addr, path string, httpClient SelfHTTPClient) error {
+	var target, err = selfAddress(addr)
+	if err != nil {
+		return err
+	}
+
+	var req *http.Request
+	req, err = http.NewRequest("GET", "http://"+target+path, nil)
+	if err != nil {
+		return err
+	}
+
+	var res *http.Response
+	res, err = httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case isRedirect(res):
+		return unexpectedRedirect(res)
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		return fmt.Errorf("http response status code: %v", res.Status)
+	default:
+		return nil
+	}
+}
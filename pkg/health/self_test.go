@@ -0,0 +1,84 @@
+package health_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelfHealthChecks(t *testing.T) {
+	var status = http.StatusOK
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/health/live", r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer s.Close()
+
+	// The listen address has an unspecified host, it is reached on localhost.
+	var _, port, _ = net.SplitHostPort(s.Listener.Addr().String())
+	var m = NewSelfModule("0.0.0.0:"+port, true, SelfRequest(s.Client(), "/health/live"))
+
+	// Listener accepting and serving.
+	{
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 2, len(reports))
+		for i, name := range []string{"accept", "request"} {
+			var report = reports[i]
+			assert.Equal(t, name, report.Name)
+			assert.NotZero(t, report.Description)
+			assert.NotZero(t, report.Duration)
+			assert.Equal(t, OK, report.Status)
+			assert.Zero(t, report.Error)
+			assert.Equal(t, 1, report.Attempts)
+		}
+	}
+
+	// Handlers failing.
+	{
+		status = http.StatusInternalServerError
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, OK, reports[0].Status)
+		assert.Equal(t, KO, reports[1].Status)
+		assert.Equal(t, "could not query the listener: http response status code: 500 Internal Server Error", reports[1].Error)
+	}
+
+	// Listener closed.
+	{
+		s.Close()
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, KO, reports[0].Status)
+		assert.True(t, strings.HasPrefix(reports[0].Error, "could not connect to the listener"))
+		assert.Equal(t, Network, reports[0].Category)
+		assert.Equal(t, KO, reports[1].Status)
+	}
+}
+
+func TestSelfHealthChecksWithoutRequest(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	var m = NewSelfModule(s.Listener.Addr().String(), true)
+	var reports = m.HealthChecks(context.Background())
+	assert.Equal(t, 1, len(reports))
+	assert.Equal(t, OK, reports[0].Status)
+}
+
+func TestNoopSelfHealthChecks(t *testing.T) {
+	var m = NewSelfModule(":8888", false, SelfRequest(http.DefaultClient, "/"))
+
+	var reports = m.HealthChecks(context.Background())
+	assert.Equal(t, 2, len(reports))
+	for _, report := range reports {
+		assert.NotZero(t, report.Name)
+		assert.NotZero(t, report.Description)
+		assert.Equal(t, "N/A", report.Duration)
+		assert.Equal(t, Deactivated, report.Status)
+		assert.Zero(t, report.Error)
+	}
+}
@@ -1,12 +1,12 @@
 package health
 
-//go:generate mockgen -destination=./mock/sentry.go -package=mock -mock_names=SentryModule=SentryModule,Sentry=Sentry  github.com/cloudtrust/flaki-service/pkg/health SentryModule,Sentry
+//go:generate mockgen -destination=./mock/sentry.go -package=mock -mock_names=SentryModule=SentryModule,Sentry=Sentry,SentryTags=SentryTags  github.com/cloudtrust/flaki-service/pkg/health SentryModule,Sentry,SentryTags
 
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -17,82 +17,285 @@ type SentryModule interface {
 }
 
 type sentryModule struct {
-	sentry     Sentry
-	httpClient SentryHTTPClient
-	enabled    bool
+	projects    []Sentry
+	httpClient  SentryHTTPClient
+	enabled     bool
+	maxBodySize int64
+	snippetSize int
+	timeout     *adaptiveTimeout
+	auth        httpAuth
+	quota       *sentryQuota
+	tags        SentryTags
 }
 
-// SentryReport is the health report returned by the sentry module.
-type SentryReport struct {
-	Name     string
-	Duration string
-	Status   Status
-	Error    string
+// sentryQuota is the configuration of the quota check.
+type sentryQuota struct {
+	publicKey string
+	interval  time.Duration
+	sampler   *sampler
+}
+
+// SentryOption is an option of the sentry health module.
+type SentryOption func(*sentryModule)
+
+// SentryMaxBodySize sets the maximum number of bytes read from the sentry health response.
+// A larger response is reported as KO. The default is 64KB.
+func SentryMaxBodySize(n int64) SentryOption {
+	return func(m *sentryModule) {
+		m.maxBodySize = n
+	}
+}
+
+// SentryResponseSnippet includes the status, the content type and the first n bytes of the body of the
+// sentry health response in the error of a failed check. It is off by default, because the body may be
+// large or sensitive.
+func SentryResponseSnippet(n int) SentryOption {
+	return func(m *sentryModule) {
+		m.snippetSize = n
+	}
+}
+
+// SentryAdaptiveTimeout bounds the ping with an adaptive timeout: the p99 of the last successful durations
+// multiplied by factor, bounded by min and max. The effective timeout is reported with the result of the check.
+func SentryAdaptiveTimeout(factor float64, min, max time.Duration) SentryOption {
+	return func(m *sentryModule) {
+		m.timeout = newAdaptiveTimeout(factor, min, max)
+	}
+}
+
+// SentryBasicAuth sets the credentials sent with basic auth to the sentry health endpoint, for an endpoint
+// behind a proxy that requires authentication. They are scrubbed from the errors of the check.
+func SentryBasicAuth(username, password string) SentryOption {
+	return func(m *sentryModule) {
+		m.auth = httpAuth{username: username, password: password}
+	}
 }
 
+// SentryBearerToken sets the bearer token sent to the sentry health endpoint. It is scrubbed from the errors
+// of the check.
+func SentryBearerToken(token string) SentryOption {
+	return func(m *sentryModule) {
+		m.auth = httpAuth{token: token}
+	}
+}
+
+// SentryQuotaCheck adds a health check that sends an empty event to the sentry store endpoint, to detect an
+// exceeded event quota: sentry then rejects the events with 429, while its health endpoint still returns
+// ok. The check is Degraded with "quota exceeded", since the service works but the errors are dropped.
+// publicKey is the public key of the DSN. With several projects, the quota of the first one is checked.
+// Each event counts in the quota, so the check sends at most one per interval. In between, it returns its
+// last report, marked as cached. 0 sends one at every health request.
+func SentryQuotaCheck(publicKey string, interval time.Duration) SentryOption {
+	return func(m *sentryModule) {
+		m.quota = &sentryQuota{
+			publicKey: publicKey,
+			interval:  interval,
+			sampler:   newSampler(interval),
+		}
+	}
+}
+
+// SentryTagsCheck adds a health check that verifies that the release and the environment of the sentry
+// client are set. Without them, the errors reported to sentry cannot be traced back to a deployment. The
+// check is Degraded with "release/environment not set" when they are missing.
+func SentryTagsCheck(client SentryTags) SentryOption {
+	return func(m *sentryModule) {
+		m.tags = client
+	}
+}
+
+// SentryReport is the health report returned by the sentry module.
+type SentryReport = Report
+
 // Sentry is the interface of the sentry client.
 type Sentry interface {
 	URL() string
 }
 
+// SentryTags is the interface of the sentry client that exposes the release and the environment tagged on
+// the events.
+type SentryTags interface {
+	Release() string
+	Environment() string
+}
+
 // SentryHTTPClient is the interface of the http client.
 type SentryHTTPClient interface {
-	Get(string) (*http.Response, error)
+	Do(*http.Request) (*http.Response, error)
 }
 
 // NewSentryModule returns the sentry health module.
-func NewSentryModule(sentry Sentry, httpClient SentryHTTPClient, enabled bool) SentryModule {
-	return &sentryModule{
-		sentry:     sentry,
-		httpClient: httpClient,
-		enabled:    enabled,
+func NewSentryModule(sentry Sentry, httpClient SentryHTTPClient, enabled bool, options ...SentryOption) SentryModule {
+	return NewSentryProjectsModule([]Sentry{sentry}, httpClient, enabled, options...)
+}
+
+// NewSentryProjectsModule returns the sentry health module for several Sentry projects, one per DSN. Each
+// project is pinged, with a check named after the project ID. The failed pings are reported as Degraded when
+// some projects are still up, and KO when all projects are down.
+func NewSentryProjectsModule(projects []Sentry, httpClient SentryHTTPClient, enabled bool, options ...SentryOption) SentryModule {
+	var m = &sentryModule{
+		projects:    projects,
+		httpClient:  httpClient,
+		enabled:     enabled,
+		maxBodySize: 64 * 1024,
+	}
+
+	// Apply options.
+	for _, o := range options {
+		o(m)
 	}
+
+	return m
 }
 
 // HealthChecks executes all health checks for Sentry.
-func (m *sentryModule) HealthChecks(context.Context) []SentryReport {
+func (m *sentryModule) HealthChecks(ctx context.Context) []SentryReport {
+	var reports = m.sentryPingChecks(ctx)
+	if m.quota != nil {
+		reports = append(reports, m.quota.sampler.sample(func() Report { return m.sentryQuotaCheck(ctx) }))
+	}
+	if m.tags != nil {
+		reports = append(reports, m.sentryTagsCheck(ctx))
+	}
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *sentryModule) Enabled() bool {
+	return m.enabled
+}
+
+// Warmup queries the Sentry health endpoint once, so the connection is established before the first health
+// check. The result is ignored.
+func (m *sentryModule) Warmup(ctx context.Context) {
+	if !m.enabled {
+		return
+	}
+	for _, project := range m.projects {
+		pingSentry(ctx, project.URL(), m.httpClient, m.auth, m.maxBodySize, 0)
+	}
+}
+
+// Reset forgets the durations of the adaptive timeout and the cached report of the quota check.
+func (m *sentryModule) Reset() {
+	if m.timeout != nil {
+		m.timeout.reset()
+	}
+	if m.quota != nil {
+		m.quota.sampler.reset()
+	}
+}
+
+// sentryPingChecks pings each project.
+func (m *sentryModule) sentryPingChecks(ctx context.Context) []SentryReport {
+	if len(m.projects) == 1 {
+		return []SentryReport{m.sentryPingCheck(ctx, "ping", m.projects[0])}
+	}
+
 	var reports = []SentryReport{}
-	reports = append(reports, m.sentryPingCheck())
+	var failures = 0
+	for i, project := range m.projects {
+		var r = m.sentryPingCheck(ctx, fmt.Sprintf("ping project %s", sentryProjectID(project.URL(), i)), project)
+		if r.Status == KO {
+			failures++
+		}
+		reports = append(reports, r)
+	}
+
+	// If some projects are still up, sentry is only degraded.
+	if failures < len(m.projects) {
+		for i := range reports {
+			if reports[i].Status == KO {
+				reports[i].Status = Degraded
+			}
+		}
+	}
+
 	return reports
 }
 
-func (m *sentryModule) sentryPingCheck() SentryReport {
-	var healthCheckName = "ping"
+// sentryProjectID returns the project ID of the sentry store URL, e.g. 42 for
+// https://sentry.example.com/api/42/store/. It returns the index of the project if the URL has no ID.
+func sentryProjectID(storeURL string, index int) string {
+	var idx = strings.LastIndex(storeURL, "/api/")
+	if idx == -1 {
+		return fmt.Sprintf("%d", index)
+	}
+
+	var id = strings.SplitN(storeURL[idx+len("/api/"):], "/", 2)[0]
+	if id == "" {
+		return fmt.Sprintf("%d", index)
+	}
+	return id
+}
+
+func (m *sentryModule) sentryPingCheck(ctx context.Context, healthCheckName string, sentry Sentry) SentryReport {
+	var healthCheckDescription = "Queries the Sentry health endpoint. The service works without it, but the errors are not tracked. With several projects, Degraded means some projects are down."
 
 	if !m.enabled {
 		return SentryReport{
-			Name:     healthCheckName,
-			Duration: "N/A",
-			Status:   Deactivated,
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
 		}
 	}
 
-	var dsn = m.sentry.URL()
+	var dsn = sentry.URL()
+	if dsn == "" {
+		return SentryReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+			Error:       notConfiguredError,
+		}
+	}
+
+	var timeout time.Duration
+	if m.timeout != nil {
+		timeout = childTimeout(ctx, m.timeout.timeout())
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
 	// Get Sentry health status.
 	var now = time.Now()
-	var err = pingSentry(dsn, m.httpClient)
+	var err = pingSentry(ctx, dsn, m.httpClient, m.auth, m.maxBodySize, m.snippetSize)
 	var duration = time.Since(now)
 
 	var error string
 	var s Status
+	var category Category
 	switch {
 	case err != nil:
-		error = fmt.Sprintf("could not ping sentry: %v", err.Error())
+		error = m.auth.scrub(fmt.Sprintf("could not ping sentry: %v", err.Error()))
 		s = KO
+		category = errorCategory(err)
 	default:
 		s = OK
 	}
 
-	return SentryReport{
-		Name:     healthCheckName,
-		Duration: duration.String(),
-		Status:   s,
-		Error:    error,
+	var report = SentryReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+	if m.timeout != nil {
+		report.Timeout = timeout.String()
+		if s == OK {
+			m.timeout.observe(duration)
+		}
 	}
+	return report
 }
 
-func pingSentry(dsn string, httpClient SentryHTTPClient) error {
+func pingSentry(ctx context.Context, dsn string, httpClient SentryHTTPClient, auth httpAuth, maxBodySize int64, snippetSize int) error {
 
 	// Build sentry health url from sentry dsn. The health url is <sentryURL>/_health
 	var url string
@@ -103,8 +306,12 @@ func pingSentry(dsn string, httpClient SentryHTTPClient) error {
 	// Query sentry health endpoint.
 	var res *http.Response
 	{
-		var err error
-		res, err = httpClient.Get(url)
+		var req, err = http.NewRequest("GET", url, nil)
+		if err != nil {
+			return err
+		}
+		auth.apply(req)
+		res, err = httpClient.Do(req.WithContext(ctx))
 		if err != nil {
 			return err
 		}
@@ -114,23 +321,182 @@ func pingSentry(dsn string, httpClient SentryHTTPClient) error {
 	}
 
 	// Chesk response status.
+	if isRedirect(res) {
+		return unexpectedRedirect(res)
+	}
 	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf("http response status code: %v", res.Status)
+		var body []byte
+		if snippetSize > 0 {
+			body, _ = readBody(ctx, res.Body, int64(snippetSize)+1)
+		}
+		var err = fmt.Errorf("http response status code: %v%s", res.Status, responseSnippet(res, body, snippetSize))
+		if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+			return withCategory(Auth, err)
+		}
+		return err
 	}
 
 	// Chesk response body. The sentry health endpoint returns "ok" when there is no issue.
+	// The read is bounded in size and in time, so a misbehaving proxy cannot make us read a huge body, nor
+	// hang the check past its timeout.
 	var response []byte
 	{
 		var err error
-		response, err = ioutil.ReadAll(res.Body)
+		response, err = readBody(ctx, res.Body, maxBodySize+1)
 		if err != nil {
 			return err
 		}
 	}
 
+	if int64(len(response)) > maxBodySize {
+		return withCategory(DataIntegrity, fmt.Errorf("response too large, it exceeds %v bytes", maxBodySize))
+	}
+
 	if strings.Compare(string(response), "ok") == 0 {
 		return nil
 	}
 
-	return fmt.Errorf("response should be 'ok' but is: %v", string(response))
+	return withCategory(DataIntegrity, fmt.Errorf("response should be 'ok'%s", responseSnippet(res, response, snippetSize)))
+}
+
+func (m *sentryModule) sentryQuotaCheck(ctx context.Context) SentryReport {
+	var healthCheckName = "quota"
+	var healthCheckDescription = "Sends an empty event to Sentry, to detect an exceeded event quota. The service works without it, but the errors are dropped."
+
+	if !m.enabled {
+		return SentryReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var dsn = m.projects[0].URL()
+	if dsn == "" {
+		return SentryReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+			Error:       notConfiguredError,
+		}
+	}
+
+	var now = time.Now()
+	var limited, err = querySentryQuota(ctx, dsn, m.quota.publicKey, m.httpClient, m.auth)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = m.auth.scrub(fmt.Sprintf("could not query sentry quota: %v", err.Error()))
+		s = KO
+		category = errorCategory(err)
+	case limited != "":
+		error = fmt.Sprintf("quota exceeded: %s", limited)
+		s = Degraded
+		category = Capacity
+	default:
+		s = OK
+	}
+
+	return SentryReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+func (m *sentryModule) sentryTagsCheck(ctx context.Context) SentryReport {
+	var healthCheckName = "release and environment"
+	var healthCheckDescription = "Checks that the release and the environment of the sentry client are set, so the errors can be traced back to a deployment."
+
+	if !m.enabled {
+		return SentryReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	var now = time.Now()
+	var release, environment = m.tags.Release(), m.tags.Environment()
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	switch {
+	case release == "" && environment == "":
+		error = "release/environment not set"
+		s = Degraded
+	case release == "":
+		error = "release/environment not set: release is empty"
+		s = Degraded
+	case environment == "":
+		error = "release/environment not set: environment is empty"
+		s = Degraded
+	default:
+		s = OK
+	}
+
+	return SentryReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Attempts:    1,
+	}
+}
+
+// querySentryQuota posts an empty event to the sentry store endpoint. It returns a description of the
+// rate limit if sentry rejects the event with 429, and an empty string otherwise. Sentry may reject the
+// empty event as invalid, it does not matter: only the rate limit is checked.
+func querySentryQuota(ctx context.Context, storeURL, publicKey string, httpClient SentryHTTPClient, auth httpAuth) (string, error) {
+	// The credentials of the DSN are sent in the sentry auth header, they must not appear in the errors.
+	var u, err = url.Parse(storeURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid sentry url")
+	}
+	u.User = nil
+
+	var req *http.Request
+	req, err = http.NewRequest("POST", u.String(), strings.NewReader("{}"))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=flaki-health/1.0, sentry_key=%s", publicKey))
+	auth.apply(req)
+
+	var res *http.Response
+	res, err = httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if isRedirect(res) {
+		return "", unexpectedRedirect(res)
+	}
+	if res.StatusCode != http.StatusTooManyRequests {
+		return "", nil
+	}
+
+	// The sentry headers tell why the event is rejected and for how long.
+	var details = []string{res.Status}
+	for _, h := range []string{"X-Sentry-Error", "X-Sentry-Rate-Limits", "Retry-After"} {
+		if v := res.Header.Get(h); v != "" {
+			details = append(details, fmt.Sprintf("%s: %s", h, v))
+		}
+	}
+	return strings.Join(details, ", "), nil
 }
@@ -2,9 +2,12 @@ package health_test
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/cloudtrust/flaki-service/pkg/health"
 	"github.com/cloudtrust/flaki-service/pkg/health/mock"
@@ -25,14 +28,414 @@ func TestSentryHealthChecks(t *testing.T) {
 
 	var m = NewSentryModule(mockSentry, s.Client(), true)
 
-	mockSentry.EXPECT().URL().Return("http://a:b@sentry.io/api/1/store/").Times(1)
+	mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).Times(1)
 	var report = m.HealthChecks(context.Background())[0]
 	assert.Equal(t, "ping", report.Name)
+	assert.NotZero(t, report.Description)
 	assert.NotZero(t, report.Duration)
 	assert.Equal(t, OK, report.Status)
 	assert.Zero(t, report.Error)
 }
 
+func TestSentryProjectsHealthChecks(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry1 = mock.NewSentry(mockCtrl)
+	var mockSentry2 = mock.NewSentry(mockCtrl)
+
+	var up = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer up.Close()
+	var down = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	var m = NewSentryProjectsModule([]Sentry{mockSentry1, mockSentry2}, up.Client(), true)
+
+	// One project down.
+	{
+		mockSentry1.EXPECT().URL().Return(up.URL + "/api/1/store/").Times(2)
+		mockSentry2.EXPECT().URL().Return(down.URL + "/api/2/store/").Times(2)
+		var reports = m.HealthChecks(context.Background())
+		assert.Len(t, reports, 2)
+		assert.Equal(t, "ping project 1", reports[0].Name)
+		assert.Equal(t, OK, reports[0].Status)
+		assert.Equal(t, "ping project 2", reports[1].Name)
+		assert.Equal(t, Degraded, reports[1].Status)
+		assert.Equal(t, "could not ping sentry: http response status code: 503 Service Unavailable", reports[1].Error)
+	}
+
+	// All projects down.
+	{
+		mockSentry1.EXPECT().URL().Return(down.URL + "/api/1/store/").Times(2)
+		mockSentry2.EXPECT().URL().Return(down.URL + "/api/2/store/").Times(2)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, KO, reports[0].Status)
+		assert.Equal(t, KO, reports[1].Status)
+	}
+}
+
+func TestSentryHealthChecksRedirect(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	// A misconfigured health endpoint redirects to a login page that returns "ok".
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.Write([]byte("ok"))
+			return
+		}
+		http.Redirect(w, r, "/login", http.StatusFound)
+	}))
+	defer s.Close()
+
+	var m = NewSentryModule(mockSentry, NewHTTPClient(), true)
+
+	mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).Times(1)
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, "could not ping sentry: unexpected redirect: 302 Found to '/login'", report.Error)
+}
+
+func TestSentryHealthChecksResponseTooLarge(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(strings.Repeat("ok", 1024)))
+	}))
+	defer s.Close()
+
+	var m = NewSentryModule(mockSentry, s.Client(), true, SentryMaxBodySize(1024))
+
+	mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).Times(1)
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "ping", report.Name)
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, "could not ping sentry: response too large, it exceeds 1024 bytes", report.Error)
+}
+
+func TestSentryHealthChecksSlowBody(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	// The proxy sends the headers, then the body never comes.
+	var body, w = io.Pipe()
+	defer w.Close()
+	var httpClient = &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{Status: "200 OK", StatusCode: http.StatusOK, Header: http.Header{}, Body: body, Request: req}, nil
+		}),
+	}
+
+	var m = NewSentryModule(mockSentry, httpClient, true)
+
+	mockSentry.EXPECT().URL().Return("http://a:b@sentry.example.com/api/1/store/").Times(1)
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var report = m.HealthChecks(ctx)[0]
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, "could not ping sentry: context deadline exceeded", report.Error)
+	assert.Equal(t, Timeout, report.Category)
+}
+
+// roundTripperFunc is a http.RoundTripper that calls the function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// sentryDSN returns a sentry DSN pointing to the server at url.
+func sentryDSN(url string) string {
+	return strings.Replace(url, "http://", "http://a:b@", 1) + "/api/1/store/"
+}
+
+func TestSentryHealthChecksResponseSnippet(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	var status = http.StatusOK
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(status)
+		w.Write([]byte("<html>maintenance</html>"))
+	}))
+	defer s.Close()
+
+	mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).Times(3)
+
+	// The body is not in the report by default.
+	{
+		var m = NewSentryModule(mockSentry, s.Client(), true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, "could not ping sentry: response should be 'ok'", report.Error)
+		assert.Equal(t, DataIntegrity, report.Category)
+	}
+
+	var m = NewSentryModule(mockSentry, s.Client(), true, SentryResponseSnippet(10))
+
+	// Unexpected body.
+	{
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, `could not ping sentry: response should be 'ok' (status: 200 OK, content-type: text/html, body: "<html>main"...)`, report.Error)
+	}
+
+	// Unexpected status.
+	{
+		status = http.StatusServiceUnavailable
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, `could not ping sentry: http response status code: 503 Service Unavailable (status: 503 Service Unavailable, content-type: text/html, body: "<html>main"...)`, report.Error)
+	}
+}
+
+func TestSentryBasicAuth(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	// The health endpoint requires basic auth, and echoes the authorization header when it is rejected.
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var username, password, ok = r.BasicAuth()
+		if !ok || username != "health" || password != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("invalid credentials: " + r.Header.Get("Authorization") + " " + password))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	// Valid credentials.
+	{
+		var m = NewSentryModule(mockSentry, s.Client(), true, SentryBasicAuth("health", "s3cr3t"))
+		mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Invalid credentials are scrubbed from the error.
+	{
+		var m = NewSentryModule(mockSentry, s.Client(), true, SentryBasicAuth("health", "wrong"), SentryResponseSnippet(100))
+		mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, Auth, report.Category)
+		assert.Contains(t, report.Error, "invalid credentials: Basic *** ***")
+		assert.NotContains(t, report.Error, "wrong")
+	}
+
+	// No credentials.
+	{
+		var m = NewSentryModule(mockSentry, s.Client(), true)
+		mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, Auth, report.Category)
+	}
+}
+
+func TestSentryBearerToken(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer t0k3n", r.Header.Get("Authorization"))
+		w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	var m = NewSentryModule(mockSentry, s.Client(), true, SentryBearerToken("t0k3n"))
+	mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).Times(1)
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, OK, report.Status)
+}
+
+func TestSentryQuotaCheck(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	var exceeded bool
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_health":
+			w.Write([]byte("ok"))
+		case "/api/1/store/":
+			assert.Equal(t, "POST", r.Method)
+			assert.Contains(t, r.Header.Get("X-Sentry-Auth"), "sentry_key=public")
+			if exceeded {
+				w.Header().Set("X-Sentry-Error", "Event dropped due to organization quota")
+				w.Header().Set("Retry-After", "60")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			// The empty event is invalid, it does not matter.
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer s.Close()
+
+	var m = NewSentryModule(mockSentry, s.Client(), true, SentryQuotaCheck("public", 0))
+	mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).AnyTimes()
+
+	// Quota not exceeded.
+	{
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 2, len(reports))
+		var report = reports[1]
+		assert.Equal(t, "quota", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// Quota exceeded, while the health endpoint is ok.
+	{
+		exceeded = true
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, OK, reports[0].Status)
+		var report = reports[1]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, Capacity, report.Category)
+		assert.Equal(t, "quota exceeded: 429 Too Many Requests, X-Sentry-Error: Event dropped due to organization quota, Retry-After: 60", report.Error)
+	}
+
+	// Sentry unreachable.
+	{
+		s.Close()
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, KO, report.Status)
+		assert.NotContains(t, report.Error, "a:b")
+	}
+}
+
+func TestSentryQuotaCheckInterval(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	var events int
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_health":
+			w.Write([]byte("ok"))
+		case "/api/1/store/":
+			events++
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer s.Close()
+
+	var m = NewSentryModule(mockSentry, s.Client(), true, SentryQuotaCheck("public", time.Hour))
+	mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).AnyTimes()
+
+	// Each event counts in the quota, a single one is sent per interval.
+	{
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, OK, report.Status)
+		assert.False(t, report.Cached)
+
+		report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, OK, report.Status)
+		assert.True(t, report.Cached)
+		assert.Equal(t, 1, events)
+	}
+
+	// Reset forgets the cached report.
+	{
+		m.(interface{ Reset() }).Reset()
+		var report = m.HealthChecks(context.Background())[1]
+		assert.False(t, report.Cached)
+		assert.Equal(t, 2, events)
+	}
+}
+
+func TestSentryQuotaCheckNoDSN(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	var requests int
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer s.Close()
+
+	var m = NewSentryModule(mockSentry, s.Client(), true, SentryQuotaCheck("public", 0))
+	mockSentry.EXPECT().URL().Return("").AnyTimes()
+
+	var reports = m.HealthChecks(context.Background())
+	assert.Equal(t, 2, len(reports))
+	var report = reports[1]
+	assert.Equal(t, "quota", report.Name)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Equal(t, "not configured", report.Error)
+	assert.Zero(t, requests)
+}
+
+func TestSentryTagsCheck(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+	var mockTags = mock.NewSentryTags(mockCtrl)
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	var m = NewSentryModule(mockSentry, s.Client(), true, SentryTagsCheck(mockTags))
+	mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).AnyTimes()
+
+	// Release and environment set.
+	{
+		mockTags.EXPECT().Release().Return("1.0").Times(1)
+		mockTags.EXPECT().Environment().Return("prod").Times(1)
+		var reports = m.HealthChecks(context.Background())
+		assert.Equal(t, 2, len(reports))
+		var report = reports[1]
+		assert.Equal(t, "release and environment", report.Name)
+		assert.NotZero(t, report.Description)
+		assert.NotZero(t, report.Duration)
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+		assert.Equal(t, 1, report.Attempts)
+	}
+
+	// Environment not set.
+	{
+		mockTags.EXPECT().Release().Return("1.0").Times(1)
+		mockTags.EXPECT().Environment().Return("").Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "release/environment not set: environment is empty", report.Error)
+	}
+
+	// Neither set.
+	{
+		mockTags.EXPECT().Release().Return("").Times(1)
+		mockTags.EXPECT().Environment().Return("").Times(1)
+		var report = m.HealthChecks(context.Background())[1]
+		assert.Equal(t, Degraded, report.Status)
+		assert.Equal(t, "release/environment not set", report.Error)
+	}
+}
+
 func TestNoopSentryHealthChecks(t *testing.T) {
 	var mockCtrl = gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -52,3 +455,67 @@ func TestNoopSentryHealthChecks(t *testing.T) {
 	assert.Equal(t, Deactivated, report.Status)
 	assert.Zero(t, report.Error)
 }
+
+func TestSentryAdaptiveTimeout(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	var delay = 0 * time.Millisecond
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	var m = NewSentryModule(mockSentry, s.Client(), true, SentryAdaptiveTimeout(2, 50*time.Millisecond, time.Second))
+
+	// Without history, the timeout is the maximum.
+	{
+		mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, OK, report.Status)
+		assert.Equal(t, "1s", report.Timeout)
+	}
+
+	// The fast pings lower the timeout to the minimum, a slow ping is then KO.
+	{
+		delay = 200 * time.Millisecond
+		mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).Times(1)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.Equal(t, Timeout, report.Category)
+		assert.Equal(t, "50ms", report.Timeout)
+	}
+}
+
+func TestSentryParentDeadline(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	// The timeout of the check is long, the deadline of the caller wins.
+	var m = NewSentryModule(mockSentry, s.Client(), true, SentryAdaptiveTimeout(2, 10*time.Second, 10*time.Second))
+
+	mockSentry.EXPECT().URL().Return(sentryDSN(s.URL)).Times(1)
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var now = time.Now()
+	var report = m.HealthChecks(ctx)[0]
+	assert.True(t, time.Since(now) < 2*time.Second)
+	assert.Equal(t, KO, report.Status)
+	assert.Equal(t, Timeout, report.Category)
+
+	var timeout, err = time.ParseDuration(report.Timeout)
+	assert.Nil(t, err)
+	assert.True(t, timeout <= 100*time.Millisecond)
+}
@@ -0,0 +1,33 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// ShutdownServers shuts the HTTP servers down gracefully, e.g. the API server and the admin server that
+// serves the health routes. They stop accepting connections and complete the requests in progress until
+// ctx is done, concurrently so a slow request on one server does not delay the others. It returns the
+// first error.
+func ShutdownServers(ctx context.Context, servers ...*http.Server) error {
+	var errs = make(chan error, len(servers))
+
+	var wg sync.WaitGroup
+	for _, s := range servers {
+		wg.Add(1)
+		go func(s *http.Server) {
+			defer wg.Done()
+			errs <- s.Shutdown(ctx)
+		}(s)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
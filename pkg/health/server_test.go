@@ -0,0 +1,54 @@
+package health_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownServers(t *testing.T) {
+	var started = make(chan struct{})
+	var api = &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})}
+	var admin = &http.Server{Handler: MakeLivenessHandler()}
+
+	var apiLis, err = net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	var adminLis net.Listener
+	adminLis, err = net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	var served = make(chan error, 2)
+	go func() { served <- api.Serve(apiLis) }()
+	go func() { served <- admin.Serve(adminLis) }()
+
+	// The request in progress is completed.
+	var status = make(chan int, 1)
+	go func() {
+		var resp, err = http.Get("http://" + apiLis.Addr().String())
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	<-started
+
+	assert.Nil(t, ShutdownServers(context.Background(), api, admin))
+	assert.Equal(t, http.StatusOK, <-status)
+	assert.Equal(t, http.ErrServerClosed, <-served)
+	assert.Equal(t, http.ErrServerClosed, <-served)
+
+	// The servers do not accept new connections.
+	_, err = http.Get("http://" + adminLis.Addr().String())
+	assert.NotNil(t, err)
+}
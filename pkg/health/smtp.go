@@ -0,0 +1,143 @@
+package health
+
+//go:generate mockgen -destination=./mock/smtp.go -package=mock -mock_names=SMTPModule=SMTPModule  github.com/cloudtrust/flaki-service/pkg/health SMTPModule
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// SMTPModule is the health check module for the SMTP relay.
+type SMTPModule interface {
+	HealthChecks(context.Context) []SMTPReport
+}
+
+type smtpModule struct {
+	addr    string
+	tls     bool
+	enabled bool
+}
+
+// SMTPReport is the health report returned by the SMTP module.
+type SMTPReport = Report
+
+// NewSMTPModule returns the SMTP health module. If tls is true, the connection to the SMTP server
+// at addr is established over TLS.
+func NewSMTPModule(addr string, tls bool, enabled bool) SMTPModule {
+	return &smtpModule{
+		addr:    addr,
+		tls:     tls,
+		enabled: enabled,
+	}
+}
+
+// HealthChecks executes all health checks for SMTP.
+func (m *smtpModule) HealthChecks(ctx context.Context) []SMTPReport {
+	var reports = []SMTPReport{}
+	reports = append(reports, m.smtpHandshakeCheck(ctx))
+	return reports
+}
+
+// Enabled returns false if the module is deactivated by configuration, see Enabler.
+func (m *smtpModule) Enabled() bool {
+	return m.enabled
+}
+
+func (m *smtpModule) smtpHandshakeCheck(ctx context.Context) SMTPReport {
+	var healthCheckName = "handshake"
+	var healthCheckDescription = "Connects to the SMTP relay and does the handshake. The service works without it, but the notification emails are not sent."
+
+	if !m.enabled {
+		return SMTPReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+		}
+	}
+
+	if m.addr == "" {
+		return SMTPReport{
+			Name:        healthCheckName,
+			Description: healthCheckDescription,
+			Duration:    "N/A",
+			Status:      Deactivated,
+			Error:       notConfiguredError,
+		}
+	}
+
+	var now = time.Now()
+	var err = smtpHandshake(ctx, m.addr, m.tls)
+	var duration = time.Since(now)
+
+	var error string
+	var s Status
+	var category Category
+	switch {
+	case err != nil:
+		error = fmt.Sprintf("could not handshake with smtp server: %v", err.Error())
+		s = KO
+		category = errorCategory(err)
+	default:
+		s = OK
+	}
+
+	return SMTPReport{
+		Name:        healthCheckName,
+		Description: healthCheckDescription,
+		Duration:    duration.String(),
+		Status:      s,
+		Error:       error,
+		Category:    category,
+		Attempts:    1,
+	}
+}
+
+// smtpHandshake issues EHLO, NOOP and QUIT to the SMTP server, within the context deadline.
+func smtpHandshake(ctx context.Context, addr string, useTLS bool) error {
+	var conn net.Conn
+	{
+		var err error
+		if useTLS {
+			conn, err = (&tls.Dialer{}).DialContext(ctx, "tcp", addr)
+		} else {
+			conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		}
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+	}
+
+	// The SMTP conversation must not outlive the context.
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var host, _, err = net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	var client *smtp.Client
+	{
+		var err error
+		client, err = smtp.NewClient(conn, host)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+	}
+
+	if err := client.Hello("localhost"); err != nil {
+		return err
+	}
+	if err := client.Noop(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
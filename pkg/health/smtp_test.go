@@ -0,0 +1,95 @@
+package health_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+// smtpServer is a minimal SMTP server that answers EHLO, NOOP and QUIT.
+func smtpServer(t *testing.T) net.Listener {
+	var l, err = net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	go func() {
+		for {
+			var conn, err = l.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				var r = bufio.NewReader(conn)
+				conn.Write([]byte("220 localhost ESMTP\r\n"))
+				for {
+					var line, err = r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+					case strings.HasPrefix(cmd, "EHLO"):
+						conn.Write([]byte("250 localhost\r\n"))
+					case strings.HasPrefix(cmd, "NOOP"):
+						conn.Write([]byte("250 OK\r\n"))
+					case strings.HasPrefix(cmd, "QUIT"):
+						conn.Write([]byte("221 Bye\r\n"))
+						return
+					default:
+						conn.Write([]byte("502 Command not implemented\r\n"))
+					}
+				}
+			}(conn)
+		}
+	}()
+	return l
+}
+
+func TestSMTPHealthChecks(t *testing.T) {
+	var l = smtpServer(t)
+	defer l.Close()
+
+	var m = NewSMTPModule(l.Addr().String(), false, true)
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var report = m.HealthChecks(ctx)[0]
+	assert.Equal(t, "handshake", report.Name)
+	assert.NotZero(t, report.Description)
+	assert.NotZero(t, report.Duration)
+	assert.Equal(t, OK, report.Status)
+	assert.Zero(t, report.Error)
+	assert.Equal(t, 1, report.Attempts)
+}
+
+func TestSMTPHealthChecksFail(t *testing.T) {
+	// Closed listener, the connection is refused.
+	var l = smtpServer(t)
+	var addr = l.Addr().String()
+	l.Close()
+
+	var m = NewSMTPModule(addr, false, true)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "handshake", report.Name)
+	assert.NotZero(t, report.Duration)
+	assert.Equal(t, KO, report.Status)
+	assert.NotZero(t, report.Error)
+	assert.Equal(t, Network, report.Category)
+}
+
+func TestNoopSMTPHealthChecks(t *testing.T) {
+	var m = NewSMTPModule("", false, false)
+
+	var report = m.HealthChecks(context.Background())[0]
+	assert.Equal(t, "handshake", report.Name)
+	assert.Equal(t, "N/A", report.Duration)
+	assert.Equal(t, Deactivated, report.Status)
+	assert.Zero(t, report.Error)
+}
@@ -0,0 +1,133 @@
+package health
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/statsd"
+)
+
+// StatsDSink exports the health to StatsD: a gauge per subsystem status, and a timer per check duration.
+// StatsD has no labels, so the label values are folded into the metric names, e.g. "redis.status" and
+// "redis.ping.duration". The metrics are kept in memory and sent in batches by SendLoop, so a StatsD server
+// that is down never blocks the health checks.
+type StatsDSink struct {
+	statsd *statsd.Statsd
+
+	mutex   sync.Mutex
+	gauges  map[string]metrics.Gauge
+	timings map[string]metrics.Histogram
+}
+
+// NewStatsDSink returns a StatsD sink. prefix is prepended to the metric names, e.g. "flaki.health.".
+func NewStatsDSink(prefix string, logger log.Logger) *StatsDSink {
+	return &StatsDSink{
+		statsd:  statsd.New(prefix, logger),
+		gauges:  map[string]metrics.Gauge{},
+		timings: map[string]metrics.Histogram{},
+	}
+}
+
+// StatusGauge returns the gauge of the subsystem statuses, to be given to the component with StatusGauge.
+func (s *StatsDSink) StatusGauge() metrics.Gauge {
+	return &statsdGauge{sink: s, suffix: "status"}
+}
+
+// DurationHistogram returns the timer of the check durations, in seconds, to be given to the instrumenting
+// middlewares. The durations are sent in milliseconds.
+func (s *StatsDSink) DurationHistogram() metrics.Histogram {
+	return &statsdTiming{sink: s, suffix: "duration"}
+}
+
+// SendLoop sends the batched metrics to the StatsD server at address every time c ticks, e.g. network
+// "udp" and address "localhost:8125". It blocks, it is meant to run in its own goroutine. The send
+// failures are logged and the batch is dropped.
+func (s *StatsDSink) SendLoop(c <-chan time.Time, network, address string) {
+	s.statsd.SendLoop(c, network, address)
+}
+
+// WriteTo writes the batched metrics in the StatsD format to w, and resets the batch.
+func (s *StatsDSink) WriteTo(w io.Writer) (int64, error) {
+	return s.statsd.WriteTo(w)
+}
+
+func (s *StatsDSink) gauge(name string) metrics.Gauge {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.gauges[name]; !ok {
+		s.gauges[name] = s.statsd.NewGauge(name)
+	}
+	return s.gauges[name]
+}
+
+func (s *StatsDSink) timing(name string) metrics.Histogram {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.timings[name]; !ok {
+		s.timings[name] = s.statsd.NewTiming(name, 1)
+	}
+	return s.timings[name]
+}
+
+// statsdInvalidChars matches the characters that are not allowed in a StatsD metric name segment.
+var statsdInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// statsdName folds the label values into a metric name: the values are joined with ".", followed by the
+// suffix. The labels are key value pairs, the keys are dropped.
+func statsdName(labelValues []string, suffix string) string {
+	var segments = []string{}
+	for i := 1; i < len(labelValues); i += 2 {
+		segments = append(segments, statsdInvalidChars.ReplaceAllString(labelValues[i], "_"))
+	}
+	return strings.Join(append(segments, suffix), ".")
+}
+
+// statsdGauge implements metrics.Gauge.
+type statsdGauge struct {
+	sink        *StatsDSink
+	suffix      string
+	labelValues []string
+}
+
+func (g *statsdGauge) With(labelValues ...string) metrics.Gauge {
+	return &statsdGauge{
+		sink:        g.sink,
+		suffix:      g.suffix,
+		labelValues: append(append([]string{}, g.labelValues...), labelValues...),
+	}
+}
+
+func (g *statsdGauge) Set(value float64) {
+	g.sink.gauge(statsdName(g.labelValues, g.suffix)).Set(value)
+}
+
+func (g *statsdGauge) Add(delta float64) {
+	g.sink.gauge(statsdName(g.labelValues, g.suffix)).Add(delta)
+}
+
+// statsdTiming implements metrics.Histogram.
+type statsdTiming struct {
+	sink        *StatsDSink
+	suffix      string
+	labelValues []string
+}
+
+func (t *statsdTiming) With(labelValues ...string) metrics.Histogram {
+	return &statsdTiming{
+		sink:        t.sink,
+		suffix:      t.suffix,
+		labelValues: append(append([]string{}, t.labelValues...), labelValues...),
+	}
+}
+
+// Observe records the duration value, in seconds.
+func (t *statsdTiming) Observe(value float64) {
+	t.sink.timing(statsdName(t.labelValues, t.suffix)).Observe(value * 1000)
+}
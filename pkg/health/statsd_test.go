@@ -0,0 +1,49 @@
+package health_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsDSink(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedis = mock.NewRedis(mockCtrl)
+	var mockChecks = mock.NewCounter(mockCtrl)
+
+	var sink = NewStatsDSink("flaki.health.", log.NewNopLogger())
+
+	var m = NewRedisShardsModule([]Redis{mockRedis, mockRedis}, true)
+	m = MakeRedisModuleInstrumentingMW(mockChecks, mockChecks, sink.DurationHistogram())(m)
+
+	var c, err = NewComponent(nil, nil, m, nil, StatusGauge(sink.StatusGauge()))
+	assert.Nil(t, err)
+
+	mockRedis.EXPECT().Do("PING").Return(nil, nil).Times(2)
+	mockChecks.EXPECT().With("subsystem", "redis", "check", "ping shard 0", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().With("subsystem", "redis", "check", "ping shard 1", "status", "OK").Return(mockChecks).Times(1)
+	mockChecks.EXPECT().Add(float64(1)).Return().Times(2)
+	c.AllHealthChecks(context.Background())
+
+	var buf bytes.Buffer
+	sink.WriteTo(&buf)
+	var lines = buf.String()
+
+	// The label values are folded into the names.
+	assert.Contains(t, lines, "flaki.health.redis.status:0.000000|g\n")
+	assert.Contains(t, lines, "flaki.health.influx.status:3.000000|g\n")
+	assert.Contains(t, lines, "flaki.health.redis.ping_shard_0.duration:")
+	assert.Contains(t, lines, "flaki.health.redis.ping_shard_1.duration:")
+
+	// The batch is reset once written.
+	buf.Reset()
+	sink.WriteTo(&buf)
+	assert.Zero(t, buf.Len())
+}
@@ -0,0 +1,47 @@
+package health
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// NewMTLSHTTPClient returns a http client that authenticates with the client certificate certFile and
+// its key keyFile, and that trusts the servers signed by the CAs in caFile. If caFile is empty, the
+// system CAs are trusted. Like NewHTTPClient, it does not follow the redirects. The client can be
+// passed to every HTTP-based health module, so they all authenticate to the health endpoints that
+// require client certificates.
+func NewMTLSHTTPClient(certFile, keyFile, caFile string) (*http.Client, error) {
+	var cert, err = tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not load client certificate: %v", err.Error())
+	}
+
+	var config = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if caFile != "" {
+		var pem []byte
+		pem, err = ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA file: %v", err.Error())
+		}
+
+		var pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA file '%s'", caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	var transport = newTransport()
+	transport.TLSClientConfig = config
+
+	return &http.Client{
+		Transport:     transport,
+		CheckRedirect: NoRedirect,
+	}, nil
+}
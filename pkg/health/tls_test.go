@@ -0,0 +1,137 @@
+package health_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMTLSHTTPClient(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	var dir, err = ioutil.TempDir("", "health")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// Client certificate.
+	var certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	var clientCert = writeClientCertificate(t, certFile, keyFile)
+
+	// The server requires a client certificate.
+	var s = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	var clientCAs = x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	s.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	s.StartTLS()
+	defer s.Close()
+
+	// The client trusts the server.
+	var caFile = filepath.Join(dir, "ca.crt")
+	assert.Nil(t, ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw}), 0600))
+
+	var dsn = strings.Replace(s.URL, "https://", "https://a:b@", 1) + "/api/1/store/"
+	mockSentry.EXPECT().URL().Return(dsn).Times(2)
+
+	// Health check with the client certificate.
+	{
+		var client, err = NewMTLSHTTPClient(certFile, keyFile, caFile)
+		assert.Nil(t, err)
+
+		var m = NewSentryModule(mockSentry, client, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, OK, report.Status)
+		assert.Zero(t, report.Error)
+	}
+
+	// Health check without the client certificate.
+	{
+		var roots = x509.NewCertPool()
+		roots.AddCert(s.Certificate())
+		var client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+
+		var m = NewSentryModule(mockSentry, client, true)
+		var report = m.HealthChecks(context.Background())[0]
+		assert.Equal(t, KO, report.Status)
+		assert.NotZero(t, report.Error)
+	}
+}
+
+func TestMTLSHTTPClientInvalid(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "health")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	writeClientCertificate(t, certFile, keyFile)
+
+	// Missing certificate.
+	_, err = NewMTLSHTTPClient(filepath.Join(dir, "missing.crt"), keyFile, "")
+	assert.NotNil(t, err)
+
+	// Missing CA file.
+	_, err = NewMTLSHTTPClient(certFile, keyFile, filepath.Join(dir, "missing.crt"))
+	assert.NotNil(t, err)
+
+	// CA file without certificate.
+	_, err = NewMTLSHTTPClient(certFile, keyFile, keyFile)
+	assert.NotNil(t, err)
+}
+
+// writeClientCertificate writes a self-signed client certificate and its key in PEM files.
+func writeClientCertificate(t *testing.T, certFile, keyFile string) *x509.Certificate {
+	var key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	var template = &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "health"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	var der []byte
+	der, err = x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+
+	var keyDER []byte
+	keyDER, err = x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	assert.Nil(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	var cert *x509.Certificate
+	cert, err = x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return cert
+}
@@ -0,0 +1,794 @@
+package health
+
+//go:generate mockgen -destination=./mock/tracing.go -package=mock -mock_names=Tracer=Tracer,Span=Span,SpanContext=SpanContext github.com/opentracing/opentracing-go Tracer,Span,SpanContext
+
+import (
+	"context"
+	"net/http"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	otag "github.com/opentracing/opentracing-go/ext"
+)
+
+// spanComponent is the value of the component tag of the health spans. It allows to filter the
+// health checks out of the service latency views.
+const spanComponent = "health-check"
+
+// MakeHTTPTracingMW try to extract an existing span from the HTTP headers. It it exists, we
+// continue the span, if not we create a new one.
+func MakeHTTPTracingMW(tracer opentracing.Tracer, operationName string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var sc, err = tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+
+			var span opentracing.Span
+			if err != nil {
+				span = tracer.StartSpan(operationName)
+			} else {
+				span = tracer.StartSpan(operationName, opentracing.ChildOf(sc))
+			}
+			defer span.Finish()
+
+			// Set tags.
+			otag.Component.Set(span, spanComponent)
+			span.SetTag("transport", "http")
+			otag.SpanKindRPCServer.Set(span)
+
+			next.ServeHTTP(w, r.WithContext(opentracing.ContextWithSpan(r.Context(), span)))
+		})
+	}
+}
+
+// startModuleSpan starts the span of the health checks of a subsystem, if there is a span in the context.
+// The operation name is namespaced per subsystem, e.g. health_check_redis.
+func startModuleSpan(ctx context.Context, tracer opentracing.Tracer, subsystem string) (context.Context, opentracing.Span) {
+	var parent = opentracing.SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+
+	var span = tracer.StartSpan("health_check_"+subsystem, opentracing.ChildOf(parent.Context()))
+
+	// Set tags.
+	otag.Component.Set(span, spanComponent)
+	if corrID, ok := ctx.Value("correlation_id").(string); ok {
+		span.SetTag("correlation_id", corrID)
+	}
+
+	return opentracing.ContextWithSpan(ctx, span), span
+}
+
+// TracingOption is an option of the tracing middlewares at module level.
+type TracingOption func(*tracingConfig)
+
+type tracingConfig struct {
+	cachedSpans bool
+}
+
+// CachedSpans emits a span for each cached report, tagged cached=true, e.g. for the influx checks sampled
+// with InfluxCheckInterval. The cached checks are not executed, without it they leave a gap in the traces
+// during the interval. The spans have no duration, they show the pattern of the cache hits.
+func CachedSpans(enabled bool) TracingOption {
+	return func(c *tracingConfig) {
+		c.cachedSpans = enabled
+	}
+}
+
+// traceCached emits a child span of the module span for each cached report, if CachedSpans is enabled.
+func traceCached(tracer opentracing.Tracer, span opentracing.Span, config tracingConfig, subsystem string, reports []Report) {
+	if span == nil || !config.cachedSpans {
+		return
+	}
+
+	for _, r := range reports {
+		if !r.Cached {
+			continue
+		}
+
+		var child = tracer.StartSpan("health_check_"+subsystem+"_"+r.Name, opentracing.ChildOf(span.Context()))
+		otag.Component.Set(child, spanComponent)
+		child.SetTag("cached", true)
+		child.SetTag("status", r.Status.String())
+		child.Finish()
+	}
+}
+
+// Tracing middleware at module level.
+type influxModuleTracingMW struct {
+	tracer opentracing.Tracer
+	config tracingConfig
+	next   InfluxModule
+}
+
+// MakeInfluxModuleTracingMW makes a tracing middleware at module level.
+func MakeInfluxModuleTracingMW(tracer opentracing.Tracer, options ...TracingOption) func(InfluxModule) InfluxModule {
+	var config = tracingConfig{}
+	for _, o := range options {
+		o(&config)
+	}
+
+	return func(next InfluxModule) InfluxModule {
+		return &influxModuleTracingMW{
+			tracer: tracer,
+			config: config,
+			next:   next,
+		}
+	}
+}
+
+// influxModuleTracingMW implements Module.
+func (m *influxModuleTracingMW) HealthChecks(ctx context.Context) []InfluxReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "influx")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	var reports = m.next.HealthChecks(ctx2)
+
+	var hr = []Report{}
+	for _, r := range reports {
+		hr = append(hr, r.Report)
+	}
+	traceCached(m.tracer, span, m.config, "influx", hr)
+
+	return reports
+}
+
+// influxModuleTracingMW implements Enabler.
+func (m *influxModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// influxModuleTracingMW implements Warmer. The warm up is not traced.
+func (m *influxModuleTracingMW) Warmup(ctx context.Context) {
+	warmup(ctx, m.next)
+}
+
+// influxModuleTracingMW implements Validator.
+func (m *influxModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// influxModuleTracingMW implements Resetter. The reset is not traced.
+func (m *influxModuleTracingMW) Reset() {
+	reset(m.next)
+}
+
+// influxModuleTracingMW implements Optioner.
+func (m *influxModuleTracingMW) Options() interface{} {
+	var o, _ = moduleOptions(m.next)
+	return o
+}
+
+// Tracing middleware at module level.
+type jaegerModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   JaegerModule
+}
+
+// MakeJaegerModuleTracingMW makes a tracing middleware at module level.
+func MakeJaegerModuleTracingMW(tracer opentracing.Tracer) func(JaegerModule) JaegerModule {
+	return func(next JaegerModule) JaegerModule {
+		return &jaegerModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// jaegerModuleTracingMW implements Module.
+func (m *jaegerModuleTracingMW) HealthChecks(ctx context.Context) []JaegerReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "jaeger")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// jaegerModuleTracingMW implements Enabler.
+func (m *jaegerModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// jaegerModuleTracingMW implements Warmer. The warm up is not traced.
+func (m *jaegerModuleTracingMW) Warmup(ctx context.Context) {
+	warmup(ctx, m.next)
+}
+
+// jaegerModuleTracingMW implements Validator.
+func (m *jaegerModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Tracing middleware at module level.
+type redisModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   RedisModule
+}
+
+// MakeRedisModuleTracingMW makes a tracing middleware at module level.
+func MakeRedisModuleTracingMW(tracer opentracing.Tracer) func(RedisModule) RedisModule {
+	return func(next RedisModule) RedisModule {
+		return &redisModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// redisModuleTracingMW implements Module.
+func (m *redisModuleTracingMW) HealthChecks(ctx context.Context) []RedisReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "redis")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// redisModuleTracingMW implements Enabler.
+func (m *redisModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// redisModuleTracingMW implements Warmer. The warm up is not traced.
+func (m *redisModuleTracingMW) Warmup(ctx context.Context) {
+	warmup(ctx, m.next)
+}
+
+// redisModuleTracingMW implements Validator.
+func (m *redisModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// redisModuleTracingMW implements ModuleAggregator.
+func (m *redisModuleTracingMW) Aggregator() Aggregator {
+	return moduleAggregator(m.next)
+}
+
+// Tracing middleware at module level.
+type sentryModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   SentryModule
+}
+
+// MakeSentryModuleTracingMW makes a tracing middleware at module level.
+func MakeSentryModuleTracingMW(tracer opentracing.Tracer) func(SentryModule) SentryModule {
+	return func(next SentryModule) SentryModule {
+		return &sentryModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// sentryModuleTracingMW implements Module.
+func (m *sentryModuleTracingMW) HealthChecks(ctx context.Context) []SentryReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "sentry")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// sentryModuleTracingMW implements Enabler.
+func (m *sentryModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// sentryModuleTracingMW implements Warmer. The warm up is not traced.
+func (m *sentryModuleTracingMW) Warmup(ctx context.Context) {
+	warmup(ctx, m.next)
+}
+
+// sentryModuleTracingMW implements Resetter. The reset is not traced.
+func (m *sentryModuleTracingMW) Reset() {
+	reset(m.next)
+}
+
+// sentryModuleTracingMW implements Optioner.
+func (m *sentryModuleTracingMW) Options() interface{} {
+	var o, _ = moduleOptions(m.next)
+	return o
+}
+
+// sentryModuleTracingMW implements Validator.
+func (m *sentryModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Tracing middleware at module level.
+type smtpModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   SMTPModule
+}
+
+// MakeSMTPModuleTracingMW makes a tracing middleware at module level.
+func MakeSMTPModuleTracingMW(tracer opentracing.Tracer) func(SMTPModule) SMTPModule {
+	return func(next SMTPModule) SMTPModule {
+		return &smtpModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// smtpModuleTracingMW implements Module.
+func (m *smtpModuleTracingMW) HealthChecks(ctx context.Context) []SMTPReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "smtp")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// smtpModuleTracingMW implements Enabler.
+func (m *smtpModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// smtpModuleTracingMW implements Validator.
+func (m *smtpModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Tracing middleware at module level.
+type dockerModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   DockerModule
+}
+
+// MakeDockerModuleTracingMW makes a tracing middleware at module level.
+func MakeDockerModuleTracingMW(tracer opentracing.Tracer) func(DockerModule) DockerModule {
+	return func(next DockerModule) DockerModule {
+		return &dockerModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// dockerModuleTracingMW implements Module.
+func (m *dockerModuleTracingMW) HealthChecks(ctx context.Context) []DockerReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "docker")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// dockerModuleTracingMW implements Enabler.
+func (m *dockerModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// Tracing middleware at module level.
+type logSinkModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   LogSinkModule
+}
+
+// MakeLogSinkModuleTracingMW makes a tracing middleware at module level.
+func MakeLogSinkModuleTracingMW(tracer opentracing.Tracer) func(LogSinkModule) LogSinkModule {
+	return func(next LogSinkModule) LogSinkModule {
+		return &logSinkModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// logSinkModuleTracingMW implements Module.
+func (m *logSinkModuleTracingMW) HealthChecks(ctx context.Context) []LogSinkReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "logsink")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// logSinkModuleTracingMW implements Enabler.
+func (m *logSinkModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// Tracing middleware at module level.
+type e2eModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   E2EModule
+}
+
+// MakeE2EModuleTracingMW makes a tracing middleware at module level.
+func MakeE2EModuleTracingMW(tracer opentracing.Tracer) func(E2EModule) E2EModule {
+	return func(next E2EModule) E2EModule {
+		return &e2eModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// e2eModuleTracingMW implements Module.
+func (m *e2eModuleTracingMW) HealthChecks(ctx context.Context) []E2EReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "e2e")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// e2eModuleTracingMW implements Enabler.
+func (m *e2eModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// Tracing middleware at module level.
+type grpcReflectionModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   GRPCReflectionModule
+}
+
+// MakeGRPCReflectionModuleTracingMW makes a tracing middleware at module level.
+func MakeGRPCReflectionModuleTracingMW(tracer opentracing.Tracer) func(GRPCReflectionModule) GRPCReflectionModule {
+	return func(next GRPCReflectionModule) GRPCReflectionModule {
+		return &grpcReflectionModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// grpcReflectionModuleTracingMW implements Module.
+func (m *grpcReflectionModuleTracingMW) HealthChecks(ctx context.Context) []GRPCReflectionReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "grpc")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// grpcReflectionModuleTracingMW implements Enabler.
+func (m *grpcReflectionModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// Tracing middleware at module level.
+type egressModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   EgressModule
+}
+
+// MakeEgressModuleTracingMW makes a tracing middleware at module level.
+func MakeEgressModuleTracingMW(tracer opentracing.Tracer) func(EgressModule) EgressModule {
+	return func(next EgressModule) EgressModule {
+		return &egressModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// egressModuleTracingMW implements Module.
+func (m *egressModuleTracingMW) HealthChecks(ctx context.Context) []EgressReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "egress")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// egressModuleTracingMW implements Enabler.
+func (m *egressModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// egressModuleTracingMW implements Validator.
+func (m *egressModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Tracing middleware at module level.
+type fdModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   FDModule
+}
+
+// MakeFDModuleTracingMW makes a tracing middleware at module level.
+func MakeFDModuleTracingMW(tracer opentracing.Tracer) func(FDModule) FDModule {
+	return func(next FDModule) FDModule {
+		return &fdModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// fdModuleTracingMW implements Module.
+func (m *fdModuleTracingMW) HealthChecks(ctx context.Context) []FDReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "fd")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// fdModuleTracingMW implements Enabler.
+func (m *fdModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// fdModuleTracingMW implements Validator.
+func (m *fdModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Tracing middleware at module level.
+type idGenModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   IDGenModule
+}
+
+// MakeIDGenModuleTracingMW makes a tracing middleware at module level.
+func MakeIDGenModuleTracingMW(tracer opentracing.Tracer) func(IDGenModule) IDGenModule {
+	return func(next IDGenModule) IDGenModule {
+		return &idGenModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// idGenModuleTracingMW implements Module.
+func (m *idGenModuleTracingMW) HealthChecks(ctx context.Context) []IDGenReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "idgen")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// idGenModuleTracingMW implements Enabler.
+func (m *idGenModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// idGenModuleTracingMW implements Validator.
+func (m *idGenModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Tracing middleware at module level.
+type featureFlagModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   FeatureFlagModule
+}
+
+// MakeFeatureFlagModuleTracingMW makes a tracing middleware at module level.
+func MakeFeatureFlagModuleTracingMW(tracer opentracing.Tracer) func(FeatureFlagModule) FeatureFlagModule {
+	return func(next FeatureFlagModule) FeatureFlagModule {
+		return &featureFlagModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// featureFlagModuleTracingMW implements Module.
+func (m *featureFlagModuleTracingMW) HealthChecks(ctx context.Context) []FeatureFlagReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "featureflag")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// featureFlagModuleTracingMW implements Enabler.
+func (m *featureFlagModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// featureFlagModuleTracingMW implements Validator.
+func (m *featureFlagModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Tracing middleware at module level.
+type replicaModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   ReplicaModule
+}
+
+// MakeReplicaModuleTracingMW makes a tracing middleware at module level.
+func MakeReplicaModuleTracingMW(tracer opentracing.Tracer) func(ReplicaModule) ReplicaModule {
+	return func(next ReplicaModule) ReplicaModule {
+		return &replicaModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// replicaModuleTracingMW implements Module.
+func (m *replicaModuleTracingMW) HealthChecks(ctx context.Context) []ReplicaReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "replica")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// replicaModuleTracingMW implements Enabler.
+func (m *replicaModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// replicaModuleTracingMW implements Validator.
+func (m *replicaModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Tracing middleware at module level.
+type commandModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   CommandModule
+}
+
+// MakeCommandModuleTracingMW makes a tracing middleware at module level.
+func MakeCommandModuleTracingMW(tracer opentracing.Tracer) func(CommandModule) CommandModule {
+	return func(next CommandModule) CommandModule {
+		return &commandModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// commandModuleTracingMW implements Module.
+func (m *commandModuleTracingMW) HealthChecks(ctx context.Context) []CommandReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "command")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// commandModuleTracingMW implements Enabler.
+func (m *commandModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// commandModuleTracingMW implements Validator.
+func (m *commandModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Tracing middleware at module level.
+type selfModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   SelfModule
+}
+
+// MakeSelfModuleTracingMW makes a tracing middleware at module level.
+func MakeSelfModuleTracingMW(tracer opentracing.Tracer) func(SelfModule) SelfModule {
+	return func(next SelfModule) SelfModule {
+		return &selfModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// selfModuleTracingMW implements Module.
+func (m *selfModuleTracingMW) HealthChecks(ctx context.Context) []SelfReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "self")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// selfModuleTracingMW implements Enabler.
+func (m *selfModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// selfModuleTracingMW implements Validator.
+func (m *selfModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Tracing middleware at module level.
+type auditLogModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   AuditLogModule
+}
+
+// MakeAuditLogModuleTracingMW makes a tracing middleware at module level.
+func MakeAuditLogModuleTracingMW(tracer opentracing.Tracer) func(AuditLogModule) AuditLogModule {
+	return func(next AuditLogModule) AuditLogModule {
+		return &auditLogModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// auditLogModuleTracingMW implements Module.
+func (m *auditLogModuleTracingMW) HealthChecks(ctx context.Context) []AuditLogReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "auditlog")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// auditLogModuleTracingMW implements Enabler.
+func (m *auditLogModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// auditLogModuleTracingMW implements Validator.
+func (m *auditLogModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
+
+// Tracing middleware at module level.
+type compositeModuleTracingMW struct {
+	tracer opentracing.Tracer
+	next   CompositeModule
+}
+
+// MakeCompositeModuleTracingMW makes a tracing middleware at module level.
+func MakeCompositeModuleTracingMW(tracer opentracing.Tracer) func(CompositeModule) CompositeModule {
+	return func(next CompositeModule) CompositeModule {
+		return &compositeModuleTracingMW{
+			tracer: tracer,
+			next:   next,
+		}
+	}
+}
+
+// compositeModuleTracingMW implements Module.
+func (m *compositeModuleTracingMW) HealthChecks(ctx context.Context) []CompositeReport {
+	var ctx2, span = startModuleSpan(ctx, m.tracer, "composite")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	return m.next.HealthChecks(ctx2)
+}
+
+// compositeModuleTracingMW implements Enabler.
+func (m *compositeModuleTracingMW) Enabled() bool {
+	return isEnabled(m.next)
+}
+
+// compositeModuleTracingMW implements Module. The start is not traced.
+func (m *compositeModuleTracingMW) Start(ctx context.Context) error {
+	return m.next.Start(ctx)
+}
+
+// compositeModuleTracingMW implements Module. The stop is not traced.
+func (m *compositeModuleTracingMW) Stop() error {
+	return m.next.Stop()
+}
+
+// compositeModuleTracingMW implements Validator.
+func (m *compositeModuleTracingMW) Validate() error {
+	return validate(m.next)
+}
@@ -0,0 +1,559 @@
+package health_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/golang/mock/gomock"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestHTTPTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeHTTPTracingMW(mockTracer, "operationName")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// HTTP request.
+	var req = httptest.NewRequest("GET", "http://cloudtrust.io/health", nil)
+	var w = httptest.NewRecorder()
+
+	// With existing tracer.
+	mockTracer.EXPECT().Extract(opentracing.HTTPHeaders, gomock.Any()).Return(mockSpanContext, nil).Times(1)
+	mockTracer.EXPECT().StartSpan("operationName", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag(gomock.Any(), gomock.Any()).Return(mockSpan).Times(2)
+	m.ServeHTTP(w, req)
+
+	// Without existing tracer.
+	mockTracer.EXPECT().Extract(opentracing.HTTPHeaders, gomock.Any()).Return(nil, fmt.Errorf("fail")).Times(1)
+	mockTracer.EXPECT().StartSpan("operationName").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag(gomock.Any(), gomock.Any()).Return(mockSpan).Times(2)
+	m.ServeHTTP(w, req)
+}
+
+func TestInfluxModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeInfluxModuleTracingMW(mockTracer)(mockInfluxModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockInfluxModule.EXPECT().HealthChecks(gomock.Any()).Return([]InfluxReport{{Report: Report{Name: "influx", Status: OK}}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_influx", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockInfluxModule.EXPECT().HealthChecks(ctx).Return([]InfluxReport{{Report: Report{Name: "influx", Status: OK}}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestInfluxModuleTracingMWCachedSpans(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockInfluxModule = mock.NewInfluxModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockCachedSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeInfluxModuleTracingMW(mockTracer, CachedSpans(true))(mockInfluxModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// A span is emitted for the cached write check only.
+	mockInfluxModule.EXPECT().HealthChecks(gomock.Any()).Return([]InfluxReport{{Report: Report{Name: "ping", Status: OK}}, {Report: Report{Name: "write", Status: OK, Cached: true}}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_influx", gomock.Any()).Return(mockSpan).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_influx_write", gomock.Any()).Return(mockCachedSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(2)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	mockCachedSpan.EXPECT().SetTag("component", "health-check").Return(mockCachedSpan).Times(1)
+	mockCachedSpan.EXPECT().SetTag("cached", true).Return(mockCachedSpan).Times(1)
+	mockCachedSpan.EXPECT().SetTag("status", "OK").Return(mockCachedSpan).Times(1)
+	mockCachedSpan.EXPECT().Finish().Return().Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+}
+
+func TestJaegerModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockJaegerModule = mock.NewJaegerModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeJaegerModuleTracingMW(mockTracer)(mockJaegerModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockJaegerModule.EXPECT().HealthChecks(gomock.Any()).Return([]JaegerReport{{Name: "jaeger", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_jaeger", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockJaegerModule.EXPECT().HealthChecks(ctx).Return([]JaegerReport{{Name: "jaeger", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestRedisModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockRedisModule = mock.NewRedisModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeRedisModuleTracingMW(mockTracer)(mockRedisModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockRedisModule.EXPECT().HealthChecks(gomock.Any()).Return([]RedisReport{{Report: Report{Name: "redis", Status: OK}}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_redis", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockRedisModule.EXPECT().HealthChecks(ctx).Return([]RedisReport{{Report: Report{Name: "redis", Status: OK}}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestSentryModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentryModule = mock.NewSentryModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeSentryModuleTracingMW(mockTracer)(mockSentryModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockSentryModule.EXPECT().HealthChecks(gomock.Any()).Return([]SentryReport{{Name: "sentry", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_sentry", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockSentryModule.EXPECT().HealthChecks(ctx).Return([]SentryReport{{Name: "sentry", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestSMTPModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSMTPModule = mock.NewSMTPModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeSMTPModuleTracingMW(mockTracer)(mockSMTPModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockSMTPModule.EXPECT().HealthChecks(gomock.Any()).Return([]SMTPReport{{Name: "smtp", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_smtp", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockSMTPModule.EXPECT().HealthChecks(ctx).Return([]SMTPReport{{Name: "smtp", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestDockerModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockDockerModule = mock.NewDockerModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeDockerModuleTracingMW(mockTracer)(mockDockerModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockDockerModule.EXPECT().HealthChecks(gomock.Any()).Return([]DockerReport{{Name: "docker", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_docker", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockDockerModule.EXPECT().HealthChecks(ctx).Return([]DockerReport{{Name: "docker", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestLogSinkModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockLogSinkModule = mock.NewLogSinkModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeLogSinkModuleTracingMW(mockTracer)(mockLogSinkModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockLogSinkModule.EXPECT().HealthChecks(gomock.Any()).Return([]LogSinkReport{{Name: "logsink", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_logsink", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockLogSinkModule.EXPECT().HealthChecks(ctx).Return([]LogSinkReport{{Name: "logsink", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestE2EModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockE2EModule = mock.NewE2EModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeE2EModuleTracingMW(mockTracer)(mockE2EModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockE2EModule.EXPECT().HealthChecks(gomock.Any()).Return([]E2EReport{{Name: "e2e", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_e2e", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockE2EModule.EXPECT().HealthChecks(ctx).Return([]E2EReport{{Name: "e2e", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestGRPCReflectionModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockGRPCReflectionModule = mock.NewGRPCReflectionModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeGRPCReflectionModuleTracingMW(mockTracer)(mockGRPCReflectionModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockGRPCReflectionModule.EXPECT().HealthChecks(gomock.Any()).Return([]GRPCReflectionReport{{Name: "grpc", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_grpc", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockGRPCReflectionModule.EXPECT().HealthChecks(ctx).Return([]GRPCReflectionReport{{Name: "grpc", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestEgressModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockEgressModule = mock.NewEgressModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeEgressModuleTracingMW(mockTracer)(mockEgressModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockEgressModule.EXPECT().HealthChecks(gomock.Any()).Return([]EgressReport{{Name: "egress", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_egress", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockEgressModule.EXPECT().HealthChecks(ctx).Return([]EgressReport{{Name: "egress", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestFDModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockFDModule = mock.NewFDModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeFDModuleTracingMW(mockTracer)(mockFDModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockFDModule.EXPECT().HealthChecks(gomock.Any()).Return([]FDReport{{Name: "fd", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_fd", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockFDModule.EXPECT().HealthChecks(ctx).Return([]FDReport{{Name: "fd", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestIDGenModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockIDGenModule = mock.NewIDGenModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeIDGenModuleTracingMW(mockTracer)(mockIDGenModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockIDGenModule.EXPECT().HealthChecks(gomock.Any()).Return([]IDGenReport{{Name: "idgen", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_idgen", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockIDGenModule.EXPECT().HealthChecks(ctx).Return([]IDGenReport{{Name: "idgen", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestFeatureFlagModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockFeatureFlagModule = mock.NewFeatureFlagModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeFeatureFlagModuleTracingMW(mockTracer)(mockFeatureFlagModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockFeatureFlagModule.EXPECT().HealthChecks(gomock.Any()).Return([]FeatureFlagReport{{Name: "featureflag", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_featureflag", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockFeatureFlagModule.EXPECT().HealthChecks(ctx).Return([]FeatureFlagReport{{Name: "featureflag", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestReplicaModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockReplicaModule = mock.NewReplicaModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeReplicaModuleTracingMW(mockTracer)(mockReplicaModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockReplicaModule.EXPECT().HealthChecks(gomock.Any()).Return([]ReplicaReport{{Name: "replica", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_replica", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockReplicaModule.EXPECT().HealthChecks(ctx).Return([]ReplicaReport{{Name: "replica", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestCommandModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockCommandModule = mock.NewCommandModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeCommandModuleTracingMW(mockTracer)(mockCommandModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockCommandModule.EXPECT().HealthChecks(gomock.Any()).Return([]CommandReport{{Name: "command", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_command", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockCommandModule.EXPECT().HealthChecks(ctx).Return([]CommandReport{{Name: "command", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestSelfModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSelfModule = mock.NewSelfModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeSelfModuleTracingMW(mockTracer)(mockSelfModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockSelfModule.EXPECT().HealthChecks(gomock.Any()).Return([]SelfReport{{Name: "self", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_self", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockSelfModule.EXPECT().HealthChecks(ctx).Return([]SelfReport{{Name: "self", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestAuditLogModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockAuditLogModule = mock.NewAuditLogModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeAuditLogModuleTracingMW(mockTracer)(mockAuditLogModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockAuditLogModule.EXPECT().HealthChecks(gomock.Any()).Return([]AuditLogReport{{Name: "auditlog", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_auditlog", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockAuditLogModule.EXPECT().HealthChecks(ctx).Return([]AuditLogReport{{Name: "auditlog", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
+
+func TestCompositeModuleTracingMW(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockCompositeModule = mock.NewCompositeModule(mockCtrl)
+	var mockTracer = mock.NewTracer(mockCtrl)
+	var mockSpan = mock.NewSpan(mockCtrl)
+	var mockSpanContext = mock.NewSpanContext(mockCtrl)
+
+	var m = MakeCompositeModuleTracingMW(mockTracer)(mockCompositeModule)
+
+	var corrID = "corrID"
+	var ctx = context.WithValue(context.Background(), "correlation_id", corrID)
+
+	// With existing span.
+	mockCompositeModule.EXPECT().HealthChecks(gomock.Any()).Return([]CompositeReport{{Name: "composite", Status: OK}}).Times(1)
+	mockTracer.EXPECT().StartSpan("health_check_composite", gomock.Any()).Return(mockSpan).Times(1)
+	mockSpan.EXPECT().Context().Return(mockSpanContext).Times(1)
+	mockSpan.EXPECT().Finish().Return().Times(1)
+	mockSpan.EXPECT().SetTag("component", "health-check").Return(mockSpan).Times(1)
+	mockSpan.EXPECT().SetTag("correlation_id", corrID).Return(mockSpan).Times(1)
+	m.HealthChecks(opentracing.ContextWithSpan(ctx, mockSpan))
+
+	// Without existing span.
+	mockCompositeModule.EXPECT().HealthChecks(ctx).Return([]CompositeReport{{Name: "composite", Status: OK}}).Times(1)
+	m.HealthChecks(ctx)
+}
@@ -0,0 +1,239 @@
+package health
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Validator is implemented by the health modules that can detect an invalid configuration, e.g. a malformed
+// URL. NewComponent rejects the modules whose configuration is invalid. It is optional, the modules without
+// it are not validated, nor are the disabled modules. A missing value is not invalid: the checks that need
+// it are Deactivated with the error "not configured".
+type Validator interface {
+	Validate() error
+}
+
+// notConfiguredError is the error of the checks whose required configuration is missing.
+const notConfiguredError = "not configured"
+
+// validate validates the configuration of m if it implements Validator. The middlewares use it to forward
+// the call to the module they wrap.
+func validate(m interface{}) error {
+	if v, ok := m.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// validateURL returns an error if u is not empty and is not an absolute http or https URL.
+func validateURL(u string) error {
+	if u == "" {
+		return nil
+	}
+
+	var parsed, err = url.Parse(u)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("url '%s' must be http or https", u)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("url '%s' has no host", u)
+	}
+	return nil
+}
+
+// Validate checks that the minimum number of healthy shards is between 1 and the number of shards.
+func (m *redisModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+	if m.minHealthy < 1 || m.minHealthy > len(m.shards) {
+		return fmt.Errorf("redis minimum healthy shards must be between 1 and %d, got %d", len(m.shards), m.minHealthy)
+	}
+	return nil
+}
+
+// Validate checks the URLs of the sentry projects.
+func (m *sentryModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+	for _, project := range m.projects {
+		if err := validateURL(project.URL()); err != nil {
+			return fmt.Errorf("invalid sentry url: %v", err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the collector address, it is a host and a port, without scheme, and the URL of the
+// sampling server.
+func (m *jaegerModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+
+	if m.collectorHealthCheckURL != "" {
+		if err := validateURL("http://" + m.collectorHealthCheckURL); err != nil {
+			return fmt.Errorf("invalid jaeger collector url: %v", err)
+		}
+	}
+	if m.sampling != nil {
+		if err := validateURL(m.sampling.serverURL); err != nil {
+			return fmt.Errorf("invalid jaeger sampling server url: %v", err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the URL of the Influx DB 2.x.
+func (m *influxModule) Validate() error {
+	if !m.enabled || m.v2 == nil {
+		return nil
+	}
+	if err := validateURL(m.v2.addr); err != nil {
+		return fmt.Errorf("invalid influx url: %v", err)
+	}
+	return nil
+}
+
+// Validate checks the address of the SMTP server.
+func (m *smtpModule) Validate() error {
+	if !m.enabled || m.addr == "" {
+		return nil
+	}
+
+	if _, _, err := net.SplitHostPort(m.addr); err != nil {
+		return fmt.Errorf("invalid smtp address: %v", err)
+	}
+	return nil
+}
+
+// Validate checks the canary URL.
+func (m *egressModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+	if err := validateURL(m.canaryURL); err != nil {
+		return fmt.Errorf("invalid egress canary url: %v", err)
+	}
+	return nil
+}
+
+// Validate checks that the thresholds are fractions of the limit, and that warn is not above crit.
+func (m *fdModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+	switch {
+	case m.warn <= 0 || m.warn > 1, m.crit <= 0 || m.crit > 1:
+		return fmt.Errorf("file descriptors thresholds must be in (0, 1], got %v and %v", m.warn, m.crit)
+	case m.warn > m.crit:
+		return fmt.Errorf("file descriptors warning threshold %v is above the critical threshold %v", m.warn, m.crit)
+	}
+	return nil
+}
+
+// Validate checks the number of bits of the timestamps, the horizon and the clock check.
+func (m *idGenModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+	switch {
+	case m.bits == 0 || m.bits > 62:
+		return fmt.Errorf("ID timestamp bits must be between 1 and 62, got %d", m.bits)
+	case m.horizon < 0:
+		return fmt.Errorf("ID overflow horizon must not be negative, got %v", m.horizon)
+	case m.clock != nil && m.clock.clock == nil:
+		return fmt.Errorf("ID clock check has no clock")
+	case m.clock != nil && m.clock.maxDrift <= 0:
+		return fmt.Errorf("ID clock max drift must be positive, got %v", m.clock.maxDrift)
+	}
+	return nil
+}
+
+// Validate checks the URL of the feature flag service.
+func (m *featureFlagModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+	if err := validateURL(m.url); err != nil {
+		return fmt.Errorf("invalid feature flag url: %v", err)
+	}
+	return nil
+}
+
+// Validate checks the SQL dialect and that warn is not above crit.
+func (m *replicaModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+	switch {
+	case m.db == nil:
+		return fmt.Errorf("replica database must not be nil")
+	case m.dialect != "postgres" && m.dialect != "mysql":
+		return fmt.Errorf("replica sql dialect must be postgres or mysql, got '%s'", m.dialect)
+	case m.warn > m.crit:
+		return fmt.Errorf("replica lag warning threshold %v is above the critical threshold %v", m.warn, m.crit)
+	}
+	return nil
+}
+
+// Validate checks that the command has a name and an executable.
+func (m *commandModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+	switch {
+	case m.name == "":
+		return fmt.Errorf("command health check name must not be empty")
+	case len(m.argv) == 0 || m.argv[0] == "":
+		return fmt.Errorf("command health check '%s' has no executable", m.name)
+	}
+	return nil
+}
+
+// Validate checks the listen address and the path of the request check.
+func (m *selfModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+	if _, err := selfAddress(m.addr); err != nil {
+		return fmt.Errorf("self listen address '%s' is invalid: %v", m.addr, err)
+	}
+	if m.request != nil && !strings.HasPrefix(m.request.path, "/") {
+		return fmt.Errorf("self request path must start with '/', got '%s'", m.request.path)
+	}
+	return nil
+}
+
+// Validate checks that the retention is not negative.
+func (m *auditLogModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+	if m.minRetention < 0 {
+		return fmt.Errorf("audit log retention must not be negative, got %v", m.minRetention)
+	}
+	return nil
+}
+
+// Validate checks that there is a shared resource, and that each check has a name and a function.
+func (m *compositeModule) Validate() error {
+	if !m.enabled {
+		return nil
+	}
+	if m.resource == nil {
+		return fmt.Errorf("composite shared resource must not be nil")
+	}
+	for i, c := range m.checks {
+		if c.Name == "" || c.Check == nil {
+			return fmt.Errorf("composite check %d must have a name and a function", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/cloudtrust/flaki-service/pkg/health"
+	"github.com/cloudtrust/flaki-service/pkg/health/mock"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+
+	// Valid.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil,
+			WithEgressModule(NewEgressModule("https://www.google.com/generate_204", NewHTTPClient(), true)),
+			WithSMTPModule(NewSMTPModule("smtp.example.com:25", false, true)),
+			WithFDModule(NewFDModule(0.8, 0.95, true)),
+			WithIDGenModule(NewIDGenModule(time.Now(), 41, time.Hour, true)),
+		)
+		assert.Nil(t, err)
+		assert.NotNil(t, c)
+	}
+
+	// Invalid, the middlewares forward the validation.
+	{
+		var egressModule = NewEgressModule("ftp://canary.example.com", NewHTTPClient(), true)
+		egressModule = MakeEgressModuleLoggingMW(log.NewNopLogger())(egressModule)
+		egressModule = MakeEgressModuleTracingMW(nil)(egressModule)
+
+		var c, err = NewComponent(nil, nil, nil, nil, WithEgressModule(egressModule))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "egress health module: invalid egress canary url")
+		assert.Nil(t, c)
+	}
+	{
+		mockSentry.EXPECT().URL().Return("sentry.example.com").Times(1)
+		var c, err = NewComponent(nil, nil, nil, NewSentryModule(mockSentry, NewHTTPClient(), true))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithSMTPModule(NewSMTPModule("smtp.example.com", false, true)))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+	for _, ratios := range [][2]float64{{0.95, 0.8}, {0, 0.8}, {0.8, 1.5}} {
+		var c, err = NewComponent(nil, nil, nil, nil, WithFDModule(NewFDModule(ratios[0], ratios[1], true)))
+		assert.NotNil(t, err, "%v", ratios)
+		assert.Nil(t, c)
+	}
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithIDGenModule(NewIDGenModule(time.Now(), 64, time.Hour, true)))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithIDGenModule(NewIDGenModule(time.Now(), 41, time.Hour, true, IDGenClockCheck(NewIDGenSystemClock(), 0))))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithReplicaModule(NewReplicaModule(nil, "oracle", time.Second, time.Minute, true)))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithCommandModule(NewCommandModule("script", nil, true)))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithSelfModule(NewSelfModule("localhost", true)))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithAuditLogModule(NewAuditLogModule("/var/log/audit", -time.Hour, true)))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithCompositeModule(NewCompositeModule(mock.NewCompositeResource(mockCtrl), []CompositeCheck{{Name: "ping"}}, true)))
+		assert.NotNil(t, err)
+		assert.Nil(t, c)
+	}
+
+	{
+		var redisModule = NewRedisShardsModule([]Redis{mock.NewRedis(mockCtrl), mock.NewRedis(mockCtrl)}, true, RedisMinHealthyShards(3))
+		var c, err = NewComponent(nil, nil, redisModule, nil)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "redis health module: redis minimum healthy shards must be between 1 and 2, got 3")
+		assert.Nil(t, c)
+	}
+	for _, min := range []int{-1, 0, 2} {
+		var redisModule = NewRedisModule(mock.NewRedis(mockCtrl), true, RedisMinHealthyShards(min))
+		var c, err = NewComponent(nil, nil, redisModule, nil)
+		assert.NotNil(t, err, "%d", min)
+		assert.Nil(t, c)
+	}
+
+	// The disabled modules are not validated.
+	{
+		var c, err = NewComponent(nil, nil, nil, nil, WithFDModule(NewFDModule(0.95, 0.8, false)))
+		assert.Nil(t, err)
+		assert.NotNil(t, c)
+	}
+}
+
+func TestNotConfigured(t *testing.T) {
+	var mockCtrl = gomock.NewController(t)
+	defer mockCtrl.Finish()
+	var mockSentry = mock.NewSentry(mockCtrl)
+	var mockHTTPClient = mock.NewEgressHTTPClient(mockCtrl)
+
+	// The empty values are not invalid, and no request is sent.
+	mockSentry.EXPECT().URL().Return("").Times(2)
+	var c, err = NewComponent(nil, nil, nil, NewSentryModule(mockSentry, mockHTTPClient, true),
+		WithEgressModule(NewEgressModule("", mockHTTPClient, true)),
+		WithSMTPModule(NewSMTPModule("", false, true)),
+	)
+	assert.Nil(t, err)
+
+	for _, reports := range []Reports{
+		c.SentryHealthChecks(context.Background()),
+		c.EgressHealthChecks(context.Background()),
+		c.SMTPHealthChecks(context.Background()),
+	} {
+		assert.Equal(t, Deactivated, reports.Reports[0].Status)
+		assert.Equal(t, "not configured", reports.Reports[0].Error)
+	}
+}